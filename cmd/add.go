@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/catalog"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/feature"
+	"github.com/mochlast/devcontainer-companion/internal/marketplace"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+var featureOptions []string
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add items to devcontainer.json without opening the hub",
+}
+
+var addFeatureCmd = &cobra.Command{
+	Use:         "feature <oci-ref>",
+	Annotations: composesConfig,
+	Short:       "Add a feature to devcontainer.json",
+	Long: `Add a feature to devcontainer.json without opening the hub.
+
+The feature can be given as a full OCI reference or as a short name from the
+containers.dev catalog, e.g. "node" or "devcontainers/node". Short names
+prefer official features; an ambiguous name fails with the candidates.
+
+Options are validated against the feature's published metadata. Existing
+features are kept; if the feature is already configured, its options are
+merged with the given ones.`,
+	Example: `  dcc add feature ghcr.io/devcontainers/features/node:1 --option version=lts
+  dcc add feature node --option version=lts`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := resolveWorkspace()
+		if err != nil {
+			return err
+		}
+
+		opts, err := parseOptionFlags(featureOptions)
+		if err != nil {
+			return err
+		}
+		ociRef, err := resolveCatalogRef(args[0], catalog.GetFeatures)
+		if err != nil {
+			return fmt.Errorf("resolving feature: %w", err)
+		}
+
+		_, featDef, err := registry.FetchItemMetadata(ociRef, noCache)
+		if err != nil {
+			return fmt.Errorf("resolving feature %s: %w", ociRef, err)
+		}
+		if featDef == nil {
+			return fmt.Errorf("%s is not a feature", ociRef)
+		}
+		if err := validateOptions(ociRef, featDef.Options, opts); err != nil {
+			return err
+		}
+
+		if existing := configuredFeatureRef(absFolder, ociRef); existing != "" && existing != ociRef {
+			ok, err := confirmReplaceFeature(cmd.ErrOrStderr(), existing, ociRef)
+			if err != nil || !ok {
+				return err
+			}
+		}
+
+		if err := feature.Add(absFolder, feature.FeatureConfig{OciRef: ociRef, Options: opts}); err != nil {
+			return fmt.Errorf("adding feature: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Added %s\n", ociRef)
+		return nil
+	},
+}
+
+var addExtensionCmd = &cobra.Command{
+	Use:         "extension <id>",
+	Annotations: composesConfig,
+	Short:       "Add a VS Code extension to devcontainer.json",
+	Long:        `Add a VS Code extension to customizations.vscode.extensions without opening the hub.`,
+	Example:     "  dcc add extension ms-python.python",
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := resolveWorkspace()
+		if err != nil {
+			return err
+		}
+
+		id := strings.TrimSpace(args[0])
+		if err := validateExtensionID(id); err != nil {
+			return err
+		}
+
+		existing, err := extractStringSlice(absFolder, vscodeExtensions)
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(existing, isExtension(id)) {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is already configured\n", id)
+			return nil
+		}
+		// Keep a custom (reordered) list as is; otherwise stay alphabetical.
+		updated := append(existing, id)
+		if sort.StringsAreSorted(existing) {
+			sort.Strings(updated)
+		}
+
+		if err := writeCustomizationList(absFolder, updated, vscodeExtensions); err != nil {
+			return fmt.Errorf("adding extension: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Added %s\n", id)
+		return nil
+	},
+}
+
+func init() {
+	addFeatureCmd.Flags().StringArrayVar(&featureOptions, "option", nil, "feature option as KEY=VALUE (repeatable)")
+	addCmd.AddCommand(addFeatureCmd)
+	addCmd.AddCommand(addExtensionCmd)
+	rootCmd.AddCommand(addCmd)
+}
+
+// confirmReplaceFeature asks before the feature configured as existing is
+// replaced by ociRef, at another version. Scripts without a terminal aren't
+// blocked: the feature is replaced and a notice is written to notices.
+func confirmReplaceFeature(notices io.Writer, existing, ociRef string) (bool, error) {
+	if !assumeYes && !stdinIsTerminal() {
+		fmt.Fprintf(notices, "Replacing %s with %s, keeping its options\n", existing, ociRef)
+		return true, nil
+	}
+	return confirm(fmt.Sprintf("Replace %s with %s?", existing, ociRef), "Its options are kept and merged with the given ones.")
+}
+
+// configuredFeatureRef returns the configured ref of the feature ociRef
+// refers to, which may pin another version, or "" if it isn't configured.
+func configuredFeatureRef(absFolder, ociRef string) string {
+	config, _, err := devcontainer.ReadConfig(absFolder)
+	if err != nil {
+		return ""
+	}
+	feats, _ := config["features"].(map[string]any)
+	for ref := range feats {
+		if devcontainer.FeatureID(ref) == devcontainer.FeatureID(ociRef) {
+			return ref
+		}
+	}
+	return ""
+}
+
+// parseOptionFlags converts repeated KEY=VALUE flags into an options map.
+func parseOptionFlags(flags []string) (map[string]any, error) {
+	opts := make(map[string]any)
+	for _, f := range flags {
+		key, val, ok := strings.Cut(f, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid option %q, expected KEY=VALUE", f)
+		}
+		opts[key] = val
+	}
+	return opts, nil
+}
+
+// validateOptions checks that every option key is defined by the item and
+// that boolean options have a boolean value.
+func validateOptions(ociRef string, defs map[string]registry.OptionDefinition, opts map[string]any) error {
+	for key, val := range opts {
+		def, ok := defs[key]
+		if !ok {
+			valid := make([]string, 0, len(defs))
+			for k := range defs {
+				valid = append(valid, k)
+			}
+			sort.Strings(valid)
+			if len(valid) == 0 {
+				return fmt.Errorf("unknown option %q: %s has no options", key, ociRef)
+			}
+			return fmt.Errorf("unknown option %q for %s (valid options: %s)", key, ociRef, strings.Join(valid, ", "))
+		}
+		if def.Type == "boolean" {
+			if s, _ := val.(string); s != "true" && s != "false" {
+				return fmt.Errorf("option %q must be true or false, got %q", key, val)
+			}
+		}
+	}
+	return nil
+}
+
+// isExtension matches the extensions entries of id, pinned to a version
+// ("publisher.name@version") or not. IDs are case-insensitive.
+func isExtension(id string) func(string) bool {
+	return func(ref string) bool {
+		refID, _ := marketplace.SplitExtensionRef(ref)
+		return strings.EqualFold(ref, id) || strings.EqualFold(refID, id)
+	}
+}
+
+// validateExtensionID checks that id looks like a Marketplace
+// "publisher.name" identifier.
+func validateExtensionID(id string) error {
+	publisher, name, ok := strings.Cut(id, ".")
+	if !ok || publisher == "" || name == "" || strings.ContainsAny(id, " \t/") {
+		return fmt.Errorf("invalid extension ID %q, expected publisher.name", id)
+	}
+	return nil
+}
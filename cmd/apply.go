@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
+)
+
+var applyCmd = &cobra.Command{
+	Use:         "apply <file|url>",
+	Annotations: composesConfig,
+	Short:       "Merge a canonical devcontainer.json into the workspace config",
+	Long: `Merge a devcontainer.json, from a file or an http(s) URL such as a raw gist,
+into the workspace config instead of overwriting it, to keep many repositories
+consistent.
+
+Objects merge key by key, features and lists such as extensions and
+forwardPorts are unioned, and other values are taken from the applied file.
+A feature in the file replaces the same feature at another version. The
+workspace keeps its own name, as well as its key order and comments. Without a
+config, the file is written as the config.`,
+	Example: `  dcc apply ./canonical.json
+  dcc apply https://gist.githubusercontent.com/me/0123abcd/raw/devcontainer.json`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := absWorkspace()
+		if err != nil {
+			return err
+		}
+		overlay, err := readApplySource(args[0])
+		if err != nil {
+			return err
+		}
+		configPath, err := applyConfig(absFolder, overlay)
+		if err != nil {
+			return fmt.Errorf("applying %s: %w", args[0], err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Applied %s to %s\n", args[0], configPath)
+		return nil
+	},
+}
+
+// readApplySource reads and parses the JSONC config at source, a path or an
+// http(s) URL.
+func readApplySource(source string) (map[string]any, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://") {
+		data, err = fetchApplySource(source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", source, err)
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal(devcontainer.ToJSON(data), &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", source, err)
+	}
+	return config, nil
+}
+
+func fetchApplySource(url string) ([]byte, error) {
+	resp, err := httpclient.Default().Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// applyConfig merges overlay into the workspace config with
+// devcontainer.Merge and returns the path written. Extensions and plugins
+// are deduplicated as the hub would, since their IDs ignore case.
+func applyConfig(absFolder string, overlay map[string]any) (string, error) {
+	config, configPath, err := devcontainer.ReadConfig(absFolder)
+	if err != nil {
+		if devcontainer.Exists(absFolder) {
+			return "", err
+		}
+		config = map[string]any{}
+	}
+	if _, ok := config["name"]; ok {
+		overlay = devcontainer.Merge(nil, overlay)
+		delete(overlay, "name")
+	}
+
+	merged := devcontainer.Merge(config, overlay)
+	for _, list := range []customizationList{vscodeExtensions, jetbrainsPlugins} {
+		raw, ok := devcontainer.Customization(merged, list.ide)[list.key].([]any)
+		if !ok {
+			continue
+		}
+		var items []string
+		for _, item := range raw {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+		if len(items) == len(raw) {
+			devcontainer.SetCustomization(merged, list.ide, list.key, list.dedupe(items))
+		}
+	}
+	return configPath, devcontainer.WriteConfig(configPath, merged)
+}
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+}
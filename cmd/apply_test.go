@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+func TestApplyConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := devcontainer.DefaultConfigPath(dir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	existing := `{
+  "name": "api",
+  // pinned for the CI runners
+  "image": "mcr.microsoft.com/devcontainers/go:1",
+  "features": {
+    "ghcr.io/devcontainers/features/node:1": {"version": "18"}
+  },
+  "customizations": {
+    "vscode": {
+      "extensions": ["MS-Python.python"]
+    }
+  }
+}
+`
+	if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	canonical := map[string]any{
+		"name":       "canonical",
+		"remoteUser": "vscode",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:2":       map[string]any{},
+			"ghcr.io/devcontainers/features/github-cli:1": map[string]any{},
+		},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"ms-python.python", "golang.go"}},
+		},
+	}
+	if _, err := applyConfig(dir, canonical); err != nil {
+		t.Fatal(err)
+	}
+
+	got, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":       "api",
+		"image":      "mcr.microsoft.com/devcontainers/go:1",
+		"remoteUser": "vscode",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:2":       map[string]any{},
+			"ghcr.io/devcontainers/features/github-cli:1": map[string]any{},
+		},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"MS-Python.python", "golang.go"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("config =\n%v\nwant\n%v", got, want)
+	}
+	if canonical["name"] != "canonical" {
+		t.Error("applyConfig modified the applied config")
+	}
+	raw, _ := os.ReadFile(path)
+	if !strings.Contains(string(raw), "// pinned for the CI runners") {
+		t.Errorf("comment lost:\n%s", raw)
+	}
+}
+
+func TestApplyConfigWithoutConfig(t *testing.T) {
+	dir := t.TempDir()
+	canonical := map[string]any{"name": "canonical", "image": "ubuntu"}
+	path, err := applyConfig(dir, canonical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path != devcontainer.DefaultConfigPath(dir) {
+		t.Errorf("wrote %s, want the default config path", path)
+	}
+	got, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, canonical) {
+		t.Errorf("config = %v, want the applied file as is", got)
+	}
+}
+
+func TestReadApplySource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/raw/devcontainer.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("{\n  // shared\n  \"remoteUser\": \"vscode\",\n}\n")) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	got, err := readApplySource(srv.URL + "/raw/devcontainer.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, map[string]any{"remoteUser": "vscode"}) {
+		t.Errorf("readApplySource = %v", got)
+	}
+	if _, err := readApplySource(srv.URL + "/missing.json"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("missing URL: err = %v, want the status", err)
+	}
+
+	file := filepath.Join(t.TempDir(), "canonical.json")
+	if err := os.WriteFile(file, []byte(`{"image": "ubuntu"`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readApplySource(file); err == nil || !strings.Contains(err.Error(), "parsing "+file) {
+		t.Errorf("invalid file: err = %v, want a parse error", err)
+	}
+}
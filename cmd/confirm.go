@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-isatty"
+
+	"github.com/mochlast/devcontainer-companion/internal/ui"
+)
+
+// confirm asks a yes/no question outside the hub. --yes answers it without
+// prompting; without a terminal to ask on, the question turns into an error
+// pointing at --yes so scripts fail instead of hanging.
+func confirm(title, description string) (bool, error) {
+	if assumeYes {
+		return true, nil
+	}
+	if !stdinIsTerminal() {
+		return false, fmt.Errorf("%s (rerun with --yes to confirm)", title)
+	}
+
+	ok := true
+	err := ui.NewForm(huh.NewGroup(
+		huh.NewConfirm().
+			Title(title).
+			Description(description).
+			Affirmative("Yes").
+			Negative("No").
+			Value(&ok),
+	)).Run()
+	if err != nil {
+		return false, fmt.Errorf("confirming: %w", err)
+	}
+	return ok, nil
+}
+
+// stdinIsTerminal reports whether a prompt can be answered on stdin.
+func stdinIsTerminal() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) || isatty.IsCygwinTerminal(os.Stdin.Fd())
+}
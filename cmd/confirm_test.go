@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestConfirmWithoutTerminal(t *testing.T) {
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = oldStdin; assumeYes = false })
+
+	if _, err := confirm("Overwrite it?", ""); err == nil || !strings.Contains(err.Error(), "--yes") {
+		t.Errorf("confirm without a terminal = %v, want an error pointing at --yes", err)
+	}
+
+	assumeYes = true
+	if ok, err := confirm("Overwrite it?", ""); !ok || err != nil {
+		t.Errorf("confirm with --yes = %v, %v", ok, err)
+	}
+}
+
+func TestConfirmReplaceFeatureWithoutTerminal(t *testing.T) {
+	stdin, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	oldStdin := os.Stdin
+	os.Stdin = stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+
+	var notices strings.Builder
+	ok, err := confirmReplaceFeature(&notices, "ghcr.io/devcontainers/features/node:1", "ghcr.io/devcontainers/features/node:2")
+	if !ok || err != nil {
+		t.Fatalf("confirmReplaceFeature without a terminal = %v, %v; want the feature replaced", ok, err)
+	}
+	if want := "Replacing ghcr.io/devcontainers/features/node:1 with ghcr.io/devcontainers/features/node:2"; !strings.Contains(notices.String(), want) {
+		t.Errorf("notice = %q, want it to contain %q", notices.String(), want)
+	}
+}
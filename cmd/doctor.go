@@ -0,0 +1,198 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/catalog"
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
+	"github.com/mochlast/devcontainer-companion/internal/ui"
+)
+
+// doctorEndpoints are the hosts dcc fetches from. Any HTTP response counts:
+// ghcr.io answers /v2/ with 401 until a token is requested.
+var doctorEndpoints = []struct{ name, url string }{
+	{"containers.dev", "https://containers.dev/"},
+	{"ghcr.io", "https://ghcr.io/v2/"},
+}
+
+// checkResult is one line of the doctor checklist. A failed critical check
+// makes doctor exit non-zero; the others only warn.
+type checkResult struct {
+	Name     string
+	OK       bool
+	Critical bool
+	Detail   string
+	Hint     string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the environment dcc depends on",
+	Long: `Run a checklist of what dcc needs and print remediation hints:
+
+  - the devcontainer CLI is installed, and its version
+  - 'devcontainer open' is available (VS Code CLI)
+  - the Docker daemon answers 'docker info'
+  - containers.dev and ghcr.io are reachable
+  - the cache directory is writable
+
+With --host the CLI and Docker checks run on the remote host.
+Exits non-zero if the CLI, Docker or cache check fails.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runDoctor(cmd.OutOrStdout(), doctorChecks())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// doctorChecks runs every check in checklist order.
+func doctorChecks() []checkResult {
+	results := checkCLI()
+	results = append(results, checkDocker())
+	for _, ep := range doctorEndpoints {
+		results = append(results, checkEndpoint(ep.name, ep.url))
+	}
+	return append(results, checkCacheDir())
+}
+
+// runDoctor prints results as a checklist and returns an error if a
+// critical check failed.
+func runDoctor(out io.Writer, results []checkResult) error {
+	theme := ui.ActiveTheme()
+	pass := lipgloss.NewStyle().Foreground(theme.Success).Render("✓")
+	fail := lipgloss.NewStyle().Foreground(theme.Error).Render("✗")
+	warn := lipgloss.NewStyle().Foreground(theme.Warning).Render("!")
+	hint := lipgloss.NewStyle().Faint(true)
+
+	failed := 0
+	for _, r := range results {
+		mark := pass
+		switch {
+		case !r.OK && r.Critical:
+			mark = fail
+			failed++
+		case !r.OK:
+			mark = warn
+		}
+		line := mark + " " + r.Name
+		if r.Detail != "" {
+			line += ": " + r.Detail
+		}
+		fmt.Fprintln(out, line)
+		if !r.OK && r.Hint != "" {
+			fmt.Fprintln(out, hint.Render("    "+r.Hint))
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("doctor: %d critical check(s) failed", failed)
+	}
+	return nil
+}
+
+// checkCLI reports the devcontainer CLI and whether it can open VS Code.
+func checkCLI() []checkResult {
+	info := detectCLI()
+	if !info.Installed {
+		return []checkResult{
+			{
+				Name:     "devcontainer CLI",
+				Critical: true,
+				Detail:   "not found",
+				Hint:     `Install it with "npm install -g @devcontainers/cli" or from VS Code: "Dev Containers: Install devcontainer CLI"`,
+			},
+			{Name: "devcontainer open", Detail: "skipped"},
+		}
+	}
+
+	version := info.Version
+	if version == "" {
+		version = "unknown version"
+	}
+	cli := checkResult{Name: "devcontainer CLI", OK: true, Critical: true, Detail: version}
+	open := checkResult{Name: "devcontainer open", OK: info.HasOpen, Detail: "available"}
+	if !info.HasOpen {
+		open.Detail = "not supported; Open is hidden in the hub"
+		open.Hint = `The npm CLI has no 'open'; install the CLI from VS Code ("Dev Containers: Install devcontainer CLI") to get it`
+		if remoteHost != "" {
+			open.Hint = "Install VS Code's 'code' command locally to open remote workspaces over Remote-SSH"
+		}
+	}
+	return []checkResult{cli, open}
+}
+
+// checkDocker asks the Docker daemon for its version.
+func checkDocker() checkResult {
+	args := []string{"info", "--format", "{{.ServerVersion}}"}
+	cmd := exec.Command("docker", args...)
+	if remoteHost != "" {
+		cmd = remoteFS().Command(append([]string{"docker"}, args...)...)
+	}
+	result := checkResult{Name: "Docker daemon", Critical: true}
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		result.Detail = "not reachable"
+		msg := lastLine(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		result.Detail += " (" + msg + ")"
+		result.Hint = "Start Docker Desktop or the docker service, and check that your user may access the socket"
+		return result
+	}
+	result.OK = true
+	result.Detail = "server " + strings.TrimSpace(string(out))
+	return result
+}
+
+// checkEndpoint reports whether url answers at all.
+func checkEndpoint(name, url string) checkResult {
+	result := checkResult{Name: name}
+	resp, err := httpclient.Default().Get(url)
+	if err != nil {
+		result.Detail = "unreachable"
+		result.Hint = "Check your connection or HTTPS_PROXY; cached and built-in catalog data is used meanwhile"
+		return result
+	}
+	resp.Body.Close()
+	result.OK = true
+	result.Detail = "reachable"
+	return result
+}
+
+// checkCacheDir creates and removes a file in the cache directory.
+func checkCacheDir() checkResult {
+	result := checkResult{Name: "cache directory", Critical: true}
+	dir, err := catalog.CacheDir()
+	if err == nil {
+		var f *os.File
+		if f, err = os.CreateTemp(dir, ".doctor-*"); err == nil {
+			f.Close()
+			err = os.Remove(f.Name())
+		}
+	}
+	if err != nil {
+		result.Detail = "not writable (" + err.Error() + ")"
+		result.Hint = "Fix the permissions of the cache directory (~/.cache/dcc, or DCC_CACHE_DIR/XDG_CACHE_HOME) or remove it so dcc can recreate it"
+		return result
+	}
+	result.OK = true
+	result.Detail = dir
+	return result
+}
+
+// lastLine returns the last non-empty line of s, trimmed.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctor(t *testing.T) {
+	results := []checkResult{
+		{Name: "devcontainer CLI", OK: true, Critical: true, Detail: "0.72.0"},
+		{Name: "containers.dev", Detail: "unreachable", Hint: "check your connection"},
+	}
+	var out bytes.Buffer
+	if err := runDoctor(&out, results); err != nil {
+		t.Fatalf("runDoctor with only a warning = %v, want nil", err)
+	}
+	if got := out.String(); !strings.Contains(got, "devcontainer CLI: 0.72.0") || !strings.Contains(got, "check your connection") {
+		t.Errorf("output = %q", got)
+	}
+
+	results = append(results, checkResult{Name: "Docker daemon", Critical: true, Detail: "not reachable", Hint: "start docker"})
+	out.Reset()
+	if err := runDoctor(&out, results); err == nil || !strings.Contains(err.Error(), "1 critical") {
+		t.Errorf("runDoctor with a failed critical check = %v", err)
+	}
+	if !strings.Contains(out.String(), "start docker") {
+		t.Errorf("hint missing from %q", out.String())
+	}
+}
+
+func TestCheckEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	if r := checkEndpoint("ghcr.io", srv.URL+"/v2/"); !r.OK {
+		t.Errorf("401 response = %+v, want reachable", r)
+	}
+	srv.Close()
+	if r := checkEndpoint("ghcr.io", srv.URL+"/v2/"); r.OK || r.Critical {
+		t.Errorf("closed server = %+v, want a non-critical failure", r)
+	}
+}
+
+func TestCheckCacheDir(t *testing.T) {
+	t.Setenv("DCC_CACHE_DIR", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if r := checkCacheDir(); !r.OK {
+		t.Fatalf("checkCacheDir = %+v", r)
+	}
+	entries, _ := os.ReadDir(filepath.Join(home, ".cache", "dcc"))
+	if len(entries) != 0 {
+		t.Errorf("probe file left behind: %v", entries)
+	}
+
+	// A file where the cache directory should be can't be written into.
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.WriteFile(filepath.Join(home, ".cache"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if r := checkCacheDir(); r.OK || !r.Critical {
+		t.Errorf("checkCacheDir with a blocked path = %+v", r)
+	}
+}
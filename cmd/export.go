@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/export"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Translate devcontainer.json into a docker run command or a compose service",
+	Long: `Translate an image-based devcontainer.json into plain Docker, for quick
+experiments without a devcontainer tool. image, forwardPorts, mounts,
+containerEnv, runArgs, privileged, capAdd, securityOpt and init are
+translated, and the workspace is mounted as the devcontainer CLI would.
+Features, lifecycle commands and other keys that need a devcontainer tool are
+listed on stderr as skipped.`,
+}
+
+var exportDockerRunCmd = &cobra.Command{
+	Use:     "docker-run",
+	Short:   "Print an equivalent docker run command",
+	Example: `  eval "$(dcc export docker-run)"`,
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExport(cmd, func(config map[string]any, absFolder string) (string, []string, error) {
+			run, warnings, err := export.DockerRun(config, absFolder)
+			return export.FormatCommand(run) + "\n", warnings, err
+		})
+	},
+}
+
+var exportComposeCmd = &cobra.Command{
+	Use:     "compose",
+	Short:   "Print a compose file with an equivalent service",
+	Example: "  dcc export compose > compose.yaml && docker compose up -d",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runExport(cmd, func(config map[string]any, absFolder string) (string, []string, error) {
+			data, warnings, err := export.Compose(config, absFolder)
+			return string(data), warnings, err
+		})
+	},
+}
+
+// runExport prints what translate makes of the workspace config, and its
+// warnings to stderr.
+func runExport(cmd *cobra.Command, translate func(config map[string]any, absFolder string) (string, []string, error)) error {
+	absFolder, err := absWorkspace()
+	if err != nil {
+		return err
+	}
+	config, _, err := devcontainer.ReadConfig(absFolder)
+	if err != nil {
+		return err
+	}
+	out, warnings, err := translate(config, absFolder)
+	if errors.Is(err, export.ErrNoImage) {
+		return fmt.Errorf("%w: the config builds its image or uses Docker Compose", err)
+	}
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "note: %s\n", w)
+	}
+	fmt.Fprint(cmd.OutOrStdout(), out)
+	return nil
+}
+
+func init() {
+	exportCmd.AddCommand(exportDockerRunCmd, exportComposeCmd)
+	rootCmd.AddCommand(exportCmd)
+}
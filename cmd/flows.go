@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/ui"
+)
+
+var templateFlowCmd = &cobra.Command{
+	Use:         "template",
+	Annotations: composesConfig,
+	Aliases:     []string{"templates"},
+	Short:       "Pick a template without opening the hub",
+	Long: `Open the template picker directly, apply the chosen template to
+devcontainer.json and exit. Same as Template in the hub.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, ctx, err := resolveFlowWorkspace()
+		if err != nil {
+			return err
+		}
+		return runTemplateFlow(absFolder, ctx.ProjectName, noCache, ctx, nil)
+	},
+}
+
+var featuresFlowCmd = &cobra.Command{
+	Use:         "features",
+	Annotations: composesConfig,
+	Aliases:     []string{"feature"},
+	Short:       "Pick features without opening the hub",
+	Long: `Open the feature picker directly, write the selected features and their
+options to devcontainer.json and exit. Same as Features in the hub.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, ctx, err := resolveFlowWorkspace()
+		if err != nil {
+			return err
+		}
+		return runFeaturesFlow(absFolder, noCache, ctx, nil)
+	},
+}
+
+var extensionsFlowCmd = &cobra.Command{
+	Use:         "extensions",
+	Annotations: composesConfig,
+	Aliases:     []string{"extension"},
+	Short:       "Pick VS Code extensions without opening the hub",
+	Long: `Open the VS Code extension picker directly, write the selection to
+customizations.vscode.extensions and exit. Same as Extensions in the hub.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := resolveWorkspace()
+		if err != nil {
+			return err
+		}
+		return runExtensionsFlow(absFolder)
+	},
+}
+
+// resolveFlowWorkspace resolves the workspace of a flow command and the
+// context its flow starts from. The config resolveWorkspace scaffolds in a
+// fresh folder doesn't count, so the template flow doesn't ask whether to
+// replace or layer on top of it.
+func resolveFlowWorkspace() (string, ui.HubContext, error) {
+	absFolder, err := absWorkspace()
+	if err != nil {
+		return "", ui.HubContext{}, err
+	}
+	existed := devcontainer.Exists(absFolder)
+	if absFolder, err = resolveWorkspace(); err != nil {
+		return "", ui.HubContext{}, err
+	}
+	ctx := flowContext(absFolder)
+	if !existed {
+		ctx.Config = nil
+	}
+	return absFolder, ctx, nil
+}
+
+// flowContext returns the hub context a flow started from the command line
+// sees: the workspace config as it is on disk, nothing built yet.
+func flowContext(absFolder string) ui.HubContext {
+	var config map[string]any
+	if devcontainer.Exists(absFolder) {
+		if c, _, err := devcontainer.ReadConfig(absFolder); err == nil {
+			config = c
+		}
+	}
+	return ui.HubContext{
+		ProjectName: filepath.Base(absFolder),
+		Config:      config,
+		CLI:         detectCLI(),
+	}
+}
+
+func init() {
+	rootCmd.AddCommand(templateFlowCmd, featuresFlowCmd, extensionsFlowCmd)
+}
@@ -1,34 +1,236 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+	"github.com/mochlast/devcontainer-companion/internal/shellquote"
 )
 
-// stripVersion removes the version tag from an OCI reference.
-// e.g. "ghcr.io/devcontainers/features/java:1" -> "ghcr.io/devcontainers/features/java"
-func stripVersion(ref string) string {
-	if idx := strings.LastIndex(ref, ":"); idx != -1 {
-		return ref[:idx]
+// shellCommand renders a command line that can be pasted into a POSIX shell.
+// Each "--flag" starts a continuation line so long paths stay readable.
+func shellCommand(name string, args ...string) string {
+	var b strings.Builder
+	b.WriteString(shellquote.Quote(name))
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			b.WriteString(" \\\n  ")
+		} else {
+			b.WriteString(" ")
+		}
+		b.WriteString(shellquote.Quote(arg))
+	}
+	return b.String()
+}
+
+// featureCheckTimeout bounds the check that features resolve before a build.
+const featureCheckTimeout = 5 * time.Second
+
+// unresolvedFeatures returns the features of config the registry doesn't
+// know, usually a deleted version or a typo, checking them concurrently with
+// check (registry.CheckResolves). Local and tarball features, and refs that
+// can't be checked because the registry is unreachable or slow, count as
+// resolved, so the check never holds up an offline build.
+func unresolvedFeatures(ctx context.Context, config map[string]any, check func(context.Context, string) error) []string {
+	ctx, cancel := context.WithTimeout(ctx, featureCheckTimeout)
+	defer cancel()
+
+	features, _ := config["features"].(map[string]any)
+	var (
+		wg         sync.WaitGroup
+		mu         sync.Mutex
+		unresolved []string
+	)
+	for ref := range features {
+		if strings.HasPrefix(ref, ".") || strings.Contains(ref, "://") {
+			continue
+		}
+		wg.Go(func() {
+			if err := check(ctx, ref); errors.Is(err, registry.ErrNotFound) {
+				mu.Lock()
+				unresolved = append(unresolved, ref)
+				mu.Unlock()
+			}
+		})
 	}
-	return ref
+	wg.Wait()
+	sort.Strings(unresolved)
+	return unresolved
 }
 
 // devcontainerBuild runs 'devcontainer build'. If noCache is true, Docker layer
-// cache is skipped for a full rebuild.
-func devcontainerBuild(folder string, noCache bool) (string, error) {
+// cache is skipped for a full rebuild. A config outside the default location
+// is passed explicitly via --config. Each output line is passed to onLine as
+// it arrives; the combined output is returned when the build ends or ctx is
+// cancelled.
+func devcontainerBuild(ctx context.Context, folder string, noCache bool, onLine func(string)) (string, error) {
 	args := []string{"build", "--workspace-folder", folder}
+	if configPath := devcontainer.ConfigPath(folder); configPath != devcontainer.DefaultConfigPath(folder) {
+		args = append(args, "--config", configPath)
+	}
 	if noCache {
 		args = append(args, "--no-cache")
 	}
-	cmd := exec.Command("devcontainer", args...)
-	output, err := cmd.CombinedOutput()
-	return string(output), err
+	if dryRun {
+		return devcontainerCommandLine(args...), nil
+	}
+	return streamOutput(devcontainerCommandContext(ctx, args...), onLine)
+}
+
+// streamOutput runs cmd, passing each line of its combined stdout and stderr
+// to onLine, and returns the whole output.
+func streamOutput(cmd *exec.Cmd, onLine func(string)) (string, error) {
+	pr, pw := io.Pipe()
+	cmd.Stdout = pw
+	cmd.Stderr = pw
+	if err := cmd.Start(); err != nil {
+		return "", err
+	}
+	waitErr := make(chan error, 1)
+	go func() {
+		err := cmd.Wait()
+		pw.Close()
+		waitErr <- err
+	}()
+
+	var output strings.Builder
+	scanner := bufio.NewScanner(pr)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	scanner.Split(scanOutputLines)
+	for scanner.Scan() {
+		line := scanner.Text()
+		output.WriteString(line)
+		output.WriteString("\n")
+		if onLine != nil {
+			onLine(line)
+		}
+	}
+	// Keep draining if a line was too long, so the command can exit.
+	io.Copy(io.Discard, pr) //nolint:errcheck
+	return output.String(), <-waitErr
+}
+
+// scanOutputLines is bufio.ScanLines that also ends a line at a bare "\r",
+// which progress output uses to redraw the current line.
+func scanOutputLines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		advance = i + 1
+		if data[i] == '\r' && i+1 < len(data) && data[i+1] == '\n' {
+			advance++
+		} else if data[i] == '\r' && i+1 == len(data) && !atEOF {
+			return 0, nil, nil // might be the start of "\r\n"
+		}
+		return advance, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// upResult is the JSON summary `devcontainer up` prints as its last stdout line.
+type upResult struct {
+	Outcome     string `json:"outcome"`
+	ContainerID string `json:"containerId"`
+	Message     string `json:"message"`
+	Description string `json:"description"`
+}
+
+// devcontainerUp runs 'devcontainer up' and returns the started container's
+// ID along with the command's combined output.
+func devcontainerUp(ctx context.Context, folder string) (string, string, error) {
+	args := []string{"up", "--workspace-folder", folder}
+	if configPath := devcontainer.ConfigPath(folder); configPath != devcontainer.DefaultConfigPath(folder) {
+		args = append(args, "--config", configPath)
+	}
+	if dryRun {
+		return "", devcontainerCommandLine(args...), nil
+	}
+	cmd := devcontainerCommandContext(ctx, args...)
+	var stdout, combined bytes.Buffer
+	cmd.Stdout = io.MultiWriter(&stdout, &combined)
+	cmd.Stderr = &combined
+	runErr := cmd.Run()
+
+	result, parseErr := parseUpResult(stdout.String())
+	switch {
+	case runErr != nil:
+		return "", combined.String(), runErr
+	case parseErr != nil:
+		return "", combined.String(), parseErr
+	case result.Outcome != "success":
+		return "", combined.String(), fmt.Errorf("devcontainer up: %s", strings.TrimSpace(result.Message+" "+result.Description))
+	}
+	return result.ContainerID, combined.String(), nil
+}
+
+// parseUpResult finds the JSON outcome line in 'devcontainer up' stdout.
+func parseUpResult(stdout string) (upResult, error) {
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "{") {
+			continue
+		}
+		var result upResult
+		if err := json.Unmarshal([]byte(line), &result); err == nil && result.Outcome != "" {
+			return result, nil
+		}
+	}
+	return upResult{}, fmt.Errorf("devcontainer up: no result in output")
 }
 
 // devcontainerOpen runs 'devcontainer open' to build, start, and connect VS Code.
-func devcontainerOpen(folder string) (string, error) {
-	cmd := exec.Command("devcontainer", "open", folder)
-	output, err := cmd.CombinedOutput()
+// The build, up and open helpers return the command line instead of running
+// it when --dry-run is set.
+func devcontainerOpen(ctx context.Context, folder string) (string, error) {
+	if remoteHost != "" {
+		name, args := remoteOpenCommand(folder)
+		if dryRun {
+			return shellCommand(name, args...), nil
+		}
+		output, err := commandContext(ctx, name, args...).CombinedOutput()
+		return string(output), err
+	}
+	if dryRun {
+		return shellCommand("devcontainer", "open", folder), nil
+	}
+	output, err := commandContext(ctx, "devcontainer", "open", folder).CombinedOutput()
 	return string(output), err
 }
+
+// editorCommand returns a command that opens path in $VISUAL or $EDITOR
+// (falling back to vi). The editor value may include arguments, e.g. "code -w".
+// The config directory is created first so the editor can save a new file.
+// With --host the remote editor runs over ssh.
+func editorCommand(path string) (*exec.Cmd, error) {
+	if remoteHost != "" {
+		return remoteEditorCommand(path), nil
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	fields := strings.Fields(editor)
+	if len(fields) == 0 {
+		fields = []string{"vi"}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("creating config directory: %w", err)
+	}
+	return exec.Command(fields[0], append(fields[1:], path)...), nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+func TestParseUpResult(t *testing.T) {
+	stdout := "[2024-01-01T00:00:00.000Z] Starting container\n" +
+		`{"outcome":"success","containerId":"abc123","remoteUser":"vscode","remoteWorkspaceFolder":"/workspaces/app"}` + "\n"
+	result, err := parseUpResult(stdout)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Outcome != "success" || result.ContainerID != "abc123" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	result, err = parseUpResult(`{"outcome":"error","message":"Command failed","description":"An error occurred setting up the container."}`)
+	if err != nil || result.Outcome != "error" || result.Message != "Command failed" {
+		t.Errorf("error outcome = %+v, %v", result, err)
+	}
+
+	if _, err := parseUpResult("no json here\n"); err == nil {
+		t.Error("expected error for output without a result line")
+	}
+}
+
+func TestShellCommand(t *testing.T) {
+	got := shellCommand("devcontainer", "build", "--workspace-folder", "/tmp/my project", "--no-cache")
+	want := "devcontainer build \\\n  --workspace-folder '/tmp/my project' \\\n  --no-cache"
+	if got != want {
+		t.Errorf("shellCommand = %q, want %q", got, want)
+	}
+}
+
+func TestStreamOutput(t *testing.T) {
+	var lines []string
+	cmd := exec.Command("sh", "-c", `printf 'step 1\n'; printf '50%%\r100%%\r\n' >&2; printf 'done'; exit 3`)
+	output, err := streamOutput(cmd, func(line string) { lines = append(lines, line) })
+	if err == nil {
+		t.Error("expected the exit status as error")
+	}
+	want := []string{"step 1", "50%", "100%", "done"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("lines = %q, want %q", lines, want)
+	}
+	if output != "step 1\n50%\n100%\ndone\n" {
+		t.Errorf("output = %q", output)
+	}
+}
+
+func TestUnresolvedFeatures(t *testing.T) {
+	config := map[string]any{"features": map[string]any{
+		"ghcr.io/devcontainers/features/node:1":   map[string]any{},
+		"ghcr.io/devcontainers/features/pyton:1":  map[string]any{},
+		"ghcr.io/devcontainers/features/go:99":    map[string]any{},
+		"ghcr.io/devcontainers/features/docker:2": map[string]any{},
+		"./local-feature":                         map[string]any{},
+		"https://example.com/feature.tgz":         map[string]any{},
+	}}
+	var checked []string
+	check := func(ctx context.Context, ref string) error {
+		switch ref {
+		case "ghcr.io/devcontainers/features/pyton:1", "ghcr.io/devcontainers/features/go:99":
+			return fmt.Errorf("resolving %s: %w", ref, &registry.StatusError{Op: "manifest request", Code: 404})
+		case "ghcr.io/devcontainers/features/docker:2":
+			return errors.New("dial tcp: no route to host") // offline counts as resolved
+		case "./local-feature", "https://example.com/feature.tgz":
+			checked = append(checked, ref)
+		}
+		return nil
+	}
+
+	got := unresolvedFeatures(context.Background(), config, check)
+	want := []string{"ghcr.io/devcontainers/features/go:99", "ghcr.io/devcontainers/features/pyton:1"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unresolvedFeatures = %v, want %v", got, want)
+	}
+	if len(checked) > 0 {
+		t.Errorf("checked non-OCI features %v", checked)
+	}
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// maxUndo caps how many earlier versions of the config a hub session keeps.
+const maxUndo = 20
+
+var errNothingToUndo = errors.New("nothing to undo in this session")
+
+// configHistory keeps devcontainer.json as it was before each change made in
+// the hub, newest last. It lives only as long as the hub session.
+type configHistory struct {
+	absFolder string
+	snapshots []configSnapshot
+}
+
+type configSnapshot struct {
+	path string
+	data []byte
+}
+
+// current returns the config file contents, or nil if there is no file yet.
+func (h *configHistory) current() []byte {
+	if !devcontainer.Exists(h.absFolder) {
+		return nil
+	}
+	data, err := devcontainer.ReadFile(devcontainer.ConfigPath(h.absFolder))
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// record keeps before as an undo step if the file has changed since. A file
+// created from scratch has nothing to go back to and is not recorded.
+func (h *configHistory) record(before []byte) {
+	if before == nil || bytes.Equal(before, h.current()) {
+		return
+	}
+	h.snapshots = append(h.snapshots, configSnapshot{path: devcontainer.ConfigPath(h.absFolder), data: before})
+	if len(h.snapshots) > maxUndo {
+		h.snapshots = h.snapshots[len(h.snapshots)-maxUndo:]
+	}
+}
+
+// undo writes back the newest snapshot and returns the restored config. The
+// contents it replaces are backed up like any other write, so an undo can be
+// undone from the backups.
+func (h *configHistory) undo() (map[string]any, error) {
+	if len(h.snapshots) == 0 {
+		return nil, errNothingToUndo
+	}
+	last := h.snapshots[len(h.snapshots)-1]
+	if err := devcontainer.BackupConfig(last.path); err != nil {
+		return nil, err
+	}
+	if err := devcontainer.WriteFile(last.path, last.data); err != nil {
+		return nil, fmt.Errorf("restoring %s: %w", last.path, err)
+	}
+	h.snapshots = h.snapshots[:len(h.snapshots)-1]
+	config, _, err := devcontainer.ReadConfig(h.absFolder)
+	return config, err
+}
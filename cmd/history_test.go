@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+func TestConfigHistoryUndo(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".devcontainer", "devcontainer.json")
+	write := func(s string) {
+		t.Helper()
+		if err := devcontainer.WriteFile(path, []byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	h := &configHistory{absFolder: dir}
+
+	// Creating the config has nothing to go back to.
+	before := h.current()
+	write(`{"name": "a"}`)
+	h.record(before)
+	if _, err := h.undo(); !errors.Is(err, errNothingToUndo) {
+		t.Fatalf("undo after create = %v, want errNothingToUndo", err)
+	}
+
+	// An unchanged file is not recorded.
+	before = h.current()
+	h.record(before)
+	if len(h.snapshots) != 0 {
+		t.Fatalf("recorded %d snapshots for an unchanged file", len(h.snapshots))
+	}
+
+	before = h.current()
+	write(`{"name": "b"}`)
+	h.record(before)
+	config, err := h.undo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config["name"] != "a" {
+		t.Errorf("restored config = %v, want name a", config)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"name": "a"}` {
+		t.Errorf("file = %s, want the original bytes", data)
+	}
+}
+
+func TestConfigHistoryUndoBacksUp(t *testing.T) {
+	devcontainer.SetBackups(5)
+	t.Cleanup(func() { devcontainer.SetBackups(0) })
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".devcontainer", "devcontainer.json")
+	h := &configHistory{absFolder: dir}
+	if err := devcontainer.WriteFile(path, []byte(`{"name": "a"}`)); err != nil {
+		t.Fatal(err)
+	}
+	before := h.current()
+	if err := devcontainer.WriteFile(path, []byte(`{"name": "b"}`)); err != nil {
+		t.Fatal(err)
+	}
+	h.record(before)
+
+	if _, err := h.undo(); err != nil {
+		t.Fatal(err)
+	}
+	found, _ := devcontainer.Backups(path)
+	if len(found) != 1 {
+		t.Fatalf("Backups = %q, want the undone contents", found)
+	}
+	if data, _ := os.ReadFile(found[0]); string(data) != `{"name": "b"}` {
+		t.Errorf("backup = %s, want the contents the undo replaced", data)
+	}
+}
+
+func TestConfigHistoryCap(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".devcontainer", "devcontainer.json")
+	h := &configHistory{absFolder: dir}
+	if err := devcontainer.WriteFile(path, []byte(`{"name": "0"}`)); err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i <= maxUndo+5; i++ {
+		before := h.current()
+		if err := devcontainer.WriteFile(path, fmt.Appendf(nil, `{"name": "%d"}`, i)); err != nil {
+			t.Fatal(err)
+		}
+		h.record(before)
+	}
+	if len(h.snapshots) != maxUndo {
+		t.Fatalf("kept %d snapshots, want %d", len(h.snapshots), maxUndo)
+	}
+	// The oldest steps were dropped; the earliest left is the 5th change.
+	if got := string(h.snapshots[0].data); got != `{"name": "5"}` {
+		t.Errorf("oldest snapshot = %s", got)
+	}
+}
@@ -1,14 +1,26 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
 
 	"github.com/mochlast/devcontainer-companion/internal/catalog"
 	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
 	"github.com/mochlast/devcontainer-companion/internal/feature"
+	"github.com/mochlast/devcontainer-companion/internal/marketplace"
+	"github.com/mochlast/devcontainer-companion/internal/preset"
 	"github.com/mochlast/devcontainer-companion/internal/registry"
 	"github.com/mochlast/devcontainer-companion/internal/template"
 	"github.com/mochlast/devcontainer-companion/internal/ui"
@@ -18,26 +30,84 @@ import (
 // selected sub-flow, and loops back. Each sub-flow reads/writes config to
 // disk, so the hub always shows the latest state. A dirty flag tracks whether
 // config has changed since the last successful build. Build and Open run
-// within the hub TUI itself.
+// within the hub TUI itself. Every change is recorded in a session history
+// so it can be undone from the hub.
 func runHub(absFolder string, noCache bool) error {
 	projectName := filepath.Base(absFolder)
-	cli := template.DetectCLI()
+	cli := detectCLI()
 	dirty := false
+	history := &configHistory{absFolder: absFolder}
+	var beforeEdit []byte
+
+	// Warm the template and feature caches in the background so the first
+	// template or feature flow doesn't wait on containers.dev.
+	go catalog.GetAll(noCache)      //nolint:errcheck
+	go devcontainer.RefreshSchema() //nolint:errcheck
 
 	// Clear the normal buffer so AltScreen transitions don't flash old content.
 	fmt.Print("\033[2J\033[H")
 
 	cb := ui.HubCallbacks{
-		Build: func(noCache bool) (string, error) {
-			return devcontainerBuild(absFolder, noCache)
+		// Under --stdout nothing on disk reflects the composed config, so
+		// Build/Up/Open only show their commands.
+		DryRun: dryRun || stdoutMode,
+		Build: func(ctx context.Context, noCache bool, onLine func(string)) (string, error) {
+			return devcontainerBuild(ctx, absFolder, noCache, onLine)
+		},
+		Up: func(ctx context.Context) (string, string, error) {
+			return devcontainerUp(ctx, absFolder)
+		},
+		CheckFeatures: func(ctx context.Context, config map[string]any) []string {
+			return unresolvedFeatures(ctx, config, registry.CheckResolves)
+		},
+		Open: func(ctx context.Context) (string, error) {
+			return devcontainerOpen(ctx, absFolder)
 		},
-		Open: func() (string, error) {
-			return devcontainerOpen(absFolder)
+		Edit: func() (*exec.Cmd, error) {
+			beforeEdit = history.current()
+			return editorCommand(devcontainer.ConfigPath(absFolder))
+		},
+		Reload: func() (map[string]any, error) {
+			history.record(beforeEdit)
+			if !devcontainer.Exists(absFolder) {
+				return nil, nil
+			}
+			config, _, err := devcontainer.ReadConfig(absFolder)
+			return config, err
+		},
+		Undo: history.undo,
+		Migrate: func() (map[string]any, error) {
+			before := history.current()
+			config, configPath, err := devcontainer.ReadConfig(absFolder)
+			if err != nil {
+				return nil, err
+			}
+			if !devcontainer.Migrate(config) {
+				return config, nil
+			}
+			if err := devcontainer.WriteConfig(configPath, config); err != nil {
+				return nil, err
+			}
+			history.record(before)
+			return config, nil
+		},
+		ToggleSchema: func() (map[string]any, error) {
+			before := history.current()
+			config, configPath, err := devcontainer.ReadConfig(absFolder)
+			if err != nil {
+				return nil, err
+			}
+			devcontainer.ToggleSchema(config)
+			if err := devcontainer.WriteConfig(configPath, config); err != nil {
+				return nil, err
+			}
+			history.record(before)
+			return config, nil
 		},
 		Preload: func(action ui.HubAction) (any, error) {
 			switch action {
 			case ui.HubActionTemplate:
-				return catalog.GetTemplates(noCache)
+				return loadTemplates(noCache)
 			case ui.HubActionFeatures:
 				return catalog.GetFeatures(noCache)
 			default:
@@ -46,6 +116,10 @@ func runHub(absFolder string, noCache bool) error {
 		},
 	}
 
+	if stdoutMode {
+		cb.Edit = nil // $EDITOR would open the file on disk
+	}
+
 	for {
 		var config map[string]any
 		if devcontainer.Exists(absFolder) {
@@ -67,6 +141,7 @@ func runHub(absFolder string, noCache bool) error {
 			Dirty:       dirty,
 		}
 
+		before := history.current()
 		switch action {
 		case ui.HubActionTemplate:
 			err = runTemplateFlow(absFolder, projectName, noCache, ctx, preloaded)
@@ -83,30 +158,98 @@ func runHub(absFolder string, noCache bool) error {
 		case ui.HubActionCustomizations:
 			err = runCustomizationsFlow(absFolder)
 			dirty = true
+		case ui.HubActionVSCodeSettings:
+			err = ui.EditVSCodeSettings(absFolder)
+			dirty = true
+		case ui.HubActionPreset:
+			err = runPresetFlow(absFolder)
+			dirty = true
 		case ui.HubActionExit:
+			if !promptBuildOnExit(dirty, cli) {
+				return nil
+			}
+			choice, err := confirmExit()
+			if err != nil {
+				return err
+			}
+			switch choice {
+			case exitBuild:
+				return buildOnExit(absFolder)
+			case exitStay:
+				continue
+			}
 			return nil
 		}
+		history.record(before)
 		if err != nil {
 			return err
 		}
 	}
 }
 
+// Choices of confirmExit.
+const (
+	exitBuild = "build"
+	exitStay  = "stay"
+	exitQuit  = "exit"
+)
+
+// promptBuildOnExit reports whether leaving the hub should offer a build:
+// the config changed since the last build, the devcontainer CLI can build
+// it, and --yes doesn't ask to skip prompts.
+func promptBuildOnExit(dirty bool, cli template.CLIInfo) bool {
+	return dirty && cli.Installed && !assumeYes && !dryRun && !stdoutMode
+}
+
+// confirmExit asks whether to build the changed config before exiting.
+// Ctrl+C exits without building.
+func confirmExit() (string, error) {
+	choice := exitBuild
+	err := ui.NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Config changed since the last build. Build now?").
+			Options(
+				huh.NewOption("Yes, build and exit", exitBuild),
+				huh.NewOption("No, back to the hub", exitStay),
+				huh.NewOption("Exit without building", exitQuit),
+			).
+			Value(&choice),
+	)).Run()
+	if errors.Is(err, huh.ErrUserAborted) {
+		return exitQuit, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("confirming exit: %w", err)
+	}
+	return choice, nil
+}
+
+// buildOnExit builds the container after the hub closed, streaming the
+// devcontainer CLI output to the terminal.
+func buildOnExit(absFolder string) error {
+	fmt.Println("Building the dev container...")
+	if _, err := devcontainerBuild(context.Background(), absFolder, false, func(line string) { fmt.Println(line) }); err != nil {
+		return fmt.Errorf("building: %w", err)
+	}
+	fmt.Println("Build complete.")
+	return nil
+}
+
 func runTemplateFlow(absFolder, projectName string, noCache bool, ctx ui.HubContext, preloaded any) error {
 	// Use preloaded catalog data if available, otherwise load now
 	var templates []catalog.CatalogEntry
 	if preloaded != nil {
 		templates = preloaded.([]catalog.CatalogEntry)
 	} else {
-		loaded, err := catalog.GetTemplates(noCache)
+		loaded, err := loadTemplates(noCache)
 		if err != nil {
-			return fmt.Errorf("loading template catalog: %w", err)
+			return err
 		}
 		templates = loaded
 	}
 
 	// Pick a template
-	selected, err := ui.PickTemplate(templates)
+	selected, err := ui.PickTemplate(catalog.MarkRecent("templates", templates), absFolder)
 	if errors.Is(err, ui.ErrPickerCancelled) {
 		return nil
 	}
@@ -114,19 +257,44 @@ func runTemplateFlow(absFolder, projectName string, noCache bool, ctx ui.HubCont
 		return err
 	}
 
+	// With a config in place, the template either replaces its base or is
+	// layered on top of it.
+	layer := false
+	if len(ctx.Config) > 0 {
+		layer, err = ui.PickTemplateMode()
+		if errors.Is(err, ui.ErrPickerCancelled) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
 	// No template selected → create empty
 	if selected == nil {
-		return template.CreateEmpty(absFolder, projectName)
+		conflicts, err := createEmptyPreservingSettings(absFolder, projectName, layer)
+		if err != nil {
+			return err
+		}
+		return resolveTemplateConflicts(absFolder, conflicts)
+	}
+
+	if selected.LocalPath != "" {
+		return runRepoTemplateFlow(absFolder, ctx, selected, layer)
 	}
 
 	// Fetch template metadata + configure options + apply — all in one TUI program
-	ociRef := ui.FormatOciRefWithVersion(selected)
+	ociRef, err := pickVersionedRef(selected.Name, ui.FormatOciRefWithVersion(selected))
+	if err != nil {
+		return err
+	}
 
 	if template.IsDevcontainerCLIAvailable() {
+		var conflicts []devcontainer.Conflict
 		_, err = ui.ShowHubForm(ctx, ui.FormConfig{
 			LoadLabel: fmt.Sprintf("Loading options for %s...", selected.Name),
 			LoadFn: func() (string, map[string]registry.OptionDefinition, error) {
-				tmplDef, _, err := registry.FetchItemMetadata(ociRef)
+				tmplDef, _, err := registry.FetchItemMetadata(ociRef, noCache)
 				if err != nil {
 					return "", nil, err
 				}
@@ -134,18 +302,22 @@ func runTemplateFlow(absFolder, projectName string, noCache bool, ctx ui.HubCont
 			},
 			PostLabel: "Applying template...",
 			PostFn: func(opts map[string]any) error {
-				return applyTemplatePreservingSettings(absFolder, ociRef, opts)
+				var err error
+				conflicts, err = applyTemplatePreservingSettings(absFolder, ociTemplate(ociRef, opts), layer)
+				return err
 			},
 		})
 		if err != nil {
 			return fmt.Errorf("configuring/applying template: %w", err)
 		}
+		catalog.AddRecent("templates", selected.OciRef) //nolint:errcheck
+		return resolveTemplateConflicts(absFolder, conflicts)
 	} else {
 		// No CLI available — just fetch metadata to show options form, then create empty
 		_, err = ui.ShowHubForm(ctx, ui.FormConfig{
 			LoadLabel: fmt.Sprintf("Loading options for %s...", selected.Name),
 			LoadFn: func() (string, map[string]registry.OptionDefinition, error) {
-				tmplDef, _, err := registry.FetchItemMetadata(ociRef)
+				tmplDef, _, err := registry.FetchItemMetadata(ociRef, noCache)
 				if err != nil {
 					return "", nil, err
 				}
@@ -155,21 +327,77 @@ func runTemplateFlow(absFolder, projectName string, noCache bool, ctx ui.HubCont
 		if err != nil {
 			return err
 		}
-		return template.CreateEmpty(absFolder, projectName)
+		conflicts, err := createEmptyPreservingSettings(absFolder, projectName, layer)
+		if err != nil {
+			return err
+		}
+		return resolveTemplateConflicts(absFolder, conflicts)
 	}
+}
 
-	return nil
+// runRepoTemplateFlow configures and applies a template from --template-repo.
+// Its files are copied by dcc, so the devcontainer CLI is not needed.
+func runRepoTemplateFlow(absFolder string, ctx ui.HubContext, selected *catalog.CatalogEntry, layer bool) error {
+	var conflicts []devcontainer.Conflict
+	_, err := ui.ShowHubForm(ctx, ui.FormConfig{
+		LoadLabel: fmt.Sprintf("Loading options for %s...", selected.Name),
+		LoadFn: func() (string, map[string]registry.OptionDefinition, error) {
+			tmplDef, err := template.LoadLocal(selected.LocalPath)
+			if err != nil {
+				return "", nil, err
+			}
+			return fmt.Sprintf("Configure %s options:", selected.Name), tmplDef.Options, nil
+		},
+		PostLabel: "Applying template...",
+		PostFn: func(opts map[string]any) error {
+			var err error
+			conflicts, err = applyTemplatePreservingSettings(absFolder, repoTemplate(selected.LocalPath, opts), layer)
+			return err
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("configuring/applying template: %w", err)
+	}
+	catalog.AddRecent("templates", selected.OciRef) //nolint:errcheck
+	return resolveTemplateConflicts(absFolder, conflicts)
+}
+
+// loadTemplates returns the templates of --template-repo, if set, followed
+// by the catalog. The catalog is optional when the repo provided templates,
+// so private templates stay usable offline.
+func loadTemplates(noCache bool) ([]catalog.CatalogEntry, error) {
+	var templates []catalog.CatalogEntry
+	if templateRepo != "" {
+		entries, err := catalog.FetchTemplatesFromRepo(templateRepo)
+		if err != nil {
+			return nil, fmt.Errorf("loading templates from %s: %w", templateRepo, err)
+		}
+		templates = entries
+	}
+	entries, err := catalog.GetTemplates(noCache)
+	if err != nil {
+		if len(templates) > 0 {
+			return templates, nil
+		}
+		return nil, fmt.Errorf("loading template catalog: %w", err)
+	}
+	return append(templates, entries...), nil
 }
 
 func runFeaturesFlow(absFolder string, noCache bool, ctx ui.HubContext, preloaded any) error {
 	existingOpts := make(map[string]map[string]any)
-	existingRefs := make(map[string]bool)
+	existingRefs := make(map[string]string) // bare ref -> configured ref
+	var configuredRefs []string
+	var image, baseImage string
 	if devcontainer.Exists(absFolder) {
-		if config, _, err := devcontainer.ReadConfig(absFolder); err == nil {
+		if config, path, err := devcontainer.ReadConfig(absFolder); err == nil {
+			image, _ = config["image"].(string)
+			baseImage, _ = devcontainer.BaseImage(config, path) // unknown on error
 			if feats, ok := config["features"].(map[string]any); ok {
 				for ref, opts := range feats {
-					bare := stripVersion(ref)
-					existingRefs[bare] = true
+					bare := devcontainer.FeatureID(ref)
+					existingRefs[bare] = ref
+					configuredRefs = append(configuredRefs, ref)
 					if m, ok := opts.(map[string]any); ok {
 						existingOpts[bare] = m
 					}
@@ -192,13 +420,13 @@ func runFeaturesFlow(absFolder string, noCache bool, ctx ui.HubContext, preloade
 
 	preSelected := make(map[string]bool)
 	for _, entry := range features {
-		if existingRefs[stripVersion(entry.OciRef)] {
+		if _, ok := existingRefs[devcontainer.FeatureID(entry.OciRef)]; ok {
 			preSelected[entry.OciRef] = true
 		}
 	}
 
 	// Pick features
-	selected, err := ui.PickFeaturesWithSelection(features, preSelected)
+	selected, err := ui.PickFeaturesWithSelection(catalog.MarkRecent("features", features), preSelected, baseImage)
 	if errors.Is(err, ui.ErrPickerCancelled) {
 		return nil
 	}
@@ -206,23 +434,47 @@ func runFeaturesFlow(absFolder string, noCache bool, ctx ui.HubContext, preloade
 		return err
 	}
 
-	// Configure each new feature
+	// Confirm before dropping previously configured features
+	if removed := removedFeatures(configuredRefs, features, selected); len(removed) > 0 {
+		confirmed, err := ui.ConfirmFeatureRemoval(removed)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	if err := warnRedundantFeatures(image, selected, existingRefs, noCache); err != nil {
+		return err
+	}
+
+	// Configure each new feature, keeping those the picker doesn't list
 	var configs []feature.FeatureConfig
+	for _, ref := range uncatalogedFeatures(configuredRefs, features) {
+		configs = append(configs, feature.FeatureConfig{OciRef: ref, Options: existingOpts[devcontainer.FeatureID(ref)]})
+	}
+	var added []string
 	for _, f := range selected {
-		ociRef := ui.FormatFeatureOciRef(&f)
-		bare := stripVersion(f.OciRef)
+		bare := devcontainer.FeatureID(f.OciRef)
 
-		// Keep existing configuration for previously selected features
-		if prev, existed := existingOpts[bare]; existed {
-			configs = append(configs, feature.FeatureConfig{OciRef: ociRef, Options: prev})
+		// Keep the configured ref (including a pinned version) and options
+		// for previously selected features
+		if ref, existed := existingRefs[bare]; existed {
+			configs = append(configs, feature.FeatureConfig{OciRef: ref, Options: existingOpts[bare]})
 			continue
 		}
 
+		ociRef, err := pickVersionedRef(f.Name, ui.FormatFeatureOciRef(&f))
+		if err != nil {
+			return err
+		}
+
 		// Fetch metadata + configure options in one TUI program
 		opts, err := ui.ShowHubForm(ctx, ui.FormConfig{
 			LoadLabel: fmt.Sprintf("Loading options for %s...", f.Name),
 			LoadFn: func() (string, map[string]registry.OptionDefinition, error) {
-				_, featDef, err := registry.FetchItemMetadata(ociRef)
+				_, featDef, err := registry.FetchItemMetadata(ociRef, noCache)
 				if err != nil {
 					return "", nil, err
 				}
@@ -232,19 +484,88 @@ func runFeaturesFlow(absFolder string, noCache bool, ctx ui.HubContext, preloade
 		if err != nil {
 			return err
 		}
-		configs = append(configs, feature.FeatureConfig{OciRef: ociRef, Options: opts})
+		note, err := ui.PromptFeatureNote(f.Name)
+		if err != nil {
+			return err
+		}
+		configs = append(configs, feature.FeatureConfig{OciRef: ociRef, Options: opts, Note: note})
+		added = append(added, ociRef)
+	}
+
+	// Offer the features the new ones depend on
+	deps := feature.ResolveDependencies(added, configs, func(ref string) (map[string]map[string]any, error) {
+		_, featDef, err := registry.FetchItemMetadata(ref, noCache)
+		if err != nil || featDef == nil {
+			return nil, err
+		}
+		return featDef.DependsOn, nil
+	})
+	if len(deps) > 0 {
+		chains := make([]string, len(deps))
+		for i, d := range deps {
+			chains[i] = dependencyChain(d)
+		}
+		confirmed, err := ui.ConfirmFeatureDependencies(chains)
+		if err != nil {
+			return err
+		}
+		if confirmed {
+			for _, d := range deps {
+				configs = append(configs, d.FeatureConfig)
+			}
+		}
+	}
+
+	// Show what is about to be written once new features are configured
+	if len(added) > 0 {
+		ok, err := confirm(fmt.Sprintf("Write %d feature(s)?", len(configs)), featureWriteSummary(configs))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
 	}
 
 	// Write features
 	if err := feature.ReplaceAll(absFolder, configs); err != nil {
 		return fmt.Errorf("replacing features: %w", err)
 	}
+	catalog.AddRecent("features", added...) //nolint:errcheck
 	return nil
 }
 
+// featureWriteSummary lists each feature's ref and options as they will be
+// written, one per line: "ghcr.io/devcontainers/features/node:1 with
+// {version: lts}".
+func featureWriteSummary(configs []feature.FeatureConfig) string {
+	lines := make([]string, len(configs))
+	for i, c := range configs {
+		lines[i] = "  - " + c.OciRef
+		if len(c.Options) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(c.Options))
+		for k := range c.Options {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		opts := make([]string, len(keys))
+		for j, k := range keys {
+			opts[j] = fmt.Sprintf("%s: %v", k, c.Options[k])
+		}
+		lines[i] += " with {" + strings.Join(opts, ", ") + "}"
+	}
+	return strings.Join(lines, "\n")
+}
+
 func runExtensionsFlow(absFolder string) error {
-	existing := extractStringList(absFolder, "customizations", "vscode", "extensions")
+	existing, err := extractStringSlice(absFolder, vscodeExtensions)
+	if err != nil {
+		return err
+	}
 
+	// The picker returns the write order (alphabetical unless reordered).
 	selected, err := ui.PickExtensions(existing)
 	if errors.Is(err, ui.ErrPickerCancelled) {
 		return nil
@@ -253,14 +574,28 @@ func runExtensionsFlow(absFolder string) error {
 		return err
 	}
 
-	sort.Strings(selected)
-	return writeCustomizationList(absFolder, selected, "vscode", "extensions")
+	return writeCustomizationList(absFolder, selected, vscodeExtensions)
 }
 
 func runPluginsFlow(absFolder string) error {
-	existing := extractStringList(absFolder, "customizations", "jetbrains", "plugins")
+	existing, err := extractStringSlice(absFolder, jetbrainsPlugins)
+	if err != nil {
+		return err
+	}
+
+	// Match pinned "xmlId:version" entries by ID, keeping the version. Entries
+	// differing only in case select the plugin once.
+	preSelected := make(map[string]bool, len(existing))
+	pinned := make(map[string]string)
+	for _, entry := range marketplace.DedupePlugins(existing) {
+		id, version := marketplace.SplitPluginRef(entry)
+		preSelected[id] = true
+		if version != "" {
+			pinned[id] = version
+		}
+	}
 
-	selected, err := ui.PickPlugins(existing)
+	selected, err := ui.PickPlugins(preSelected, pinned)
 	if errors.Is(err, ui.ErrPickerCancelled) {
 		return nil
 	}
@@ -269,7 +604,19 @@ func runPluginsFlow(absFolder string) error {
 	}
 
 	sort.Strings(selected)
-	return writeCustomizationList(absFolder, selected, "jetbrains", "plugins")
+	return writeCustomizationList(absFolder, selected, jetbrainsPlugins)
+}
+
+func runPresetFlow(absFolder string) error {
+	names, err := preset.List()
+	if err != nil {
+		return err
+	}
+	name, err := ui.PickPreset(names)
+	if err != nil || name == "" {
+		return err
+	}
+	return preset.Apply(absFolder, name)
 }
 
 func runCustomizationsFlow(absFolder string) error {
@@ -278,56 +625,174 @@ func runCustomizationsFlow(absFolder string) error {
 
 // --- helpers ---
 
-func extractStringList(absFolder, topKey, ideKey, listKey string) map[string]bool {
-	result := make(map[string]bool)
-	if !devcontainer.Exists(absFolder) {
-		return result
+// pickVersionedRef offers the tags published for ociRef and returns the ref
+// with the chosen tag. If the tags can't be listed (offline, unsupported
+// registry) the ref is returned unchanged.
+func pickVersionedRef(name, ociRef string) (string, error) {
+	tags, err := registry.FetchVersionTags(ociRef)
+	if err != nil || len(tags) < 2 {
+		return ociRef, nil
 	}
-	config, _, err := devcontainer.ReadConfig(absFolder)
+	_, _, current, _ := registry.ParseOciRef(ociRef)
+	tag, err := ui.PickVersion(name, tags, current)
 	if err != nil {
-		return result
+		return "", err
 	}
-	top, _ := config[topKey].(map[string]any)
-	ide, _ := top[ideKey].(map[string]any)
-	items, _ := ide[listKey].([]any)
-	for _, item := range items {
-		if s, ok := item.(string); ok {
-			result[s] = true
+	return devcontainer.FeatureID(ociRef) + ":" + tag, nil
+}
+
+// dependencyChain renders a dependency as "node → common-utils (ref)" using
+// the features' short names.
+func dependencyChain(d feature.Dependency) string {
+	names := make([]string, len(d.Chain))
+	for i, ref := range d.Chain {
+		names[i] = path.Base(devcontainer.FeatureID(ref))
+	}
+	return fmt.Sprintf("%s (%s)", strings.Join(names, " → "), d.OciRef)
+}
+
+// warnRedundantFeatures points out newly selected features that the official
+// template behind image likely provides already. Its metadata is only
+// fetched when there is something to check; a fetch failure falls back to
+// the built-in heuristic.
+func warnRedundantFeatures(image string, selected []catalog.CatalogEntry, existingRefs map[string]string, noCache bool) error {
+	templateID := feature.OfficialTemplate(image)
+	if templateID == "" {
+		return nil
+	}
+	var refs []string
+	for _, f := range selected {
+		if _, existed := existingRefs[devcontainer.FeatureID(f.OciRef)]; !existed {
+			refs = append(refs, f.OciRef)
 		}
 	}
-	return result
+	if len(refs) == 0 {
+		return nil
+	}
+
+	tmpl, _, _ := registry.FetchItemMetadata("ghcr.io/devcontainers/templates/"+templateID, noCache)
+	redundant := feature.Redundancies(templateID, tmpl, refs)
+	if len(redundant) == 0 {
+		return nil
+	}
+	name := templateID
+	if tmpl != nil && tmpl.Name != "" {
+		name = tmpl.Name
+	}
+	return ui.WarnRedundantFeatures(name, redundant)
 }
 
-func writeCustomizationList(absFolder string, items []string, ideKey, listKey string) error {
-	config, configPath, err := devcontainer.ReadConfig(absFolder)
+// removedFeatures returns the configured refs (sorted) of features listed
+// in features, the catalog the picker showed, that are missing from the new
+// selection, compared without version tags. Refs the catalog doesn't list,
+// such as local, tarball or private-registry features, can't be picked, so
+// they are never removed (see uncatalogedFeatures).
+func removedFeatures(configured []string, features, selected []catalog.CatalogEntry) []string {
+	listed := featureIDs(features)
+	kept := featureIDs(selected)
+	var removed []string
+	for _, ref := range configured {
+		if id := devcontainer.FeatureID(ref); listed[id] && !kept[id] {
+			removed = append(removed, ref)
+		}
+	}
+	sort.Strings(removed)
+	return removed
+}
+
+// uncatalogedFeatures returns the configured refs (sorted) that features,
+// the catalog the picker showed, doesn't list. They are kept as configured.
+func uncatalogedFeatures(configured []string, features []catalog.CatalogEntry) []string {
+	listed := featureIDs(features)
+	var rest []string
+	for _, ref := range configured {
+		if !listed[devcontainer.FeatureID(ref)] {
+			rest = append(rest, ref)
+		}
+	}
+	sort.Strings(rest)
+	return rest
+}
+
+// featureIDs returns the versionless refs of entries.
+func featureIDs(entries []catalog.CatalogEntry) map[string]bool {
+	ids := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		ids[devcontainer.FeatureID(e.OciRef)] = true
+	}
+	return ids
+}
+
+// customizationList is a string list in an IDE namespace of customizations,
+// e.g. customizations.vscode.extensions. Supporting another IDE's list only
+// takes another value.
+type customizationList struct {
+	ide    string
+	key    string
+	dedupe func([]string) []string // drops duplicate entries, nil keeps all
+}
+
+var (
+	vscodeExtensions = customizationList{"vscode", "extensions", marketplace.DedupeExtensions}
+	jetbrainsPlugins = customizationList{"jetbrains", "plugins", marketplace.DedupePlugins}
+)
+
+func extractStringList(absFolder string, list customizationList) (map[string]bool, error) {
+	items, err := extractStringSlice(absFolder, list)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	result := make(map[string]bool, len(items))
+	for _, s := range items {
+		result[s] = true
 	}
+	return result, nil
+}
 
-	customizations, _ := config["customizations"].(map[string]any)
-	if customizations == nil {
-		customizations = make(map[string]any)
+// extractStringSlice returns the entries of a customizations list in their
+// configured order, or nil without a config. A config that doesn't parse or
+// a list that isn't one of strings is an error, rather than an empty list
+// that a write would then replace.
+func extractStringSlice(absFolder string, list customizationList) ([]string, error) {
+	if !devcontainer.Exists(absFolder) {
+		return nil, nil
 	}
-	ide, _ := customizations[ideKey].(map[string]any)
-	if ide == nil {
-		ide = make(map[string]any)
+	config, _, err := devcontainer.ReadConfig(absFolder)
+	if err != nil {
+		return nil, err
+	}
+	raw, ok := devcontainer.Customization(config, list.ide)[list.key]
+	if !ok {
+		return nil, nil
 	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("customizations.%s.%s is not a list", list.ide, list.key)
+	}
+	result := make([]string, 0, len(items))
+	for i, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("customizations.%s.%s[%d] is not a string", list.ide, list.key, i)
+		}
+		result = append(result, s)
+	}
+	return result, nil
+}
 
-	if len(items) > 0 {
-		ide[listKey] = items
-	} else {
-		delete(ide, listKey)
+func writeCustomizationList(absFolder string, items []string, list customizationList) error {
+	config, configPath, err := devcontainer.ReadConfig(absFolder)
+	if err != nil {
+		return err
 	}
 
-	if len(ide) > 0 {
-		customizations[ideKey] = ide
-	} else {
-		delete(customizations, ideKey)
+	if list.dedupe != nil {
+		items = list.dedupe(items)
 	}
-	if len(customizations) > 0 {
-		config["customizations"] = customizations
+	if len(items) > 0 {
+		devcontainer.SetCustomization(config, list.ide, list.key, items)
 	} else {
-		delete(config, "customizations")
+		devcontainer.SetCustomization(config, list.ide, list.key, nil)
 	}
 
 	return devcontainer.WriteConfig(configPath, config)
@@ -336,55 +801,268 @@ func writeCustomizationList(absFolder string, items []string, ideKey, listKey st
 // templateKeys are the keys that `devcontainer templates apply` legitimately owns.
 // These get overwritten by the template; everything else is preserved.
 var templateKeys = map[string]bool{
-	"image":                true,
-	"build":                true,
-	"dockerFile":           true,
-	"dockerComposeFile":    true,
-	"service":              true,
-	"workspaceFolder":      true,
-	"workspaceMount":       true,
-	"hostRequirements":     true,
-}
-
-// applyTemplatePreservingSettings applies a template and then restores any
-// user-configured settings (features, extensions, lifecycle commands, etc.)
-// that were present before the template overwrote devcontainer.json.
-func applyTemplatePreservingSettings(absFolder, ociRef string, opts map[string]any) error {
-	// Read existing config before applying — if none exists, just apply.
-	oldConfig, configPath, err := devcontainer.ReadConfig(absFolder)
+	"image":             true,
+	"build":             true,
+	"dockerFile":        true,
+	"dockerComposeFile": true,
+	"service":           true,
+	"workspaceFolder":   true,
+	"workspaceMount":    true,
+	"hostRequirements":  true,
+}
+
+// applyTemplatePreservingSettings applies a template and merges the settings
+// configured before (features, extensions, lifecycle commands, etc.) back
+// into what the template wrote. With layer, the previous base (image, build,
+// ...) is kept as well, so the template only adds to the config. It returns
+// the values both changed, for resolveTemplateConflicts.
+func applyTemplatePreservingSettings(absFolder string, apply templateApplier, layer bool) ([]devcontainer.Conflict, error) {
+	return preserveSettings(absFolder, layer, func() ([]byte, error) {
+		out, err := applyTemplateScratch(apply)
+		if err != nil {
+			return nil, err
+		}
+		if !stdoutMode {
+			if err := writeTemplateFiles(absFolder, out.files); err != nil {
+				return nil, err
+			}
+		}
+		return out.config, nil
+	})
+}
+
+// createEmptyPreservingSettings writes the empty template the same way.
+func createEmptyPreservingSettings(absFolder, projectName string, layer bool) ([]devcontainer.Conflict, error) {
+	return preserveSettings(absFolder, layer, func() ([]byte, error) {
+		config, err := template.EmptyConfig(projectName)
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := devcontainer.WriteConfigTo(&buf, config, nil); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	})
+}
+
+// preserveSettings three-way merges the config with the template's config
+// that generate returns, after writing the template's other files into the
+// workspace, and writes the result once, so the config is never left with
+// the template's output alone. The merge base is what the template last
+// applied to this config generated (devcontainer.Merge3), so settings added
+// since are kept and settings only the template changed are updated; values
+// both changed keep the config's and are returned as conflicts. Without
+// layer, templateKeys come from the new template, with layer from the
+// config where it has them.
+func preserveSettings(absFolder string, layer bool, generate func() ([]byte, error)) ([]devcontainer.Conflict, error) {
+	mine, configPath, readErr := devcontainer.ReadConfig(absFolder)
+	generated, err := generate()
 	if err != nil {
-		// No existing config — apply template directly.
-		return template.Apply(absFolder, ociRef, opts)
+		return nil, err
+	}
+	var theirs map[string]any
+	if err := json.Unmarshal(devcontainer.ToJSON(generated), &theirs); err != nil {
+		return nil, fmt.Errorf("parsing applied template: %w", err)
+	}
+
+	// Without a config there is nothing to keep: write the template as is.
+	if readErr != nil {
+		if err := devcontainer.WriteFile(configPath, generated); err != nil {
+			return nil, err
+		}
+		saveTemplateBase(configPath, theirs)
+		return nil, nil
 	}
+	base := template.LoadBase(templateBaseKey(configPath))
 
-	// Save non-template keys.
-	preserved := make(map[string]any)
-	for k, v := range oldConfig {
+	merged, conflicts := devcontainer.Merge3(withoutTemplateKeys(base), withoutTemplateKeys(mine), withoutTemplateKeys(theirs))
+	for k := range templateKeys {
+		from := theirs
+		if _, ok := mine[k]; ok && layer {
+			from = mine
+		}
+		if v, ok := from[k]; ok {
+			merged[k] = v
+		}
+	}
+	// Keys the config lacks are laid out as the template wrote them.
+	if err := devcontainer.WriteConfigKeeping(configPath, merged, generated); err != nil {
+		return nil, err
+	}
+	saveTemplateBase(configPath, theirs)
+	return conflicts, nil
+}
+
+// withoutTemplateKeys returns a copy of config without templateKeys, or nil
+// for a nil config.
+func withoutTemplateKeys(config map[string]any) map[string]any {
+	if config == nil {
+		return nil
+	}
+	rest := make(map[string]any, len(config))
+	for k, v := range config {
 		if !templateKeys[k] {
-			preserved[k] = v
+			rest[k] = v
 		}
 	}
+	return rest
+}
 
-	// Apply the template (overwrites devcontainer.json).
-	if err := template.Apply(absFolder, ociRef, opts); err != nil {
-		return err
+// templateBaseKey identifies the config at configPath, on the --host if set,
+// for the template base store.
+func templateBaseKey(configPath string) string {
+	if remoteHost != "" {
+		return remoteHost + ":" + configPath
 	}
+	return configPath
+}
 
-	// If there was nothing to preserve, we're done.
-	if len(preserved) == 0 {
+// saveTemplateBase records what a template generated for configPath as the
+// base of the next apply. Under --stdout the config isn't written, so
+// neither is its base.
+func saveTemplateBase(configPath string, generated map[string]any) {
+	if !stdoutMode {
+		template.SaveBase(templateBaseKey(configPath), generated) //nolint:errcheck // without a base the next apply asks more
+	}
+}
+
+// resolveTemplateConflicts asks which value to keep where both the config and
+// the applied template changed one. The merge kept the config's, so only the
+// template's picks are written.
+func resolveTemplateConflicts(absFolder string, conflicts []devcontainer.Conflict) error {
+	if len(conflicts) == 0 {
 		return nil
 	}
+	resolved, err := ui.ResolveTemplateConflicts(conflicts)
+	if err != nil || len(resolved) == 0 {
+		return err
+	}
+	config, configPath, err := devcontainer.ReadConfig(absFolder)
+	if err != nil {
+		return err
+	}
+	for _, c := range resolved {
+		devcontainer.SetTheirs(config, c)
+	}
+	return devcontainer.WriteConfig(configPath, config)
+}
+
+// templateApplier applies the chosen template into a folder.
+type templateApplier func(folder string) error
+
+// ociTemplate applies an OCI template with `devcontainer templates apply`.
+func ociTemplate(ociRef string, opts map[string]any) templateApplier {
+	return func(folder string) error {
+		return template.Apply(folder, ociRef, opts)
+	}
+}
 
-	// Read the new config written by the template.
-	newConfig, _, err := devcontainer.ReadConfig(absFolder)
+// repoTemplate copies a template folder from a --template-repo clone.
+func repoTemplate(templateDir string, opts map[string]any) templateApplier {
+	return func(folder string) error {
+		return template.ApplyLocal(folder, templateDir, opts)
+	}
+}
+
+// applyTemplate applies a template in a scratch folder and writes what it
+// generated into the workspace: its devcontainer.json to configPath, which
+// may be another location than the .devcontainer/devcontainer.json
+// templates write, and its other files next to where they were written.
+// The config and the files it replaces are backed up first (see
+// writeTemplateFiles). Under --stdout only the config is kept, in memory;
+// with --host everything goes to the remote workspace.
+func applyTemplate(absFolder, configPath string, apply templateApplier) error {
+	out, err := applyTemplateScratch(apply)
 	if err != nil {
-		return fmt.Errorf("reading config after template apply: %w", err)
+		return err
 	}
+	if !stdoutMode {
+		if err := writeTemplateFiles(absFolder, out.files); err != nil {
+			return err
+		}
+	}
+	if err := devcontainer.BackupConfig(configPath); err != nil {
+		return err
+	}
+	return devcontainer.WriteFile(configPath, out.config)
+}
+
+// templateOutput is what a template wrote into a scratch folder.
+type templateOutput struct {
+	config []byte         // .devcontainer/devcontainer.json
+	files  []templateFile // everything else, e.g. a Dockerfile or scripts
+}
+
+// templateFile is a file a template wrote, other than its config.
+type templateFile struct {
+	path string // relative to the workspace folder
+	data []byte
+	mode os.FileMode
+}
+
+// applyTemplateScratch applies a template in a temporary folder and returns
+// what it wrote there.
+func applyTemplateScratch(apply templateApplier) (templateOutput, error) {
+	dir, err := os.MkdirTemp("", "dcc-template-")
+	if err != nil {
+		return templateOutput{}, fmt.Errorf("creating scratch folder: %w", err)
+	}
+	defer os.RemoveAll(dir)
 
-	// Merge preserved keys back — user settings win on conflict.
-	for k, v := range preserved {
-		newConfig[k] = v
+	if err := apply(dir); err != nil {
+		return templateOutput{}, err
+	}
+	var out templateOutput
+	configPath := devcontainer.DefaultConfigPath(dir)
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if path == configPath {
+			out.config = data
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		out.files = append(out.files, templateFile{path: rel, data: data, mode: info.Mode().Perm()})
+		return nil
+	})
+	if err != nil {
+		return templateOutput{}, fmt.Errorf("reading applied template: %w", err)
 	}
+	if out.config == nil {
+		return templateOutput{}, fmt.Errorf("reading applied template: no %s written", filepath.Join(".devcontainer", "devcontainer.json"))
+	}
+	return out, nil
+}
 
-	return devcontainer.WriteConfig(configPath, newConfig)
+// writeTemplateFiles writes files into the workspace absFolder through the
+// devcontainer FileSystem, keeping executable modes of scripts. Files it
+// changes, such as an existing Dockerfile, are backed up like the config.
+func writeTemplateFiles(absFolder string, files []templateFile) error {
+	for _, f := range files {
+		path := filepath.Join(absFolder, f.path)
+		if existing, err := devcontainer.ReadFile(path); err == nil && !bytes.Equal(existing, f.data) {
+			if err := devcontainer.BackupConfig(path); err != nil {
+				return err
+			}
+		}
+		if err := devcontainer.WriteFile(path, f.data); err != nil {
+			return err
+		}
+		if f.mode&0o111 != 0 {
+			if err := devcontainer.Chmod(path, f.mode); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/catalog"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/feature"
+	"github.com/mochlast/devcontainer-companion/internal/template"
+)
+
+func TestPreserveSettings(t *testing.T) {
+	base := map[string]any{
+		"name":     "proj",
+		"image":    "mcr.microsoft.com/devcontainers/base:ubuntu",
+		"features": map[string]any{"ghcr.io/devcontainers/features/node:1": map[string]any{}},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go"}},
+		},
+	}
+	// What a tooling template writes over the existing config.
+	tooling := map[string]any{
+		"name":     "Python 3",
+		"image":    "mcr.microsoft.com/devcontainers/python:3",
+		"features": map[string]any{"ghcr.io/devcontainers/features/python:1": map[string]any{}},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"ms-python.python"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		layer     bool
+		wantImage string
+	}{
+		{"replace", false, "mcr.microsoft.com/devcontainers/python:3"},
+		{"layer", true, "mcr.microsoft.com/devcontainers/base:ubuntu"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			dir := t.TempDir()
+			path := devcontainer.ConfigPath(dir)
+			if err := devcontainer.WriteConfig(path, base); err != nil {
+				t.Fatal(err)
+			}
+			conflicts, err := preserveSettings(dir, tt.layer, templateConfig(tooling))
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Without a recorded base the names differing is a conflict.
+			wantConflicts := []devcontainer.Conflict{{Path: []string{"name"}, Mine: "proj", Theirs: "Python 3"}}
+			if !reflect.DeepEqual(conflicts, wantConflicts) {
+				t.Errorf("conflicts = %v, want %v", conflicts, wantConflicts)
+			}
+
+			got, _, err := devcontainer.ReadConfig(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got["image"] != tt.wantImage {
+				t.Errorf("image = %v, want %v", got["image"], tt.wantImage)
+			}
+			if got["name"] != "proj" {
+				t.Errorf("name = %v, want the configured one", got["name"])
+			}
+			wantFeatures := map[string]any{
+				"ghcr.io/devcontainers/features/node:1":   map[string]any{},
+				"ghcr.io/devcontainers/features/python:1": map[string]any{},
+			}
+			if !reflect.DeepEqual(got["features"], wantFeatures) {
+				t.Errorf("features = %v, want both templates' features", got["features"])
+			}
+			exts := got["customizations"].(map[string]any)["vscode"].(map[string]any)["extensions"]
+			if !reflect.DeepEqual(exts, []any{"golang.go", "ms-python.python"}) {
+				t.Errorf("extensions = %v", exts)
+			}
+		})
+	}
+}
+
+func TestPreserveSettingsWithoutConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	written := map[string]any{"image": "ubuntu"}
+	_, err := preserveSettings(dir, true, templateConfig(written))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, written) {
+		t.Errorf("config = %v, want the template as written", got)
+	}
+}
+
+func TestPreserveSettingsAgainstBase(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	v1 := map[string]any{
+		"name":              "Go",
+		"image":             "mcr.microsoft.com/devcontainers/go:1",
+		"postCreateCommand": "go mod download",
+		"remoteUser":        "vscode",
+	}
+	v2 := map[string]any{
+		"name":              "Go",
+		"image":             "mcr.microsoft.com/devcontainers/go:2",
+		"postCreateCommand": "go mod download && make tools",
+		"remoteUser":        "gopher",
+	}
+
+	// The first apply records its output as the base of the next.
+	if _, err := preserveSettings(dir, false, templateConfig(v1)); err != nil {
+		t.Fatal(err)
+	}
+	config, path, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config["remoteUser"] = "root"
+	config["forwardPorts"] = []any{8080.0}
+	if err := devcontainer.WriteConfig(path, config); err != nil {
+		t.Fatal(err)
+	}
+
+	conflicts, err := preserveSettings(dir, false, templateConfig(v2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":              "Go",
+		"image":             "mcr.microsoft.com/devcontainers/go:2",
+		"postCreateCommand": "go mod download && make tools",
+		"remoteUser":        "root",
+		"forwardPorts":      []any{8080.0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("config = %v, want %v", got, want)
+	}
+	wantConflicts := []devcontainer.Conflict{{Path: []string{"remoteUser"}, Mine: "root", Theirs: "gopher"}}
+	if !reflect.DeepEqual(conflicts, wantConflicts) {
+		t.Errorf("conflicts = %v, want %v", conflicts, wantConflicts)
+	}
+}
+
+func TestPreserveSettingsFailedApply(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	path := devcontainer.ConfigPath(dir)
+	if err := devcontainer.WriteConfig(path, map[string]any{"image": "ubuntu", "remoteUser": "root"}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	// The template is applied in a scratch folder, so neither a failed apply
+	// nor one whose config doesn't parse touches the config.
+	for _, generate := range []func() ([]byte, error){
+		func() ([]byte, error) { return nil, errors.New("template apply failed") },
+		func() ([]byte, error) { return []byte("{"), nil },
+	} {
+		if _, err := preserveSettings(dir, false, generate); err == nil {
+			t.Error("preserveSettings succeeded, want the apply's error")
+		}
+		if after, _ := os.ReadFile(path); string(after) != string(before) {
+			t.Errorf("config = %s, want it unchanged", after)
+		}
+	}
+}
+
+func TestPreserveSettingsBacksUpOnce(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	devcontainer.SetBackups(5)
+	t.Cleanup(func() { devcontainer.SetBackups(0) })
+	dir := t.TempDir()
+	path := devcontainer.ConfigPath(dir)
+	if err := devcontainer.WriteConfig(path, map[string]any{"image": "ubuntu", "remoteUser": "root"}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := os.ReadFile(path)
+
+	if _, err := preserveSettings(dir, false, templateConfig(map[string]any{"image": "debian"})); err != nil {
+		t.Fatal(err)
+	}
+	found, _ := devcontainer.Backups(path)
+	if len(found) != 1 {
+		t.Fatalf("Backups = %q, want one for the apply", found)
+	}
+	if data, _ := os.ReadFile(found[0]); string(data) != string(before) {
+		t.Errorf("backup = %s, want the config before the apply", data)
+	}
+}
+
+// templateConfig stands in for a template apply that generates config.
+func templateConfig(config map[string]any) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		var buf bytes.Buffer
+		err := devcontainer.WriteConfigTo(&buf, config, nil)
+		return buf.Bytes(), err
+	}
+}
+
+func TestApplyTemplateRemoteCopiesFiles(t *testing.T) {
+	// The FileSystem stays local, so the "remote" workspace is a temp dir.
+	remoteHost = "dev@server"
+	t.Cleanup(func() { remoteHost = "" })
+
+	files := map[string]string{
+		".devcontainer/devcontainer.json": `{"build": {"dockerfile": "Dockerfile"}, "postCreateCommand": ".devcontainer/post-create.sh"}`,
+		".devcontainer/Dockerfile":        "FROM mcr.microsoft.com/devcontainers/base:ubuntu\n",
+		".devcontainer/post-create.sh":    "#!/bin/sh\nnpm ci\n",
+		".github/dependabot.yml":          "version: 2\n",
+	}
+	apply := func(folder string) error {
+		for rel, data := range files {
+			path := filepath.Join(folder, rel)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return err
+			}
+			mode := os.FileMode(0o644)
+			if strings.HasSuffix(rel, ".sh") {
+				mode = 0o755
+			}
+			if err := os.WriteFile(path, []byte(data), mode); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	dir := t.TempDir()
+	if err := applyTemplate(dir, devcontainer.DefaultConfigPath(dir), apply); err != nil {
+		t.Fatal(err)
+	}
+	for rel, want := range files {
+		if got, err := os.ReadFile(filepath.Join(dir, rel)); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v; want %q", rel, got, err, want)
+		}
+	}
+	info, err := os.Stat(filepath.Join(dir, ".devcontainer", "post-create.sh"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("post-create.sh mode = %v, want it executable", info.Mode().Perm())
+	}
+}
+
+func TestRemovedFeatures(t *testing.T) {
+	features := []catalog.CatalogEntry{
+		{OciRef: "ghcr.io/devcontainers/features/node:1"},
+		{OciRef: "ghcr.io/devcontainers/features/go:1"},
+		{OciRef: "ghcr.io/devcontainers/features/python:1"},
+	}
+	configured := []string{
+		"ghcr.io/devcontainers/features/node:1.2.0", // pinned, still selected
+		"ghcr.io/devcontainers/features/go@sha256:abc",
+		"ghcr.io/devcontainers/features/python:1",
+		"./local-feature",
+		"https://example.com/feature.tgz",
+		"registry.example.com/team/features/tools:2",
+	}
+	selected := []catalog.CatalogEntry{features[0]}
+
+	want := []string{"ghcr.io/devcontainers/features/go@sha256:abc", "ghcr.io/devcontainers/features/python:1"}
+	if got := removedFeatures(configured, features, selected); !reflect.DeepEqual(got, want) {
+		t.Errorf("removedFeatures = %q, want %q", got, want)
+	}
+	want = []string{"./local-feature", "https://example.com/feature.tgz", "registry.example.com/team/features/tools:2"}
+	if got := uncatalogedFeatures(configured, features); !reflect.DeepEqual(got, want) {
+		t.Errorf("uncatalogedFeatures = %q, want %q", got, want)
+	}
+	if got := removedFeatures(configured, features, features); len(got) != 0 {
+		t.Errorf("removedFeatures with everything selected = %q", got)
+	}
+}
+
+func TestPromptBuildOnExit(t *testing.T) {
+	installed := template.CLIInfo{Installed: true}
+	if !promptBuildOnExit(true, installed) {
+		t.Error("dirty config with the CLI installed does not prompt")
+	}
+	if promptBuildOnExit(false, installed) {
+		t.Error("clean config prompts")
+	}
+	if promptBuildOnExit(true, template.CLIInfo{}) {
+		t.Error("prompts without the devcontainer CLI")
+	}
+
+	assumeYes = true
+	t.Cleanup(func() { assumeYes = false })
+	if promptBuildOnExit(true, installed) {
+		t.Error("--yes still prompts")
+	}
+}
+
+func TestFlowContext(t *testing.T) {
+	dir := t.TempDir()
+	if ctx := flowContext(dir); ctx.Config != nil || ctx.Dirty {
+		t.Fatalf("flowContext without a config = %+v, want no config and not dirty", ctx)
+	}
+
+	config := map[string]any{"name": "proj", "image": "mcr.microsoft.com/devcontainers/base:ubuntu"}
+	if err := devcontainer.WriteConfig(devcontainer.ConfigPath(dir), config); err != nil {
+		t.Fatal(err)
+	}
+	ctx := flowContext(dir)
+	if !reflect.DeepEqual(ctx.Config, config) {
+		t.Errorf("Config = %v, want %v", ctx.Config, config)
+	}
+	if want := filepath.Base(dir); ctx.ProjectName != want {
+		t.Errorf("ProjectName = %q, want %q", ctx.ProjectName, want)
+	}
+}
+
+func TestExtractStringSliceErrors(t *testing.T) {
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{`{"image": "ubuntu",`, "parsing devcontainer.json"},
+		{`{"customizations": {"vscode": {"extensions": "golang.go"}}}`, "customizations.vscode.extensions is not a list"},
+		{`{"customizations": {"vscode": {"extensions": ["golang.go", 42]}}}`, "customizations.vscode.extensions[1] is not a string"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		path := devcontainer.DefaultConfigPath(dir)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := extractStringSlice(dir, vscodeExtensions); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: extractStringSlice = %v, %v; want an error containing %q", tt.config, got, err, tt.want)
+		}
+	}
+
+	if got, err := extractStringSlice(t.TempDir(), vscodeExtensions); got != nil || err != nil {
+		t.Errorf("without a config: extractStringSlice = %v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestWriteCustomizationListDedupes(t *testing.T) {
+	dir := t.TempDir()
+	if err := devcontainer.WriteConfig(devcontainer.DefaultConfigPath(dir), map[string]any{"image": "ubuntu"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCustomizationList(dir, []string{"ms-python.python", "MS-Python.Python@2024.2.0", "golang.go"}, vscodeExtensions); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeCustomizationList(dir, []string{"com.intellij.python", "com.intellij.Python:241.1"}, jetbrainsPlugins); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := extractStringSlice(dir, vscodeExtensions); err != nil || !reflect.DeepEqual(got, []string{"ms-python.python", "golang.go"}) {
+		t.Errorf("extensions = %v, %v", got, err)
+	}
+	if got, err := extractStringSlice(dir, jetbrainsPlugins); err != nil || !reflect.DeepEqual(got, []string{"com.intellij.python"}) {
+		t.Errorf("plugins = %v, %v", got, err)
+	}
+	if !slices.ContainsFunc([]string{"ms-python.python"}, isExtension("MS-PYTHON.python")) {
+		t.Error("isExtension should ignore case")
+	}
+}
+
+func TestFeatureWriteSummary(t *testing.T) {
+	got := featureWriteSummary([]feature.FeatureConfig{
+		{OciRef: "ghcr.io/devcontainers/features/node:1", Options: map[string]any{"version": "lts", "nvmVersion": "latest"}},
+		{OciRef: "ghcr.io/devcontainers/features/github-cli:1"},
+		{OciRef: "ghcr.io/devcontainers/features/docker-in-docker:2", Options: map[string]any{"moby": false}},
+	})
+	want := "  - ghcr.io/devcontainers/features/node:1 with {nvmVersion: latest, version: lts}\n" +
+		"  - ghcr.io/devcontainers/features/github-cli:1\n" +
+		"  - ghcr.io/devcontainers/features/docker-in-docker:2 with {moby: false}"
+	if got != want {
+		t.Errorf("featureWriteSummary =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestResolveFlowWorkspaceFreshFolder(t *testing.T) {
+	dir := t.TempDir()
+	oldFolder, oldExplicit := workspaceFolder, explicitFolder
+	workspaceFolder, explicitFolder = dir, true
+	t.Cleanup(func() { workspaceFolder, explicitFolder = oldFolder, oldExplicit })
+
+	// The scaffolded config is written but isn't one to replace or layer.
+	absFolder, ctx, err := resolveFlowWorkspace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !devcontainer.Exists(absFolder) || ctx.Config != nil {
+		t.Errorf("fresh folder: config exists = %v, ctx.Config = %v; want a scaffold outside the context", devcontainer.Exists(absFolder), ctx.Config)
+	}
+
+	if _, ctx, err = resolveFlowWorkspace(); err != nil || len(ctx.Config) == 0 {
+		t.Errorf("existing config: ctx.Config = %v, %v; want the config", ctx.Config, err)
+	}
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/catalog"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/feature"
+	"github.com/mochlast/devcontainer-companion/internal/marketplace"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+	"github.com/mochlast/devcontainer-companion/internal/template"
+	"github.com/mochlast/devcontainer-companion/internal/ui"
+)
+
+var (
+	initTemplate        string
+	initTemplateOptions []string
+	initFeatures        []string
+	initExtensions      []string
+	initPorts           []string
+	initForce           bool
+)
+
+var initCmd = &cobra.Command{
+	Use:         "init",
+	Annotations: composesConfig,
+	Short:       "Scaffold a devcontainer.json from flags without opening the hub",
+	Long: `Compose a complete devcontainer.json in one shot: apply a template, add
+features, extensions and forwarded ports, then print the result.
+
+Templates and features can be given as full OCI references or as short names
+from the containers.dev catalog (e.g. "python", "node"). For a short feature
+name, "name:value" sets the feature's version option (e.g. node:lts).`,
+	Example: "  dcc init --template python --feature node:lts --extension ms-python.python --port 8000",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := absWorkspace()
+		if err != nil {
+			return err
+		}
+
+		// Resolve and validate everything before the first write, so a bad
+		// ref or option leaves an existing config untouched.
+		apply, err := resolveInitTemplate()
+		if err != nil {
+			return err
+		}
+		features := make([]feature.FeatureConfig, 0, len(initFeatures))
+		for _, f := range initFeatures {
+			fc, err := resolveInitFeature(f)
+			if err != nil {
+				return err
+			}
+			features = append(features, fc)
+		}
+		extensions := make([]string, 0, len(initExtensions))
+		for _, e := range initExtensions {
+			id := strings.TrimSpace(e)
+			if err := validateExtensionID(id); err != nil {
+				return err
+			}
+			extensions = append(extensions, id)
+		}
+
+		configPath := devcontainer.ConfigPath(absFolder)
+		if devcontainer.Exists(absFolder) && !initForce {
+			ok, err := confirm(fmt.Sprintf("%s already exists. Overwrite it?", configPath), "Its settings are replaced by the composed config.")
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+
+		if apply == nil {
+			err = template.CreateEmpty(absFolder, filepath.Base(absFolder))
+		} else {
+			err = applyTemplate(absFolder, configPath, apply)
+		}
+		if err != nil {
+			return err
+		}
+		for _, fc := range features {
+			if err := feature.Add(absFolder, fc); err != nil {
+				return fmt.Errorf("adding feature %s: %w", fc.OciRef, err)
+			}
+		}
+		if len(extensions) > 0 {
+			existing, err := extractStringSlice(absFolder, vscodeExtensions)
+			if err != nil {
+				return err
+			}
+			// Like add extension: IDs already configured, in any casing or
+			// version, are kept; a custom order is kept, otherwise the list
+			// stays alphabetical.
+			list := marketplace.DedupeExtensions(append(slices.Clone(existing), extensions...))
+			if sort.StringsAreSorted(existing) {
+				sort.Strings(list)
+			}
+			if err := writeCustomizationList(absFolder, list, vscodeExtensions); err != nil {
+				return fmt.Errorf("writing extensions: %w", err)
+			}
+		}
+		if len(initPorts) > 0 {
+			if err := addForwardPorts(absFolder, initPorts); err != nil {
+				return err
+			}
+		}
+
+		if stdoutMode {
+			return nil // printed by the root command
+		}
+		data, err := devcontainer.ReadFile(configPath)
+		if err != nil {
+			return fmt.Errorf("reading devcontainer.json: %w", err)
+		}
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	},
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initTemplate, "template", "", "template name or OCI ref (default: empty base image)")
+	initCmd.Flags().StringArrayVar(&initTemplateOptions, "template-option", nil, "template option as KEY=VALUE (repeatable)")
+	initCmd.Flags().StringArrayVar(&initFeatures, "feature", nil, "feature name or OCI ref (repeatable)")
+	initCmd.Flags().StringArrayVar(&initExtensions, "extension", nil, "VS Code extension ID (repeatable)")
+	initCmd.Flags().StringArrayVar(&initPorts, "port", nil, "port to forward (repeatable)")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite an existing devcontainer.json")
+	rootCmd.AddCommand(initCmd)
+}
+
+// resolveInitTemplate resolves --template and checks --template-option
+// against its options. It returns nil without a template, for an empty
+// config.
+func resolveInitTemplate() (templateApplier, error) {
+	if initTemplate == "" {
+		if len(initTemplateOptions) > 0 {
+			return nil, fmt.Errorf("--template-option requires --template")
+		}
+		return nil, nil
+	}
+
+	opts, err := parseOptionFlags(initTemplateOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if templateRepo != "" && !looksLikeOciRef(initTemplate) {
+		templateDir, err := findRepoTemplate(initTemplate)
+		if err != nil {
+			return nil, err
+		}
+		if templateDir != "" {
+			tmplDef, err := template.LoadLocal(templateDir)
+			if err != nil {
+				return nil, err
+			}
+			if err := validateOptions(initTemplate, tmplDef.Options, opts); err != nil {
+				return nil, err
+			}
+			return repoTemplate(templateDir, opts), nil
+		}
+	}
+
+	ociRef, err := resolveCatalogRef(initTemplate, catalog.GetTemplates)
+	if err != nil {
+		return nil, fmt.Errorf("resolving template: %w", err)
+	}
+
+	tmplDef, _, err := registry.FetchItemMetadata(ociRef, noCache)
+	if err != nil {
+		return nil, fmt.Errorf("resolving template %s: %w", ociRef, err)
+	}
+	if tmplDef == nil {
+		return nil, fmt.Errorf("%s is not a template", ociRef)
+	}
+	if err := validateOptions(ociRef, tmplDef.Options, opts); err != nil {
+		return nil, err
+	}
+	return ociTemplate(ociRef, opts), nil
+}
+
+// findRepoTemplate returns the folder of the --template-repo template whose
+// id is name, or "" when the repo has none.
+func findRepoTemplate(name string) (string, error) {
+	entries, err := catalog.FetchTemplatesFromRepo(templateRepo)
+	if err != nil {
+		return "", fmt.Errorf("loading templates from %s: %w", templateRepo, err)
+	}
+	for _, e := range entries {
+		if strings.EqualFold(filepath.Base(e.LocalPath), name) {
+			return e.LocalPath, nil
+		}
+	}
+	return "", nil
+}
+
+// resolveInitFeature resolves a single --feature value and checks its
+// version option.
+func resolveInitFeature(value string) (feature.FeatureConfig, error) {
+	name, version := value, ""
+	if !looksLikeOciRef(value) {
+		name, version, _ = strings.Cut(value, ":")
+	}
+
+	ociRef, err := resolveCatalogRef(name, catalog.GetFeatures)
+	if err != nil {
+		return feature.FeatureConfig{}, fmt.Errorf("resolving feature: %w", err)
+	}
+
+	_, featDef, err := registry.FetchItemMetadata(ociRef, noCache)
+	if err != nil {
+		return feature.FeatureConfig{}, fmt.Errorf("resolving feature %s: %w", ociRef, err)
+	}
+	if featDef == nil {
+		return feature.FeatureConfig{}, fmt.Errorf("%s is not a feature", ociRef)
+	}
+
+	opts := make(map[string]any)
+	if version != "" {
+		opts["version"] = version
+	}
+	if err := validateOptions(ociRef, featDef.Options, opts); err != nil {
+		return feature.FeatureConfig{}, err
+	}
+	return feature.FeatureConfig{OciRef: ociRef, Options: opts}, nil
+}
+
+// addForwardPorts appends ports to forwardPorts, storing numeric ports as numbers.
+func addForwardPorts(absFolder string, ports []string) error {
+	config, configPath, err := devcontainer.ReadConfig(absFolder)
+	if err != nil {
+		return err
+	}
+
+	existing, _ := config["forwardPorts"].([]any)
+	for _, p := range ports {
+		p = strings.TrimSpace(p)
+		if n, err := strconv.Atoi(p); err == nil {
+			existing = append(existing, n)
+		} else {
+			existing = append(existing, p)
+		}
+	}
+	config["forwardPorts"] = existing
+
+	return devcontainer.WriteConfig(configPath, config)
+}
+
+// looksLikeOciRef reports whether s is a full OCI reference (registry host
+// followed by a path) rather than a catalog short name.
+func looksLikeOciRef(s string) bool {
+	host, _, ok := strings.Cut(strings.TrimPrefix(s, "oci://"), "/")
+	return ok && strings.ContainsAny(host, ".:")
+}
+
+// resolveCatalogRef returns name unchanged if it is a full OCI reference.
+// Otherwise it resolves a short name such as "node" or "devcontainers/node"
+// against the catalog with catalog.Resolve and returns the versioned
+// reference.
+func resolveCatalogRef(name string, load func(noCache bool) ([]catalog.CatalogEntry, error)) (string, error) {
+	if looksLikeOciRef(name) {
+		return name, nil
+	}
+
+	entries, err := load(noCache)
+	if err != nil {
+		return "", fmt.Errorf("loading catalog: %w", err)
+	}
+	entry, err := catalog.Resolve(entries, name)
+	if err != nil {
+		return "", err
+	}
+	return ui.FormatOciRefWithVersion(&entry), nil
+}
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// templateRepoFixture creates a Git repo with a "go" template in
+// src/go that writes a config and a Dockerfile.
+func templateRepoFixture(t *testing.T) string {
+	t.Helper()
+	repo := t.TempDir()
+	files := map[string]string{
+		"src/go/devcontainer-template.json":      `{"id": "go", "name": "Go", "options": {}}`,
+		"src/go/.devcontainer/devcontainer.json": `{"name": "Go", "build": {"dockerfile": "Dockerfile"}}`,
+		"src/go/.devcontainer/Dockerfile":        "FROM mcr.microsoft.com/devcontainers/go:1\n",
+	}
+	for rel, data := range files {
+		path := filepath.Join(repo, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=dcc", "-c", "user.email=dcc@example.com", "commit", "-q", "-m", "templates"},
+	} {
+		git := exec.Command("git", args...)
+		git.Dir = repo
+		if out, err := git.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	return repo
+}
+
+// runInit runs dcc init with args and resets its flags afterwards.
+func runInit(t *testing.T, args ...string) error {
+	t.Helper()
+	t.Cleanup(func() {
+		initTemplate, initTemplateOptions, initFeatures, initExtensions, initPorts, initForce = "", nil, nil, nil, nil, false
+		templateRepo, backupMode, workspaceFolder, explicitFolder = "", false, ".", false
+		devcontainer.SetBackups(0)
+		rootCmd.SetArgs(nil)
+		rootCmd.SetOut(nil)
+	})
+	rootCmd.SetArgs(append([]string{"init"}, args...))
+	rootCmd.SetOut(io.Discard)
+	return rootCmd.Execute()
+}
+
+func TestInitForceTemplateBacksUp(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("DCC_CACHE_DIR", t.TempDir())
+	repo := templateRepoFixture(t)
+
+	dir := t.TempDir()
+	configPath := devcontainer.DefaultConfigPath(dir)
+	dockerfile := filepath.Join(dir, ".devcontainer", "Dockerfile")
+	for path, data := range map[string]string{configPath: `{"image": "ubuntu"}`, dockerfile: "FROM ubuntu\n"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := runInit(t, "-w", dir, "--force", "--backup", "--template", "go", "--template-repo", "file://"+repo); err != nil {
+		t.Fatal(err)
+	}
+	for path, want := range map[string]string{configPath: `{"image": "ubuntu"}`, dockerfile: "FROM ubuntu\n"} {
+		found, err := devcontainer.Backups(path)
+		if err != nil || len(found) != 1 {
+			t.Fatalf("Backups(%s) = %q, %v; want one", filepath.Base(path), found, err)
+		}
+		if data, _ := os.ReadFile(found[0]); string(data) != want {
+			t.Errorf("backup of %s = %q, want %q", filepath.Base(path), data, want)
+		}
+	}
+	if data, _ := os.ReadFile(dockerfile); string(data) != "FROM mcr.microsoft.com/devcontainers/go:1\n" {
+		t.Errorf("Dockerfile = %q, want the template's", data)
+	}
+}
+
+func TestInitValidatesBeforeWriting(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	dir := t.TempDir()
+	configPath := devcontainer.DefaultConfigPath(dir)
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	const existing = `{"image": "ubuntu"}`
+	if err := os.WriteFile(configPath, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("invalid extension", func(t *testing.T) {
+		// It fails before the existing config is replaced.
+		if err := runInit(t, "-w", dir, "--force", "--extension", "ms-python.python", "--extension", "not an id"); err == nil {
+			t.Fatal("expected an invalid extension ID to fail")
+		}
+		if data, _ := os.ReadFile(configPath); string(data) != existing {
+			t.Errorf("config = %s, want it untouched", data)
+		}
+	})
+
+	t.Run("duplicate extensions", func(t *testing.T) {
+		// IDs are compared case-insensitively, the first wins.
+		if err := runInit(t, "-w", dir, "--force", "--extension", "MS-Python.python", "--extension", "ms-python.python"); err != nil {
+			t.Fatal(err)
+		}
+		config, _, err := devcontainer.ReadConfig(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, _ := devcontainer.Customization(config, "vscode")["extensions"].([]any)
+		if len(got) != 1 || got[0] != "MS-Python.python" {
+			t.Errorf("extensions = %v, want [MS-Python.python]", got)
+		}
+	})
+}
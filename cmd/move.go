@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+var moveTo string
+
+var moveCmd = &cobra.Command{
+	Use:   "move --to <name>",
+	Short: "Move devcontainer.json into or out of a named .devcontainer folder",
+	Long: `Move the current devcontainer.json to .devcontainer/<name>/devcontainer.json,
+for a repository with several configs, or back to .devcontainer/devcontainer.json
+with --to . (or an empty name). The folder is created; nothing else is changed
+or moved, and an existing file at the target is never overwritten.`,
+	Example: `  dcc move --to backend
+  dcc move --config .devcontainer/backend/devcontainer.json --to .`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if stdoutMode {
+			return fmt.Errorf("move only works on files, not with --stdout")
+		}
+		name, err := moveTarget(moveTo)
+		if err != nil {
+			return err
+		}
+		absFolder, err := absWorkspace()
+		if err != nil {
+			return err
+		}
+		if !devcontainer.Exists(absFolder) {
+			return fmt.Errorf("no devcontainer.json found in %s", absFolder)
+		}
+
+		from := devcontainer.ConfigPath(absFolder)
+		to := devcontainer.NamedConfigPath(absFolder, name)
+		if from == to {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is already there, nothing to move\n", to)
+			return nil
+		}
+		config, _, err := devcontainer.ReadConfig(absFolder)
+		if err != nil {
+			return err
+		}
+		if err := devcontainer.MoveFile(from, to); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Moved to %s\n", to)
+		if filepath.Dir(from) != filepath.Dir(to) {
+			for _, p := range relativePaths(config) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Note: %s is relative to devcontainer.json and was not moved\n", p)
+			}
+		}
+		return nil
+	},
+}
+
+// moveTarget checks the --to name: a single folder name, or "" or "." for
+// the default location.
+func moveTarget(name string) (string, error) {
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	switch {
+	case name == "" || name == ".":
+		return "", nil
+	case name == ".." || strings.ContainsAny(name, `/\`):
+		return "", fmt.Errorf("invalid --to %q, expected a folder name like backend", name)
+	}
+	return name, nil
+}
+
+// relativePaths lists the keys of config holding paths that resolve against
+// the directory of devcontainer.json, e.g. `build.dockerfile ("Dockerfile")`.
+func relativePaths(config map[string]any) []string {
+	var found []string
+	check := func(key string, value any) {
+		if p, ok := value.(string); ok && p != "" && !filepath.IsAbs(p) && !strings.HasPrefix(p, "${") {
+			found = append(found, fmt.Sprintf("%s (%q)", key, p))
+		}
+	}
+	if build, ok := config["build"].(map[string]any); ok {
+		check("build.dockerfile", build["dockerfile"])
+		check("build.context", build["context"])
+	}
+	switch compose := config["dockerComposeFile"].(type) {
+	case string:
+		check("dockerComposeFile", compose)
+	case []any:
+		for _, f := range compose {
+			check("dockerComposeFile", f)
+		}
+	}
+	return found
+}
+
+func init() {
+	moveCmd.Flags().StringVar(&moveTo, "to", "", "folder under .devcontainer to move the config to; . for .devcontainer/devcontainer.json")
+	moveCmd.MarkFlagRequired("to") //nolint:errcheck
+	rootCmd.AddCommand(moveCmd)
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoveTarget(t *testing.T) {
+	tests := []struct {
+		in, want string
+		wantErr  bool
+	}{
+		{"backend", "backend", false},
+		{" backend/ ", "backend", false},
+		{".", "", false},
+		{"", "", false},
+		{"..", "", true},
+		{"a/b", "", true},
+	}
+	for _, tt := range tests {
+		got, err := moveTarget(tt.in)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("moveTarget(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestRelativePaths(t *testing.T) {
+	config := map[string]any{
+		"build":             map[string]any{"dockerfile": "Dockerfile", "context": "${localWorkspaceFolder}"},
+		"dockerComposeFile": []any{"../compose.yml", "/srv/compose.override.yml"},
+	}
+	want := []string{`build.dockerfile ("Dockerfile")`, `dockerComposeFile ("../compose.yml")`}
+	if got := relativePaths(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("relativePaths() = %q, want %q", got, want)
+	}
+	if got := relativePaths(map[string]any{"image": "alpine"}); got != nil {
+		t.Errorf("relativePaths() of an image config = %q", got)
+	}
+}
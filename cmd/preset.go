@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/preset"
+)
+
+var presetCmd = &cobra.Command{
+	Use:   "preset",
+	Short: "Save devcontainer.json as a named preset and apply it elsewhere",
+	Long: `Presets are devcontainer.json fragments stored in ~/.config/dcc/presets/<name>.json.
+
+Applying a preset merges it into the workspace config: objects merge key by
+key, arrays (extensions, forwardPorts, mounts, ...) gain the preset's missing
+entries, and other values are replaced by the preset's. A preset feature
+replaces the same feature at another version.`,
+}
+
+var presetSaveCmd = &cobra.Command{
+	Use:     "save <name>",
+	Short:   "Save the current devcontainer.json as a preset",
+	Long:    `Save the current devcontainer.json, without its name, as a preset. Comments and key order are kept.`,
+	Example: "  dcc preset save go-api",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := absWorkspace()
+		if err != nil {
+			return err
+		}
+		name := args[0]
+		path, err := preset.Path(name)
+		if err != nil {
+			return err
+		}
+
+		config, configPath, err := devcontainer.ReadConfig(absFolder)
+		if err != nil {
+			return err
+		}
+		raw, _ := devcontainer.ReadFile(configPath)
+
+		if _, err := os.Stat(path); err == nil {
+			ok, err := confirm(fmt.Sprintf("Replace preset %s?", name), path)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return nil
+			}
+		}
+		if err := preset.Save(name, config, raw); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Saved %s\n", path)
+		return nil
+	},
+}
+
+var presetApplyCmd = &cobra.Command{
+	Use:         "apply <name>",
+	Annotations: composesConfig,
+	Short:       "Merge a preset into devcontainer.json",
+	Example:     "  dcc preset apply go-api",
+	Args:        cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := resolveWorkspace()
+		if err != nil {
+			return err
+		}
+		if err := preset.Apply(absFolder, args[0]); err != nil {
+			return fmt.Errorf("applying preset: %w", err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Applied %s to %s\n", args[0], devcontainer.ConfigPath(absFolder))
+		return nil
+	},
+}
+
+var presetListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List saved presets",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		names, err := preset.List()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(cmd.OutOrStdout(), "No presets saved yet (dcc preset save <name>)")
+			return nil
+		}
+		for _, name := range names {
+			fmt.Fprintln(cmd.OutOrStdout(), name)
+		}
+		return nil
+	},
+}
+
+func init() {
+	presetCmd.AddCommand(presetSaveCmd, presetApplyCmd, presetListCmd)
+	rootCmd.AddCommand(presetCmd)
+}
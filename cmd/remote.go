@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/shellquote"
+	"github.com/mochlast/devcontainer-companion/internal/template"
+)
+
+// remoteFS is the --host filesystem.
+func remoteFS() devcontainer.SSHFileSystem {
+	return devcontainer.SSHFileSystem{Host: remoteHost}
+}
+
+// remotePath makes a path on the --host absolute. Relative paths, including
+// the default ".", resolve against the remote home directory.
+func remotePath(p string) (string, error) {
+	if path.IsAbs(p) {
+		return path.Clean(p), nil
+	}
+	home, err := remoteFS().Home()
+	if err != nil {
+		return "", err
+	}
+	return path.Join(home, p), nil
+}
+
+// cancelGrace is how long a cancelled command may take to exit after the
+// interrupt before it is killed.
+const cancelGrace = 5 * time.Second
+
+// devcontainerCommand returns a command running the devcontainer CLI, over
+// ssh on the --host when one is set.
+func devcontainerCommand(args ...string) *exec.Cmd {
+	return devcontainerCommandContext(context.Background(), args...)
+}
+
+// devcontainerCommandContext is devcontainerCommand stopped when ctx is
+// cancelled.
+func devcontainerCommandContext(ctx context.Context, args ...string) *exec.Cmd {
+	if remoteHost != "" {
+		return remoteCommandContext(ctx, append([]string{"devcontainer"}, args...)...)
+	}
+	return commandContext(ctx, "devcontainer", args...)
+}
+
+// interruptOnEOF runs "$@" on the host and interrupts it once the shell's
+// stdin, the ssh client's, is closed. The command replaces the shell, so
+// the watcher knows its pid as $$ and, unlike a background job, it doesn't
+// ignore interrupts; the watcher reads a copy of stdin since background
+// jobs otherwise read /dev/null.
+const interruptOnEOF = `exec 3<&0
+(cat >/dev/null; kill -INT $$) <&3 >/dev/null 2>&1 &
+exec "$@" </dev/null 3<&-`
+
+// remoteCommandContext runs argv on the --host, stopped when ctx is
+// cancelled. Without a terminal ssh doesn't pass signals on, so
+// interrupting the local client would leave the build running on the host;
+// cancelling closes the client's stdin instead, and interruptOnEOF
+// interrupts the remote command.
+func remoteCommandContext(ctx context.Context, argv ...string) *exec.Cmd {
+	ssh := remoteFS().Command(argv...)
+	if ctx.Done() == nil {
+		return ssh // never cancelled; keeps stdin free for the caller
+	}
+	ssh = remoteFS().Command(append([]string{"sh", "-c", interruptOnEOF, "sh"}, argv...)...)
+	cmd := exec.CommandContext(ctx, ssh.Args[0], ssh.Args[1:]...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		// Only fails once Stdin is set or the command started.
+		return commandContext(ctx, ssh.Args[0], ssh.Args[1:]...)
+	}
+	cmd.Cancel = stdin.Close
+	cmd.WaitDelay = cancelGrace
+	return cmd
+}
+
+// commandContext is exec.CommandContext, but interrupts the command first so
+// the devcontainer CLI can stop its docker build, and kills it only if it
+// hasn't exited after cancelGrace.
+func commandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Cancel = func() error {
+		if err := cmd.Process.Signal(os.Interrupt); err != nil {
+			return cmd.Process.Kill() // no interrupts on Windows
+		}
+		return nil
+	}
+	cmd.WaitDelay = cancelGrace
+	return cmd
+}
+
+// devcontainerCommandLine renders devcontainerCommand for --dry-run.
+func devcontainerCommandLine(args ...string) string {
+	if remoteHost == "" {
+		return shellCommand("devcontainer", args...)
+	}
+	quoted := []string{"devcontainer"}
+	for _, arg := range args {
+		quoted = append(quoted, shellquote.Quote(arg))
+	}
+	return shellCommand("ssh", remoteHost, strings.Join(quoted, " "))
+}
+
+// detectCLI probes the devcontainer CLI that Build and Up use. On a --host
+// that is the remote CLI, and Open needs a local VS Code to connect.
+func detectCLI() template.CLIInfo {
+	if remoteHost == "" {
+		return template.DetectCLI()
+	}
+	info := template.DetectCLIVia(devcontainerCommand)
+	if _, err := exec.LookPath("code"); err == nil && info.Installed {
+		info.HasOpen = true
+	}
+	return info
+}
+
+// remoteOpenCommand opens the remote folder in VS Code over Remote-SSH, which
+// then offers to reopen it in the container.
+func remoteOpenCommand(folder string) (string, []string) {
+	return "code", []string{"--remote", "ssh-remote+" + remoteHost, folder}
+}
+
+// remoteEditorCommand opens path in the remote user's $VISUAL or $EDITOR.
+func remoteEditorCommand(p string) *exec.Cmd {
+	script := `mkdir -p "$(dirname "$1")" && exec ${VISUAL:-${EDITOR:-vi}} "$1"`
+	return remoteFS().Interactive("sh", "-c", script, "sh", p)
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDevcontainerCommandLine(t *testing.T) {
+	remoteHost = "dev@server"
+	t.Cleanup(func() { remoteHost = "" })
+
+	got := devcontainerCommandLine("build", "--workspace-folder", "/home/dev/my app")
+	want := `ssh dev@server 'devcontainer build --workspace-folder '\''/home/dev/my app'\'''`
+	if got != want {
+		t.Errorf("devcontainerCommandLine = %q, want %q", got, want)
+	}
+	if args := devcontainerCommand("up").Args; args[len(args)-1] != "devcontainer up" {
+		t.Errorf("remote command = %q", args)
+	}
+}
+
+func TestInterruptOnEOF(t *testing.T) {
+	// The command ends by itself while stdin stays open.
+	cmd := exec.Command("sh", "-c", interruptOnEOF, "sh", "sh", "-c", "exit 3")
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Errorf("finished command: err = %v, want its exit status 3", err)
+	}
+
+	// Closing stdin, as cancelling the ssh client does, interrupts it.
+	cmd = exec.Command("sh", "-c", interruptOnEOF, "sh", "sleep", "30")
+	if stdin, err = cmd.StdinPipe(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	stdin.Close()
+	if err := cmd.Wait(); err == nil || time.Since(start) > 10*time.Second {
+		t.Errorf("cancelled command: err = %v after %v, want it interrupted", err, time.Since(start))
+	}
+}
+
+func TestRemoteCommandContextCancel(t *testing.T) {
+	remoteHost = "dev@server"
+	t.Cleanup(func() { remoteHost = "" })
+
+	if cmd := devcontainerCommandContext(context.Background(), "up"); cmd.Stdin != nil || cmd.Cancel != nil {
+		t.Error("a command that can't be cancelled should keep stdin free")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := devcontainerCommandContext(ctx, "build")
+	if cmd.Stdin == nil || cmd.Cancel == nil || !strings.Contains(strings.Join(cmd.Args, " "), "kill -INT") {
+		t.Errorf("cancellable remote command = %q, want the interrupt wrapper reading stdin", cmd.Args)
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/feature"
+	"github.com/mochlast/devcontainer-companion/internal/marketplace"
+)
+
+var removeCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove items from devcontainer.json without opening the hub",
+}
+
+var removeFeatureCmd = &cobra.Command{
+	Use:         "feature <oci-ref|glob>",
+	Annotations: composesConfig,
+	Short:       "Remove features from devcontainer.json",
+	Long: `Remove a feature, at any version, from devcontainer.json without opening
+the hub. Its note is removed with it.
+
+A glob removes every configured feature it matches: "*" matches any run of
+characters, including "/", and "?" a single one. The matches are listed and
+confirmed before anything is removed.`,
+	Example: `  dcc remove feature ghcr.io/devcontainers/features/node
+  dcc remove feature 'ghcr.io/myorg/*'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := absWorkspace()
+		if err != nil {
+			return err
+		}
+		config, _, err := devcontainer.ReadConfig(absFolder)
+		if err != nil {
+			return err
+		}
+
+		pattern := strings.TrimSpace(args[0])
+		feats, _ := config["features"].(map[string]any)
+		var removed []string
+		for ref := range feats {
+			if matchesFeature(pattern, ref) {
+				removed = append(removed, ref)
+			}
+		}
+		sort.Strings(removed)
+
+		ok, err := confirmRemoval(cmd, pattern, removed, "feature")
+		if err != nil || !ok {
+			return err
+		}
+		if err := feature.Remove(absFolder, removed); err != nil {
+			return fmt.Errorf("removing features: %w", err)
+		}
+		for _, ref := range removed {
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", ref)
+		}
+		return nil
+	},
+}
+
+var removeExtensionCmd = &cobra.Command{
+	Use:         "extension <id|glob>",
+	Annotations: composesConfig,
+	Short:       "Remove VS Code extensions from devcontainer.json",
+	Long: `Remove a VS Code extension from customizations.vscode.extensions without
+opening the hub. The vscode and customizations objects are dropped when they
+end up empty.
+
+A glob removes every configured extension it matches, ignoring case: "*"
+matches any run of characters and "?" a single one. The matches are listed
+and confirmed before anything is removed.`,
+	Example: `  dcc remove extension ms-python.python
+  dcc remove extension 'ms-*'`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := absWorkspace()
+		if err != nil {
+			return err
+		}
+
+		pattern := strings.TrimSpace(args[0])
+		existing, err := extractStringSlice(absFolder, vscodeExtensions)
+		if err != nil {
+			return err
+		}
+		var remaining, removed []string
+		for _, ref := range existing {
+			if matchesExtension(pattern, ref) {
+				removed = append(removed, ref)
+			} else {
+				remaining = append(remaining, ref)
+			}
+		}
+
+		ok, err := confirmRemoval(cmd, pattern, removed, "extension")
+		if err != nil || !ok {
+			return err
+		}
+		if err := writeCustomizationList(absFolder, remaining, vscodeExtensions); err != nil {
+			return fmt.Errorf("removing extension: %w", err)
+		}
+		for _, ref := range removed {
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", ref)
+		}
+		return nil
+	},
+}
+
+// confirmRemoval reports whether the entries a remove pattern matched should
+// be removed. Nothing matching is no error; the matches of a glob are listed
+// and confirmed first.
+func confirmRemoval(cmd *cobra.Command, pattern string, matched []string, kind string) (bool, error) {
+	if len(matched) == 0 {
+		if isGlob(pattern) {
+			fmt.Fprintf(cmd.OutOrStdout(), "No configured %s matches %s, nothing to remove\n", kind, pattern)
+		} else {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s is not configured, nothing to remove\n", pattern)
+		}
+		return false, nil
+	}
+	if !isGlob(pattern) {
+		return true, nil
+	}
+	var desc strings.Builder
+	for _, ref := range matched {
+		desc.WriteString("  - " + ref + "\n")
+	}
+	return confirm(fmt.Sprintf("Remove %d %s(s) matching %s?", len(matched), kind, pattern), strings.TrimRight(desc.String(), "\n"))
+}
+
+// matchesFeature matches a configured feature ref against a glob, or else
+// the feature pattern refers to at any version.
+func matchesFeature(pattern, ref string) bool {
+	if isGlob(pattern) {
+		return matchGlob(pattern, ref) || matchGlob(pattern, devcontainer.FeatureID(ref))
+	}
+	return devcontainer.FeatureID(ref) == devcontainer.FeatureID(pattern)
+}
+
+// matchesExtension matches an extensions entry against a glob, or else the
+// extension pattern refers to, pinned or not.
+func matchesExtension(pattern, ref string) bool {
+	if isGlob(pattern) {
+		id, _ := marketplace.SplitExtensionRef(ref)
+		return matchGlob(pattern, ref) || matchGlob(pattern, id)
+	}
+	return isExtension(pattern)(ref)
+}
+
+// isGlob reports whether pattern has wildcards.
+func isGlob(pattern string) bool {
+	return strings.ContainsAny(pattern, "*?")
+}
+
+// matchGlob reports whether s matches the whole of pattern, ignoring case.
+// Unlike path.Match, "*" also matches "/", so "ghcr.io/myorg/*" covers the
+// nested features of an org.
+func matchGlob(pattern, s string) bool {
+	var expr strings.Builder
+	expr.WriteString("(?i)^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.MustCompile(expr.String()).MatchString(s)
+}
+
+func init() {
+	removeCmd.AddCommand(removeFeatureCmd, removeExtensionCmd)
+	rootCmd.AddCommand(removeCmd)
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import "testing"
+
+func TestMatchesFeature(t *testing.T) {
+	tests := []struct {
+		pattern, ref string
+		want         bool
+	}{
+		{"ghcr.io/devcontainers/features/node", "ghcr.io/devcontainers/features/node:1", true},
+		{"ghcr.io/devcontainers/features/node:2", "ghcr.io/devcontainers/features/node:1", true},
+		{"ghcr.io/devcontainers/features/node", "ghcr.io/devcontainers/features/node-extra:1", false},
+		{"ghcr.io/myorg/*", "ghcr.io/myorg/features/tool:1", true},
+		{"ghcr.io/myorg/*", "ghcr.io/devcontainers/features/node:1", false},
+		{"*/python", "ghcr.io/devcontainers/features/python:1", true},
+		{"ghcr.io/devcontainers/features/node:?", "ghcr.io/devcontainers/features/node:1", true},
+	}
+	for _, tt := range tests {
+		if got := matchesFeature(tt.pattern, tt.ref); got != tt.want {
+			t.Errorf("matchesFeature(%q, %q) = %v, want %v", tt.pattern, tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestMatchesExtension(t *testing.T) {
+	tests := []struct {
+		pattern, ref string
+		want         bool
+	}{
+		{"ms-python.python", "MS-Python.python", true},
+		{"ms-python.python", "ms-python.python@2024.1.0", true},
+		{"ms-*", "ms-python.python", true},
+		{"MS-*", "ms-vscode.cpptools@1.20.0", true},
+		{"ms-*", "golang.go", false},
+		{"*.python", "ms-python.python@2024.1.0", true},
+		{"ms-python.*", "ms-python.vscode-pylance", true},
+		{"ms-python.py??on", "ms-python.python", true},
+	}
+	for _, tt := range tests {
+		if got := matchesExtension(tt.pattern, tt.ref); got != tt.want {
+			t.Errorf("matchesExtension(%q, %q) = %v, want %v", tt.pattern, tt.ref, got, tt.want)
+		}
+	}
+}
@@ -1,38 +1,104 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
 	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
+	"github.com/mochlast/devcontainer-companion/internal/prefs"
 	"github.com/mochlast/devcontainer-companion/internal/template"
+	"github.com/mochlast/devcontainer-companion/internal/ui"
 )
 
 var version = "dev"
 
 var (
 	workspaceFolder string
+	configFile      string
 	noCache         bool
+	dryRun          bool
+	stdoutMode      bool
+	templateRepo    string
+	remoteHost      string
+	assumeYes       bool
+	noColor         bool
+	themeName       string
+	noAutoRoot      bool
+	indentStyle     string
+	compactArrays   bool
+	profileMode     bool
+	backupMode      bool
+	explicitFolder  bool // -w was given
 )
 
+// composesConfig annotates the commands that compose a config, which is
+// printed after them under --stdout. Other commands print only their own
+// output.
+var composesConfig = map[string]string{"dcc.composesConfig": "true"}
+
+// configOut receives the final devcontainer.json under --stdout. The TUI is
+// moved to stderr so nothing else reaches it.
+var configOut io.Writer = os.Stdout
+
 var rootCmd = &cobra.Command{
-	Use:     "dcc",
-	Short:   "Devcontainer CLI Companion",
-	Long:    "dcc helps you create and configure devcontainers interactively.",
-	Version: version,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		absFolder, err := filepath.Abs(workspaceFolder)
+	Use:         "dcc",
+	Short:       "Devcontainer CLI Companion",
+	Long:        "dcc helps you create and configure devcontainers interactively.",
+	Version:     version,
+	Annotations: composesConfig,
+	// Execute prints returned errors itself; don't dump usage on failures.
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		explicitFolder = cmd.Flags().Changed("workspace-folder")
+		if profileMode {
+			httpclient.EnableProfile(os.Stderr)
+		}
+		if remoteHost != "" {
+			devcontainer.UseFileSystem(remoteFS())
+		}
+		if stdoutMode {
+			devcontainer.UseMemory()
+			configOut = os.Stdout
+			os.Stdout = os.Stderr
+			lipgloss.SetDefaultRenderer(lipgloss.NewRenderer(os.Stderr))
+		}
+		indent, err := devcontainer.ParseIndent(indentStyle)
 		if err != nil {
-			return fmt.Errorf("resolving workspace folder: %w", err)
+			return err
 		}
-		if !devcontainer.Exists(absFolder) {
-			projectName := filepath.Base(absFolder)
-			if err := template.CreateEmpty(absFolder, projectName); err != nil {
-				return err
-			}
+		devcontainer.SetFormat(devcontainer.Format{Indent: indent, CompactArrays: compactArrays})
+		p, _ := prefs.Load() // an unreadable file just means the defaults
+		ui.SetSecretKeys(p.SecretKeys)
+		template.SetDefaultImage(p.DefaultImage)
+		template.SetIncludeSchema(!p.NoSchema)
+		devcontainer.SetBackups(backupCount(p.Backups))
+		return applyTheme(p.Theme)
+	},
+	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
+		if !stdoutMode || cmd.Annotations["dcc.composesConfig"] != "true" {
+			return nil
+		}
+		absFolder, err := absWorkspace()
+		if err != nil {
+			return err
+		}
+		return printConfig(absFolder)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := resolveWorkspace()
+		if err != nil {
+			return err
 		}
 		return runHub(absFolder, noCache)
 	},
@@ -40,11 +106,148 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&workspaceFolder, "workspace-folder", "w", ".", "workspace folder path")
-	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "bypass catalog cache")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "explicit devcontainer.json path (default: probe standard locations)")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "bypass catalog and OCI metadata caches")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "print devcontainer build/up/open commands instead of running them")
+	rootCmd.PersistentFlags().BoolVar(&stdoutMode, "stdout", false, "print the resulting devcontainer.json to stdout instead of writing files")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "answer yes to prompts outside the hub: overwriting an existing config in init, replacing a configured feature version in add feature, writing newly configured features")
+	rootCmd.PersistentFlags().StringVar(&remoteHost, "host", "", "edit the workspace on a remote host over ssh (user@server); -w is a path there")
+	rootCmd.PersistentFlags().StringVar(&templateRepo, "template-repo", "", "Git repo with src/<template>/devcontainer-template.json to offer alongside the catalog")
+	rootCmd.PersistentFlags().BoolVar(&noAutoRoot, "no-auto-root", false, "without -w, use the current directory instead of the enclosing git root or .devcontainer folder")
+	rootCmd.PersistentFlags().StringVar(&indentStyle, "indent", "2", "indentation of written devcontainer.json files: tab, 2 or 4")
+	rootCmd.PersistentFlags().BoolVar(&compactArrays, "compact-arrays", true, "write arrays of strings, numbers and booleans on a single line")
+	rootCmd.PersistentFlags().BoolVar(&backupMode, "backup", false, fmt.Sprintf("keep timestamped backups of devcontainer.json before each write (the newest %d, or backups in ~/.config/dcc/config.json)", devcontainer.DefaultBackups))
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colors (also set by NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVar(&profileMode, "profile", false, "log the duration of each network request to stderr and summarize them on exit")
+	rootCmd.PersistentFlags().MarkHidden("profile") //nolint:errcheck
+	rootCmd.PersistentFlags().StringVar(&themeName, "theme", "", "color theme: "+strings.Join(ui.ThemeNames(), ", ")+" (default: theme in ~/.config/dcc/config.json, else default)")
+}
+
+// backupCount returns how many config backups to keep: preferred, the
+// backups preference, or under --backup at least DefaultBackups.
+func backupCount(preferred int) int {
+	if backupMode && preferred <= 0 {
+		return devcontainer.DefaultBackups
+	}
+	return max(preferred, 0)
+}
+
+// applyTheme sets up colors from --no-color/NO_COLOR, else from --theme or
+// preferred, the theme preference.
+func applyTheme(preferred string) error {
+	if noColor || ui.NoColorRequested() {
+		ui.DisableColor()
+		return nil
+	}
+	name := themeName
+	if name == "" {
+		name = preferred
+	}
+	if name == "" {
+		return nil
+	}
+	return ui.SetTheme(name)
+}
+
+// printConfig writes the workspace's devcontainer.json, as composed in
+// memory under --stdout, to configOut.
+func printConfig(absFolder string) error {
+	data, err := devcontainer.ReadFile(devcontainer.ConfigPath(absFolder))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("reading devcontainer.json: %w", err)
+	}
+	_, err = configOut.Write(data)
+	return err
+}
+
+// absWorkspace returns the absolute workspace folder and applies the
+// --config override, without touching the filesystem. With --host both are
+// paths on the remote host.
+func absWorkspace() (string, error) {
+	if remoteHost != "" {
+		return remoteWorkspace()
+	}
+	absFolder, err := filepath.Abs(workspaceFolder)
+	if err != nil {
+		return "", fmt.Errorf("resolving workspace folder: %w", err)
+	}
+	if !noAutoRoot && !explicitFolder {
+		if root := findWorkspaceRoot(absFolder); root != absFolder {
+			fmt.Fprintf(os.Stderr, "Using workspace folder %s\n", root)
+			// Later calls in this run resolve to the root directly.
+			workspaceFolder, absFolder = root, root
+		}
+	}
+	if configFile != "" {
+		absConfig, err := filepath.Abs(configFile)
+		if err != nil {
+			return "", fmt.Errorf("resolving config path: %w", err)
+		}
+		devcontainer.SetConfigPath(absConfig)
+	}
+	return absFolder, nil
+}
+
+// findWorkspaceRoot walks up from dir to the nearest folder holding a
+// devcontainer config or a .git entry (a directory, or a file in worktrees
+// and submodules). Without one, dir itself is the root.
+func findWorkspaceRoot(dir string) string {
+	for d := dir; ; {
+		for _, marker := range []string{".devcontainer", ".devcontainer.json", ".git"} {
+			if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+				return d
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+func remoteWorkspace() (string, error) {
+	absFolder, err := remotePath(workspaceFolder)
+	if err != nil {
+		return "", fmt.Errorf("resolving workspace folder: %w", err)
+	}
+	if configFile != "" {
+		absConfig, err := remotePath(configFile)
+		if err != nil {
+			return "", fmt.Errorf("resolving config path: %w", err)
+		}
+		devcontainer.SetConfigPath(absConfig)
+	}
+	return absFolder, nil
+}
+
+// resolveWorkspace returns the absolute workspace folder and creates a
+// minimal devcontainer.json if none exists yet.
+func resolveWorkspace() (string, error) {
+	absFolder, err := absWorkspace()
+	if err != nil {
+		return "", err
+	}
+	if !devcontainer.Exists(absFolder) {
+		projectName := filepath.Base(absFolder)
+		if err := template.CreateEmpty(absFolder, projectName); err != nil {
+			return "", err
+		}
+	}
+	return absFolder, nil
 }
 
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	start := time.Now()
+	err := rootCmd.Execute()
+	if profileMode {
+		fmt.Fprintf(os.Stderr, "\nNetwork requests while dcc ran for %s:\n", time.Since(start).Round(time.Millisecond))
+		httpclient.WriteProfile(os.Stderr)
+	}
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
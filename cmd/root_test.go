@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFindWorkspaceRoot(t *testing.T) {
+	repo := t.TempDir()
+	mkdir := func(parts ...string) string {
+		t.Helper()
+		dir := filepath.Join(append([]string{repo}, parts...)...)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+	mkdir(".git")
+	deep := mkdir("services", "api", "internal")
+	sub := mkdir("tools", "devtool")
+	mkdir("tools", "devtool", ".devcontainer")
+	subDeep := mkdir("tools", "devtool", "cmd")
+
+	tests := []struct {
+		name, dir, want string
+	}{
+		{"git root", deep, repo},
+		{"at the root", repo, repo},
+		{"nearest .devcontainer wins", subDeep, sub},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findWorkspaceRoot(tt.dir); got != tt.want {
+				t.Errorf("findWorkspaceRoot(%s) = %s, want %s", tt.dir, got, tt.want)
+			}
+		})
+	}
+
+	// Worktrees and submodules have a .git file.
+	wt := t.TempDir()
+	if err := os.WriteFile(filepath.Join(wt, ".git"), []byte("gitdir: /elsewhere\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	nested := filepath.Join(wt, "pkg")
+	if err := os.Mkdir(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got := findWorkspaceRoot(nested); got != wt {
+		t.Errorf("findWorkspaceRoot in a worktree = %s, want %s", got, wt)
+	}
+}
+
+func TestComposesConfigAnnotation(t *testing.T) {
+	composers := map[string]bool{
+		"dcc":                  true,
+		"dcc init":             true,
+		"dcc apply":            true,
+		"dcc add feature":      true,
+		"dcc add extension":    true,
+		"dcc remove feature":   true,
+		"dcc remove extension": true,
+		"dcc preset apply":     true,
+		"dcc template":         true,
+		"dcc features":         true,
+		"dcc extensions":       true,
+	}
+	var walk func(cmd *cobra.Command)
+	walk = func(cmd *cobra.Command) {
+		name := cmd.CommandPath()
+		if got := cmd.Annotations["dcc.composesConfig"] == "true"; got != composers[name] {
+			t.Errorf("%s: composesConfig = %v, want %v", name, got, composers[name])
+		}
+		for _, sub := range cmd.Commands() {
+			walk(sub)
+		}
+	}
+	walk(rootCmd)
+}
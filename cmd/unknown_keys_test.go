@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/feature"
+	"github.com/mochlast/devcontainer-companion/internal/preset"
+)
+
+// unknownKeysConfig mixes keys dcc edits with ones it has no editor for,
+// including a vendor key and a customizations namespace of another tool.
+const unknownKeysConfig = `{
+  "name": "api",
+  "image": "mcr.microsoft.com/devcontainers/go:1",
+  "overrideCommand": false,
+  "userEnvProbe": "loginInteractiveShell",
+  "x-acme": {
+    "owner": "platform-team",
+    "tier": 2,
+    "checks": ["lint", "sbom"]
+  },
+  "features": {
+    "ghcr.io/devcontainers/features/node:1": {}
+  },
+  "customizations": {
+    "acme-ide": {
+      "theme": "dark"
+    },
+    "vscode": {
+      "extensions": ["golang.go"]
+    }
+  }
+}
+`
+
+// unknownKeySnippets are the unknown keys as written, which every flow must
+// leave byte for byte.
+var unknownKeySnippets = []string{
+	`"overrideCommand": false`,
+	`"userEnvProbe": "loginInteractiveShell"`,
+	"\"x-acme\": {\n    \"owner\": \"platform-team\",\n    \"tier\": 2,\n    \"checks\": [\"lint\", \"sbom\"]\n  }",
+	"\"acme-ide\": {\n      \"theme\": \"dark\"\n    }",
+}
+
+func TestFlowsKeepUnknownKeys(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if err := preset.Save("team", map[string]any{"remoteUser": "vscode"}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	flows := []struct {
+		name string
+		run  func(dir string) error
+	}{
+		{"extensions", func(dir string) error {
+			return writeCustomizationList(dir, []string{"golang.go", "ms-python.python"}, vscodeExtensions)
+		}},
+		{"plugins", func(dir string) error {
+			return writeCustomizationList(dir, []string{"org.jetbrains.plugins.go"}, jetbrainsPlugins)
+		}},
+		{"replace features", func(dir string) error {
+			return feature.ReplaceAll(dir, []feature.FeatureConfig{
+				{OciRef: "ghcr.io/devcontainers/features/github-cli:1", Options: map[string]any{"version": "latest"}, Note: "gh in CI scripts"},
+			})
+		}},
+		{"add feature", func(dir string) error {
+			return feature.Add(dir, feature.FeatureConfig{OciRef: "ghcr.io/devcontainers/features/python:1"})
+		}},
+		{"remove feature", func(dir string) error {
+			return feature.Remove(dir, []string{"ghcr.io/devcontainers/features/node:1"})
+		}},
+		{"forward ports", func(dir string) error {
+			return addForwardPorts(dir, []string{"3000"})
+		}},
+		{"apply", func(dir string) error {
+			_, err := applyConfig(dir, map[string]any{"remoteUser": "vscode", "forwardPorts": []any{8080}})
+			return err
+		}},
+		{"preset", func(dir string) error {
+			return preset.Apply(dir, "team")
+		}},
+		{"replace template", func(dir string) error {
+			_, err := preserveSettings(dir, false, goTemplateOutput)
+			return err
+		}},
+		{"layer template", func(dir string) error {
+			_, err := preserveSettings(dir, true, goTemplateOutput)
+			return err
+		}},
+		{"migrate", func(dir string) error {
+			return rewriteConfig(dir, func(config map[string]any) {
+				config["extensions"] = []any{"ms-azuretools.vscode-docker"}
+				devcontainer.Migrate(config)
+			})
+		}},
+		{"toggle schema", func(dir string) error {
+			return rewriteConfig(dir, func(config map[string]any) { devcontainer.ToggleSchema(config) })
+		}},
+	}
+
+	for _, flow := range flows {
+		t.Run(flow.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := devcontainer.DefaultConfigPath(dir)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(unknownKeysConfig), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := flow.run(dir); err != nil {
+				t.Fatal(err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) == unknownKeysConfig {
+				t.Fatal("the flow didn't write the config")
+			}
+			for _, snippet := range unknownKeySnippets {
+				if !strings.Contains(string(data), snippet) {
+					t.Errorf("%s lost or rewritten:\n%s", snippet, data)
+				}
+			}
+			assertUnknownKeys(t, dir)
+		})
+	}
+}
+
+// goTemplateOutput stands in for a template apply, which generates a config
+// of its own for the workspace.
+func goTemplateOutput() ([]byte, error) {
+	return []byte(`{
+  "name": "Go",
+  "image": "mcr.microsoft.com/devcontainers/go:1-1.23-bookworm",
+  "remoteUser": "vscode",
+  "customizations": {"vscode": {"extensions": ["golang.go"]}}
+}
+`), nil
+}
+
+// rewriteConfig reads, changes and writes the config as the hub's callbacks
+// do.
+func rewriteConfig(dir string, change func(map[string]any)) error {
+	config, configPath, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		return err
+	}
+	change(config)
+	return devcontainer.WriteConfig(configPath, config)
+}
+
+// assertUnknownKeys checks that the config at dir still has the values of
+// unknownKeysConfig's unknown keys.
+func assertUnknownKeys(t *testing.T, dir string) {
+	t.Helper()
+	config, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"overrideCommand": false,
+		"userEnvProbe":    "loginInteractiveShell",
+		"x-acme":          map[string]any{"owner": "platform-team", "tier": float64(2), "checks": []any{"lint", "sbom"}},
+	}
+	for key, value := range want {
+		if !reflect.DeepEqual(config[key], value) {
+			t.Errorf("%s = %#v, want %#v", key, config[key], value)
+		}
+	}
+	if got := devcontainer.Customization(config, "acme-ide"); !reflect.DeepEqual(got, map[string]any{"theme": "dark"}) {
+		t.Errorf("customizations.acme-ide = %#v", got)
+	}
+}
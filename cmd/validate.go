@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+var (
+	validateJSON    bool
+	validateOffline bool
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Lint-check devcontainer.json and exit non-zero on problems",
+	Long: `Check the current devcontainer.json without modifying it:
+
+  - the config parses (JSONC) and matches the devcontainer.json schema
+  - forwarded ports are in the range 1-65535
+  - every feature OCI reference resolves (skipped with --offline)
+
+The schema is the published one, fetched weekly into the cache, or a bundled
+subset until it has been fetched.
+
+Suitable as a pre-commit hook.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		absFolder, err := absWorkspace()
+		if err != nil {
+			return err
+		}
+
+		if !validateOffline {
+			// Offline, the cached or bundled schema still applies.
+			devcontainer.RefreshSchema() //nolint:errcheck
+		}
+		configPath := devcontainer.ConfigPath(absFolder)
+		var problems []devcontainer.ValidationError
+		if config, _, err := devcontainer.ReadConfig(absFolder); err != nil {
+			problems = append(problems, devcontainer.ValidationError{Path: "(file)", Message: err.Error()})
+		} else {
+			problems = validateConfig(config, !validateOffline)
+		}
+
+		out := cmd.OutOrStdout()
+		if validateJSON {
+			if err := writeValidationJSON(out, configPath, problems); err != nil {
+				return err
+			}
+		} else if len(problems) == 0 {
+			fmt.Fprintf(out, "%s: no problems found\n", configPath)
+		} else {
+			fmt.Fprintf(out, "%s:\n", configPath)
+			for _, p := range problems {
+				fmt.Fprintf(out, "  %s\n", p.Error())
+			}
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf("validation failed: %d problem(s)", len(problems))
+		}
+		return nil
+	},
+}
+
+func init() {
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "print results as JSON")
+	validateCmd.Flags().BoolVar(&validateOffline, "offline", false, "skip checks that need the network (feature resolution, schema refresh)")
+	rootCmd.AddCommand(validateCmd)
+}
+
+// validateConfig runs the schema, port and (optionally) feature resolution
+// checks and returns the problems sorted by path.
+func validateConfig(config map[string]any, checkFeatures bool) []devcontainer.ValidationError {
+	problems := devcontainer.Validate(config)
+	reported := make(map[string]bool, len(problems))
+	for _, p := range problems {
+		reported[p.Path] = true
+	}
+
+	for _, p := range checkForwardPorts(config) {
+		if !reported[p.Path] {
+			problems = append(problems, p)
+		}
+	}
+	if checkFeatures {
+		problems = append(problems, checkFeatureRefs(context.Background(), config, registry.CheckResolves)...)
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Path < problems[j].Path })
+	return problems
+}
+
+// checkForwardPorts reports forwardPorts entries whose port number (the
+// trailing part of "host:port" strings) is outside 1-65535.
+func checkForwardPorts(config map[string]any) []devcontainer.ValidationError {
+	ports, _ := config["forwardPorts"].([]any)
+	var problems []devcontainer.ValidationError
+	for i, p := range ports {
+		var port int
+		switch v := p.(type) {
+		case float64:
+			port = int(v)
+		case string:
+			n, err := strconv.Atoi(v[strings.LastIndex(v, ":")+1:])
+			if err != nil {
+				problems = append(problems, devcontainer.ValidationError{
+					Path:    fmt.Sprintf("forwardPorts[%d]", i),
+					Message: fmt.Sprintf("invalid port %q", v),
+				})
+				continue
+			}
+			port = n
+		default:
+			continue
+		}
+		if port < 1 || port > 65535 {
+			problems = append(problems, devcontainer.ValidationError{
+				Path:    fmt.Sprintf("forwardPorts[%d]", i),
+				Message: fmt.Sprintf("port %d out of range (1-65535)", port),
+			})
+		}
+	}
+	return problems
+}
+
+// checkFeatureRefs reports features whose OCI reference doesn't resolve,
+// asking the registry with check each time rather than trusting cached
+// metadata, so a removed feature fails the check. Local and URL features
+// are skipped.
+func checkFeatureRefs(ctx context.Context, config map[string]any, check func(context.Context, string) error) []devcontainer.ValidationError {
+	features, _ := config["features"].(map[string]any)
+	refs := make([]string, 0, len(features))
+	for ref := range features {
+		if !strings.HasPrefix(ref, ".") && !strings.Contains(ref, "://") {
+			refs = append(refs, ref)
+		}
+	}
+	sort.Strings(refs)
+
+	var problems []devcontainer.ValidationError
+	for _, ref := range refs {
+		err := check(ctx, ref)
+		switch {
+		case errors.Is(err, registry.ErrNotFound):
+			problems = append(problems, devcontainer.ValidationError{
+				Path:    fmt.Sprintf("features[%q]", ref),
+				Message: "not found in the registry",
+			})
+		case err != nil:
+			problems = append(problems, devcontainer.ValidationError{
+				Path:    fmt.Sprintf("features[%q]", ref),
+				Message: fmt.Sprintf("cannot resolve: %v", err),
+			})
+		}
+	}
+	return problems
+}
+
+type validationReport struct {
+	Config   string                         `json:"config"`
+	Valid    bool                           `json:"valid"`
+	Problems []devcontainer.ValidationError `json:"problems"`
+}
+
+func writeValidationJSON(out io.Writer, configPath string, problems []devcontainer.ValidationError) error {
+	report := validationReport{
+		Config:   configPath,
+		Valid:    len(problems) == 0,
+		Problems: problems,
+	}
+	if report.Problems == nil {
+		report.Problems = []devcontainer.ValidationError{}
+	}
+
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("writing validation report: %w", err)
+	}
+	return nil
+}
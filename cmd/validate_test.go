@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+func TestCheckFeatureRefs(t *testing.T) {
+	config := map[string]any{"features": map[string]any{
+		"ghcr.io/devcontainers/features/node:1":  map[string]any{},
+		"ghcr.io/devcontainers/features/nodee:1": map[string]any{},
+		"ghcr.io/private/features/internal:1":    map[string]any{},
+		"./local-feature":                        map[string]any{},
+		"https://example.com/features/tool.tgz":  map[string]any{},
+	}}
+	var checked []string
+	check := func(_ context.Context, ref string) error {
+		checked = append(checked, ref)
+		switch ref {
+		case "ghcr.io/devcontainers/features/nodee:1":
+			return fmt.Errorf("resolving %s: %w", ref, registry.ErrNotFound)
+		case "ghcr.io/private/features/internal:1":
+			return errors.New("registry unreachable")
+		}
+		return nil
+	}
+
+	problems := checkFeatureRefs(context.Background(), config, check)
+	if len(checked) != 3 {
+		t.Errorf("checked %v, want only the OCI features", checked)
+	}
+	if len(problems) != 2 {
+		t.Fatalf("problems = %v, want the missing and the unreachable feature", problems)
+	}
+	if p := problems[0]; p.Path != `features["ghcr.io/devcontainers/features/nodee:1"]` || p.Message != "not found in the registry" {
+		t.Errorf("problems[0] = %v", p)
+	}
+	if p := problems[1]; p.Path != `features["ghcr.io/private/features/internal:1"]` || p.Message != "cannot resolve: registry unreachable" {
+		t.Errorf("problems[1] = %v", p)
+	}
+}
@@ -0,0 +1,24 @@
+// Package cachedir locates the directory dcc caches catalogs and registry
+// metadata in.
+package cachedir
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Dir returns $DCC_CACHE_DIR, else $XDG_CACHE_HOME/dcc, else ~/.cache/dcc.
+// Like the XDG spec demands, a relative XDG_CACHE_HOME is ignored.
+func Dir() (string, error) {
+	if dir := os.Getenv("DCC_CACHE_DIR"); dir != "" {
+		return filepath.Abs(dir)
+	}
+	if xdg := os.Getenv("XDG_CACHE_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "dcc"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cache", "dcc"), nil
+}
@@ -0,0 +1,35 @@
+package cachedir
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDir(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	tests := []struct {
+		cacheDir, xdg string
+		want          string
+	}{
+		{"", "", filepath.Join(home, ".cache", "dcc")},
+		{"", "/var/cache/me", "/var/cache/me/dcc"},
+		{"", "relative/cache", filepath.Join(home, ".cache", "dcc")},
+		{"/tmp/dcc-ci", "/var/cache/me", "/tmp/dcc-ci"},
+	}
+	for _, tt := range tests {
+		t.Setenv("DCC_CACHE_DIR", tt.cacheDir)
+		t.Setenv("XDG_CACHE_HOME", tt.xdg)
+		got, err := Dir()
+		if err != nil || got != tt.want {
+			t.Errorf("DCC_CACHE_DIR=%q XDG_CACHE_HOME=%q: Dir() = %q, %v; want %q", tt.cacheDir, tt.xdg, got, err, tt.want)
+		}
+	}
+
+	// A relative DCC_CACHE_DIR resolves against the working directory.
+	t.Chdir(home)
+	t.Setenv("DCC_CACHE_DIR", "cache")
+	if got, _ := Dir(); got != filepath.Join(home, "cache") {
+		t.Errorf("relative DCC_CACHE_DIR: Dir() = %q", got)
+	}
+}
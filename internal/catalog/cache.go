@@ -2,31 +2,56 @@ package catalog
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+
+	"github.com/mochlast/devcontainer-companion/internal/cachedir"
 )
 
-const defaultTTL = 1 * time.Hour
+// DefaultTTL applies when DCC_CACHE_TTL is unset or invalid.
+const DefaultTTL = 1 * time.Hour
 
 type cachedCatalog struct {
 	Entries   []CatalogEntry `json:"entries"`
 	FetchedAt time.Time      `json:"fetchedAt"`
 }
 
-func cacheDir() (string, error) {
-	home, err := os.UserHomeDir()
+// CacheDir returns the cache directory, see cachedir.Dir, creating it if
+// needed.
+func CacheDir() (string, error) {
+	dir, err := cachedir.Dir()
 	if err != nil {
 		return "", err
 	}
-	dir := filepath.Join(home, ".cache", "dcc")
 	return dir, os.MkdirAll(dir, 0o755)
 }
 
+// CacheTTL returns how long cached catalogs are fresh, from DCC_CACHE_TTL,
+// which accepts a Go duration ("6h", "30m") or a number of seconds.
+func CacheTTL() time.Duration {
+	val := os.Getenv("DCC_CACHE_TTL")
+	if val == "" {
+		return DefaultTTL
+	}
+	if d, err := time.ParseDuration(val); err == nil && d > 0 {
+		return d
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return DefaultTTL
+}
+
 func cachePath(kind string) (string, error) {
-	dir, err := cacheDir()
+	dir, err := CacheDir()
 	if err != nil {
 		return "", err
 	}
@@ -35,6 +60,12 @@ func cachePath(kind string) (string, error) {
 
 // LoadCached loads cached catalog entries if the cache is still valid.
 func LoadCached(kind string) ([]CatalogEntry, bool) {
+	return loadCache(kind, false)
+}
+
+// loadCache loads cached catalog entries. Unless allowStale is set, entries
+// older than CacheTTL are ignored.
+func loadCache(kind string, allowStale bool) ([]CatalogEntry, bool) {
 	path, err := cachePath(kind)
 	if err != nil {
 		return nil, false
@@ -50,12 +81,12 @@ func LoadCached(kind string) ([]CatalogEntry, bool) {
 		return nil, false
 	}
 
-	if time.Since(cached.FetchedAt) > defaultTTL {
+	if !allowStale && time.Since(cached.FetchedAt) > CacheTTL() {
 		return nil, false
 	}
 
-	// Invalidate cache written before SourceURL was added
-	if len(cached.Entries) > 0 && cached.Entries[0].SourceURL == "" {
+	// Invalidate empty caches and caches written before SourceURL was added
+	if len(cached.Entries) == 0 || cached.Entries[0].SourceURL == "" {
 		return nil, false
 	}
 
@@ -79,7 +110,24 @@ func SaveCache(kind string, entries []CatalogEntry) error {
 		return fmt.Errorf("marshaling cache: %w", err)
 	}
 
-	return os.WriteFile(path, data, 0o644)
+	// Write via rename so a concurrent reader (or a second writer warming the
+	// same cache) never sees a partially written file.
+	tmp, err := os.CreateTemp(filepath.Dir(path), kind+"-*.json")
+	if err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 // GetTemplates returns templates from cache or fetches them.
@@ -87,21 +135,17 @@ func SaveCache(kind string, entries []CatalogEntry) error {
 func GetTemplates(noCache bool) ([]CatalogEntry, error) {
 	if !noCache {
 		if entries, ok := LoadCached("templates"); ok {
-			return sortOfficialFirst(entries), nil
+			return prepareEntries(entries), nil
 		}
 	}
 
-	entries, err := FetchTemplates()
+	entries, err := fetchShared("templates", FetchTemplates)
 	if err != nil {
-		// Fallback to expired cache on error
-		if cached, ok := LoadCached("templates"); ok {
-			return sortOfficialFirst(cached), nil
-		}
-		return nil, err
+		return fallback("templates", err)
 	}
 
 	_ = SaveCache("templates", entries)
-	return sortOfficialFirst(entries), nil
+	return prepareEntries(entries), nil
 }
 
 // GetFeatures returns features from cache or fetches them.
@@ -109,29 +153,97 @@ func GetTemplates(noCache bool) ([]CatalogEntry, error) {
 func GetFeatures(noCache bool) ([]CatalogEntry, error) {
 	if !noCache {
 		if entries, ok := LoadCached("features"); ok {
-			return sortOfficialFirst(entries), nil
+			return prepareEntries(entries), nil
 		}
 	}
 
-	entries, err := FetchFeatures()
+	entries, err := fetchShared("features", FetchFeatures)
 	if err != nil {
-		// Fallback to expired cache on error
-		if cached, ok := LoadCached("features"); ok {
-			return sortOfficialFirst(cached), nil
+		return fallback("features", err)
+	}
+
+	_ = SaveCache("features", entries)
+	return prepareEntries(entries), nil
+}
+
+// fallback returns the expired cache, then the bundled snapshot, when the
+// live catalog could not be fetched, or err wrapped in ErrCatalogUnavailable
+// if neither is available. Entries are marked Stale when the catalog was
+// fetched but did not parse.
+func fallback(kind string, err error) ([]CatalogEntry, error) {
+	entries, ok := loadCache(kind, true)
+	if !ok {
+		entries, ok = loadSnapshot(kind)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %w", ErrCatalogUnavailable, err)
+	}
+	if errors.Is(err, ErrCatalogFormat) {
+		for i := range entries {
+			entries[i].Stale = true
+		}
+	}
+	return prepareEntries(entries), nil
+}
+
+// IsStale reports whether entries are fallback data served because the
+// containers.dev catalog format changed.
+func IsStale(entries []CatalogEntry) bool {
+	for _, e := range entries {
+		if e.Stale {
+			return true
 		}
+	}
+	return false
+}
+
+// fetches collapses concurrent fetches of the same catalog (e.g. the hub's
+// background warm-up and a preload) into a single request.
+var fetches singleflight.Group
+
+func fetchShared(kind string, fetch func() ([]CatalogEntry, error)) ([]CatalogEntry, error) {
+	v, err, _ := fetches.Do(kind, func() (any, error) {
+		return fetch()
+	})
+	if err != nil {
 		return nil, err
 	}
+	entries := v.([]CatalogEntry)
+	// Callers sort in place, so each gets its own copy.
+	return append([]CatalogEntry(nil), entries...), nil
+}
 
-	_ = SaveCache("features", entries)
-	return sortOfficialFirst(entries), nil
+// GetAll returns templates and features, fetching both concurrently when
+// the cache is cold.
+func GetAll(noCache bool) (templates, features []CatalogEntry, err error) {
+	var g errgroup.Group
+	g.Go(func() error {
+		var err error
+		templates, err = GetTemplates(noCache)
+		return err
+	})
+	g.Go(func() error {
+		var err error
+		features, err = GetFeatures(noCache)
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return nil, nil, err
+	}
+	return templates, features, nil
+}
+
+// prepareEntries categorizes entries and sorts official ones first.
+func prepareEntries(entries []CatalogEntry) []CatalogEntry {
+	return sortOfficialFirst(withCategories(entries))
 }
 
-// sortOfficialFirst sorts entries so that official devcontainers entries
-// (ghcr.io/devcontainers/) appear at the top, preserving relative order within
-// each group.
+// sortOfficialFirst sorts entries of preferred orgs (DCC_PREFERRED_ORGS),
+// then official devcontainers entries (ghcr.io/devcontainers/), to the top,
+// preserving relative order within each group.
 func sortOfficialFirst(entries []CatalogEntry) []CatalogEntry {
 	sort.SliceStable(entries, func(i, j int) bool {
-		return IsOfficial(entries[i].OciRef) && !IsOfficial(entries[j].OciRef)
+		return SourceRank(entries[i].OciRef) < SourceRank(entries[j].OciRef)
 	})
 	return entries
 }
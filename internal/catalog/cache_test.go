@@ -0,0 +1,54 @@
+package catalog
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheTTL(t *testing.T) {
+	tests := []struct {
+		env  string
+		want time.Duration
+	}{
+		{"", DefaultTTL},
+		{"24h", 24 * time.Hour},
+		{"600", 10 * time.Minute},
+		{"bogus", DefaultTTL},
+		{"0", DefaultTTL},
+	}
+	for _, tt := range tests {
+		t.Setenv("DCC_CACHE_TTL", tt.env)
+		if got := CacheTTL(); got != tt.want {
+			t.Errorf("DCC_CACHE_TTL=%q: CacheTTL() = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestLoadCachedHonorsTTLAndDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DCC_CACHE_DIR", dir)
+
+	// A catalog fetched two hours ago.
+	data, err := json.Marshal(cachedCatalog{
+		Entries:   []CatalogEntry{{Name: "Go", OciRef: "ghcr.io/devcontainers/templates/go", SourceURL: "https://github.com/devcontainers/templates"}},
+		FetchedAt: time.Now().Add(-2 * time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "templates.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DCC_CACHE_TTL", "")
+	if _, ok := LoadCached("templates"); ok {
+		t.Error("LoadCached used a cache older than the default TTL")
+	}
+	t.Setenv("DCC_CACHE_TTL", "3h")
+	if entries, ok := LoadCached("templates"); !ok || len(entries) != 1 {
+		t.Errorf("LoadCached with DCC_CACHE_TTL=3h = %v, %v; want the cached catalog", entries, ok)
+	}
+}
@@ -0,0 +1,97 @@
+package catalog
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Categories lists the picker categories in cycling order. Entries that
+// match none of the keywords are Tools.
+var Categories = []string{"Languages", "Databases", "Cloud", "Containers", "Tools"}
+
+// categoryKeywords maps words of a template or feature ID or name to a
+// category. containers.dev doesn't publish categories, so this covers the
+// common ecosystems; everything else falls back to Tools.
+var categoryKeywords = map[string]string{
+	// Databases
+	"postgres": "Databases", "postgresql": "Databases", "mysql": "Databases",
+	"mariadb": "Databases", "mongo": "Databases", "mongodb": "Databases",
+	"redis": "Databases", "sqlite": "Databases", "sqlserver": "Databases",
+	"mssql": "Databases", "cassandra": "Databases", "couchdb": "Databases",
+	"neo4j": "Databases", "elasticsearch": "Databases", "influxdb": "Databases",
+	"cockroachdb": "Databases", "duckdb": "Databases", "supabase": "Databases",
+	"dynamodb": "Databases", "sql": "Databases", "database": "Databases",
+
+	// Containers and orchestration
+	"docker": "Containers", "podman": "Containers", "buildah": "Containers",
+	"kubectl": "Containers", "kubernetes": "Containers", "k8s": "Containers",
+	"helm": "Containers", "minikube": "Containers", "k3d": "Containers",
+	"kind": "Containers", "k9s": "Containers", "skaffold": "Containers",
+	"tilt": "Containers", "compose": "Containers",
+
+	// Cloud providers and infrastructure as code
+	"aws": "Cloud", "azure": "Cloud", "azd": "Cloud", "gcloud": "Cloud",
+	"google": "Cloud", "terraform": "Cloud", "terragrunt": "Cloud",
+	"pulumi": "Cloud", "cdk": "Cloud", "flyctl": "Cloud", "heroku": "Cloud",
+	"doctl": "Cloud", "oci": "Cloud", "ibmcloud": "Cloud", "vercel": "Cloud",
+	"netlify": "Cloud", "firebase": "Cloud", "ansible": "Cloud",
+
+	// Languages and runtimes
+	"node": "Languages", "nodejs": "Languages", "javascript": "Languages",
+	"typescript": "Languages", "deno": "Languages", "bun": "Languages",
+	"python": "Languages", "conda": "Languages", "anaconda": "Languages",
+	"miniconda": "Languages", "go": "Languages", "golang": "Languages",
+	"java": "Languages", "kotlin": "Languages", "scala": "Languages",
+	"rust": "Languages", "ruby": "Languages", "rails": "Languages",
+	"php": "Languages", "dotnet": "Languages", "csharp": "Languages",
+	"cpp": "Languages", "elixir": "Languages", "erlang": "Languages",
+	"haskell": "Languages", "julia": "Languages", "dart": "Languages",
+	"flutter": "Languages", "swift": "Languages", "perl": "Languages",
+	"lua": "Languages", "r": "Languages", "zig": "Languages",
+	"nim": "Languages", "clojure": "Languages", "ocaml": "Languages",
+	"fortran": "Languages", "gleam": "Languages", "crystal": "Languages",
+}
+
+// categoryPriority decides between categories when an entry mentions
+// several, e.g. "Python 3 & PostgreSQL" is a database setup.
+var categoryPriority = map[string]int{"Databases": 0, "Containers": 1, "Cloud": 2, "Languages": 3}
+
+// CategoryOf derives the category of a template or feature from the words
+// of its ID (the last OCI path segment) and name.
+func CategoryOf(e CatalogEntry) string {
+	id := e.OciRef
+	if i := strings.LastIndex(id, "/"); i != -1 {
+		id = id[i+1:]
+	}
+	if i := strings.Index(id, ":"); i != -1 {
+		id = id[:i]
+	}
+
+	best := "Tools"
+	for _, word := range categoryWords(id + " " + e.Name) {
+		c, ok := categoryKeywords[word]
+		if !ok {
+			continue
+		}
+		if best == "Tools" || categoryPriority[c] < categoryPriority[best] {
+			best = c
+		}
+	}
+	return best
+}
+
+// categoryWords splits s into lowercase words at anything but letters and
+// digits, so "docker-in-docker" and "C++ (gcc)" both split cleanly.
+func categoryWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// withCategories fills in Category for each entry.
+func withCategories(entries []CatalogEntry) []CatalogEntry {
+	for i := range entries {
+		entries[i].Category = CategoryOf(entries[i])
+	}
+	return entries
+}
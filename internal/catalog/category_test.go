@@ -0,0 +1,24 @@
+package catalog
+
+import "testing"
+
+func TestCategoryOf(t *testing.T) {
+	tests := []struct {
+		entry CatalogEntry
+		want  string
+	}{
+		{CatalogEntry{Name: "Node.js", OciRef: "ghcr.io/devcontainers/features/node"}, "Languages"},
+		{CatalogEntry{Name: "Go", OciRef: "ghcr.io/devcontainers/templates/go:4"}, "Languages"},
+		{CatalogEntry{Name: "Docker (docker-in-docker)", OciRef: "ghcr.io/devcontainers/features/docker-in-docker"}, "Containers"},
+		{CatalogEntry{Name: "Kubectl, Helm, and Minikube", OciRef: "ghcr.io/devcontainers/features/kubectl-helm-minikube"}, "Containers"},
+		{CatalogEntry{Name: "Terraform, tflint, and TFGrunt", OciRef: "ghcr.io/devcontainers/features/terraform"}, "Cloud"},
+		{CatalogEntry{Name: "Python 3 & PostgreSQL", OciRef: "ghcr.io/devcontainers/templates/postgres"}, "Databases"},
+		{CatalogEntry{Name: "Git (from source)", OciRef: "ghcr.io/devcontainers/features/git"}, "Tools"},
+		{CatalogEntry{Name: "Common Utilities", OciRef: "ghcr.io/devcontainers/features/common-utils"}, "Tools"},
+	}
+	for _, tt := range tests {
+		if got := CategoryOf(tt.entry); got != tt.want {
+			t.Errorf("CategoryOf(%s) = %q, want %q", tt.entry.OciRef, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,15 @@
 package catalog
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
 
 	"golang.org/x/net/html"
+
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
 )
 
 const (
@@ -14,6 +17,14 @@ const (
 	featuresURL  = "https://containers.dev/features"
 )
 
+// ErrCatalogFormat reports a containers.dev page dcc can read but not
+// parse, most likely after a redesign that needs a newer dcc.
+var ErrCatalogFormat = errors.New("containers.dev catalog format changed, please update dcc")
+
+// ErrCatalogUnavailable reports that a catalog could neither be fetched nor
+// served from the cache or the bundled snapshot.
+var ErrCatalogUnavailable = errors.New("catalog unavailable")
+
 // FetchTemplates fetches and parses the template catalog from containers.dev.
 func FetchTemplates() ([]CatalogEntry, error) {
 	return fetchCatalog(templatesURL)
@@ -25,7 +36,7 @@ func FetchFeatures() ([]CatalogEntry, error) {
 }
 
 func fetchCatalog(url string) ([]CatalogEntry, error) {
-	resp, err := http.Get(url)
+	resp, err := httpclient.Default().Get(url)
 	if err != nil {
 		return nil, fmt.Errorf("fetching %s: %w", url, err)
 	}
@@ -45,6 +56,7 @@ func fetchCatalog(url string) ([]CatalogEntry, error) {
 
 // parseHTML extracts CatalogEntry items from the HTML table on containers.dev.
 // The table has class "tg" and 4 columns: Name, Maintainer, Reference, Version.
+// A page without the table or without entries in it yields ErrCatalogFormat.
 func parseHTML(htmlContent string) ([]CatalogEntry, error) {
 	doc, err := html.Parse(strings.NewReader(htmlContent))
 	if err != nil {
@@ -54,7 +66,7 @@ func parseHTML(htmlContent string) ([]CatalogEntry, error) {
 	var entries []CatalogEntry
 	table := findTable(doc)
 	if table == nil {
-		return nil, fmt.Errorf("could not find catalog table in HTML")
+		return nil, fmt.Errorf("%w: no catalog table found", ErrCatalogFormat)
 	}
 
 	rows := findElements(table, "tr")
@@ -80,6 +92,9 @@ func parseHTML(htmlContent string) ([]CatalogEntry, error) {
 			entries = append(entries, entry)
 		}
 	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no entries in the catalog table", ErrCatalogFormat)
+	}
 
 	return entries, nil
 }
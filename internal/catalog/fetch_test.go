@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+)
+
+const catalogTable = `<table class="tg" id="collectionTable">
+<tr><td><b>Name</b></td><td><b>Maintainer</b></td><td><b>Reference</b></td><td><b>Version</b></td></tr>
+<tr><td><a href="https://github.com/devcontainers/features/tree/main/src/node">Node.js</a></td>
+<td>Dev Container Spec Maintainers</td><td>ghcr.io/devcontainers/features/node</td><td>1.6.1</td></tr>
+</table>`
+
+func TestParseHTML(t *testing.T) {
+	entries, err := parseHTML(catalogTable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name != "Node.js" || entries[0].Version != "1.6.1" ||
+		entries[0].SourceURL == "" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
+
+func TestParseHTMLFormatChanged(t *testing.T) {
+	redesigned, err := os.ReadFile("testdata/redesigned.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	emptyTable := `<table class="tg"><tr><td><b>Name</b></td></tr></table>`
+
+	for name, page := range map[string]string{"no table": string(redesigned), "empty table": emptyTable} {
+		if _, err := parseHTML(page); !errors.Is(err, ErrCatalogFormat) {
+			t.Errorf("%s: err = %v, want ErrCatalogFormat", name, err)
+		}
+	}
+}
+
+func TestFallbackAfterFormatChange(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	formatErr := fmt.Errorf("%w: no catalog table found", ErrCatalogFormat)
+
+	// Without a cache the bundled snapshot is used.
+	entries, err := fallback("features", formatErr)
+	if err != nil || len(entries) == 0 || !entries[0].Offline || !IsStale(entries) {
+		t.Fatalf("snapshot fallback = %d entries, %v", len(entries), err)
+	}
+
+	cached := []CatalogEntry{{Name: "Node.js", OciRef: "ghcr.io/devcontainers/features/node", SourceURL: "https://example.com"}}
+	if err := SaveCache("features", cached); err != nil {
+		t.Fatal(err)
+	}
+	entries, err = fallback("features", formatErr)
+	if err != nil || len(entries) != 1 || entries[0].Offline || !IsStale(entries) {
+		t.Errorf("cache fallback = %+v, %v", entries, err)
+	}
+
+	// Network errors fall back without the stale warning.
+	entries, _ = fallback("features", errors.New("dial tcp: no route to host"))
+	if IsStale(entries) {
+		t.Error("entries marked stale after a network error")
+	}
+
+	// Without cache or snapshot the catalog is unavailable.
+	netErr := errors.New("dial tcp: no route to host")
+	if _, err := fallback("collections", netErr); !errors.Is(err, ErrCatalogUnavailable) || !errors.Is(err, netErr) {
+		t.Errorf("err = %v, want ErrCatalogUnavailable wrapping the fetch error", err)
+	}
+}
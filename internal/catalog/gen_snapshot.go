@@ -0,0 +1,50 @@
+//go:build ignore
+
+// gen_snapshot refreshes the bundled offline catalog from containers.dev.
+// Run it with `go generate ./internal/catalog`.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mochlast/devcontainer-companion/internal/catalog"
+)
+
+func main() {
+	for kind, fetch := range map[string]func() ([]catalog.CatalogEntry, error){
+		"templates": catalog.FetchTemplates,
+		"features":  catalog.FetchFeatures,
+	} {
+		if err := write(kind, fetch); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// write stores the official entries of one catalog kind in snapshot/<kind>.json.
+func write(kind string, fetch func() ([]catalog.CatalogEntry, error)) error {
+	entries, err := fetch()
+	if err != nil {
+		return fmt.Errorf("fetching %s: %w", kind, err)
+	}
+
+	var official []catalog.CatalogEntry
+	for _, e := range entries {
+		if catalog.IsOfficial(e.OciRef) {
+			official = append(official, e)
+		}
+	}
+	if len(official) == 0 {
+		return fmt.Errorf("no official %s found", kind)
+	}
+
+	data, err := json.MarshalIndent(official, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling %s: %w", kind, err)
+	}
+	return os.WriteFile(filepath.Join("snapshot", kind+".json"), append(data, '\n'), 0o644)
+}
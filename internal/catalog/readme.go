@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
-	"time"
+
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
 )
 
 // SourceURLToReadmeURL converts a GitHub tree URL to the raw README.md URL.
@@ -38,15 +41,23 @@ func SourceURLToReadmeURL(sourceURL string) string {
 	return fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s/README.md", owner, repo, branch, path)
 }
 
-// FetchReadme fetches the README content from the given source URL.
+// FetchReadme fetches the README content from the given source URL. A
+// file:// URL names a local template folder and reads its README.md.
 func FetchReadme(sourceURL string) (string, error) {
+	if dir, ok := strings.CutPrefix(sourceURL, "file://"); ok {
+		data, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		if err != nil {
+			return "", fmt.Errorf("reading README: %w", err)
+		}
+		return string(data), nil
+	}
+
 	readmeURL := SourceURLToReadmeURL(sourceURL)
 	if readmeURL == "" {
 		return "", fmt.Errorf("could not derive README URL from %q", sourceURL)
 	}
 
-	client := &http.Client{Timeout: 8 * time.Second}
-	resp, err := client.Get(readmeURL)
+	resp, err := httpclient.Default().Get(readmeURL)
 	if err != nil {
 		return "", fmt.Errorf("fetching README: %w", err)
 	}
@@ -0,0 +1,125 @@
+package catalog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// maxRecent is how many templates and features recent.json remembers each.
+const maxRecent = 10
+
+// recentFile is ~/.cache/dcc/recent.json: versionless OCI refs per catalog
+// kind ("templates", "features"), most recently used first.
+type recentFile map[string][]string
+
+func recentPath() (string, error) {
+	dir, err := CacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "recent.json"), nil
+}
+
+func loadRecent() (recentFile, error) {
+	path, err := recentPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return recentFile{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading recent items: %w", err)
+	}
+	recent := recentFile{}
+	if err := json.Unmarshal(data, &recent); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return recent, nil
+}
+
+// Recent returns the recently used refs of a catalog kind, most recent first.
+// A missing or unreadable file yields none.
+func Recent(kind string) []string {
+	recent, err := loadRecent()
+	if err != nil {
+		return nil
+	}
+	return recent[kind]
+}
+
+// AddRecent records refs as just used. Tags are dropped, so every version
+// of a feature shares one entry; the oldest entries beyond maxRecent go.
+func AddRecent(kind string, refs ...string) error {
+	recent, err := loadRecent()
+	if err != nil {
+		recent = recentFile{} // start over rather than fail the flow
+	}
+	for _, ref := range refs {
+		recent[kind] = pushRecent(recent[kind], ref)
+	}
+
+	path, err := recentPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(recent, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling recent items: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// pushRecent moves ref to the front of list, evicting the least recently
+// used entry when the list is full.
+func pushRecent(list []string, ref string) []string {
+	ref = devcontainer.FeatureID(ref)
+	out := []string{ref}
+	for _, r := range list {
+		if r != ref && len(out) < maxRecent {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// MarkRecent flags the entries of a kind's recent list and moves them to
+// the front, most recent first. The remaining entries keep their order.
+func MarkRecent(kind string, entries []CatalogEntry) []CatalogEntry {
+	return markRecent(entries, Recent(kind))
+}
+
+func markRecent(entries []CatalogEntry, recent []string) []CatalogEntry {
+	rank := make(map[string]int, len(recent))
+	for i, ref := range recent {
+		rank[ref] = i
+	}
+	pinned := make([]CatalogEntry, len(recent))
+	found := make([]bool, len(recent))
+	rest := make([]CatalogEntry, 0, len(entries))
+	for _, e := range entries {
+		if i, ok := rank[devcontainer.FeatureID(e.OciRef)]; ok && !found[i] {
+			e.Recent = true
+			pinned[i], found[i] = e, true
+			continue
+		}
+		rest = append(rest, e)
+	}
+
+	out := make([]CatalogEntry, 0, len(entries))
+	for i, e := range pinned {
+		if found[i] {
+			out = append(out, e)
+		}
+	}
+	return append(out, rest...)
+}
@@ -0,0 +1,74 @@
+package catalog
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAddRecent(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	if got := Recent("features"); len(got) != 0 {
+		t.Fatalf("Recent before any use = %v", got)
+	}
+	for i := 0; i < maxRecent+2; i++ {
+		if err := AddRecent("features", fmt.Sprintf("ghcr.io/x/features/f%d:1", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Re-using an entry moves it to the front instead of duplicating it.
+	if err := AddRecent("features", "ghcr.io/x/features/f5:2"); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Recent("features")
+	if len(got) != maxRecent || got[0] != "ghcr.io/x/features/f5" || got[1] != "ghcr.io/x/features/f11" {
+		t.Errorf("Recent = %v", got)
+	}
+	for _, ref := range got {
+		if ref == "ghcr.io/x/features/f0" || ref == "ghcr.io/x/features/f1" {
+			t.Errorf("least recently used %s not evicted", ref)
+		}
+	}
+	if len(Recent("templates")) != 0 {
+		t.Error("features leaked into the templates list")
+	}
+}
+
+func TestAddRecentFreshCacheDir(t *testing.T) {
+	t.Setenv("DCC_CACHE_DIR", filepath.Join(t.TempDir(), "not", "created", "yet"))
+
+	refs := []string{
+		"localhost:5000/features/tool:2",
+		"ghcr.io/devcontainers/features/node@sha256:abcdef0",
+	}
+	if err := AddRecent("features", refs...); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"ghcr.io/devcontainers/features/node", "localhost:5000/features/tool"}
+	if got := Recent("features"); !reflect.DeepEqual(got, want) {
+		t.Errorf("Recent = %v, want %v", got, want)
+	}
+}
+
+func TestMarkRecent(t *testing.T) {
+	entries := []CatalogEntry{
+		{Name: "a", OciRef: "ghcr.io/devcontainers/features/a"},
+		{Name: "b", OciRef: "ghcr.io/devcontainers/features/b"},
+		{Name: "c", OciRef: "localhost:5000/features/c"},
+	}
+	got := markRecent(entries, []string{"localhost:5000/features/c", "gone", "ghcr.io/devcontainers/features/b"})
+
+	var names []string
+	for _, e := range got {
+		names = append(names, fmt.Sprintf("%s:%v", e.Name, e.Recent))
+	}
+	if want := []string{"c:true", "b:true", "a:false"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("markRecent order = %v, want %v", names, want)
+	}
+	if entries[1].Recent {
+		t.Error("markRecent modified its input")
+	}
+}
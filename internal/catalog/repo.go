@@ -0,0 +1,116 @@
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// repoTemplateMetadata is the part of devcontainer-template.json shown in the picker.
+type repoTemplateMetadata struct {
+	ID          string `json:"id"`
+	Version     string `json:"version"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// FetchTemplatesFromRepo clones (or updates) a Git repository laid out like
+// devcontainers/templates and returns an entry for each
+// src/<template>/devcontainer-template.json. url may be a clone URL or a
+// short "github.com/org/repo". The clone is kept under ~/.cache/dcc/repos and
+// reused as-is when updating fails, e.g. offline.
+func FetchTemplatesFromRepo(url string) ([]CatalogEntry, error) {
+	cloneURL, key := repoCloneURL(url)
+	dir, err := syncRepo(cloneURL, key)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "src", "*", "devcontainer-template.json"))
+	if err != nil {
+		return nil, fmt.Errorf("scanning %s: %w", url, err)
+	}
+	sort.Strings(paths)
+
+	var entries []CatalogEntry
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		var meta repoTemplateMetadata
+		if err := json.Unmarshal(data, &meta); err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", path, err)
+		}
+
+		templateDir := filepath.Dir(path)
+		id := filepath.Base(templateDir)
+		name := meta.Name
+		if name == "" {
+			name = id
+		}
+		entries = append(entries, CatalogEntry{
+			Name:       name,
+			Maintainer: key,
+			OciRef:     key + "/src/" + id,
+			Version:    meta.Version,
+			SourceURL:  "file://" + templateDir,
+			LocalPath:  templateDir,
+		})
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no src/*/devcontainer-template.json found in %s", url)
+	}
+	return withCategories(entries), nil
+}
+
+// repoCloneURL turns a repository argument into a clone URL and a cache key
+// such as "github.com/org/repo". Short forms without a scheme use HTTPS.
+func repoCloneURL(url string) (cloneURL, key string) {
+	url = strings.TrimSpace(url)
+	key = url
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git@"} {
+		key = strings.TrimPrefix(key, prefix)
+	}
+	key = strings.TrimSuffix(strings.Replace(key, ":", "/", 1), ".git")
+	key = strings.TrimSuffix(key, "/")
+
+	cloneURL = url
+	if !strings.Contains(url, "://") && !strings.HasPrefix(url, "git@") {
+		cloneURL = "https://" + key + ".git"
+	}
+	return cloneURL, key
+}
+
+// syncRepo makes a shallow clone of cloneURL current and returns its path.
+func syncRepo(cloneURL, key string) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git not found in PATH; it is needed for --template-repo")
+	}
+	base, err := CacheDir()
+	if err != nil {
+		return "", fmt.Errorf("locating cache directory: %w", err)
+	}
+	dir := filepath.Join(base, "repos", filepath.FromSlash(key))
+
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		fetch := exec.Command("git", "-C", dir, "fetch", "--depth", "1", "origin", "HEAD")
+		if fetch.Run() == nil {
+			exec.Command("git", "-C", dir, "reset", "--hard", "FETCH_HEAD").Run() //nolint:errcheck
+		}
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+		return "", fmt.Errorf("creating cache directory: %w", err)
+	}
+	out, err := exec.Command("git", "clone", "--depth", "1", cloneURL, dir).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("cloning %s: %w\n%s", cloneURL, err, strings.TrimSpace(string(out)))
+	}
+	return dir, nil
+}
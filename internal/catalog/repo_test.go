@@ -0,0 +1,20 @@
+package catalog
+
+import "testing"
+
+func TestRepoCloneURL(t *testing.T) {
+	tests := []struct {
+		in, cloneURL, key string
+	}{
+		{"github.com/org/templates", "https://github.com/org/templates.git", "github.com/org/templates"},
+		{"https://github.com/org/templates.git", "https://github.com/org/templates.git", "github.com/org/templates"},
+		{"git@github.com:org/templates.git", "git@github.com:org/templates.git", "github.com/org/templates"},
+		{"gitlab.example.com/team/dc/", "https://gitlab.example.com/team/dc.git", "gitlab.example.com/team/dc"},
+	}
+	for _, tt := range tests {
+		cloneURL, key := repoCloneURL(tt.in)
+		if cloneURL != tt.cloneURL || key != tt.key {
+			t.Errorf("repoCloneURL(%q) = %q, %q, want %q, %q", tt.in, cloneURL, key, tt.cloneURL, tt.key)
+		}
+	}
+}
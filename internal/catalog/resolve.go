@@ -0,0 +1,102 @@
+package catalog
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// maxSuggestions caps the entries an unresolved name suggests.
+const maxSuggestions = 5
+
+// Matches returns the entries a short name such as "node" or
+// "devcontainers/node" refers to: the last segment of the name must equal
+// the entry's ID (the last path segment of its ref) and the other segments
+// must appear in the ref's path in the same order. Matching ignores case.
+func Matches(entries []CatalogEntry, name string) []CatalogEntry {
+	want := strings.Split(strings.ToLower(strings.Trim(name, "/")), "/")
+	var matches []CatalogEntry
+	for _, e := range entries {
+		if matchesSegments(refSegments(e.OciRef), want) {
+			matches = append(matches, e)
+		}
+	}
+	return matches
+}
+
+// Resolve returns the entry a short name refers to, see Matches. Among
+// several matches the one from the highest SourceRank wins; if that still
+// leaves more than one, or none match, the error lists candidates.
+func Resolve(entries []CatalogEntry, name string) (CatalogEntry, error) {
+	matches := Matches(entries, name)
+	if len(matches) == 0 {
+		if suggestions := suggest(entries, name); len(suggestions) > 0 {
+			return CatalogEntry{}, fmt.Errorf("%q not found in the catalog, did you mean %s?", name, strings.Join(suggestions, ", "))
+		}
+		return CatalogEntry{}, fmt.Errorf("%q not found in the catalog", name)
+	}
+
+	best := SourceRank(matches[0].OciRef)
+	for _, m := range matches[1:] {
+		best = min(best, SourceRank(m.OciRef))
+	}
+	var top []CatalogEntry
+	for _, m := range matches {
+		if SourceRank(m.OciRef) == best {
+			top = append(top, m)
+		}
+	}
+	if len(top) > 1 {
+		refs := make([]string, len(top))
+		for i, m := range top {
+			refs[i] = devcontainer.FeatureID(m.OciRef)
+		}
+		sort.Strings(refs)
+		return CatalogEntry{}, fmt.Errorf("%q is ambiguous, use one of: %s", name, strings.Join(refs, ", "))
+	}
+	return top[0], nil
+}
+
+// suggest returns the refs of up to maxSuggestions entries whose ID
+// contains the last segment of name.
+func suggest(entries []CatalogEntry, name string) []string {
+	id := strings.ToLower(name[strings.LastIndex(name, "/")+1:])
+	if id == "" {
+		return nil
+	}
+	var refs []string
+	for _, e := range entries {
+		segments := refSegments(e.OciRef)
+		if strings.Contains(segments[len(segments)-1], id) {
+			refs = append(refs, devcontainer.FeatureID(e.OciRef))
+		}
+	}
+	sort.Strings(refs)
+	return refs[:min(len(refs), maxSuggestions)]
+}
+
+// matchesSegments reports whether want's last segment is the last of ref
+// and its others are a subsequence of the rest.
+func matchesSegments(ref, want []string) bool {
+	if len(ref) == 0 || ref[len(ref)-1] != want[len(want)-1] {
+		return false
+	}
+	rest := ref[:len(ref)-1]
+	for _, w := range want[:len(want)-1] {
+		i := slices.Index(rest, w)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+1:]
+	}
+	return true
+}
+
+// refSegments splits an OCI ref without its tag into lowercase path
+// segments.
+func refSegments(ociRef string) []string {
+	return strings.Split(strings.ToLower(devcontainer.FeatureID(ociRef)), "/")
+}
@@ -0,0 +1,68 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+)
+
+var resolveEntries = []CatalogEntry{
+	{OciRef: "ghcr.io/devcontainers/features/node", Version: "1"},
+	{OciRef: "ghcr.io/devcontainers-extra/features/node", Version: "1"},
+	{OciRef: "ghcr.io/devcontainers/features/go", Version: "1"},
+	{OciRef: "ghcr.io/someone/features/nodejs-tools", Version: "2"},
+	{OciRef: "ghcr.io/someone/features/rust", Version: "1"},
+	{OciRef: "ghcr.io/other/features/rust", Version: "1"},
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"node", "ghcr.io/devcontainers/features/node"}, // official wins
+		{"NODE", "ghcr.io/devcontainers/features/node"},
+		{"devcontainers/node", "ghcr.io/devcontainers/features/node"},
+		{"devcontainers-extra/node", "ghcr.io/devcontainers-extra/features/node"},
+		{"devcontainers/features/go", "ghcr.io/devcontainers/features/go"},
+		{"someone/rust", "ghcr.io/someone/features/rust"},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(resolveEntries, tt.name)
+		if err != nil {
+			t.Errorf("Resolve(%q): %v", tt.name, err)
+			continue
+		}
+		if got.OciRef != tt.want {
+			t.Errorf("Resolve(%q) = %s, want %s", tt.name, got.OciRef, tt.want)
+		}
+	}
+}
+
+func TestResolvePreferredOrg(t *testing.T) {
+	t.Setenv("DCC_PREFERRED_ORGS", "ghcr.io/devcontainers-extra")
+	got, err := Resolve(resolveEntries, "node")
+	if err != nil || got.OciRef != "ghcr.io/devcontainers-extra/features/node" {
+		t.Errorf("Resolve(node) = %s, %v; want the preferred org's feature", got.OciRef, err)
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	// Two non-official features of the same name need an org.
+	_, err := Resolve(resolveEntries, "rust")
+	if err == nil || !strings.Contains(err.Error(), "ambiguous") ||
+		!strings.Contains(err.Error(), "ghcr.io/other/features/rust, ghcr.io/someone/features/rust") {
+		t.Errorf("Resolve(rust) error = %v, want both candidates", err)
+	}
+
+	_, err = Resolve(resolveEntries, "nod")
+	if err == nil || !strings.Contains(err.Error(), "did you mean ghcr.io/devcontainers-extra/features/node, ghcr.io/devcontainers/features/node, ghcr.io/someone/features/nodejs-tools?") {
+		t.Errorf("Resolve(nod) error = %v, want suggestions", err)
+	}
+
+	// Org segments must appear in the ref, in order.
+	if _, err := Resolve(resolveEntries, "features/devcontainers/node"); err == nil {
+		t.Error("Resolve matched segments out of order")
+	}
+	if _, err := Resolve(resolveEntries, "python"); err == nil || strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("Resolve(python) error = %v, want not found without suggestions", err)
+	}
+}
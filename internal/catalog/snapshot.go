@@ -0,0 +1,31 @@
+package catalog
+
+import (
+	"embed"
+	"encoding/json"
+)
+
+//go:generate go run gen_snapshot.go
+
+// snapshotFS holds the official templates and features as of the last
+// `go generate`, used when neither the network nor the disk cache is available.
+//
+//go:embed snapshot/templates.json snapshot/features.json
+var snapshotFS embed.FS
+
+// loadSnapshot returns the bundled catalog for kind ("templates" or
+// "features"), with every entry marked Offline.
+func loadSnapshot(kind string) ([]CatalogEntry, bool) {
+	data, err := snapshotFS.ReadFile("snapshot/" + kind + ".json")
+	if err != nil {
+		return nil, false
+	}
+	var entries []CatalogEntry
+	if err := json.Unmarshal(data, &entries); err != nil || len(entries) == 0 {
+		return nil, false
+	}
+	for i := range entries {
+		entries[i].Offline = true
+	}
+	return entries, true
+}
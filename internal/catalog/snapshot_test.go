@@ -0,0 +1,20 @@
+package catalog
+
+import "testing"
+
+func TestLoadSnapshot(t *testing.T) {
+	for _, kind := range []string{"templates", "features"} {
+		entries, ok := loadSnapshot(kind)
+		if !ok {
+			t.Fatalf("no bundled %s snapshot", kind)
+		}
+		for _, e := range entries {
+			if !e.Offline {
+				t.Errorf("%s entry %s not marked offline", kind, e.OciRef)
+			}
+			if !IsOfficial(e.OciRef) || e.Name == "" || e.SourceURL == "" {
+				t.Errorf("%s entry incomplete: %+v", kind, e)
+			}
+		}
+	}
+}
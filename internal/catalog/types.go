@@ -1,6 +1,9 @@
 package catalog
 
-import "strings"
+import (
+	"os"
+	"strings"
+)
 
 // CatalogEntry represents a template or feature from the containers.dev catalog.
 type CatalogEntry struct {
@@ -9,6 +12,11 @@ type CatalogEntry struct {
 	OciRef     string `json:"ociRef"`
 	Version    string `json:"version"`
 	SourceURL  string `json:"sourceURL,omitempty"`
+	Offline    bool   `json:"-"` // from the bundled snapshot, not a live fetch
+	LocalPath  string `json:"-"` // template folder in a --template-repo clone
+	Recent     bool   `json:"-"` // recently used, see MarkRecent
+	Category   string `json:"-"` // see CategoryOf
+	Stale      bool   `json:"-"` // fallback data because the live catalog didn't parse, see ErrCatalogFormat
 }
 
 // FilterValue returns the string used for fuzzy-filtering in the TUI picker.
@@ -21,3 +29,43 @@ func (e CatalogEntry) FilterValue() string {
 func IsOfficial(ociRef string) bool {
 	return strings.HasPrefix(ociRef, "ghcr.io/devcontainers/")
 }
+
+// PreferredOrgs returns the OCI prefixes listed in DCC_PREFERRED_ORGS
+// (comma-separated, e.g. "ghcr.io/mycorp"), each ending in a slash.
+func PreferredOrgs() []string {
+	var orgs []string
+	for _, org := range strings.Split(os.Getenv("DCC_PREFERRED_ORGS"), ",") {
+		org = strings.TrimSpace(org)
+		if org == "" {
+			continue
+		}
+		if !strings.HasSuffix(org, "/") {
+			org += "/"
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs
+}
+
+// IsPreferred reports whether the OCI reference belongs to one of the
+// PreferredOrgs.
+func IsPreferred(ociRef string) bool {
+	for _, org := range PreferredOrgs() {
+		if strings.HasPrefix(ociRef, org) {
+			return true
+		}
+	}
+	return false
+}
+
+// SourceRank orders entries by publisher: 0 for preferred orgs, 1 for
+// official, 2 for everything else.
+func SourceRank(ociRef string) int {
+	switch {
+	case IsPreferred(ociRef):
+		return 0
+	case IsOfficial(ociRef):
+		return 1
+	}
+	return 2
+}
@@ -0,0 +1,29 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortPreferredOrgsFirst(t *testing.T) {
+	t.Setenv("DCC_PREFERRED_ORGS", " ghcr.io/mycorp , ,ghcr.io/other/")
+	entries := []CatalogEntry{
+		{OciRef: "ghcr.io/someone/features/a"},
+		{OciRef: "ghcr.io/devcontainers/features/b"},
+		{OciRef: "ghcr.io/mycorp/features/c"},
+		{OciRef: "ghcr.io/mycorporation/features/d"},
+		{OciRef: "ghcr.io/other/features/e"},
+	}
+	var got []string
+	for _, e := range sortOfficialFirst(entries) {
+		got = append(got, e.OciRef[len(e.OciRef)-1:])
+	}
+	if want := []string{"c", "e", "b", "a", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("order = %v, want %v (preferred, official, rest)", got, want)
+	}
+
+	t.Setenv("DCC_PREFERRED_ORGS", "")
+	if PreferredOrgs() != nil || SourceRank("ghcr.io/mycorp/features/c") != 2 {
+		t.Error("an empty DCC_PREFERRED_ORGS still prefers orgs")
+	}
+}
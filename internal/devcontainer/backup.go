@@ -0,0 +1,80 @@
+package devcontainer
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultBackups is how many backups of each config --backup keeps.
+const DefaultBackups = 5
+
+// backupTimeFormat stamps backup names; it sorts chronologically.
+const backupTimeFormat = "20060102-150405.000"
+
+// backups is how many backups WriteConfig keeps of each config, see
+// SetBackups.
+var backups int
+
+// now is the clock backups are stamped with.
+var now = time.Now
+
+// SetBackups makes WriteConfig copy a config to <config>.<time>.bak next to
+// it before changing it, keeping the newest n copies. 0 turns backups off.
+func SetBackups(n int) {
+	backups = n
+}
+
+// Backups returns the backups of the config at path, oldest first.
+func Backups(path string) ([]string, error) {
+	matches, err := files.Glob(path + ".*.bak")
+	if err != nil {
+		return nil, fmt.Errorf("listing backups of %s: %w", filepath.Base(path), err)
+	}
+	var found []string
+	for _, m := range matches {
+		stamp := strings.TrimSuffix(strings.TrimPrefix(m, path+"."), ".bak")
+		if _, err := time.Parse(backupTimeFormat, stamp); err == nil {
+			found = append(found, m)
+		}
+	}
+	sort.Strings(found)
+	return found, nil
+}
+
+// BackupConfig backs up the config at path under SetBackups, for files
+// replaced other than through WriteConfig, such as by a template apply. It
+// works for any file; template applies also back up the Dockerfiles and
+// scripts they replace with it.
+func BackupConfig(path string) error {
+	if backups <= 0 || memoryFiles != nil {
+		return nil
+	}
+	existing, err := ReadFile(path)
+	if err != nil || len(existing) == 0 {
+		return nil
+	}
+	return backupConfig(path, existing)
+}
+
+// backupConfig saves existing, the contents of the config at path about to
+// be replaced, and removes the oldest backups beyond the limit.
+func backupConfig(path string, existing []byte) error {
+	name := path + "." + now().Format(backupTimeFormat) + ".bak"
+	if err := files.WriteFile(name, existing); err != nil {
+		return fmt.Errorf("backing up %s: %w", filepath.Base(path), err)
+	}
+	found, err := Backups(path)
+	if err != nil {
+		return err
+	}
+	for len(found) > backups {
+		if err := files.Remove(found[0]); err != nil {
+			return fmt.Errorf("removing old backup: %w", err)
+		}
+		found = found[1:]
+	}
+	return nil
+}
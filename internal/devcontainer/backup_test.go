@@ -0,0 +1,101 @@
+package devcontainer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWriteConfigBackups(t *testing.T) {
+	SetBackups(2)
+	clock := time.Date(2026, 10, 14, 9, 12, 3, 0, time.UTC)
+	now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	t.Cleanup(func() {
+		SetBackups(0)
+		now = time.Now
+	})
+
+	path := DefaultConfigPath(t.TempDir())
+	for _, name := range []string{"one", "two", "two", "three", "four"} {
+		if err := WriteConfig(path, map[string]any{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first write had nothing to back up and the repeated one changed
+	// nothing; of the rest, the newest two are kept.
+	found, err := Backups(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		path + ".20261014-091205.000.bak",
+		path + ".20261014-091206.000.bak",
+	}
+	if len(found) != len(want) || found[0] != want[0] || found[1] != want[1] {
+		t.Fatalf("Backups = %q, want %q", found, want)
+	}
+	for i, name := range []string{"two", "three"} {
+		data, err := os.ReadFile(found[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := "{\n  \"name\": \"" + name + "\"\n}\n"; string(data) != want {
+			t.Errorf("backup %d = %q, want %q", i, data, want)
+		}
+	}
+
+	// Other files next to the config are left alone.
+	other := filepath.Join(filepath.Dir(path), "devcontainer.json.old.bak")
+	if err := os.WriteFile(other, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := Backups(path); len(found) != 2 {
+		t.Errorf("Backups = %q, want only the timestamped backups", found)
+	}
+}
+
+func TestWriteConfigWithoutBackups(t *testing.T) {
+	path := DefaultConfigPath(t.TempDir())
+	for _, name := range []string{"one", "two"} {
+		if err := WriteConfig(path, map[string]any{"name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if found, _ := Backups(path); len(found) != 0 {
+		t.Errorf("backups written while off: %q", found)
+	}
+}
+
+func TestBackupConfig(t *testing.T) {
+	path := DefaultConfigPath(t.TempDir())
+	if err := WriteConfig(path, map[string]any{"name": "mine"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := BackupConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := Backups(path); len(found) != 0 {
+		t.Errorf("BackupConfig backed up with backups off: %q", found)
+	}
+
+	SetBackups(1)
+	t.Cleanup(func() { SetBackups(0) })
+	if err := BackupConfig(path); err != nil {
+		t.Fatal(err)
+	}
+	found, _ := Backups(path)
+	if len(found) != 1 {
+		t.Fatalf("Backups = %q, want one", found)
+	}
+	if data, _ := os.ReadFile(found[0]); string(data) != "{\n  \"name\": \"mine\"\n}\n" {
+		t.Errorf("backup = %q", data)
+	}
+	if err := BackupConfig(DefaultConfigPath(t.TempDir())); err != nil {
+		t.Errorf("BackupConfig of a missing config = %v", err)
+	}
+}
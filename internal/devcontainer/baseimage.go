@@ -0,0 +1,68 @@
+package devcontainer
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// BaseImage returns the image the container of config starts from: its
+// image, or the base of the final stage of its Dockerfile, which is found
+// relative to configPath. It returns "" when that isn't known before the
+// build: for Compose configs, for a base given by a build argument and for
+// a FROM with an explicit --platform.
+func BaseImage(config map[string]any, configPath string) (string, error) {
+	if image, ok := config["image"].(string); ok && image != "" {
+		return image, nil
+	}
+
+	build, _ := config["build"].(map[string]any)
+	dockerfile, _ := build["dockerfile"].(string)
+	if dockerfile == "" {
+		dockerfile, _ = config["dockerFile"].(string) // legacy top-level form
+	}
+	if dockerfile == "" {
+		return "", nil
+	}
+
+	path := filepath.Join(filepath.Dir(configPath), dockerfile)
+	data, err := ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading Dockerfile: %w", err)
+	}
+	return dockerfileBase(string(data)), nil
+}
+
+// dockerfileBase returns the image the final stage of a Dockerfile starts
+// from, following FROM lines that name an earlier stage.
+func dockerfileBase(dockerfile string) string {
+	stages := make(map[string]string) // stage name -> its base
+	base := ""
+	for _, line := range strings.Split(strings.ReplaceAll(dockerfile, "\\\n", " "), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "FROM") {
+			continue
+		}
+		fields = fields[1:]
+		platform := false
+		for len(fields) > 0 && strings.HasPrefix(fields[0], "--") {
+			platform = platform || strings.HasPrefix(fields[0], "--platform")
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			continue
+		}
+
+		base = fields[0]
+		if from, ok := stages[strings.ToLower(base)]; ok {
+			base = from
+		}
+		if platform || strings.Contains(base, "$") || base == "scratch" {
+			base = ""
+		}
+		if len(fields) >= 3 && strings.EqualFold(fields[1], "AS") {
+			stages[strings.ToLower(fields[2])] = base
+		}
+	}
+	return base
+}
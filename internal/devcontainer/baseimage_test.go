@@ -0,0 +1,51 @@
+package devcontainer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBaseImage(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, ".devcontainer", "devcontainer.json")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	dockerfile := "ARG VARIANT=20\n" +
+		"FROM node:${VARIANT} AS tools\n" +
+		"FROM --platform=linux/amd64 golang:1.25 AS cross\n" +
+		"from mcr.microsoft.com/devcontainers/base:ubuntu \\\n  as base\n" +
+		"RUN apt-get update\n" +
+		"FROM base\n" +
+		"COPY --from=tools /usr/local/bin/node /usr/local/bin/\n"
+	if err := os.WriteFile(filepath.Join(dir, ".devcontainer", "Dockerfile"), []byte(dockerfile), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   string
+	}{
+		{"image", map[string]any{"image": "ubuntu:22.04"}, "ubuntu:22.04"},
+		{"dockerfile stage", map[string]any{"build": map[string]any{"dockerfile": "Dockerfile"}}, "mcr.microsoft.com/devcontainers/base:ubuntu"},
+		{"legacy dockerFile", map[string]any{"dockerFile": "Dockerfile"}, "mcr.microsoft.com/devcontainers/base:ubuntu"},
+		{"compose", map[string]any{"dockerComposeFile": "compose.yml", "service": "app"}, ""},
+	}
+	for _, tt := range tests {
+		got, err := BaseImage(tt.config, configPath)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: BaseImage = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := BaseImage(map[string]any{"build": map[string]any{"dockerfile": "missing"}}, configPath); err == nil {
+		t.Error("a missing Dockerfile should be reported")
+	}
+	for _, d := range []string{"FROM node:${VARIANT}\n", "FROM --platform=linux/amd64 ubuntu\n", "FROM scratch\n"} {
+		if got := dockerfileBase(d); got != "" {
+			t.Errorf("dockerfileBase(%q) = %q, want unknown", d, got)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package devcontainer
+
+// Customization returns customizations.<ide> of config, such as the
+// "vscode" or "codespaces" namespace, or nil if it isn't set.
+func Customization(config map[string]any, ide string) map[string]any {
+	custom, _ := config["customizations"].(map[string]any)
+	namespace, _ := custom[ide].(map[string]any)
+	return namespace
+}
+
+// SetCustomization sets customizations.<ide>.<key> to value. A nil value
+// deletes the key, and the namespace and customizations are removed when
+// that leaves them empty.
+func SetCustomization(config map[string]any, ide, key string, value any) {
+	custom, _ := config["customizations"].(map[string]any)
+	namespace, _ := custom[ide].(map[string]any)
+	if value == nil {
+		if namespace == nil {
+			return
+		}
+		delete(namespace, key)
+		if len(namespace) == 0 {
+			delete(custom, ide)
+		}
+		if len(custom) == 0 {
+			delete(config, "customizations")
+		}
+		return
+	}
+
+	if custom == nil {
+		custom = make(map[string]any)
+		config["customizations"] = custom
+	}
+	if namespace == nil {
+		namespace = make(map[string]any)
+		custom[ide] = namespace
+	}
+	namespace[key] = value
+}
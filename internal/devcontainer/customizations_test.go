@@ -0,0 +1,45 @@
+package devcontainer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSetCustomization(t *testing.T) {
+	config := map[string]any{"name": "x"}
+
+	// Deleting from a missing namespace creates nothing.
+	SetCustomization(config, "codespaces", "openFiles", nil)
+	if _, ok := config["customizations"]; ok {
+		t.Fatal("deleting a missing key created customizations")
+	}
+
+	SetCustomization(config, "codespaces", "openFiles", []any{"README.md"})
+	SetCustomization(config, "vscode", "extensions", []any{"golang.go"})
+	want := map[string]any{
+		"name": "x",
+		"customizations": map[string]any{
+			"codespaces": map[string]any{"openFiles": []any{"README.md"}},
+			"vscode":     map[string]any{"extensions": []any{"golang.go"}},
+		},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Fatalf("config = %v, want %v", config, want)
+	}
+	if got := Customization(config, "codespaces"); !reflect.DeepEqual(got, map[string]any{"openFiles": []any{"README.md"}}) {
+		t.Errorf("Customization(codespaces) = %v", got)
+	}
+	if got := Customization(config, "jetbrains"); got != nil {
+		t.Errorf("Customization(jetbrains) = %v, want nil", got)
+	}
+
+	// Emptied namespaces and customizations are removed.
+	SetCustomization(config, "codespaces", "openFiles", nil)
+	if _, ok := config["customizations"].(map[string]any)["codespaces"]; ok {
+		t.Error("empty codespaces namespace kept")
+	}
+	SetCustomization(config, "vscode", "extensions", nil)
+	if !reflect.DeepEqual(config, map[string]any{"name": "x"}) {
+		t.Errorf("config = %v, want customizations removed", config)
+	}
+}
@@ -0,0 +1,34 @@
+package devcontainer
+
+import "fmt"
+
+// Format is the layout WriteConfig writes JSON in.
+type Format struct {
+	Indent        string // one indentation level, e.g. "  " or "\t"
+	CompactArrays bool   // arrays of primitives on a single line
+}
+
+// DefaultFormat indents by two spaces and keeps primitive arrays on one line.
+var DefaultFormat = Format{Indent: "  ", CompactArrays: true}
+
+// format is the layout configs are written in, see SetFormat.
+var format = DefaultFormat
+
+// SetFormat sets the layout of the configs written from now on.
+func SetFormat(f Format) {
+	format = f
+}
+
+// ParseIndent returns the indentation an --indent value names: "tab", "2"
+// or "4".
+func ParseIndent(name string) (string, error) {
+	switch name {
+	case "tab":
+		return "\t", nil
+	case "2":
+		return "  ", nil
+	case "4":
+		return "    ", nil
+	}
+	return "", fmt.Errorf("invalid indent %q: use tab, 2 or 4", name)
+}
@@ -0,0 +1,116 @@
+package devcontainer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// formatConfig nests objects, a primitive array and an array of objects.
+var formatConfig = map[string]any{
+	"customizations": map[string]any{
+		"vscode": map[string]any{
+			"extensions": []any{"golang.go", "ms-python.python"},
+		},
+	},
+	"mounts": []any{map[string]any{"source": "cache", "target": "/cache"}},
+}
+
+func TestMarshalOrderedIndent(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{"tab", Format{Indent: "\t", CompactArrays: true}, `{
+	"customizations": {
+		"vscode": {
+			"extensions": ["golang.go", "ms-python.python"]
+		}
+	},
+	"mounts": [
+		{
+			"source": "cache",
+			"target": "/cache"
+		}
+	]
+}
+`},
+		{"four spaces", Format{Indent: "    ", CompactArrays: true}, `{
+    "customizations": {
+        "vscode": {
+            "extensions": ["golang.go", "ms-python.python"]
+        }
+    },
+    "mounts": [
+        {
+            "source": "cache",
+            "target": "/cache"
+        }
+    ]
+}
+`},
+		{"expanded arrays", Format{Indent: "  "}, `{
+  "customizations": {
+    "vscode": {
+      "extensions": [
+        "golang.go",
+        "ms-python.python"
+      ]
+    }
+  },
+  "mounts": [
+    {
+      "source": "cache",
+      "target": "/cache"
+    }
+  ]
+}
+`},
+	}
+	for _, tt := range tests {
+		if got := string(marshalOrdered(formatConfig, nil, tt.format)); got != tt.want {
+			t.Errorf("%s:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMarshalOrderedIndentKeepsComments(t *testing.T) {
+	original := "{\n  // The project name\n  \"name\": \"test\", // shown in the title\n  \"runArgs\": [\"--init\"]\n}\n"
+	config := map[string]any{"name": "test", "runArgs": []any{"--init"}}
+
+	got := string(marshalOrdered(config, extractKeyOrder([]byte(original)), Format{Indent: "\t"}))
+
+	want := "{\n\t// The project name\n\t\"name\": \"test\", // shown in the title\n\t\"runArgs\": [\n\t\t\"--init\"\n\t]\n}\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestWriteConfigUsesFormat(t *testing.T) {
+	SetFormat(Format{Indent: "\t", CompactArrays: true})
+	t.Cleanup(func() { SetFormat(DefaultFormat) })
+
+	path := filepath.Join(t.TempDir(), "devcontainer.json")
+	if err := WriteConfig(path, map[string]any{"name": "test"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(data); !strings.Contains(got, "\n\t\"name\"") {
+		t.Errorf("written without tab indent:\n%s", got)
+	}
+}
+
+func TestParseIndent(t *testing.T) {
+	for name, want := range map[string]string{"tab": "\t", "2": "  ", "4": "    "} {
+		if got, err := ParseIndent(name); err != nil || got != want {
+			t.Errorf("ParseIndent(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseIndent("3"); err == nil {
+		t.Error("ParseIndent(3) succeeded")
+	}
+}
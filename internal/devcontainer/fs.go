@@ -0,0 +1,82 @@
+package devcontainer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileSystem is where workspace configs are read and written: the local disk,
+// or a remote host (SSHFileSystem).
+type FileSystem interface {
+	ReadFile(path string) ([]byte, error)
+	// WriteFile creates the parent directory as needed.
+	WriteFile(path string, data []byte) error
+	IsFile(path string) bool
+	Glob(pattern string) ([]string, error)
+	// Rename moves a file, creating the new parent directory as needed.
+	Rename(from, to string) error
+	Remove(path string) error
+	Chmod(path string, mode os.FileMode) error
+}
+
+// files is the FileSystem behind ReadFile, WriteFile, ConfigPath and Exists.
+var files FileSystem = localFS{}
+
+// UseFileSystem makes config reads and writes go to fsys.
+func UseFileSystem(fsys FileSystem) {
+	files = fsys
+}
+
+type localFS struct{}
+
+func (localFS) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (localFS) WriteFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func (localFS) IsFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
+
+func (localFS) Glob(pattern string) ([]string, error) {
+	return filepath.Glob(pattern)
+}
+
+func (localFS) Rename(from, to string) error {
+	if err := os.MkdirAll(filepath.Dir(to), 0o755); err != nil {
+		return fmt.Errorf("creating directory: %w", err)
+	}
+	return os.Rename(from, to)
+}
+
+func (localFS) Remove(path string) error {
+	return os.Remove(path)
+}
+
+func (localFS) Chmod(path string, mode os.FileMode) error {
+	return os.Chmod(path, mode)
+}
+
+// MoveFile moves the config file at from to to, creating the directory of
+// to, and refuses to overwrite an existing file. Configs kept in memory
+// (UseMemory) are not moved.
+func MoveFile(from, to string) error {
+	if files.IsFile(to) {
+		return fmt.Errorf("%s already exists", to)
+	}
+	if err := files.Rename(from, to); err != nil {
+		return fmt.Errorf("moving %s: %w", filepath.Base(from), err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package devcontainer
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	from, to := DefaultConfigPath(dir), NamedConfigPath(dir, "backend")
+	if err := WriteFile(from, []byte(`{"name": "api"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := MoveFile(from, to); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(to); err != nil || string(data) != `{"name": "api"}` {
+		t.Errorf("moved file = %q, %v", data, err)
+	}
+	if _, err := os.Stat(from); !os.IsNotExist(err) {
+		t.Errorf("source still exists: %v", err)
+	}
+	if got := ConfigPath(dir); got != to {
+		t.Errorf("ConfigPath after the move = %s, want %s", got, to)
+	}
+
+	// An existing target is never overwritten.
+	if err := WriteFile(from, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	if err := MoveFile(from, to); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Errorf("MoveFile onto an existing file = %v", err)
+	}
+	if data, _ := os.ReadFile(to); string(data) != `{"name": "api"}` {
+		t.Errorf("target was overwritten: %q", data)
+	}
+}
@@ -1,60 +1,156 @@
 package devcontainer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
 	"path/filepath"
+	"sort"
 
 	"github.com/tidwall/jsonc"
 )
 
+// configPathOverride is an explicit config file set via SetConfigPath.
+// When non-empty it bypasses the standard location lookup.
+var configPathOverride string
+
+// SetConfigPath makes ConfigPath return the given file for every workspace.
+// An empty path restores the standard lookup.
+func SetConfigPath(path string) {
+	configPathOverride = path
+}
+
+// DefaultConfigPath returns .devcontainer/devcontainer.json in the workspace
+// folder. This is where new configs are created and where the devcontainer CLI
+// writes applied templates.
+func DefaultConfigPath(workspaceFolder string) string {
+	return filepath.Join(workspaceFolder, ".devcontainer", "devcontainer.json")
+}
+
+// NamedConfigPath returns .devcontainer/<name>/devcontainer.json in the
+// workspace folder, or DefaultConfigPath if name is empty.
+func NamedConfigPath(workspaceFolder, name string) string {
+	if name == "" {
+		return DefaultConfigPath(workspaceFolder)
+	}
+	return filepath.Join(workspaceFolder, ".devcontainer", name, "devcontainer.json")
+}
+
+// ConfigPath resolves the devcontainer.json for a workspace folder. It probes
+// the locations allowed by the spec in priority order:
+//
+//  1. .devcontainer/devcontainer.json
+//  2. .devcontainer.json
+//  3. .devcontainer/<folder>/devcontainer.json (first folder alphabetically)
+//
+// If none exists, DefaultConfigPath is returned.
+func ConfigPath(workspaceFolder string) string {
+	if configPathOverride != "" {
+		return configPathOverride
+	}
+
+	candidates := []string{
+		DefaultConfigPath(workspaceFolder),
+		filepath.Join(workspaceFolder, ".devcontainer.json"),
+	}
+	named, _ := files.Glob(filepath.Join(workspaceFolder, ".devcontainer", "*", "devcontainer.json"))
+	sort.Strings(named)
+	candidates = append(candidates, named...)
+
+	for _, path := range candidates {
+		if files.IsFile(path) {
+			return path
+		}
+	}
+	return DefaultConfigPath(workspaceFolder)
+}
+
+// utf8BOM is the byte order mark some editors write at the start of a file.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// ToJSON converts JSONC to JSON for json.Unmarshal: it drops a leading UTF-8
+// BOM and blanks out comments and trailing commas in objects and arrays.
+// Apart from the BOM, byte offsets stay intact.
+func ToJSON(data []byte) []byte {
+	return jsonc.ToJSON(bytes.TrimPrefix(data, utf8BOM))
+}
+
 // ReadConfig reads and parses the devcontainer.json from a workspace folder.
-// Supports JSONC (JSON with comments).
+// The file is located via ConfigPath. Supports JSONC (JSON with comments and
+// trailing commas), with or without a UTF-8 BOM.
 func ReadConfig(workspaceFolder string) (map[string]any, string, error) {
-	configPath := filepath.Join(workspaceFolder, ".devcontainer", "devcontainer.json")
+	configPath := ConfigPath(workspaceFolder)
 
-	data, err := os.ReadFile(configPath)
+	data, err := ReadFile(configPath)
 	if err != nil {
 		return nil, configPath, fmt.Errorf("reading devcontainer.json: %w", err)
 	}
 
-	// Strip JSONC comments
-	cleanJSON := jsonc.ToJSON(data)
-
 	var config map[string]any
-	if err := json.Unmarshal(cleanJSON, &config); err != nil {
+	if err := json.Unmarshal(ToJSON(data), &config); err != nil {
 		return nil, configPath, fmt.Errorf("parsing devcontainer.json: %w", err)
 	}
 
 	return config, configPath, nil
 }
 
-// WriteConfig writes a config map to the given path as formatted JSON with 2-space indent.
-// If the file already exists, the key ordering from the existing file is preserved.
-// New keys are appended in alphabetical order.
+// WriteConfig writes a config map to the given path as formatted JSON, laid
+// out as set by SetFormat (2-space indent by default).
+// If the file already exists, the key ordering and the JSONC comments attached
+// to keys are preserved. New keys are appended in alphabetical order.
+// Under SetBackups, the contents it replaces are backed up first.
 func WriteConfig(path string, config map[string]any) error {
-	// Read existing file to preserve key ordering.
-	var order *keyOrder
-	if existing, err := os.ReadFile(path); err == nil {
-		order = extractKeyOrder(jsonc.ToJSON(existing))
-	}
+	return WriteConfigKeeping(path, config, nil)
+}
 
-	data := marshalOrdered(config, order)
+// WriteConfigKeeping writes config like WriteConfig, laying out the keys the
+// existing file lacks as they were in previous, the contents the file had
+// before something else rewrote it. This keeps the order and comments of
+// keys carried over from previous, which would otherwise be written as new.
+func WriteConfigKeeping(path string, config map[string]any, previous []byte) error {
+	// Read existing file to preserve key ordering and comments.
+	existing, _ := ReadFile(path)
 
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("creating directory: %w", err)
+	var order *keyOrder
+	if len(existing) > 0 {
+		order = extractKeyOrder(existing)
+	}
+	if len(previous) > 0 {
+		order = order.withFallback(extractKeyOrder(previous))
 	}
+	buf := bytes.NewBuffer(marshalOrdered(config, order, format))
+	if backups > 0 && memoryFiles == nil && len(existing) > 0 && !bytes.Equal(existing, buf.Bytes()) {
+		if err := backupConfig(path, existing); err != nil {
+			return err
+		}
+	}
+	return WriteFile(path, buf.Bytes())
+}
 
-	if err := os.WriteFile(path, data, 0o644); err != nil {
+// WriteConfigTo writes a config map to w as formatted JSON, laid out like
+// WriteConfig.
+// baseline is the previous file contents, if any; its key ordering and JSONC
+// comments are preserved as in WriteConfig.
+func WriteConfigTo(w io.Writer, config map[string]any, baseline []byte) error {
+	if _, err := w.Write(MarshalConfig(config, baseline, format)); err != nil {
 		return fmt.Errorf("writing devcontainer.json: %w", err)
 	}
-
 	return nil
 }
 
-// Exists checks if a .devcontainer directory exists in the workspace folder.
+// MarshalConfig returns a config map as JSON laid out in f rather than the
+// format set by SetFormat, keeping the key order and comments of baseline
+// like WriteConfigTo.
+func MarshalConfig(config map[string]any, baseline []byte, f Format) []byte {
+	var order *keyOrder
+	if len(baseline) > 0 {
+		order = extractKeyOrder(baseline)
+	}
+	return marshalOrdered(config, order, f)
+}
+
+// Exists checks if a devcontainer.json can be found for the workspace folder.
 func Exists(workspaceFolder string) bool {
-	_, err := os.Stat(filepath.Join(workspaceFolder, ".devcontainer"))
-	return err == nil
+	return FileExists(ConfigPath(workspaceFolder))
 }
@@ -0,0 +1,235 @@
+package devcontainer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestConfigPathPriority(t *testing.T) {
+	dir := t.TempDir()
+
+	// No config at all → default location.
+	if got, want := ConfigPath(dir), DefaultConfigPath(dir); got != want {
+		t.Errorf("empty workspace: got %q, want %q", got, want)
+	}
+	if Exists(dir) {
+		t.Error("Exists should be false without any config")
+	}
+
+	// Named configs are the lowest priority; first folder alphabetically wins.
+	frontend := filepath.Join(dir, ".devcontainer", "frontend", "devcontainer.json")
+	backend := filepath.Join(dir, ".devcontainer", "backend", "devcontainer.json")
+	mustWrite(t, frontend)
+	mustWrite(t, backend)
+	if got := ConfigPath(dir); got != backend {
+		t.Errorf("named configs: got %q, want %q", got, backend)
+	}
+	if !Exists(dir) {
+		t.Error("Exists should be true with a named config")
+	}
+
+	// Root-level .devcontainer.json beats named configs.
+	rootLevel := filepath.Join(dir, ".devcontainer.json")
+	mustWrite(t, rootLevel)
+	if got := ConfigPath(dir); got != rootLevel {
+		t.Errorf("root-level config: got %q, want %q", got, rootLevel)
+	}
+
+	// .devcontainer/devcontainer.json beats everything.
+	mustWrite(t, DefaultConfigPath(dir))
+	if got, want := ConfigPath(dir), DefaultConfigPath(dir); got != want {
+		t.Errorf("default config: got %q, want %q", got, want)
+	}
+}
+
+func TestConfigPathOverride(t *testing.T) {
+	dir := t.TempDir()
+	mustWrite(t, DefaultConfigPath(dir))
+
+	explicit := filepath.Join(dir, "custom.json")
+	SetConfigPath(explicit)
+	defer SetConfigPath("")
+
+	if got := ConfigPath(dir); got != explicit {
+		t.Errorf("got %q, want %q", got, explicit)
+	}
+	if Exists(dir) {
+		t.Error("Exists should report the explicit file, which is missing")
+	}
+}
+
+func TestReadConfigNamedLayout(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".devcontainer", "backend", "devcontainer.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileForTest(path, []byte(`{"name": "backend"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	config, configPath, err := ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if configPath != path {
+		t.Errorf("configPath = %q, want %q", configPath, path)
+	}
+	if config["name"] != "backend" {
+		t.Errorf("name = %v, want backend", config["name"])
+	}
+}
+
+func mustWrite(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileForTest(path, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadConfigJSONCFixtures(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    map[string]any
+	}{
+		{"bom.json", map[string]any{
+			"name":  "bom",
+			"image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+		}},
+		{"trailing-commas.jsonc", map[string]any{
+			"name": "trailing-commas",
+			"features": map[string]any{
+				"ghcr.io/devcontainers/features/node:1": map[string]any{},
+				"ghcr.io/devcontainers/features/go:1":   map[string]any{},
+			},
+			"forwardPorts": []any{3000.0, 8080.0},
+		}},
+		{"bom-comments-trailing-commas.jsonc", map[string]any{
+			"name":  "combined",
+			"image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+			"features": map[string]any{
+				"ghcr.io/devcontainers/features/node:1": map[string]any{"version": "lts"},
+			},
+			"forwardPorts": []any{3000.0, 8080.0},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			dir := t.TempDir()
+			path := DefaultConfigPath(dir)
+			data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := writeFileForTest(path, data); err != nil {
+				t.Fatal(err)
+			}
+
+			config, _, err := ReadConfig(dir)
+			if err != nil {
+				t.Fatalf("ReadConfig: %v", err)
+			}
+			if !reflect.DeepEqual(config, tt.want) {
+				t.Errorf("config = %v, want %v", config, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteConfigOverBOM(t *testing.T) {
+	dir := t.TempDir()
+	path := DefaultConfigPath(dir)
+	data, err := os.ReadFile("testdata/bom-comments-trailing-commas.jsonc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileForTest(path, data); err != nil {
+		t.Fatal(err)
+	}
+
+	config, _, err := ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config["remoteUser"] = "vscode"
+	if err := WriteConfig(path, config); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.HasPrefix(written, utf8BOM) {
+		t.Error("written config starts with a BOM")
+	}
+	// Comments survive, including the header comment before the BOM-prefixed
+	// root object.
+	for _, comment := range []string{"// Saved by an editor that writes a BOM.", "// display name", "// Node for the frontend"} {
+		if !bytes.Contains(written, []byte(comment)) {
+			t.Errorf("comment %q lost:\n%s", comment, written)
+		}
+	}
+	if _, _, err := ReadConfig(dir); err != nil {
+		t.Errorf("re-reading written config: %v", err)
+	}
+}
+
+func TestWriteConfigKeeping(t *testing.T) {
+	path := DefaultConfigPath(t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileForTest(path, []byte("{\n  \"name\": \"Go\",\n  \"image\": \"go\"\n}\n")); err != nil {
+		t.Fatal(err)
+	}
+	previous := []byte(`{
+  "image": "ubuntu",
+  // pinned by the platform team
+  "x-acme": {"tier": 2, "owner": "platform"},
+  "overrideCommand": false
+}
+`)
+	config := map[string]any{
+		"name":            "Go",
+		"image":           "go",
+		"x-acme":          map[string]any{"tier": 2, "owner": "platform"},
+		"overrideCommand": false,
+		"remoteUser":      "vscode",
+	}
+	if err := WriteConfigKeeping(path, config, previous); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "name": "Go",
+  "image": "go",
+  // pinned by the platform team
+  "x-acme": {
+    "tier": 2,
+    "owner": "platform"
+  },
+  "overrideCommand": false,
+  "remoteUser": "vscode"
+}
+`
+	if string(written) != want {
+		t.Errorf("written =\n%s\nwant\n%s", written, want)
+	}
+}
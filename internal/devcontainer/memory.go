@@ -0,0 +1,51 @@
+package devcontainer
+
+import "os"
+
+// memoryFiles holds the configs written while in-memory mode is on, keyed by
+// path. nil means configs are written to the FileSystem.
+var memoryFiles map[string][]byte
+
+// UseMemory makes WriteConfig and WriteFile keep configs in memory instead of
+// touching disk. ReadConfig, ReadFile and Exists see the in-memory contents
+// first, so multi-step flows compose as usual.
+func UseMemory() {
+	memoryFiles = make(map[string][]byte)
+}
+
+// ReadFile returns the raw contents of a config file, preferring the
+// in-memory copy written under UseMemory.
+func ReadFile(path string) ([]byte, error) {
+	if data, ok := memoryFiles[path]; ok {
+		return data, nil
+	}
+	return files.ReadFile(path)
+}
+
+// WriteFile stores raw config contents at path, creating its directory, or
+// keeps them in memory under UseMemory.
+func WriteFile(path string, data []byte) error {
+	if memoryFiles != nil {
+		memoryFiles[path] = data
+		return nil
+	}
+	return files.WriteFile(path, data)
+}
+
+// Chmod sets the mode of a file written with WriteFile. Under UseMemory,
+// where only configs are kept, it does nothing.
+func Chmod(path string, mode os.FileMode) error {
+	if memoryFiles != nil {
+		return nil
+	}
+	return files.Chmod(path, mode)
+}
+
+// FileExists reports whether path is a file, in memory under UseMemory or
+// on the FileSystem.
+func FileExists(path string) bool {
+	if _, ok := memoryFiles[path]; ok {
+		return true
+	}
+	return files.IsFile(path)
+}
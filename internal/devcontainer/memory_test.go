@@ -0,0 +1,50 @@
+package devcontainer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUseMemory(t *testing.T) {
+	UseMemory()
+	t.Cleanup(func() { memoryFiles = nil })
+
+	dir := t.TempDir()
+	path := DefaultConfigPath(dir)
+	if err := WriteConfig(path, map[string]any{"name": "mem"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("WriteConfig touched disk under UseMemory (stat err %v)", err)
+	}
+	if !Exists(dir) {
+		t.Error("Exists = false for an in-memory config")
+	}
+
+	config, _, err := ReadConfig(dir)
+	if err != nil || config["name"] != "mem" {
+		t.Fatalf("ReadConfig = %v, %v", config, err)
+	}
+	config["image"] = "ubuntu"
+	if err := WriteConfig(path, config); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ReadFile(path)
+	if want := "{\n  \"name\": \"mem\",\n  \"image\": \"ubuntu\"\n}\n"; string(data) != want {
+		t.Errorf("in-memory config = %q, want %q (existing order kept)", data, want)
+	}
+}
+
+func TestWriteConfigToBaseline(t *testing.T) {
+	baseline := []byte("{\n  // the image\n  \"image\": \"ubuntu\",\n  \"name\": \"x\"\n}\n")
+	var buf bytes.Buffer
+	if err := WriteConfigTo(&buf, map[string]any{"name": "x", "image": "debian"}, baseline); err != nil {
+		t.Fatal(err)
+	}
+	want := "{\n  // the image\n  \"image\": \"debian\",\n  \"name\": \"x\"\n}\n"
+	if buf.String() != want {
+		t.Errorf("WriteConfigTo() = %q, want %q", buf.String(), want)
+	}
+}
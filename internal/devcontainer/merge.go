@@ -0,0 +1,89 @@
+package devcontainer
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Merge returns base with overlay merged in; neither map is modified.
+//
+//   - objects merge key by key, recursively
+//   - arrays are unioned: base entries keep their order, overlay entries not
+//     already present are appended
+//   - anything else, and values of different types, take the overlay value
+//
+// Features are matched without their version tag, so an overlay
+// "node:2" replaces a configured "node:1" together with its options; the
+// same ref merges its options like any object.
+func Merge(base, overlay map[string]any) map[string]any {
+	merged := mergeObject(base, overlay)
+	if features, ok := overlay["features"].(map[string]any); ok {
+		if configured, ok := merged["features"].(map[string]any); ok {
+			for ref := range features {
+				for existing := range configured {
+					if existing != ref && FeatureID(existing) == FeatureID(ref) {
+						delete(configured, existing)
+					}
+				}
+			}
+		}
+	}
+	return merged
+}
+
+func mergeObject(base, overlay map[string]any) map[string]any {
+	merged := make(map[string]any, len(base)+len(overlay))
+	for k, v := range base {
+		merged[k] = mergeValue(nil, v)
+	}
+	for k, v := range overlay {
+		merged[k] = mergeValue(merged[k], v)
+	}
+	return merged
+}
+
+// mergeValue merges overlay into base; a nil base copies overlay.
+func mergeValue(base, overlay any) any {
+	switch o := overlay.(type) {
+	case map[string]any:
+		b, _ := base.(map[string]any)
+		return mergeObject(b, o)
+	case []any:
+		b, ok := base.([]any)
+		if !ok {
+			b = nil
+		}
+		merged := make([]any, 0, len(b)+len(o))
+		for _, v := range b {
+			merged = append(merged, mergeValue(nil, v))
+		}
+		for _, v := range o {
+			if !containsValue(merged, v) {
+				merged = append(merged, mergeValue(nil, v))
+			}
+		}
+		return merged
+	}
+	return overlay
+}
+
+func containsValue(list []any, v any) bool {
+	for _, item := range list {
+		if reflect.DeepEqual(item, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureID drops the version tag or digest from a feature reference, keeping
+// a registry port such as localhost:5000.
+func FeatureID(ref string) string {
+	if i := strings.Index(ref, "@"); i != -1 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
@@ -0,0 +1,179 @@
+package devcontainer
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Conflict is a value that both sides of Merge3 changed, differently.
+type Conflict struct {
+	Path   []string // keys from the top level down
+	Mine   any      // mine's value, nil if mine removed it
+	Theirs any      // theirs' value, nil if theirs removed it
+}
+
+// Merge3 merges the changes mine and theirs made to base, their common
+// ancestor, without modifying any of them. A change only one side made wins;
+// where both sides changed a value differently the result keeps mine's and
+// the value is returned as a Conflict, see SetTheirs. A nil base means none
+// is known: values only one side has are kept, and any value the two sides
+// have differently conflicts.
+//
+//   - objects merge key by key, recursively
+//   - arrays merge as sets: entries either side added are kept, entries
+//     either side removed from base are dropped, mine's order first
+//   - features are matched without their version tag; mine's version
+//     stays unless mine kept base's and theirs changed it
+func Merge3(base, mine, theirs map[string]any) (map[string]any, []Conflict) {
+	mine, theirs, base = alignFeatures(mine, theirs, base)
+	var conflicts []Conflict
+	merged, _ := merge3Value(nil, base, base != nil, mine, true, theirs, true, &conflicts)
+	return merged.(map[string]any), conflicts
+}
+
+// merge3Value merges one value present (ok) or absent on each side and
+// reports whether the result is present.
+func merge3Value(path []string, b any, bok bool, m any, mok bool, t any, tok bool, conflicts *[]Conflict) (any, bool) {
+	switch {
+	case same(m, mok, t, tok):
+		return mergeValue(nil, m), mok
+	case same(m, mok, b, bok):
+		return mergeValue(nil, t), tok
+	case same(t, tok, b, bok):
+		return mergeValue(nil, m), mok
+	}
+
+	mm, mIsMap := m.(map[string]any)
+	tm, tIsMap := t.(map[string]any)
+	if mIsMap && tIsMap {
+		bm, _ := b.(map[string]any)
+		return merge3Object(path, bm, mm, tm, conflicts), true
+	}
+	ma, mIsArray := m.([]any)
+	ta, tIsArray := t.([]any)
+	if mIsArray && tIsArray {
+		ba, _ := b.([]any)
+		return merge3Array(ba, ma, ta), true
+	}
+
+	*conflicts = append(*conflicts, Conflict{Path: append([]string(nil), path...), Mine: m, Theirs: t})
+	return mergeValue(nil, m), mok
+}
+
+func merge3Object(path []string, b, m, t map[string]any, conflicts *[]Conflict) map[string]any {
+	keys := make(map[string]bool, len(m)+len(t))
+	for _, obj := range []map[string]any{b, m, t} {
+		for k := range obj {
+			keys[k] = true
+		}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	// Sorted so conflicts are reported in a stable order.
+	sort.Strings(sorted)
+
+	merged := make(map[string]any, len(keys))
+	for _, k := range sorted {
+		bv, bok := b[k]
+		mv, mok := m[k]
+		tv, tok := t[k]
+		if v, ok := merge3Value(append(path, k), bv, bok, mv, mok, tv, tok, conflicts); ok {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+func merge3Array(b, m, t []any) []any {
+	merged := make([]any, 0, len(m)+len(t))
+	for _, v := range m {
+		// Dropped by theirs.
+		if containsValue(b, v) && !containsValue(t, v) {
+			continue
+		}
+		merged = append(merged, mergeValue(nil, v))
+	}
+	for _, v := range t {
+		// Added by theirs.
+		if !containsValue(b, v) && !containsValue(merged, v) {
+			merged = append(merged, mergeValue(nil, v))
+		}
+	}
+	return merged
+}
+
+// same reports whether two values are equal, counting presence.
+func same(a any, aok bool, b any, bok bool) bool {
+	return aok == bok && (!aok || reflect.DeepEqual(a, b))
+}
+
+// alignFeatures renames features that differ only in their version tag to
+// one ref on all sides, so they merge as the same feature: theirs' ref if
+// mine still has base's, else mine's. The maps are copied where renamed.
+func alignFeatures(mine, theirs, base map[string]any) (map[string]any, map[string]any, map[string]any) {
+	mf, _ := mine["features"].(map[string]any)
+	tf, _ := theirs["features"].(map[string]any)
+	bf, _ := base["features"].(map[string]any)
+	if len(mf) == 0 || len(tf) == 0 {
+		return mine, theirs, base
+	}
+
+	renames := make(map[string]string) // from -> to, on any side
+	for mref := range mf {
+		for tref := range tf {
+			if mref == tref || FeatureID(mref) != FeatureID(tref) {
+				continue
+			}
+			if _, ok := bf[mref]; ok {
+				renames[mref] = tref
+			} else {
+				renames[tref] = mref
+			}
+		}
+	}
+	if len(renames) == 0 {
+		return mine, theirs, base
+	}
+	rename := func(config map[string]any) map[string]any {
+		features, ok := config["features"].(map[string]any)
+		if !ok {
+			return config
+		}
+		renamed := make(map[string]any, len(features))
+		for ref, v := range features {
+			if to, ok := renames[ref]; ok {
+				ref = to
+			}
+			renamed[ref] = v
+		}
+		copied := make(map[string]any, len(config))
+		for k, v := range config {
+			copied[k] = v
+		}
+		copied["features"] = renamed
+		return copied
+	}
+	return rename(mine), rename(theirs), rename(base)
+}
+
+// SetTheirs resolves c in config, a result of Merge3, with theirs' value,
+// removing the key if theirs removed it.
+func SetTheirs(config map[string]any, c Conflict) {
+	obj := config
+	for _, k := range c.Path[:len(c.Path)-1] {
+		next, ok := obj[k].(map[string]any)
+		if !ok {
+			next = make(map[string]any)
+			obj[k] = next
+		}
+		obj = next
+	}
+	key := c.Path[len(c.Path)-1]
+	if c.Theirs == nil {
+		delete(obj, key)
+		return
+	}
+	obj[key] = mergeValue(nil, c.Theirs)
+}
@@ -0,0 +1,151 @@
+package devcontainer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := map[string]any{
+		"name":  "app",
+		"image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:1": map[string]any{"version": "18"},
+		},
+		"forwardPorts": []any{3000.0, 5432.0},
+		"remoteUser":   "vscode",
+	}
+	mine := map[string]any{
+		"name":  "my app",
+		"image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:1": map[string]any{"version": "20"},
+			"ghcr.io/devcontainers/features/go:1":   map[string]any{},
+		},
+		"forwardPorts": []any{3000.0, 5432.0, 8080.0},
+		"remoteUser":   "root",
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go"}},
+		},
+	}
+	theirs := map[string]any{
+		"name":  "app",
+		"image": "mcr.microsoft.com/devcontainers/base:noble",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:2": map[string]any{"version": "18"},
+		},
+		"forwardPorts": []any{3000.0},
+		"remoteUser":   "node",
+	}
+
+	got, conflicts := Merge3(base, mine, theirs)
+	want := map[string]any{
+		"name":  "my app",
+		"image": "mcr.microsoft.com/devcontainers/base:noble",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:2": map[string]any{"version": "20"},
+			"ghcr.io/devcontainers/features/go:1":   map[string]any{},
+		},
+		"forwardPorts": []any{3000.0, 8080.0},
+		"remoteUser":   "root",
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge3 =\n%v\nwant\n%v", got, want)
+	}
+	wantConflicts := []Conflict{{Path: []string{"remoteUser"}, Mine: "root", Theirs: "node"}}
+	if !reflect.DeepEqual(conflicts, wantConflicts) {
+		t.Errorf("conflicts = %v, want %v", conflicts, wantConflicts)
+	}
+	if _, ok := mine["features"].(map[string]any)["ghcr.io/devcontainers/features/node:1"]; !ok {
+		t.Error("Merge3 modified mine")
+	}
+}
+
+func TestMerge3Removals(t *testing.T) {
+	base := map[string]any{
+		"postCreateCommand": "npm install",
+		"containerEnv":      map[string]any{"A": "1", "B": "2"},
+		"runArgs":           []any{"--init"},
+	}
+	mine := map[string]any{
+		"containerEnv": map[string]any{"A": "1", "B": "3"},
+		"runArgs":      []any{"--init"},
+	}
+	theirs := map[string]any{
+		"postCreateCommand": "npm ci",
+		"containerEnv":      map[string]any{"A": "1"},
+	}
+
+	got, conflicts := Merge3(base, mine, theirs)
+	want := map[string]any{
+		"containerEnv": map[string]any{"A": "1", "B": "3"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge3 =\n%v\nwant\n%v", got, want)
+	}
+	wantConflicts := []Conflict{
+		{Path: []string{"containerEnv", "B"}, Mine: "3", Theirs: nil},
+		{Path: []string{"postCreateCommand"}, Mine: nil, Theirs: "npm ci"},
+	}
+	if !reflect.DeepEqual(conflicts, wantConflicts) {
+		t.Errorf("conflicts = %v, want %v", conflicts, wantConflicts)
+	}
+
+	for _, c := range conflicts {
+		SetTheirs(got, c)
+	}
+	want = map[string]any{
+		"containerEnv":      map[string]any{"A": "1"},
+		"postCreateCommand": "npm ci",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("after SetTheirs =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestMerge3WithoutBase(t *testing.T) {
+	mine := map[string]any{
+		"image":        "mcr.microsoft.com/devcontainers/go:1",
+		"forwardPorts": []any{8080.0},
+		"remoteUser":   "vscode",
+	}
+	theirs := map[string]any{
+		"image":        "mcr.microsoft.com/devcontainers/base:ubuntu",
+		"forwardPorts": []any{3000.0},
+		"remoteUser":   "vscode",
+		"features":     map[string]any{"ghcr.io/devcontainers/features/node:1": map[string]any{}},
+	}
+
+	got, conflicts := Merge3(nil, mine, theirs)
+	want := map[string]any{
+		"image":        "mcr.microsoft.com/devcontainers/go:1",
+		"forwardPorts": []any{8080.0, 3000.0},
+		"remoteUser":   "vscode",
+		"features":     map[string]any{"ghcr.io/devcontainers/features/node:1": map[string]any{}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge3 =\n%v\nwant\n%v", got, want)
+	}
+	wantConflicts := []Conflict{{
+		Path:   []string{"image"},
+		Mine:   "mcr.microsoft.com/devcontainers/go:1",
+		Theirs: "mcr.microsoft.com/devcontainers/base:ubuntu",
+	}}
+	if !reflect.DeepEqual(conflicts, wantConflicts) {
+		t.Errorf("conflicts = %v, want %v", conflicts, wantConflicts)
+	}
+}
+
+func TestSetTheirsCreatesParents(t *testing.T) {
+	config := map[string]any{}
+	SetTheirs(config, Conflict{Path: []string{"customizations", "vscode", "settings"}, Theirs: map[string]any{"a": true}})
+	want := map[string]any{
+		"customizations": map[string]any{"vscode": map[string]any{"settings": map[string]any{"a": true}}},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("SetTheirs = %v, want %v", config, want)
+	}
+}
@@ -0,0 +1,211 @@
+package devcontainer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := map[string]any{
+		"name":  "app",
+		"image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:1":   map[string]any{"version": "18"},
+			"ghcr.io/devcontainers/features/python:1": map[string]any{"version": "3.11"},
+		},
+		"forwardPorts": []any{3000.0},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go"}},
+		},
+	}
+	overlay := map[string]any{
+		"image": "mcr.microsoft.com/devcontainers/go:1",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:2":   map[string]any{},
+			"ghcr.io/devcontainers/features/python:1": map[string]any{"installTools": true},
+		},
+		"forwardPorts": []any{3000.0, 8080.0},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"ms-python.python", "golang.go"}},
+		},
+	}
+
+	got := Merge(base, overlay)
+	want := map[string]any{
+		"name":  "app",
+		"image": "mcr.microsoft.com/devcontainers/go:1",
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:2":   map[string]any{},
+			"ghcr.io/devcontainers/features/python:1": map[string]any{"version": "3.11", "installTools": true},
+		},
+		"forwardPorts": []any{3000.0, 8080.0},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go", "ms-python.python"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge =\n%v\nwant\n%v", got, want)
+	}
+	if _, ok := base["features"].(map[string]any)["ghcr.io/devcontainers/features/node:1"]; !ok {
+		t.Error("Merge modified base")
+	}
+}
+
+func TestMergeNestedCustomizations(t *testing.T) {
+	base := map[string]any{
+		"customizations": map[string]any{
+			"vscode": map[string]any{
+				"extensions": []any{"golang.go", "eamodio.gitlens"},
+				"settings": map[string]any{
+					"editor.formatOnSave": false,
+					"go.toolsManagement":  map[string]any{"autoUpdate": true, "checkForUpdates": "local"},
+					"files.exclude":       map[string]any{"**/.git": true},
+				},
+			},
+			"dcc": map[string]any{"notes": map[string]any{"ghcr.io/devcontainers/features/node": "frontend"}},
+		},
+	}
+	overlay := map[string]any{
+		"customizations": map[string]any{
+			"vscode": map[string]any{
+				"extensions": []any{"eamodio.gitlens", "esbenp.prettier-vscode"},
+				"settings": map[string]any{
+					"editor.formatOnSave": true,
+					"go.toolsManagement":  map[string]any{"checkForUpdates": "off"},
+				},
+			},
+			"jetbrains": map[string]any{"plugins": []any{"com.github.copilot"}},
+		},
+	}
+
+	got := Merge(base, overlay)
+	want := map[string]any{
+		"customizations": map[string]any{
+			"vscode": map[string]any{
+				"extensions": []any{"golang.go", "eamodio.gitlens", "esbenp.prettier-vscode"},
+				"settings": map[string]any{
+					"editor.formatOnSave": true,
+					"go.toolsManagement":  map[string]any{"autoUpdate": true, "checkForUpdates": "off"},
+					"files.exclude":       map[string]any{"**/.git": true},
+				},
+			},
+			"jetbrains": map[string]any{"plugins": []any{"com.github.copilot"}},
+			"dcc":       map[string]any{"notes": map[string]any{"ghcr.io/devcontainers/features/node": "frontend"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge =\n%v\nwant\n%v", got, want)
+	}
+
+	// The result shares nothing with its inputs.
+	Customization(got, "vscode")["settings"].(map[string]any)["go.toolsManagement"].(map[string]any)["autoUpdate"] = false
+	Customization(got, "jetbrains")["plugins"].([]any)[0] = "changed"
+	if Customization(base, "vscode")["settings"].(map[string]any)["go.toolsManagement"].(map[string]any)["autoUpdate"] != true {
+		t.Error("changing the result changed base")
+	}
+	if Customization(overlay, "jetbrains")["plugins"].([]any)[0] != "com.github.copilot" {
+		t.Error("changing the result changed overlay")
+	}
+}
+
+func TestMergeFeatures(t *testing.T) {
+	base := map[string]any{
+		"features": map[string]any{
+			"ghcr.io/devcontainers/features/node:1":           map[string]any{"version": "18", "nodeGypDependencies": false},
+			"ghcr.io/devcontainers/features/go:1":             map[string]any{"version": "1.22"},
+			"ghcr.io/devcontainers/features/docker-in-docker": map[string]any{},
+			"localhost:5000/features/tool:1":                  map[string]any{},
+		},
+	}
+	overlay := map[string]any{
+		"features": map[string]any{
+			// Another version replaces the configured one and its options.
+			"ghcr.io/devcontainers/features/node:2": map[string]any{"version": "lts"},
+			// The same ref merges options.
+			"ghcr.io/devcontainers/features/go:1": map[string]any{"golangciLintVersion": "latest"},
+			// A pinned version replaces an unversioned ref.
+			"ghcr.io/devcontainers/features/docker-in-docker:2": map[string]any{"moby": false},
+			// New features are added.
+			"ghcr.io/devcontainers/features/python:1": map[string]any{},
+		},
+	}
+
+	got := Merge(base, overlay)
+	want := map[string]any{
+		"ghcr.io/devcontainers/features/node:2":             map[string]any{"version": "lts"},
+		"ghcr.io/devcontainers/features/go:1":               map[string]any{"version": "1.22", "golangciLintVersion": "latest"},
+		"ghcr.io/devcontainers/features/docker-in-docker:2": map[string]any{"moby": false},
+		"ghcr.io/devcontainers/features/python:1":           map[string]any{},
+		"localhost:5000/features/tool:1":                    map[string]any{},
+	}
+	if !reflect.DeepEqual(got["features"], want) {
+		t.Errorf("features =\n%v\nwant\n%v", got["features"], want)
+	}
+	if len(base["features"].(map[string]any)) != 4 {
+		t.Error("Merge modified base's features")
+	}
+}
+
+func TestMergeValues(t *testing.T) {
+	base := map[string]any{
+		"name":         "app",
+		"remoteUser":   "vscode",
+		"forwardPorts": []any{3000.0, "db:5432"},
+		"mounts": []any{
+			map[string]any{"type": "volume", "source": "cache", "target": "/cache"},
+		},
+		"postCreateCommand": []any{"npm", "install"},
+		"containerEnv":      map[string]any{"A": "1"},
+		"onCreateCommand":   "make setup",
+	}
+	overlay := map[string]any{
+		"remoteUser":   "root",
+		"forwardPorts": []any{"db:5432", 8080.0},
+		"mounts": []any{
+			map[string]any{"type": "volume", "source": "cache", "target": "/cache"},
+			"source=${localEnv:HOME}/.ssh,target=/root/.ssh,type=bind",
+		},
+		// A value of another type replaces the configured one.
+		"postCreateCommand": "npm ci",
+		"containerEnv":      "not an object",
+		"onCreateCommand":   map[string]any{"setup": "make setup"},
+	}
+
+	got := Merge(base, overlay)
+	want := map[string]any{
+		"name":         "app",
+		"remoteUser":   "root",
+		"forwardPorts": []any{3000.0, "db:5432", 8080.0},
+		"mounts": []any{
+			map[string]any{"type": "volume", "source": "cache", "target": "/cache"},
+			"source=${localEnv:HOME}/.ssh,target=/root/.ssh,type=bind",
+		},
+		"postCreateCommand": "npm ci",
+		"containerEnv":      "not an object",
+		"onCreateCommand":   map[string]any{"setup": "make setup"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge =\n%v\nwant\n%v", got, want)
+	}
+
+	if got := Merge(nil, overlay); !reflect.DeepEqual(got, overlay) {
+		t.Errorf("Merge(nil, overlay) = %v, want a copy of overlay", got)
+	}
+	if got := Merge(base, nil); !reflect.DeepEqual(got, base) {
+		t.Errorf("Merge(base, nil) = %v, want a copy of base", got)
+	}
+}
+
+func TestFeatureID(t *testing.T) {
+	for ref, want := range map[string]string{
+		"ghcr.io/devcontainers/features/node:1":              "ghcr.io/devcontainers/features/node",
+		"ghcr.io/devcontainers/features/node":                "ghcr.io/devcontainers/features/node",
+		"localhost:5000/features/tool":                       "localhost:5000/features/tool",
+		"localhost:5000/features/tool:2":                     "localhost:5000/features/tool",
+		"ghcr.io/devcontainers/features/node@sha256:abcdef0": "ghcr.io/devcontainers/features/node",
+	} {
+		if got := FeatureID(ref); got != want {
+			t.Errorf("FeatureID(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
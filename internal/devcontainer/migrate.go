@@ -0,0 +1,130 @@
+package devcontainer
+
+// Deprecation is a deprecated top-level key found in a config.
+type Deprecation struct {
+	Key         string
+	Replacement string // where Migrate moves it, "" if it is dropped
+}
+
+// deprecations lists the known deprecated keys and how Migrate handles them,
+// in the order they're reported. A key whose applies returns false is left
+// alone.
+var deprecations = []struct {
+	key         string
+	replacement string
+	migrate     func(config map[string]any, value any)
+	applies     func(config map[string]any) bool
+}{
+	{"extensions", "customizations.vscode.extensions", migrateExtensions, nil},
+	{"settings", "customizations.vscode.settings", migrateSettings, nil},
+	{"dockerFile", "build.dockerfile", func(config map[string]any, value any) { migrateBuildKey(config, "dockerfile", value) }, nil},
+	// context was the build context of dockerFile. Without a Dockerfile
+	// build, moving it would add a build section to an image or Compose
+	// config.
+	{"context", "build.context", func(config map[string]any, value any) { migrateBuildKey(config, "context", value) }, hasDockerfileBuild},
+	// devPort configured the port of the VS Code server and has no effect
+	// anymore.
+	{"devPort", "", func(map[string]any, any) {}, nil},
+}
+
+// hasDockerfileBuild reports whether config builds from a Dockerfile, in
+// the legacy dockerFile or the build form. Migrate moves dockerFile before
+// context, so build is set by then.
+func hasDockerfileBuild(config map[string]any) bool {
+	_, legacy := config["dockerFile"]
+	_, build := config["build"]
+	return legacy || build
+}
+
+// Deprecations returns the deprecated keys config uses.
+func Deprecations(config map[string]any) []Deprecation {
+	var found []Deprecation
+	for _, d := range deprecations {
+		if _, ok := config[d.key]; ok && (d.applies == nil || d.applies(config)) {
+			found = append(found, Deprecation{Key: d.key, Replacement: d.replacement})
+		}
+	}
+	return found
+}
+
+// Migrate moves the deprecated keys of config to their current place, or
+// drops them if nothing replaces them, and reports whether it changed
+// anything. Values already set in the current place win over the deprecated
+// ones.
+func Migrate(config map[string]any) bool {
+	changed := false
+	for _, d := range deprecations {
+		value, ok := config[d.key]
+		if !ok || (d.applies != nil && !d.applies(config)) {
+			continue
+		}
+		delete(config, d.key)
+		d.migrate(config, value)
+		changed = true
+	}
+	return changed
+}
+
+// vscodeCustomizations returns customizations.vscode, creating it.
+func vscodeCustomizations(config map[string]any) map[string]any {
+	custom, _ := config["customizations"].(map[string]any)
+	if custom == nil {
+		custom = make(map[string]any)
+		config["customizations"] = custom
+	}
+	vscode, _ := custom["vscode"].(map[string]any)
+	if vscode == nil {
+		vscode = make(map[string]any)
+		custom["vscode"] = vscode
+	}
+	return vscode
+}
+
+// migrateExtensions appends the extensions that
+// customizations.vscode.extensions doesn't list yet.
+func migrateExtensions(config map[string]any, value any) {
+	legacy, _ := value.([]any)
+	if len(legacy) == 0 {
+		return
+	}
+	vscode := vscodeCustomizations(config)
+	current, _ := vscode["extensions"].([]any)
+	for _, ext := range legacy {
+		if !containsValue(current, ext) {
+			current = append(current, ext)
+		}
+	}
+	vscode["extensions"] = current
+}
+
+// migrateSettings adds the settings customizations.vscode.settings doesn't
+// set yet.
+func migrateSettings(config map[string]any, value any) {
+	legacy, _ := value.(map[string]any)
+	if len(legacy) == 0 {
+		return
+	}
+	vscode := vscodeCustomizations(config)
+	current, _ := vscode["settings"].(map[string]any)
+	if current == nil {
+		current = make(map[string]any, len(legacy))
+	}
+	for k, v := range legacy {
+		if _, ok := current[k]; !ok {
+			current[k] = v
+		}
+	}
+	vscode["settings"] = current
+}
+
+// migrateBuildKey sets build.<key> unless build already has it.
+func migrateBuildKey(config map[string]any, key string, value any) {
+	build, _ := config["build"].(map[string]any)
+	if build == nil {
+		build = make(map[string]any)
+		config["build"] = build
+	}
+	if _, ok := build[key]; !ok {
+		build[key] = value
+	}
+}
@@ -0,0 +1,125 @@
+package devcontainer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   map[string]any
+	}{
+		{
+			name:   "extensions",
+			config: map[string]any{"extensions": []any{"golang.go", "ms-python.python"}},
+			want: map[string]any{"customizations": map[string]any{
+				"vscode": map[string]any{"extensions": []any{"golang.go", "ms-python.python"}},
+			}},
+		},
+		{
+			name: "extensions merge after the current ones",
+			config: map[string]any{
+				"extensions": []any{"golang.go", "ms-python.python"},
+				"customizations": map[string]any{
+					"vscode":    map[string]any{"extensions": []any{"ms-python.python", "dbaeumer.vscode-eslint"}},
+					"jetbrains": map[string]any{"plugins": []any{"com.intellij.go"}},
+				},
+			},
+			want: map[string]any{"customizations": map[string]any{
+				"vscode":    map[string]any{"extensions": []any{"ms-python.python", "dbaeumer.vscode-eslint", "golang.go"}},
+				"jetbrains": map[string]any{"plugins": []any{"com.intellij.go"}},
+			}},
+		},
+		{
+			name: "settings keep current values",
+			config: map[string]any{
+				"settings": map[string]any{"editor.tabSize": 2.0, "go.useLanguageServer": true},
+				"customizations": map[string]any{
+					"vscode": map[string]any{"settings": map[string]any{"editor.tabSize": 4.0}},
+				},
+			},
+			want: map[string]any{"customizations": map[string]any{
+				"vscode": map[string]any{"settings": map[string]any{"editor.tabSize": 4.0, "go.useLanguageServer": true}},
+			}},
+		},
+		{
+			name:   "dockerFile and context",
+			config: map[string]any{"dockerFile": "Dockerfile", "context": "..", "build": map[string]any{"target": "dev"}},
+			want:   map[string]any{"build": map[string]any{"dockerfile": "Dockerfile", "context": "..", "target": "dev"}},
+		},
+		{
+			name:   "build.dockerfile wins",
+			config: map[string]any{"dockerFile": "old.Dockerfile", "build": map[string]any{"dockerfile": "Dockerfile"}},
+			want:   map[string]any{"build": map[string]any{"dockerfile": "Dockerfile"}},
+		},
+		{
+			name:   "devPort is dropped",
+			config: map[string]any{"name": "app", "devPort": 8000.0},
+			want:   map[string]any{"name": "app"},
+		},
+		{
+			name:   "empty legacy values are dropped",
+			config: map[string]any{"extensions": []any{}, "settings": map[string]any{}},
+			want:   map[string]any{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !Migrate(tt.config) {
+				t.Error("Migrate reported no change")
+			}
+			if !reflect.DeepEqual(tt.config, tt.want) {
+				t.Errorf("config = %v, want %v", tt.config, tt.want)
+			}
+			if found := Deprecations(tt.config); len(found) != 0 {
+				t.Errorf("Deprecations after Migrate = %v", found)
+			}
+		})
+	}
+}
+
+func TestMigrateCurrentConfig(t *testing.T) {
+	config := map[string]any{
+		"image": "mcr.microsoft.com/devcontainers/go:1",
+		"build": map[string]any{"context": ".."},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go"}, "settings": map[string]any{}},
+		},
+	}
+	want := map[string]any{
+		"image": "mcr.microsoft.com/devcontainers/go:1",
+		"build": map[string]any{"context": ".."},
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go"}, "settings": map[string]any{}},
+		},
+	}
+	if Migrate(config) {
+		t.Error("Migrate changed a config without deprecated keys")
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %v, want it unchanged", config)
+	}
+
+	// A top-level context only belongs to a Dockerfile build.
+	image := map[string]any{"image": "mcr.microsoft.com/devcontainers/go:1", "context": ".."}
+	if found := Deprecations(image); len(found) != 0 {
+		t.Errorf("Deprecations of an image config = %v, want none", found)
+	}
+	if Migrate(image) || image["build"] != nil {
+		t.Errorf("Migrate moved context of an image config: %v", image)
+	}
+}
+
+func TestDeprecations(t *testing.T) {
+	config := map[string]any{"devPort": 8000.0, "settings": map[string]any{}, "extensions": []any{"golang.go"}}
+	want := []Deprecation{
+		{Key: "extensions", Replacement: "customizations.vscode.extensions"},
+		{Key: "settings", Replacement: "customizations.vscode.settings"},
+		{Key: "devPort"},
+	}
+	if got := Deprecations(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("Deprecations = %v, want %v", got, want)
+	}
+}
@@ -4,108 +4,363 @@ import (
 	"bytes"
 	"encoding/json"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
-// keyOrder records the key ordering of JSON objects at each nesting level.
+// keyOrder records the key ordering of JSON objects at each nesting level,
+// along with any JSONC comments attached to the keys. For arrays, keys stays
+// empty and children and comments are keyed by elementKey, so comments
+// follow their element when others are added, removed or reordered.
 type keyOrder struct {
 	keys     []string
 	children map[string]*keyOrder
+	comments map[string]*keyComments
+	header   []string // comments before the opening brace (top level only)
+	footer   []string // comments after the last key, before the closing brace
 }
 
-// extractKeyOrder parses JSON data and returns the key ordering tree.
-// For each object in the JSON, it records the keys in their original order.
+// keyComments holds the comments attached to a single key or array element.
+type keyComments struct {
+	leading  []string // whole-line comments directly above the key
+	trailing string   // comment on the same line, after the value
+}
+
+// orderParser walks comment-stripped JSON with a token decoder and looks up
+// comments in the original JSONC. ToJSON keeps every byte offset intact once
+// a BOM is removed, so decoder offsets index directly into the raw input.
+type orderParser struct {
+	raw   []byte
+	clean []byte
+	dec   *json.Decoder
+}
+
+// extractKeyOrder parses JSON or JSONC data and returns the key ordering tree.
+// For each object in the JSON, it records the keys in their original order and
+// the comments found around them.
 func extractKeyOrder(data []byte) *keyOrder {
-	dec := json.NewDecoder(bytes.NewReader(data))
-	order, _ := decodeValueOrder(dec)
+	data = bytes.TrimPrefix(data, utf8BOM)
+	clean := ToJSON(data)
+	p := &orderParser{
+		raw:   data,
+		clean: clean,
+		dec:   json.NewDecoder(bytes.NewReader(clean)),
+	}
+
+	rootStart := bytes.IndexFunc(clean, func(r rune) bool { return !unicode.IsSpace(r) })
+	order, _ := p.decodeValueOrder()
+	if order != nil && rootStart > 0 {
+		sameLine, lines := p.commentsIn(0, rootStart)
+		if sameLine != "" {
+			lines = append([]string{sameLine}, lines...)
+		}
+		order.header = lines
+	}
 	return order
 }
 
-func decodeValueOrder(dec *json.Decoder) (*keyOrder, error) {
-	t, err := dec.Token()
+func (p *orderParser) decodeValueOrder() (*keyOrder, error) {
+	t, err := p.dec.Token()
 	if err != nil {
 		return nil, err
 	}
 	if delim, ok := t.(json.Delim); ok {
 		switch delim {
 		case '{':
-			return decodeObjectOrder(dec)
+			return p.decodeObjectOrder()
 		case '[':
-			return decodeArrayOrder(dec)
+			return p.decodeArrayOrder()
 		}
 	}
 	return nil, nil
 }
 
-func decodeObjectOrder(dec *json.Decoder) (*keyOrder, error) {
+func (p *orderParser) decodeObjectOrder() (*keyOrder, error) {
 	order := &keyOrder{
 		children: make(map[string]*keyOrder),
+		comments: make(map[string]*keyComments),
 	}
-	for dec.More() {
-		t, err := dec.Token()
+	prevKey := ""
+	// More skips whitespace, so the end of the previous token has to be
+	// recorded before calling it.
+	gapStart := int(p.dec.InputOffset())
+	for p.dec.More() {
+		t, err := p.dec.Token()
 		if err != nil {
 			return order, err
 		}
 		key, ok := t.(string)
 		if !ok {
+			gapStart = int(p.dec.InputOffset())
 			continue
 		}
 		order.keys = append(order.keys, key)
 
-		child, err := decodeValueOrder(dec)
+		// Comments between the previous value and this key: a comment on the
+		// first line trails the previous key, the rest lead this one.
+		keyEnd := int(p.dec.InputOffset())
+		keyStart := gapStart + bytes.IndexByte(p.clean[gapStart:keyEnd], '"')
+		sameLine, lines := p.commentsIn(gapStart, keyStart)
+		if sameLine != "" {
+			if prevKey != "" {
+				order.commentsFor(prevKey).trailing = sameLine
+			} else {
+				lines = append([]string{sameLine}, lines...)
+			}
+		}
+		if len(lines) > 0 {
+			order.commentsFor(key).leading = lines
+		}
+
+		child, err := p.decodeValueOrder()
 		if err != nil {
 			return order, err
 		}
 		if child != nil {
 			order.children[key] = child
 		}
+		prevKey = key
+		gapStart = int(p.dec.InputOffset())
+	}
+
+	// consume closing '}' and pick up comments after the last value
+	p.dec.Token() //nolint:errcheck
+	gapEnd := int(p.dec.InputOffset()) - 1
+	if gapEnd > gapStart {
+		sameLine, lines := p.commentsIn(gapStart, gapEnd)
+		if sameLine != "" {
+			if prevKey != "" {
+				order.commentsFor(prevKey).trailing = sameLine
+			} else {
+				lines = append([]string{sameLine}, lines...)
+			}
+		}
+		order.footer = lines
 	}
-	// consume closing '}'
-	dec.Token() //nolint:errcheck
 	return order, nil
 }
 
-func decodeArrayOrder(dec *json.Decoder) (*keyOrder, error) {
-	for dec.More() {
-		decodeValueOrder(dec) //nolint:errcheck
+func (p *orderParser) decodeArrayOrder() (*keyOrder, error) {
+	order := &keyOrder{
+		children: make(map[string]*keyOrder),
+		comments: make(map[string]*keyComments),
 	}
-	// consume closing ']'
-	dec.Token() //nolint:errcheck
-	return nil, nil
+	prevKey := ""
+	gapStart := int(p.dec.InputOffset())
+	for i := 0; p.dec.More(); i++ {
+		start := bytes.IndexFunc(p.clean[gapStart:], func(r rune) bool { return !unicode.IsSpace(r) && r != ',' })
+		if start < 0 {
+			break
+		}
+		start += gapStart
+
+		child, err := p.decodeValueOrder()
+		if err != nil {
+			return order, err
+		}
+		key := elementKey(p.clean[start:p.dec.InputOffset()], i)
+		if child != nil {
+			order.children[key] = child
+		}
+
+		// Comments between elements are split like those between keys.
+		sameLine, lines := p.commentsIn(gapStart, start)
+		if sameLine != "" {
+			if prevKey != "" {
+				order.commentsFor(prevKey).trailing = sameLine
+			} else {
+				lines = append([]string{sameLine}, lines...)
+			}
+		}
+		if len(lines) > 0 {
+			order.commentsFor(key).leading = lines
+		}
+		prevKey = key
+		gapStart = int(p.dec.InputOffset())
+	}
+
+	// consume closing ']' and pick up comments after the last element
+	p.dec.Token() //nolint:errcheck
+	gapEnd := int(p.dec.InputOffset()) - 1
+	if gapEnd > gapStart {
+		sameLine, lines := p.commentsIn(gapStart, gapEnd)
+		if sameLine != "" {
+			if prevKey != "" {
+				order.commentsFor(prevKey).trailing = sameLine
+			} else {
+				lines = append([]string{sameLine}, lines...)
+			}
+		}
+		order.footer = lines
+	}
+	return order, nil
+}
+
+// elementKey identifies the array element at index i, given as JSON:
+// primitives by their value, objects and arrays by their index.
+func elementKey(data []byte, i int) string {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return "[" + strconv.Itoa(i) + "]"
+	}
+	return elementKeyOf(v, i)
+}
+
+// elementKeyOf is elementKey for a decoded value.
+func elementKeyOf(v any, i int) string {
+	switch v.(type) {
+	case map[string]any, []any:
+		return "[" + strconv.Itoa(i) + "]"
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// commentsIn returns the comments in raw[start:end]. The comment on the first
+// line (the line of the preceding token) is returned separately from the
+// comments on the following lines.
+func (p *orderParser) commentsIn(start, end int) (sameLine string, lines []string) {
+	if start < 0 || end > len(p.raw) || start >= end {
+		return "", nil
+	}
+	raw := p.raw[start:end]
+	clean := p.clean[start:end]
+
+	offset := 0
+	for i, line := range bytes.Split(raw, []byte("\n")) {
+		comment := extractComment(line, clean[offset:offset+len(line)])
+		offset += len(line) + 1
+		if comment == "" {
+			continue
+		}
+		if i == 0 {
+			sameLine = comment
+		} else {
+			lines = append(lines, comment)
+		}
+	}
+	return sameLine, lines
 }
 
-// marshalOrdered serializes value as indented JSON, preserving key ordering
-// from order for existing keys and appending new keys alphabetically.
-func marshalOrdered(value any, order *keyOrder) []byte {
+// extractComment returns the comment text on a raw line, using the stripped
+// line to tell comment bytes (blanked by jsonc) from JSON. Trailing commas are
+// also blanked by jsonc and are skipped.
+func extractComment(raw, clean []byte) string {
+	for i := range raw {
+		if raw[i] != clean[i] && raw[i] != ',' {
+			return strings.TrimSpace(string(raw[i:]))
+		}
+	}
+	return ""
+}
+
+// commentsFor returns the comments for key, creating the entry if needed.
+func (o *keyOrder) commentsFor(key string) *keyComments {
+	c, ok := o.comments[key]
+	if !ok {
+		c = &keyComments{}
+		o.comments[key] = c
+	}
+	return c
+}
+
+// withFallback returns o with the keys only fallback has appended in
+// fallback's order, along with their nested ordering and comments. Either
+// may be nil.
+func (o *keyOrder) withFallback(fallback *keyOrder) *keyOrder {
+	if fallback == nil {
+		return o
+	}
+	if o == nil {
+		return fallback
+	}
+	merged := &keyOrder{
+		keys:     append([]string(nil), o.keys...),
+		children: make(map[string]*keyOrder, len(o.children)),
+		comments: make(map[string]*keyComments, len(o.comments)),
+		header:   o.header,
+		footer:   o.footer,
+	}
+	seen := make(map[string]bool, len(o.keys))
+	for _, k := range o.keys {
+		seen[k] = true
+	}
+	for k, child := range o.children {
+		merged.children[k] = child.withFallback(fallback.children[k])
+	}
+	for k, c := range o.comments {
+		merged.comments[k] = c
+	}
+	for _, k := range fallback.keys {
+		if seen[k] {
+			continue
+		}
+		merged.keys = append(merged.keys, k)
+		if child, ok := fallback.children[k]; ok {
+			merged.children[k] = child
+		}
+		if c, ok := fallback.comments[k]; ok {
+			merged.comments[k] = c
+		}
+	}
+	return merged
+}
+
+// marshalOrdered serializes value as JSON laid out by f, preserving key
+// ordering from order for existing keys and appending new keys
+// alphabetically.
+func marshalOrdered(value any, order *keyOrder, f Format) []byte {
 	var buf bytes.Buffer
-	writeValue(&buf, value, order, "  ", 0)
+	if order != nil {
+		for _, line := range order.header {
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	writeValue(&buf, value, order, f, 0)
 	buf.WriteByte('\n')
 	return buf.Bytes()
 }
 
-func writeValue(buf *bytes.Buffer, value any, order *keyOrder, indent string, depth int) {
+func writeValue(buf *bytes.Buffer, value any, order *keyOrder, f Format, depth int) {
 	switch v := value.(type) {
 	case map[string]any:
-		writeObject(buf, v, order, indent, depth)
+		writeObject(buf, v, order, f, depth)
 	case []any:
-		writeArray(buf, v, indent, depth)
+		writeArray(buf, v, order, f, depth)
 	default:
 		b, _ := json.Marshal(v)
 		buf.Write(b)
 	}
 }
 
-func writeObject(buf *bytes.Buffer, m map[string]any, order *keyOrder, indent string, depth int) {
+func writeObject(buf *bytes.Buffer, m map[string]any, order *keyOrder, f Format, depth int) {
 	if len(m) == 0 {
 		buf.WriteString("{}")
 		return
 	}
 
 	keys := orderedKeysForMap(m, order)
+	if depth == 0 {
+		keys = schemaFirst(keys)
+	}
 
 	buf.WriteString("{\n")
-	prefix := strings.Repeat(indent, depth+1)
+	prefix := strings.Repeat(f.Indent, depth+1)
 	for i, k := range keys {
+		var comments *keyComments
+		if order != nil {
+			comments = order.comments[k]
+		}
+		if comments != nil {
+			for _, line := range comments.leading {
+				buf.WriteString(prefix)
+				buf.WriteString(line)
+				buf.WriteByte('\n')
+			}
+		}
+
 		buf.WriteString(prefix)
 		keyBytes, _ := json.Marshal(k)
 		buf.Write(keyBytes)
@@ -115,25 +370,37 @@ func writeObject(buf *bytes.Buffer, m map[string]any, order *keyOrder, indent st
 		if order != nil {
 			childOrder = order.children[k]
 		}
-		writeValue(buf, m[k], childOrder, indent, depth+1)
+		writeValue(buf, m[k], childOrder, f, depth+1)
 
 		if i < len(keys)-1 {
 			buf.WriteByte(',')
 		}
+		if comments != nil && comments.trailing != "" {
+			buf.WriteByte(' ')
+			buf.WriteString(comments.trailing)
+		}
 		buf.WriteByte('\n')
 	}
-	buf.WriteString(strings.Repeat(indent, depth))
+	if order != nil {
+		for _, line := range order.footer {
+			buf.WriteString(prefix)
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	buf.WriteString(strings.Repeat(f.Indent, depth))
 	buf.WriteByte('}')
 }
 
-func writeArray(buf *bytes.Buffer, arr []any, indent string, depth int) {
+func writeArray(buf *bytes.Buffer, arr []any, order *keyOrder, f Format, depth int) {
 	if len(arr) == 0 {
 		buf.WriteString("[]")
 		return
 	}
 
 	// Check if all elements are primitives for compact single-line output.
-	allPrimitive := true
+	// Comments need lines of their own.
+	allPrimitive := f.CompactArrays && (order == nil || len(order.comments) == 0 && len(order.footer) == 0)
 	for _, v := range arr {
 		switch v.(type) {
 		case map[string]any, []any:
@@ -155,16 +422,40 @@ func writeArray(buf *bytes.Buffer, arr []any, indent string, depth int) {
 	}
 
 	buf.WriteString("[\n")
-	prefix := strings.Repeat(indent, depth+1)
+	prefix := strings.Repeat(f.Indent, depth+1)
 	for i, v := range arr {
+		var comments *keyComments
+		var childOrder *keyOrder
+		if order != nil {
+			key := elementKeyOf(v, i)
+			comments, childOrder = order.comments[key], order.children[key]
+		}
+		if comments != nil {
+			for _, line := range comments.leading {
+				buf.WriteString(prefix)
+				buf.WriteString(line)
+				buf.WriteByte('\n')
+			}
+		}
 		buf.WriteString(prefix)
-		writeValue(buf, v, nil, indent, depth+1)
+		writeValue(buf, v, childOrder, f, depth+1)
 		if i < len(arr)-1 {
 			buf.WriteByte(',')
 		}
+		if comments != nil && comments.trailing != "" {
+			buf.WriteByte(' ')
+			buf.WriteString(comments.trailing)
+		}
 		buf.WriteByte('\n')
 	}
-	buf.WriteString(strings.Repeat(indent, depth))
+	if order != nil {
+		for _, line := range order.footer {
+			buf.WriteString(prefix)
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	buf.WriteString(strings.Repeat(f.Indent, depth))
 	buf.WriteByte(']')
 }
 
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+
+	"github.com/tidwall/jsonc"
 )
 
 func TestExtractKeyOrder(t *testing.T) {
@@ -43,7 +45,7 @@ func TestMarshalOrderedPreservesExistingOrder(t *testing.T) {
 		"features": map[string]any{},
 	}
 
-	got := string(marshalOrdered(config, order))
+	got := string(marshalOrdered(config, order, DefaultFormat))
 
 	// Verify keys appear in original order, not alphabetical.
 	nameIdx := indexOf(got, `"name"`)
@@ -66,7 +68,7 @@ func TestMarshalOrderedAppendsNewKeysAlphabetically(t *testing.T) {
 		"capAdd":   []any{"SYS_PTRACE"},
 	}
 
-	got := string(marshalOrdered(config, order))
+	got := string(marshalOrdered(config, order, DefaultFormat))
 
 	nameIdx := indexOf(got, `"name"`)
 	imageIdx := indexOf(got, `"image"`)
@@ -96,7 +98,7 @@ func TestMarshalOrderedDeletedKeysSkipped(t *testing.T) {
 		"image": "ubuntu",
 	}
 
-	got := string(marshalOrdered(config, order))
+	got := string(marshalOrdered(config, order, DefaultFormat))
 
 	if indexOf(got, `"features"`) != -1 {
 		t.Errorf("deleted key should not appear:\n%s", got)
@@ -111,7 +113,7 @@ func TestMarshalOrderedNoOriginal(t *testing.T) {
 		"features": map[string]any{},
 	}
 
-	got := string(marshalOrdered(config, nil))
+	got := string(marshalOrdered(config, nil, DefaultFormat))
 
 	featIdx := indexOf(got, `"features"`)
 	imageIdx := indexOf(got, `"image"`)
@@ -144,7 +146,7 @@ func TestMarshalOrderedNestedPreservation(t *testing.T) {
 		},
 	}
 
-	got := string(marshalOrdered(config, order))
+	got := string(marshalOrdered(config, order, DefaultFormat))
 
 	// Top-level: customizations before name.
 	custIdx := indexOf(got, `"customizations"`)
@@ -165,7 +167,7 @@ func TestMarshalOrderedPrimitiveArrayCompact(t *testing.T) {
 	config := map[string]any{
 		"forwardPorts": []any{float64(3000), float64(8080)},
 	}
-	got := string(marshalOrdered(config, nil))
+	got := string(marshalOrdered(config, nil, DefaultFormat))
 
 	// Primitive arrays should be on one line.
 	expected := `[3000, 8080]`
@@ -195,7 +197,7 @@ func TestMarshalOrderedRoundTrip(t *testing.T) {
 	var config map[string]any
 	json.Unmarshal([]byte(original), &config)
 
-	got := string(marshalOrdered(config, order))
+	got := string(marshalOrdered(config, order, DefaultFormat))
 
 	// Re-parse to verify valid JSON.
 	var reparsed map[string]any
@@ -268,6 +270,10 @@ func writeFileForTest(path string, data []byte) error {
 	return os.WriteFile(path, data, 0o644)
 }
 
+func jsoncToJSONForTest(s string) []byte {
+	return jsonc.ToJSON([]byte(s))
+}
+
 func readFileForTest(t *testing.T, path string) string {
 	t.Helper()
 	data, err := os.ReadFile(path)
@@ -276,3 +282,188 @@ func readFileForTest(t *testing.T, path string) string {
 	}
 	return string(data)
 }
+
+func TestMarshalOrderedPreservesComments(t *testing.T) {
+	original := `// Project devcontainer
+{
+  // Display name
+  "name": "test", // shown in the IDE
+  "image": "ubuntu",
+  "features": {
+    // Node for the frontend build
+    "ghcr.io/devcontainers/features/node:1": {}, // pinned major
+    "ghcr.io/devcontainers/features/go:1": {}
+    // add python later
+  },
+  /* user for tool connections */
+  "remoteUser": "vscode"
+}
+`
+	order := extractKeyOrder([]byte(original))
+
+	var config map[string]any
+	if err := json.Unmarshal(jsoncToJSONForTest(original), &config); err != nil {
+		t.Fatal(err)
+	}
+	// Edit one unrelated key.
+	config["remoteUser"] = "root"
+
+	got := string(marshalOrdered(config, order, DefaultFormat))
+
+	want := `// Project devcontainer
+{
+  // Display name
+  "name": "test", // shown in the IDE
+  "image": "ubuntu",
+  "features": {
+    // Node for the frontend build
+    "ghcr.io/devcontainers/features/node:1": {}, // pinned major
+    "ghcr.io/devcontainers/features/go:1": {}
+    // add python later
+  },
+  /* user for tool connections */
+  "remoteUser": "root"
+}
+`
+	if got != want {
+		t.Errorf("comments not preserved:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+}
+
+func TestMarshalOrderedPreservesArrayComments(t *testing.T) {
+	original := `{
+  "forwardPorts": [
+    3000, // web
+    // the API
+    8080
+  ],
+  "mounts": [
+    // build cache
+    {"source": "cache", "target": "/cache", "type": "volume"}
+  ],
+  "customizations": {
+    "vscode": {
+      "extensions": [
+        "golang.go",
+        // "ms-python.python"
+      ]
+    }
+  }
+}
+`
+	order := extractKeyOrder([]byte(original))
+
+	var config map[string]any
+	if err := json.Unmarshal(jsoncToJSONForTest(original), &config); err != nil {
+		t.Fatal(err)
+	}
+	// Comments follow their element when others are added before it.
+	config["forwardPorts"] = []any{5432, 3000.0, 8080.0}
+	vscode := config["customizations"].(map[string]any)["vscode"].(map[string]any)
+	vscode["extensions"] = append(vscode["extensions"].([]any), "redhat.vscode-yaml")
+
+	got := string(marshalOrdered(config, order, DefaultFormat))
+
+	want := `{
+  "forwardPorts": [
+    5432,
+    3000, // web
+    // the API
+    8080
+  ],
+  "mounts": [
+    // build cache
+    {
+      "source": "cache",
+      "target": "/cache",
+      "type": "volume"
+    }
+  ],
+  "customizations": {
+    "vscode": {
+      "extensions": [
+        "golang.go",
+        "redhat.vscode-yaml"
+        // "ms-python.python"
+      ]
+    }
+  }
+}
+`
+	if got != want {
+		t.Errorf("array comments not preserved:\n--- got ---\n%s\n--- want ---\n%s", got, want)
+	}
+
+	// Arrays without comments stay compact.
+	if got := string(marshalOrdered(map[string]any{"runArgs": []any{"--init"}}, extractKeyOrder([]byte(`{"runArgs": ["--init"]}`)), DefaultFormat)); got != "{\n  \"runArgs\": [\"--init\"]\n}\n" {
+		t.Errorf("uncommented array = %q, want it on one line", got)
+	}
+}
+
+func TestMarshalOrderedTrailingCommentOnLastKey(t *testing.T) {
+	original := `{
+  "name": "test",
+  "image": "ubuntu" // base image
+}`
+	order := extractKeyOrder([]byte(original))
+
+	config := map[string]any{"name": "test", "image": "debian"}
+	got := string(marshalOrdered(config, order, DefaultFormat))
+
+	if indexOf(got, `"image": "debian" // base image`) == -1 {
+		t.Errorf("trailing comment on last key not preserved:\n%s", got)
+	}
+}
+
+func TestMarshalOrderedDropsCommentsOfDeletedKeys(t *testing.T) {
+	original := `{
+  "name": "test",
+  // remove me
+  "image": "ubuntu" // and me
+}`
+	order := extractKeyOrder([]byte(original))
+
+	got := string(marshalOrdered(map[string]any{"name": "test"}, order, DefaultFormat))
+
+	if indexOf(got, "remove me") != -1 || indexOf(got, "and me") != -1 {
+		t.Errorf("comments of deleted key should be dropped:\n%s", got)
+	}
+}
+
+func TestWriteConfigPreservesComments(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/devcontainer.json"
+
+	initial := []byte(`{
+  // keep this
+  "name": "test",
+  "image": "ubuntu" // and this
+}
+`)
+	if err := writeFileForTest(path, initial); err != nil {
+		t.Fatal(err)
+	}
+
+	config := map[string]any{
+		"name":       "test",
+		"image":      "ubuntu",
+		"remoteUser": "vscode",
+	}
+	if err := WriteConfig(path, config); err != nil {
+		t.Fatal(err)
+	}
+
+	got := readFileForTest(t, path)
+	if indexOf(got, "// keep this\n  \"name\"") == -1 {
+		t.Errorf("leading comment not preserved:\n%s", got)
+	}
+	if indexOf(got, `"image": "ubuntu", // and this`) == -1 {
+		t.Errorf("trailing comment not preserved:\n%s", got)
+	}
+
+	// The written file must still parse as JSONC.
+	var reparsed map[string]any
+	if err := json.Unmarshal(jsoncToJSONForTest(got), &reparsed); err != nil {
+		t.Fatalf("output is not valid JSONC: %v\n%s", err, got)
+	}
+}
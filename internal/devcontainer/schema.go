@@ -0,0 +1,88 @@
+package devcontainer
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mochlast/devcontainer-companion/internal/cachedir"
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
+)
+
+// SchemaSourceURL is the published devcontainer.json base schema, which
+// RefreshSchema caches for Validate. The schema at SchemaURL only combines
+// it with editor-specific schemas.
+const SchemaSourceURL = "https://raw.githubusercontent.com/devcontainers/spec/main/schemas/devContainer.base.schema.json"
+
+// schemaTTL is how long a cached schema is used before RefreshSchema fetches
+// it again. The spec changes rarely.
+const schemaTTL = 7 * 24 * time.Hour
+
+// schemaCacheFile is the name of the cached schema in the cache directory.
+const schemaCacheFile = "devContainer.base.schema.json"
+
+// RefreshSchema fetches the published schema into the cache unless the
+// cached copy is younger than a week. Validate uses the cached copy from
+// the next load on; without one, it falls back to the bundled schema.
+func RefreshSchema() error {
+	path, err := schemaCachePath()
+	if err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) < schemaTTL {
+		return nil
+	}
+
+	data, err := fetchSchema(SchemaSourceURL)
+	if err != nil {
+		return fmt.Errorf("fetching devcontainer schema: %w", err)
+	}
+	if _, err := parseSchema(data); err != nil {
+		return fmt.Errorf("parsing devcontainer schema from %s: %w", SchemaSourceURL, err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func fetchSchema(url string) ([]byte, error) {
+	resp, err := httpclient.Default().Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+func schemaCachePath() (string, error) {
+	dir, err := cachedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, schemaCacheFile), nil
+}
+
+// cachedSchema returns the schema cached by RefreshSchema, at any age, or
+// nil if there is none or it doesn't parse.
+func cachedSchema() *schemaNode {
+	path, err := schemaCachePath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	root, err := parseSchema(data)
+	if err != nil {
+		return nil
+	}
+	return root
+}
@@ -0,0 +1,32 @@
+package devcontainer
+
+// SchemaKey is the key editors read the schema of a JSON file from.
+const SchemaKey = "$schema"
+
+// SchemaURL is the official devcontainer.json schema, which gives editors
+// completion and validation for the file.
+const SchemaURL = "https://raw.githubusercontent.com/devcontainers/spec/main/schemas/devContainer.schema.json"
+
+// ToggleSchema removes $schema from config if it is set and points it at
+// SchemaURL otherwise. It reports whether config has $schema now.
+func ToggleSchema(config map[string]any) bool {
+	if _, ok := config[SchemaKey]; ok {
+		delete(config, SchemaKey)
+		return false
+	}
+	config[SchemaKey] = SchemaURL
+	return true
+}
+
+// schemaFirst moves $schema to the front of the top-level keys, where
+// editors and readers expect it.
+func schemaFirst(keys []string) []string {
+	for i, k := range keys {
+		if k == SchemaKey {
+			copy(keys[1:i+1], keys[:i])
+			keys[0] = SchemaKey
+			break
+		}
+	}
+	return keys
+}
@@ -0,0 +1,38 @@
+package devcontainer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToggleSchema(t *testing.T) {
+	config := map[string]any{"name": "test"}
+	if !ToggleSchema(config) || config[SchemaKey] != SchemaURL {
+		t.Fatalf("ToggleSchema didn't add $schema: %v", config)
+	}
+	if ToggleSchema(config) {
+		t.Fatal("ToggleSchema reported $schema after removing it")
+	}
+	if _, ok := config[SchemaKey]; ok {
+		t.Errorf("$schema kept: %v", config)
+	}
+}
+
+func TestMarshalOrderedSchemaFirst(t *testing.T) {
+	// $schema stays first whether it was elsewhere in the file or is new.
+	originals := []string{
+		"{\n  \"name\": \"test\",\n  \"$schema\": \"" + SchemaURL + "\",\n  \"settings\": {\"a\": 1, \"$schema\": \"x\"}\n}\n",
+		"{\n  \"name\": \"test\",\n  \"settings\": {\"a\": 1, \"$schema\": \"x\"}\n}\n",
+	}
+	config := map[string]any{"name": "test", "image": "ubuntu", SchemaKey: SchemaURL, "settings": map[string]any{"$schema": "x", "a": 1.0}}
+	for _, original := range originals {
+		got := string(marshalOrdered(config, extractKeyOrder([]byte(original)), DefaultFormat))
+		if want := "{\n  \"$schema\": "; !strings.HasPrefix(got, want) {
+			t.Errorf("$schema not first:\n%s", got)
+		}
+		// Nested objects keep their own order.
+		if strings.Index(got, `"a": 1`) > strings.Index(got, `"$schema": "x"`) {
+			t.Errorf("nested $schema moved:\n%s", got)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package devcontainer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/shellquote"
+)
+
+// SSHFileSystem reads and writes configs on a remote host by running
+// commands through the ssh client, so ~/.ssh/config, keys and the agent
+// apply as usual. Connections are shared between calls.
+type SSHFileSystem struct {
+	Host string // "user@server" or a Host alias from ~/.ssh/config
+}
+
+// exitNotFound is the exit status ReadFile's remote script uses for a missing file.
+const exitNotFound = 3
+
+// Command returns an ssh command running argv on the host. Each argument is
+// quoted for the remote shell.
+func (s SSHFileSystem) Command(argv ...string) *exec.Cmd {
+	quoted := make([]string, len(argv))
+	for i, arg := range argv {
+		quoted[i] = shellquote.Quote(arg)
+	}
+	return s.script(strings.Join(quoted, " "))
+}
+
+// Interactive is Command with a terminal allocated, for editors and shells.
+func (s SSHFileSystem) Interactive(argv ...string) *exec.Cmd {
+	cmd := s.Command(argv...)
+	cmd.Args = append([]string{"ssh", "-t"}, cmd.Args[1:]...)
+	return cmd
+}
+
+// script returns an ssh command running a shell snippet on the host.
+func (s SSHFileSystem) script(snippet string) *exec.Cmd {
+	return exec.Command("ssh", append(SSHOptions(), s.Host, snippet)...)
+}
+
+// SSHOptions are the ssh flags dcc passes on every connection. A control
+// socket keeps one connection open for a minute instead of reconnecting for
+// each file access.
+func SSHOptions() []string {
+	return []string{
+		"-o", "ControlMaster=auto",
+		"-o", "ControlPath=" + filepath.Join(os.TempDir(), "dcc-ssh-%C"),
+		"-o", "ControlPersist=60",
+	}
+}
+
+func (s SSHFileSystem) run(cmd *exec.Cmd, stdin []byte) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return stdout.Bytes(), fmt.Errorf("%s: %w: %s", s.Host, err, msg)
+		}
+		return stdout.Bytes(), fmt.Errorf("%s: %w", s.Host, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Home returns the remote user's home directory, which relative remote paths
+// resolve against.
+func (s SSHFileSystem) Home() (string, error) {
+	out, err := s.run(s.script("pwd"), nil)
+	if err != nil {
+		return "", fmt.Errorf("connecting to %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func (s SSHFileSystem) ReadFile(path string) ([]byte, error) {
+	q := shellquote.Quote(path)
+	data, err := s.run(s.script(fmt.Sprintf("test -f %s || exit %d; cat %s", q, exitNotFound, q)), nil)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == exitNotFound {
+		return nil, &fs.PathError{Op: "open", Path: s.Host + ":" + path, Err: fs.ErrNotExist}
+	}
+	return data, err
+}
+
+func (s SSHFileSystem) WriteFile(path string, data []byte) error {
+	snippet := fmt.Sprintf("mkdir -p %s && cat > %s", shellquote.Quote(filepath.Dir(path)), shellquote.Quote(path))
+	if _, err := s.run(s.script(snippet), data); err != nil {
+		return fmt.Errorf("writing %s on %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func (s SSHFileSystem) IsFile(path string) bool {
+	_, err := s.run(s.script("test -f "+shellquote.Quote(path)), nil)
+	return err == nil
+}
+
+// Rename uses mv -n, so an existing file is never replaced.
+func (s SSHFileSystem) Rename(from, to string) error {
+	snippet := fmt.Sprintf("mkdir -p %s && mv -n %s %s", shellquote.Quote(filepath.Dir(to)), shellquote.Quote(from), shellquote.Quote(to))
+	if _, err := s.run(s.script(snippet), nil); err != nil {
+		return fmt.Errorf("moving devcontainer.json on %w", err)
+	}
+	return nil
+}
+
+func (s SSHFileSystem) Remove(path string) error {
+	if _, err := s.run(s.script("rm -f "+shellquote.Quote(path)), nil); err != nil {
+		return fmt.Errorf("removing %s on %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+func (s SSHFileSystem) Chmod(path string, mode os.FileMode) error {
+	if _, err := s.run(s.script(fmt.Sprintf("chmod %o %s", mode.Perm(), shellquote.Quote(path))), nil); err != nil {
+		return fmt.Errorf("changing the mode of %s on %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// Glob expands pattern on the host. Only "*" is a wildcard; the host's
+// shell expands it, so unlike in filepath.Glob it doesn't match a leading
+// dot.
+func (s SSHFileSystem) Glob(pattern string) ([]string, error) {
+	segments := strings.Split(pattern, "/")
+	for i, seg := range segments {
+		parts := strings.Split(seg, "*")
+		for j, part := range parts {
+			if part != "" {
+				parts[j] = shellquote.Quote(part)
+			}
+		}
+		segments[i] = strings.Join(parts, "*")
+	}
+	snippet := fmt.Sprintf(`for f in %s; do [ -e "$f" ] && printf '%%s\n' "$f"; done; true`, strings.Join(segments, "/"))
+	out, err := s.run(s.script(snippet), nil)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if line != "" {
+			matches = append(matches, line)
+		}
+	}
+	return matches, nil
+}
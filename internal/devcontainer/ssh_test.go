@@ -0,0 +1,105 @@
+package devcontainer
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeSSH puts an ssh on PATH that runs the remote command with the local
+// shell, so SSHFileSystem can be exercised against a temp dir.
+func fakeSSH(t *testing.T) {
+	t.Helper()
+	bin := t.TempDir()
+	script := "#!/bin/sh\nwhile [ \"$1\" = -o ] || [ \"$1\" = -t ]; do [ \"$1\" = -o ] && shift; shift; done\nshift\nexec sh -c \"$*\"\n"
+	if err := os.WriteFile(filepath.Join(bin, "ssh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestSSHFileSystem(t *testing.T) {
+	fakeSSH(t)
+	dir := filepath.Join(t.TempDir(), "my project")
+	remote := SSHFileSystem{Host: "dev@server"}
+
+	path := filepath.Join(dir, ".devcontainer", "devcontainer.json")
+	if _, err := remote.ReadFile(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("ReadFile of a missing file = %v, want fs.ErrNotExist", err)
+	}
+	if err := remote.WriteFile(path, []byte(`{"name": "it's remote"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := remote.ReadFile(path); err != nil || string(data) != `{"name": "it's remote"}` {
+		t.Errorf("ReadFile = %q, %v", data, err)
+	}
+	if !remote.IsFile(path) || remote.IsFile(filepath.Dir(path)) {
+		t.Error("IsFile does not tell the config from its directory")
+	}
+
+	named := filepath.Join(dir, ".devcontainer", "api", "devcontainer.json")
+	if err := remote.WriteFile(named, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	matches, err := remote.Glob(filepath.Join(dir, ".devcontainer", "*", "devcontainer.json"))
+	if err != nil || len(matches) != 1 || matches[0] != named {
+		t.Errorf("Glob = %q, %v; want [%s]", matches, err, named)
+	}
+
+	moved := filepath.Join(dir, ".devcontainer", "web", "devcontainer.json")
+	if err := remote.Rename(named, moved); err != nil {
+		t.Fatal(err)
+	}
+	if !remote.IsFile(moved) || remote.IsFile(named) {
+		t.Error("Rename did not move the file")
+	}
+
+	// Wildcards inside a segment match dotfiles, as locally.
+	root := filepath.Join(dir, ".devcontainer.json")
+	backup := root + ".20261014-091203.412.bak"
+	if err := remote.WriteFile(backup, []byte("{}")); err != nil {
+		t.Fatal(err)
+	}
+	matches, err = remote.Glob(root + ".*.bak")
+	if err != nil || len(matches) != 1 || matches[0] != backup {
+		t.Errorf("Glob = %q, %v; want [%s]", matches, err, backup)
+	}
+	if err := remote.Remove(backup); err != nil || remote.IsFile(backup) {
+		t.Errorf("Remove = %v, file still there: %v", err, remote.IsFile(backup))
+	}
+
+	script := filepath.Join(dir, ".devcontainer", "post-create.sh")
+	if err := remote.WriteFile(script, []byte("#!/bin/sh\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Chmod(script, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("mode after Chmod = %v, want 0755", info.Mode().Perm())
+	}
+}
+
+func TestUseFileSystem(t *testing.T) {
+	fakeSSH(t)
+	UseFileSystem(SSHFileSystem{Host: "server"})
+	t.Cleanup(func() { UseFileSystem(localFS{}) })
+
+	dir := t.TempDir()
+	if Exists(dir) {
+		t.Fatal("Exists = true before writing")
+	}
+	if err := WriteConfig(DefaultConfigPath(dir), map[string]any{"name": "remote"}); err != nil {
+		t.Fatal(err)
+	}
+	config, _, err := ReadConfig(dir)
+	if err != nil || config["name"] != "remote" {
+		t.Errorf("ReadConfig = %v, %v", config, err)
+	}
+}
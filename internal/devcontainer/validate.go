@@ -0,0 +1,465 @@
+package devcontainer
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// schemaJSON is a flattened subset of the devcontainer.json schema that
+// validation falls back to until RefreshSchema has cached the published one.
+//
+//go:embed devContainer.schema.json
+var schemaJSON []byte
+
+// ValidationError describes a single schema violation.
+type ValidationError struct {
+	Path    string `json:"path"` // offending key path, e.g. "hostRequirements.cpus" or "forwardPorts[1]"
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// schemaNode is the subset of JSON Schema understood by Validate.
+type schemaNode struct {
+	Ref                  string                 `json:"$ref"`
+	Type                 schemaTypes            `json:"type"`
+	Enum                 []any                  `json:"enum"`
+	Properties           map[string]*schemaNode `json:"properties"`
+	AdditionalProperties *schemaNode            `json:"additionalProperties"`
+	Items                *schemaNode            `json:"items"`
+	Minimum              *float64               `json:"minimum"`
+	Maximum              *float64               `json:"maximum"`
+	Definitions          map[string]*schemaNode `json:"definitions"`
+	AllOf                []*schemaNode          `json:"allOf"`
+	AnyOf                []*schemaNode          `json:"anyOf"`
+	OneOf                []*schemaNode          `json:"oneOf"`
+
+	never bool // the false schema, as in "additionalProperties": false
+}
+
+// UnmarshalJSON also accepts the boolean schemas true and false. Tuple
+// "items" arrays aren't understood and leave items unchecked.
+func (n *schemaNode) UnmarshalJSON(data []byte) error {
+	switch string(bytes.TrimSpace(data)) {
+	case "true":
+		*n = schemaNode{}
+		return nil
+	case "false":
+		*n = schemaNode{never: true}
+		return nil
+	}
+	type plain schemaNode
+	aux := struct {
+		*plain
+		Items json.RawMessage `json:"items"`
+	}{plain: (*plain)(n)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	if items := bytes.TrimSpace(aux.Items); len(items) > 0 && items[0] != '[' {
+		n.Items = &schemaNode{}
+		return json.Unmarshal(items, n.Items)
+	}
+	return nil
+}
+
+// schemaTypes accepts both "type": "string" and "type": ["string", "array"].
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(data, &multi); err != nil {
+		return err
+	}
+	*t = multi
+	return nil
+}
+
+var (
+	rootSchema     *schemaNode
+	rootSchemaOnce sync.Once
+)
+
+// loadSchema returns the schema cached by RefreshSchema, or else the bundled
+// one.
+func loadSchema() *schemaNode {
+	rootSchemaOnce.Do(func() {
+		if rootSchema = cachedSchema(); rootSchema != nil {
+			return
+		}
+		var err error
+		if rootSchema, err = parseSchema(schemaJSON); err != nil {
+			panic(fmt.Sprintf("parsing bundled devcontainer schema: %v", err))
+		}
+	})
+	return rootSchema
+}
+
+func parseSchema(data []byte) (*schemaNode, error) {
+	root := &schemaNode{}
+	if err := json.Unmarshal(data, root); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+// Validate checks a config against the devcontainer.json schema and
+// returns the violations sorted by path. Unknown keys are only reported when
+// they look like a typo of a known key, so custom keys (e.g. from feature
+// metadata extensions) are tolerated.
+func Validate(config map[string]any) []ValidationError {
+	return validateWith(loadSchema(), config)
+}
+
+func validateWith(root *schemaNode, config map[string]any) []ValidationError {
+	v := &validator{root: root, flat: make(map[*schemaNode]*schemaNode)}
+	v.validate(root, config, "")
+	sort.SliceStable(v.errs, func(i, j int) bool { return v.errs[i].Path < v.errs[j].Path })
+	return v.errs
+}
+
+type validator struct {
+	root *schemaNode
+	errs []ValidationError
+	flat map[*schemaNode]*schemaNode // flatten results, which also stop $ref cycles
+}
+
+func (v *validator) addf(path, format string, args ...any) {
+	v.errs = append(v.errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) resolve(node *schemaNode) *schemaNode {
+	const prefix = "#/definitions/"
+	for node != nil && strings.HasPrefix(node.Ref, prefix) {
+		node = v.root.Definitions[strings.TrimPrefix(node.Ref, prefix)]
+	}
+	return node
+}
+
+// flatten resolves node and folds its allOf, anyOf and oneOf branches into
+// one node that accepts what any branch accepts, as in the image, Dockerfile
+// and Compose variants of the published schema. The node's own type and
+// properties apply on top. Unknown keys are then only checked for typos
+// against the properties of all branches together.
+func (v *validator) flatten(node *schemaNode) *schemaNode {
+	node = v.resolve(node)
+	if node == nil || (len(node.AllOf) == 0 && len(node.AnyOf) == 0 && len(node.OneOf) == 0) {
+		return node
+	}
+	if flat, ok := v.flat[node]; ok {
+		return flat
+	}
+	v.flat[node] = &schemaNode{} // accepts anything while a $ref cycle is flattened
+
+	var flat *schemaNode
+	for _, branches := range [][]*schemaNode{node.AllOf, node.AnyOf, node.OneOf} {
+		for _, branch := range branches {
+			if b := v.flatten(branch); b != nil && !b.never {
+				flat = mergeAlternatives(flat, b)
+			}
+		}
+	}
+	if flat == nil {
+		flat = &schemaNode{}
+	}
+	if len(node.Type) > 0 {
+		flat.Type = node.Type
+	}
+	if len(node.Enum) > 0 {
+		flat.Enum = node.Enum
+	}
+	flat.Properties = mergeProperties(flat.Properties, node.Properties)
+	if node.AdditionalProperties != nil {
+		flat.AdditionalProperties = node.AdditionalProperties
+	}
+	if node.Items != nil {
+		flat.Items = node.Items
+	}
+	v.flat[node] = flat
+	return flat
+}
+
+// mergeAlternatives returns a node that accepts what a or b accepts. A
+// constraint only one of them has is dropped, as the other allows anything
+// there. a may be nil.
+func mergeAlternatives(a, b *schemaNode) *schemaNode {
+	if a == nil {
+		c := *b
+		c.Properties = mergeProperties(nil, b.Properties)
+		return &c
+	}
+	m := &schemaNode{Properties: mergeProperties(a.Properties, b.Properties)}
+	if len(a.Type) > 0 && len(b.Type) > 0 {
+		m.Type = append(schemaTypes{}, a.Type...)
+		for _, t := range b.Type {
+			if !slices.Contains(m.Type, t) {
+				m.Type = append(m.Type, t)
+			}
+		}
+	}
+	if len(a.Enum) > 0 && len(b.Enum) > 0 {
+		m.Enum = append([]any{}, a.Enum...)
+		for _, e := range b.Enum {
+			if !inEnum(e, m.Enum) {
+				m.Enum = append(m.Enum, e)
+			}
+		}
+	}
+	// Bounds only apply to numbers, so a branch that takes none keeps the
+	// other's, as in ports given as a number up to 65535 or a string.
+	m.Minimum = mergeBound(a.Minimum, b.Minimum, a, b, math.Min)
+	m.Maximum = mergeBound(a.Maximum, b.Maximum, a, b, math.Max)
+	if a.Items != nil && b.Items != nil {
+		m.Items = &schemaNode{AnyOf: []*schemaNode{a.Items, b.Items}}
+	}
+	switch {
+	case a.AdditionalProperties != nil && !a.AdditionalProperties.never:
+		m.AdditionalProperties = a.AdditionalProperties
+	case b.AdditionalProperties != nil && !b.AdditionalProperties.never:
+		m.AdditionalProperties = b.AdditionalProperties
+	}
+	return m
+}
+
+// mergeBound merges the bounds x of a and y of b with pick. A missing bound
+// leaves numbers unbounded unless its node doesn't take them.
+func mergeBound(x, y *float64, a, b *schemaNode, pick func(float64, float64) float64) *float64 {
+	switch {
+	case x != nil && y != nil:
+		return ptr(pick(*x, *y))
+	case x != nil && !takesNumbers(b):
+		return x
+	case y != nil && !takesNumbers(a):
+		return y
+	}
+	return nil
+}
+
+// takesNumbers reports whether node's type allows numbers.
+func takesNumbers(node *schemaNode) bool {
+	return len(node.Type) == 0 || slices.Contains(node.Type, "number") || slices.Contains(node.Type, "integer")
+}
+
+// mergeProperties returns the properties of a and b, matching either
+// definition of a property both have.
+func mergeProperties(a, b map[string]*schemaNode) map[string]*schemaNode {
+	if len(a) == 0 && len(b) == 0 {
+		return nil
+	}
+	m := make(map[string]*schemaNode, len(a)+len(b))
+	for k, p := range a {
+		m[k] = p
+	}
+	for k, p := range b {
+		if prev, ok := m[k]; ok && prev != p {
+			p = &schemaNode{AnyOf: []*schemaNode{prev, p}}
+		}
+		m[k] = p
+	}
+	return m
+}
+
+func ptr[T any](v T) *T { return &v }
+
+func (v *validator) validate(node *schemaNode, value any, path string) {
+	node = v.flatten(node)
+	if node == nil || node.never {
+		return
+	}
+
+	if len(node.Type) > 0 && !matchesAnyType(value, node.Type) {
+		v.addf(path, "expected %s, got %s", strings.Join(node.Type, " or "), typeName(value))
+		return
+	}
+
+	if len(node.Enum) > 0 && !inEnum(value, node.Enum) {
+		allowed := make([]string, len(node.Enum))
+		for i, e := range node.Enum {
+			allowed[i] = fmt.Sprintf("%v", e)
+		}
+		v.addf(path, "invalid value %q (allowed: %s)", fmt.Sprintf("%v", value), strings.Join(allowed, ", "))
+		return
+	}
+
+	if n, ok := toFloat(value); ok {
+		if node.Minimum != nil && n < *node.Minimum {
+			v.addf(path, "must be >= %v", *node.Minimum)
+		}
+		if node.Maximum != nil && n > *node.Maximum {
+			v.addf(path, "must be <= %v", *node.Maximum)
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(node, val, path)
+	case []any:
+		for i, item := range val {
+			v.validate(node.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	case []string:
+		for i, item := range val {
+			v.validate(node.Items, item, fmt.Sprintf("%s[%d]", path, i))
+		}
+	}
+}
+
+func (v *validator) validateObject(node *schemaNode, obj map[string]any, path string) {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := k
+		if path != "" {
+			childPath = path + "." + k
+		}
+		if prop, ok := node.Properties[k]; ok {
+			v.validate(prop, obj[k], childPath)
+			continue
+		}
+		if node.AdditionalProperties != nil && !node.AdditionalProperties.never {
+			v.validate(node.AdditionalProperties, obj[k], childPath)
+			continue
+		}
+		if suggestion := closestKey(k, node.Properties); suggestion != "" {
+			v.addf(childPath, "unknown property (did you mean %q?)", suggestion)
+		}
+	}
+}
+
+func matchesAnyType(value any, types []string) bool {
+	for _, t := range types {
+		if matchesType(value, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesType(value any, t string) bool {
+	switch t {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		switch value.(type) {
+		case []any, []string:
+			return true
+		}
+		return false
+	case "null":
+		return value == nil
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	}
+	return true
+}
+
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any, []string:
+		return "array"
+	}
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func toFloat(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func inEnum(value any, enum []any) bool {
+	for _, e := range enum {
+		if e == value {
+			return true
+		}
+	}
+	return false
+}
+
+// closestKey returns the known property closest to key if it is likely a
+// typo (case-insensitive match or an edit distance of at most 2).
+func closestKey(key string, props map[string]*schemaNode) string {
+	if len(key) < 4 {
+		return ""
+	}
+	best, bestDist := "", 3
+	for name := range props {
+		if strings.EqualFold(name, key) {
+			return name
+		}
+		if d := editDistance(strings.ToLower(key), strings.ToLower(name)); d < bestDist || (d == bestDist && name < best) {
+			best, bestDist = name, d
+		}
+	}
+	if bestDist > 2 {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
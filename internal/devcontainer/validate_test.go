@@ -0,0 +1,162 @@
+package devcontainer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateValidConfig(t *testing.T) {
+	var config map[string]any
+	err := json.Unmarshal([]byte(`{
+  "name": "test",
+  "image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+  "features": {"ghcr.io/devcontainers/features/node:1": {"version": "lts"}},
+  "forwardPorts": [3000, "db:5432"],
+  "postCreateCommand": ["npm", "install"],
+  "postStartCommand": {"server": "npm start", "watch": ["npm", "run", "watch"]},
+  "shutdownAction": "stopContainer",
+  "hostRequirements": {"cpus": 4, "memory": "8gb"},
+  "customizations": {"vscode": {"extensions": ["golang.go"]}},
+  "mounts": ["source=x,target=/x,type=bind", {"source": "y", "target": "/y", "type": "volume"}],
+  "myVendorKey": {"anything": true}
+}`), &config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if errs := Validate(config); len(errs) != 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateReportsIssues(t *testing.T) {
+	config := map[string]any{
+		"remoteUsr":        "vscode",
+		"shutdownAction":   "halt",
+		"init":             "yes",
+		"forwardPorts":     []any{float64(3000), float64(70000)},
+		"hostRequirements": map[string]any{"cpus": float64(2.5), "memroy": "8gb"},
+		"capAdd":           []string{"SYS_PTRACE"},
+	}
+
+	errs := Validate(config)
+
+	want := map[string]bool{
+		"forwardPorts[1]":         true,
+		"hostRequirements.cpus":   true,
+		"hostRequirements.memroy": true,
+		"init":                    true,
+		"remoteUsr":               true,
+		"shutdownAction":          true,
+	}
+	got := make(map[string]bool)
+	for _, e := range errs {
+		got[e.Path] = true
+	}
+	for path := range want {
+		if !got[path] {
+			t.Errorf("missing error for %s (got %v)", path, errs)
+		}
+	}
+	if len(errs) != len(want) {
+		t.Errorf("got %d errors, want %d: %v", len(errs), len(want), errs)
+	}
+}
+
+func TestValidateSuggestsKnownKey(t *testing.T) {
+	errs := Validate(map[string]any{"remoteUsr": "vscode"})
+	if len(errs) != 1 {
+		t.Fatalf("got %v, want one error", errs)
+	}
+	if want := `unknown property (did you mean "remoteUser"?)`; errs[0].Message != want {
+		t.Errorf("message = %q, want %q", errs[0].Message, want)
+	}
+}
+
+func TestValidateToleratesCustomKeys(t *testing.T) {
+	config := map[string]any{
+		"name":              "test",
+		"x-company-profile": "backend",
+		"telemetry":         map[string]any{"enabled": false},
+	}
+	if errs := Validate(config); len(errs) != 0 {
+		t.Errorf("custom keys should be tolerated, got %v", errs)
+	}
+}
+
+func TestValidatePublishedSchemaLayout(t *testing.T) {
+	data, err := os.ReadFile("testdata/base.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := parseSchema(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	valid := []map[string]any{
+		{"image": "ubuntu", "forwardPorts": []any{float64(3000), "db:5432"}, "appPort": "8080", "x-acme": true},
+		{"build": map[string]any{"dockerfile": "Dockerfile", "args": map[string]any{"GO": "1.23"}}, "shutdownAction": "none"},
+		{"dockerFile": "Dockerfile", "context": ".."},
+		{"dockerComposeFile": []any{"compose.yml"}, "service": "app", "shutdownAction": "stopCompose"},
+		{"image": "ubuntu", "mounts": []any{"source=x,target=/x,type=bind", map[string]any{"type": "volume", "target": "/y"}}},
+	}
+	for _, config := range valid {
+		if errs := validateWith(root, config); len(errs) != 0 {
+			t.Errorf("%v: expected no errors, got %v", config, errs)
+		}
+	}
+
+	errs := validateWith(root, map[string]any{
+		"imagee":         "ubuntu",
+		"init":           "yes",
+		"forwardPorts":   []any{float64(70000)},
+		"shutdownAction": "halt",
+		"build":          map[string]any{"args": map[string]any{"GO": float64(1)}},
+		"mounts":         []any{map[string]any{"type": "tmpfs", "target": "/t"}},
+	})
+	want := []string{"build.args.GO", "forwardPorts[0]", "imagee", "init", "mounts[0].type", "shutdownAction"}
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Path)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("error paths = %v, want %v (%v)", got, want, errs)
+	}
+	for _, e := range errs {
+		if e.Path == "imagee" && !strings.Contains(e.Message, `"image"`) {
+			t.Errorf("imagee: %s, want a suggestion of image", e.Message)
+		}
+	}
+}
+
+func TestCachedSchema(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DCC_CACHE_DIR", dir)
+	if cachedSchema() != nil {
+		t.Fatal("cachedSchema without a cached file should be nil")
+	}
+
+	data, err := os.ReadFile("testdata/base.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, schemaCacheFile), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := cachedSchema()
+	if root == nil || root.Definitions["composeContainer"] == nil {
+		t.Fatalf("cachedSchema = %v, want the cached schema", root)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, schemaCacheFile), []byte("<html>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if cachedSchema() != nil {
+		t.Error("cachedSchema should ignore a cached file that doesn't parse")
+	}
+}
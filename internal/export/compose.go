@@ -0,0 +1,248 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ServiceName is the name of the service Compose writes.
+const ServiceName = "app"
+
+// composeService holds the fields of a compose service in the order they're
+// written.
+type composeService struct {
+	image       string
+	command     string
+	init        bool
+	privileged  bool
+	user        string
+	hostname    string
+	workingDir  string
+	networkMode string
+	shmSize     string
+	ports       []string
+	env         map[string]string
+	mounts      []mount  // long volume syntax
+	volumes     []string // short volume syntax from runArgs -v
+	capAdd      []string
+	capDrop     []string
+	securityOpt []string
+	devices     []string
+	extraHosts  []string
+	dns         []string
+	labels      []string
+}
+
+// Compose returns a compose file with one service, ServiceName, that runs the
+// container of config with workspace mounted as its workspace folder, and
+// warnings about what isn't translated. Like the devcontainer CLI it keeps
+// the container running with `sleep infinity` unless overrideCommand is
+// false. The common runArgs (--cap-add, --network, -e, -v, ...) become
+// service fields, others are reported.
+func Compose(config map[string]any, workspace string) ([]byte, []string, error) {
+	c, err := readContainer(config, workspace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s := composeService{
+		image:       c.image,
+		init:        c.init,
+		privileged:  c.privileged,
+		workingDir:  c.workingDir,
+		ports:       c.ports,
+		env:         c.env,
+		mounts:      c.mounts,
+		capAdd:      c.capAdd,
+		securityOpt: c.securityOpt,
+	}
+	if override, ok := config["overrideCommand"].(bool); !ok || override {
+		s.command = "sleep infinity"
+	}
+	warnings := append(c.warnings, s.addRunArgs(c.runArgs)...)
+
+	var b strings.Builder
+	b.WriteString("services:\n  " + ServiceName + ":\n")
+	w := yamlWriter{b: &b, indent: "    "}
+	w.scalar("image", s.image)
+	if s.command != "" {
+		w.scalar("command", s.command)
+	}
+	w.flag("init", s.init)
+	w.flag("privileged", s.privileged)
+	w.scalar("user", s.user)
+	w.scalar("hostname", s.hostname)
+	w.scalar("working_dir", s.workingDir)
+	w.scalar("network_mode", s.networkMode)
+	w.scalar("shm_size", s.shmSize)
+	w.list("ports", s.ports)
+	if len(s.env) > 0 {
+		b.WriteString("    environment:\n")
+		for _, k := range sortedKeys(s.env) {
+			fmt.Fprintf(&b, "      %s: %s\n", yamlString(k), yamlString(s.env[k]))
+		}
+	}
+	if len(s.mounts)+len(s.volumes) > 0 {
+		b.WriteString("    volumes:\n")
+		for _, m := range s.mounts {
+			fields, skipped := composeVolume(m)
+			for i, f := range fields {
+				prefix := "        "
+				if i == 0 {
+					prefix = "      - "
+				}
+				b.WriteString(prefix + f + "\n")
+			}
+			for _, part := range skipped {
+				warnings = append(warnings, fmt.Sprintf("mount option %q is skipped in compose", part))
+			}
+		}
+		for _, v := range s.volumes {
+			b.WriteString("      - " + yamlString(v) + "\n")
+		}
+	}
+	w.list("cap_add", s.capAdd)
+	w.list("cap_drop", s.capDrop)
+	w.list("security_opt", s.securityOpt)
+	w.list("devices", s.devices)
+	w.list("extra_hosts", s.extraHosts)
+	w.list("dns", s.dns)
+	w.list("labels", s.labels)
+	return []byte(b.String()), warnings, nil
+}
+
+// addRunArgs moves the docker run flags compose has fields for into s and
+// returns warnings for the others.
+func (s *composeService) addRunArgs(args []string) []string {
+	lists := map[string]*[]string{
+		"--cap-add":      &s.capAdd,
+		"--cap-drop":     &s.capDrop,
+		"--security-opt": &s.securityOpt,
+		"--device":       &s.devices,
+		"--add-host":     &s.extraHosts,
+		"--dns":          &s.dns,
+		"--label":        &s.labels,
+		"-l":             &s.labels,
+		"--publish":      &s.ports,
+		"-p":             &s.ports,
+		"--volume":       &s.volumes,
+		"-v":             &s.volumes,
+	}
+	scalars := map[string]*string{
+		"--network":  &s.networkMode,
+		"--net":      &s.networkMode,
+		"--hostname": &s.hostname,
+		"-h":         &s.hostname,
+		"--shm-size": &s.shmSize,
+		"--user":     &s.user,
+		"-u":         &s.user,
+	}
+	flags := map[string]*bool{
+		"--privileged": &s.privileged,
+		"--init":       &s.init,
+	}
+
+	var warnings []string
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if b, ok := flags[name]; ok {
+			*b = !hasValue || value == "true"
+			continue
+		}
+		// The value follows the flag unless given with "=".
+		takesValue := lists[name] != nil || scalars[name] != nil || name == "-e" || name == "--env" || name == "--mount"
+		if takesValue && !hasValue && i+1 < len(args) {
+			i++
+			value, hasValue = args[i], true
+		}
+		switch {
+		case !hasValue && takesValue:
+			warnings = append(warnings, fmt.Sprintf("runArgs %s has no value and is skipped", name))
+		case lists[name] != nil:
+			*lists[name] = append(*lists[name], value)
+		case scalars[name] != nil:
+			*scalars[name] = value
+		case name == "-e" || name == "--env":
+			if k, v, ok := strings.Cut(value, "="); ok {
+				s.env[k] = v
+			} else {
+				warnings = append(warnings, fmt.Sprintf("runArgs %s %s passes a host variable and is skipped", name, value))
+			}
+		case name == "--mount":
+			s.mounts = append(s.mounts, parseMount(value))
+		default:
+			// An unknown flag's value most likely follows it.
+			arg := args[i]
+			if !hasValue && strings.HasPrefix(arg, "-") && i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				i++
+				arg += " " + args[i]
+			}
+			warnings = append(warnings, fmt.Sprintf("runArgs %s is not translated to compose", arg))
+		}
+	}
+	return warnings
+}
+
+// composeVolume writes m in the long volume syntax, one "key: value" per
+// line, and returns the parts compose has no field for.
+func composeVolume(m mount) (fields, skipped []string) {
+	for _, p := range m {
+		switch p.key {
+		case "type", "consistency":
+			fields = append(fields, p.key+": "+yamlString(p.value))
+		case "source", "src":
+			fields = append(fields, "source: "+yamlString(p.value))
+		case "target", "dst", "destination":
+			fields = append(fields, "target: "+yamlString(p.value))
+		case "readonly", "ro":
+			if p.value == "" || p.value == "true" || p.value == "1" {
+				fields = append(fields, "read_only: true")
+			}
+		default:
+			skipped = append(skipped, (mount{p}).String())
+		}
+	}
+	return fields, skipped
+}
+
+// yamlWriter writes the scalar and list fields of a compose service.
+type yamlWriter struct {
+	b      *strings.Builder
+	indent string
+}
+
+// scalar writes key unless value is empty.
+func (w yamlWriter) scalar(key, value string) {
+	if value != "" {
+		fmt.Fprintf(w.b, "%s%s: %s\n", w.indent, key, yamlString(value))
+	}
+}
+
+// flag writes key if it is set.
+func (w yamlWriter) flag(key string, value bool) {
+	if value {
+		fmt.Fprintf(w.b, "%s%s: true\n", w.indent, key)
+	}
+}
+
+// list writes key unless values is empty.
+func (w yamlWriter) list(key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	fmt.Fprintf(w.b, "%s%s:\n", w.indent, key)
+	for _, v := range values {
+		fmt.Fprintf(w.b, "%s  - %s\n", w.indent, yamlString(v))
+	}
+}
+
+// yamlString quotes s for YAML, whose double-quoted strings accept JSON
+// escapes, and doubles "$" so compose doesn't interpolate it.
+func yamlString(s string) string {
+	var b strings.Builder
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.Encode(strings.ReplaceAll(s, "$", "$$")) //nolint:errcheck // strings always encode
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -0,0 +1,123 @@
+package export
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompose(t *testing.T) {
+	data, warnings, err := Compose(map[string]any{
+		"image":        "mcr.microsoft.com/devcontainers/go:1",
+		"forwardPorts": []any{3000.0},
+		"containerEnv": map[string]any{"PRICE": "$5", "GREETING": "hi"},
+		"mounts":       []any{"source=cache,target=/cache,type=volume,readonly"},
+		"privileged":   true,
+		"init":         true,
+		"capAdd":       []any{"SYS_PTRACE"},
+		"securityOpt":  []any{"seccomp=unconfined"},
+	}, "/home/me/proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 0 {
+		t.Errorf("warnings = %q, want none", warnings)
+	}
+	want := `services:
+  app:
+    image: "mcr.microsoft.com/devcontainers/go:1"
+    command: "sleep infinity"
+    init: true
+    privileged: true
+    working_dir: "/workspaces/proj"
+    ports:
+      - "3000:3000"
+    environment:
+      "GREETING": "hi"
+      "PRICE": "$$5"
+    volumes:
+      - type: "bind"
+        source: "/home/me/proj"
+        target: "/workspaces/proj"
+      - source: "cache"
+        target: "/cache"
+        type: "volume"
+        read_only: true
+    cap_add:
+      - "SYS_PTRACE"
+    security_opt:
+      - "seccomp=unconfined"
+`
+	if string(data) != want {
+		t.Errorf("Compose =\n%s\nwant\n%s", data, want)
+	}
+}
+
+func TestComposeOverrideCommand(t *testing.T) {
+	data, _, err := Compose(map[string]any{"image": "debian", "overrideCommand": false}, "/w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "command:") {
+		t.Errorf("overrideCommand false still writes a command:\n%s", data)
+	}
+}
+
+func TestComposeRunArgs(t *testing.T) {
+	data, warnings, err := Compose(map[string]any{
+		"image": "debian",
+		"runArgs": []any{
+			"--cap-add=NET_ADMIN", "--cap-drop", "MKNOD", "--network", "host", "--hostname=dev",
+			"-e", "MODE=debug", "-v", "/var/run/docker.sock:/var/run/docker.sock",
+			"--add-host", "api.local:127.0.0.1", "--device=/dev/fuse", "--shm-size=1g", "-u", "1000",
+			"--label", "team=infra", "--dns", "1.1.1.1", "--mount", "type=tmpfs,target=/tmp",
+			"--gpus", "all", "-e", "HOME",
+		},
+	}, "/w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+	for _, want := range []string{
+		"    user: \"1000\"\n",
+		"    hostname: \"dev\"\n",
+		"    network_mode: \"host\"\n",
+		"    shm_size: \"1g\"\n",
+		"      \"MODE\": \"debug\"\n",
+		"      - type: \"tmpfs\"\n        target: \"/tmp\"\n",
+		"      - \"/var/run/docker.sock:/var/run/docker.sock\"\n",
+		"    cap_add:\n      - \"NET_ADMIN\"\n",
+		"    cap_drop:\n      - \"MKNOD\"\n",
+		"    devices:\n      - \"/dev/fuse\"\n",
+		"    extra_hosts:\n      - \"api.local:127.0.0.1\"\n",
+		"    dns:\n      - \"1.1.1.1\"\n",
+		"    labels:\n      - \"team=infra\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("compose lacks %q:\n%s", want, out)
+		}
+	}
+	joined := strings.Join(warnings, "\n")
+	if len(warnings) != 2 || !strings.Contains(joined, "--gpus all is not translated") || !strings.Contains(joined, "-e HOME passes a host variable") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestComposeSkippedMountOption(t *testing.T) {
+	_, warnings, err := Compose(map[string]any{
+		"image":  "debian",
+		"mounts": []any{"type=bind,source=/a,target=/b,bind-propagation=rslave"},
+	}, "/w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "bind-propagation=rslave") {
+		t.Errorf("warnings = %q", warnings)
+	}
+}
+
+func TestComposeNeedsImage(t *testing.T) {
+	if _, _, err := Compose(map[string]any{"build": map[string]any{"dockerfile": "Dockerfile"}}, "/w"); !errors.Is(err, ErrNoImage) {
+		t.Errorf("error = %v, want ErrNoImage", err)
+	}
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/shellquote"
+)
+
+// DockerRun returns the docker run arguments, starting with "docker", that
+// run the container of config with workspace mounted as its workspace
+// folder, and warnings about what isn't translated. runArgs are passed as
+// they are; ${localEnv:...} variables take the value of the environment dcc
+// runs in.
+func DockerRun(config map[string]any, workspace string) ([]string, []string, error) {
+	c, err := readContainer(config, workspace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	args := append([]string(nil), runPrefix...)
+	if c.init {
+		args = append(args, "--init")
+	}
+	if c.privileged {
+		args = append(args, "--privileged")
+	}
+	for _, capability := range c.capAdd {
+		args = append(args, "--cap-add", capability)
+	}
+	for _, opt := range c.securityOpt {
+		args = append(args, "--security-opt", opt)
+	}
+	for _, p := range c.ports {
+		args = append(args, "-p", p)
+	}
+	for _, k := range sortedKeys(c.env) {
+		args = append(args, "-e", k+"="+c.env[k])
+	}
+	for _, m := range c.mounts {
+		args = append(args, "--mount", m.String())
+	}
+	args = append(args, "-w", c.workingDir)
+	args = append(args, c.runArgs...)
+	args = append(args, c.image)
+	return args, c.warnings, nil
+}
+
+// runPrefix is the start of every DockerRun command, kept on one line by
+// FormatCommand.
+var runPrefix = []string{"docker", "run", "--rm", "-it"}
+
+// FormatCommand renders the arguments of DockerRun as a shell command with
+// each further option and the image on a line of their own, quoted where the
+// shell would split or expand them.
+func FormatCommand(args []string) string {
+	var b strings.Builder
+	for i, arg := range args {
+		switch {
+		case i == 0:
+		case i >= len(runPrefix) && (strings.HasPrefix(arg, "-") || i == len(args)-1):
+			b.WriteString(" \\\n  ")
+		default:
+			b.WriteString(" ")
+		}
+		b.WriteString(shellquote.Quote(arg))
+	}
+	return b.String()
+}
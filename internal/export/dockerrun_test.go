@@ -0,0 +1,112 @@
+package export
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// runOptions returns the arguments of DockerRun between the fixed prefix and
+// the image.
+func runOptions(t *testing.T, config map[string]any) []string {
+	t.Helper()
+	args, _, err := DockerRun(config, "/home/me/proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(args[:len(runPrefix)], runPrefix) {
+		t.Fatalf("args start with %v, want %v", args[:len(runPrefix)], runPrefix)
+	}
+	if image := args[len(args)-1]; image != config["image"] {
+		t.Fatalf("last arg = %q, want the image", image)
+	}
+	return args[len(runPrefix) : len(args)-1]
+}
+
+func TestDockerRunMappings(t *testing.T) {
+	t.Setenv("DCC_EXPORT_TOKEN", "s3cret")
+	const image = "mcr.microsoft.com/devcontainers/base:ubuntu"
+	workspace := []string{"--mount", "type=bind,source=/home/me/proj,target=/workspaces/proj", "-w", "/workspaces/proj"}
+	tests := []struct {
+		name   string
+		config map[string]any
+		want   []string
+	}{
+		{"image only", map[string]any{}, workspace},
+		{"forwardPorts", map[string]any{"forwardPorts": []any{3000.0, 8080}}, append([]string{"-p", "3000:3000", "-p", "8080:8080"}, workspace...)},
+		{"containerEnv sorted", map[string]any{"containerEnv": map[string]any{"B": "2", "A": "${localEnv:DCC_EXPORT_TOKEN}"}},
+			append([]string{"-e", "A=s3cret", "-e", "B=2"}, workspace...)},
+		{"mounts", map[string]any{"mounts": []any{
+			"source=${localWorkspaceFolder}/.cache,target=/cache,type=bind",
+			map[string]any{"type": "volume", "source": "${localWorkspaceFolderBasename}-node", "target": "${containerWorkspaceFolder}/node_modules"},
+		}}, []string{
+			"--mount", "type=bind,source=/home/me/proj,target=/workspaces/proj",
+			"--mount", "source=/home/me/proj/.cache,target=/cache,type=bind",
+			"--mount", "type=volume,source=proj-node,target=/workspaces/proj/node_modules",
+			"-w", "/workspaces/proj",
+		}},
+		{"runArgs after the options", map[string]any{"runArgs": []any{"--network=host", "--gpus", "all"}},
+			append(slices.Clone(workspace), "--network=host", "--gpus", "all")},
+		{"privileged", map[string]any{"privileged": true}, append([]string{"--privileged"}, workspace...)},
+		{"capAdd", map[string]any{"capAdd": []any{"SYS_PTRACE", "NET_ADMIN"}}, append([]string{"--cap-add", "SYS_PTRACE", "--cap-add", "NET_ADMIN"}, workspace...)},
+		{"securityOpt", map[string]any{"securityOpt": []any{"seccomp=unconfined"}}, append([]string{"--security-opt", "seccomp=unconfined"}, workspace...)},
+		{"init", map[string]any{"init": true}, append([]string{"--init"}, workspace...)},
+		{"init false", map[string]any{"init": false, "privileged": false}, workspace},
+		{"workspaceMount and workspaceFolder", map[string]any{
+			"workspaceMount":  "source=${localWorkspaceFolder},target=/src,type=bind,consistency=cached",
+			"workspaceFolder": "/src",
+		}, []string{"--mount", "source=/home/me/proj,target=/src,type=bind,consistency=cached", "-w", "/src"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config["image"] = image
+			if got := runOptions(t, tt.config); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("options = %q\nwant      %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerRunWarnings(t *testing.T) {
+	_, warnings, err := DockerRun(map[string]any{
+		"image":             "debian",
+		"features":          map[string]any{"ghcr.io/devcontainers/features/node:1": map[string]any{}},
+		"postCreateCommand": "npm ci",
+		"forwardPorts":      []any{"db:5432"},
+		"containerEnv":      map[string]any{"PATH": "${containerEnv:PATH}:/opt/bin"},
+	}, "/w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	joined := strings.Join(warnings, "\n")
+	for _, want := range []string{"features is skipped", "postCreateCommand is skipped", `"db:5432"`, "${containerEnv:PATH} is left as is"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("warnings lack %q:\n%s", want, joined)
+		}
+	}
+}
+
+func TestDockerRunNeedsImage(t *testing.T) {
+	for _, config := range []map[string]any{
+		{"build": map[string]any{"dockerfile": "Dockerfile"}},
+		{"dockerComposeFile": "compose.yaml", "service": "app"},
+	} {
+		if _, _, err := DockerRun(config, "/w"); !errors.Is(err, ErrNoImage) {
+			t.Errorf("DockerRun(%v) error = %v, want ErrNoImage", config, err)
+		}
+	}
+}
+
+func TestFormatCommand(t *testing.T) {
+	got := FormatCommand([]string{"docker", "run", "--rm", "-it", "-e", "GREETING=hi there", "-e", "O'K=1", "-w", "/w", "debian"})
+	want := "docker run --rm -it \\\n" +
+		"  -e 'GREETING=hi there' \\\n" +
+		"  -e 'O'\\''K=1' \\\n" +
+		"  -w /w \\\n" +
+		"  debian"
+	if got != want {
+		t.Errorf("FormatCommand =\n%s\nwant\n%s", got, want)
+	}
+}
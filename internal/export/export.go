@@ -0,0 +1,237 @@
+// Package export translates an image-based devcontainer.json into a plain
+// `docker run` command or a Docker Compose service, for running the
+// container without a devcontainer tool. Only what Docker itself can do is
+// translated: features need a build, and lifecycle commands and IDE
+// customizations need a tool to run them, so they're reported as skipped.
+package export
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/mountspec"
+)
+
+// ErrNoImage is returned for configs that build their image (build,
+// dockerFile) or use Docker Compose.
+var ErrNoImage = errors.New("export needs an image-based config")
+
+// skippedKeys are the keys that need a devcontainer tool, with the reason
+// they're skipped.
+var skippedKeys = []struct{ key, reason string }{
+	{"features", "features need a build"},
+	{"initializeCommand", "lifecycle commands need a devcontainer tool"},
+	{"onCreateCommand", "lifecycle commands need a devcontainer tool"},
+	{"updateContentCommand", "lifecycle commands need a devcontainer tool"},
+	{"postCreateCommand", "lifecycle commands need a devcontainer tool"},
+	{"postStartCommand", "lifecycle commands need a devcontainer tool"},
+	{"postAttachCommand", "lifecycle commands need a devcontainer tool"},
+	{"remoteEnv", "remoteEnv applies to tool processes only"},
+}
+
+// variable matches a ${...} devcontainer variable.
+var variable = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// container is the part of a config plain Docker runs, with variables
+// expanded.
+type container struct {
+	image       string
+	ports       []string // "3000:3000"
+	mounts      []mount  // workspace mount first
+	env         map[string]string
+	capAdd      []string
+	securityOpt []string
+	privileged  bool
+	init        bool
+	runArgs     []string
+	workingDir  string
+	warnings    []string
+}
+
+// mount is a --mount value as ordered key=value parts, e.g. type=bind.
+type mount []mountPart
+
+type mountPart struct{ key, value string }
+
+func (m mount) String() string {
+	parts := make([]string, len(m))
+	for i, p := range m {
+		parts[i] = p.key
+		if p.value != "" {
+			parts[i] += "=" + p.value
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// parseMount splits a mount string in docker --mount syntax.
+func parseMount(s string) mount {
+	var m mount
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		m = append(m, mountPart{strings.TrimSpace(key), strings.TrimSpace(value)})
+	}
+	return m
+}
+
+// objectMount orders the keys of a mount object like the spec does, other
+// keys after them alphabetically.
+func objectMount(obj map[string]any) mount {
+	spec := mountspec.FromObject(obj)
+	m := parseMount(spec.String())
+	rest := make([]string, 0, len(spec.Fields))
+	for key := range spec.Fields {
+		rest = append(rest, key)
+	}
+	sort.Strings(rest)
+	for _, key := range rest {
+		m = append(m, mountPart{key, fmt.Sprint(spec.Fields[key])})
+	}
+	return m
+}
+
+// expander replaces devcontainer variables the way the devcontainer CLI
+// would for the workspace.
+type expander struct {
+	local     string // workspace folder on the host
+	container string // workspace folder in the container
+	warnings  []string
+	unknown   map[string]bool
+}
+
+func (e *expander) expand(s string) string {
+	return variable.ReplaceAllStringFunc(s, func(match string) string {
+		name := match[2 : len(match)-1]
+		switch name {
+		case "localWorkspaceFolder":
+			return e.local
+		case "localWorkspaceFolderBasename":
+			return filepath.Base(e.local)
+		case "containerWorkspaceFolder":
+			return e.container
+		case "containerWorkspaceFolderBasename":
+			return path.Base(e.container)
+		}
+		if rest, ok := strings.CutPrefix(name, "localEnv:"); ok {
+			env, def, _ := strings.Cut(rest, ":")
+			return lookupEnv(env, def)
+		}
+		if !e.unknown[match] {
+			e.unknown[match] = true
+			e.warnings = append(e.warnings, fmt.Sprintf("%s is left as is", match))
+		}
+		return match
+	})
+}
+
+// readContainer reads what Docker can run from config. workspace is the
+// host folder mounted as the workspace.
+func readContainer(config map[string]any, workspace string) (container, error) {
+	image, _ := config["image"].(string)
+	if image == "" {
+		return container{}, ErrNoImage
+	}
+
+	folder, _ := config["workspaceFolder"].(string)
+	if folder == "" {
+		folder = "/workspaces/" + filepath.Base(workspace)
+	}
+	e := &expander{local: workspace, unknown: make(map[string]bool)}
+	folder = e.expand(folder)
+	e.container = folder
+
+	c := container{image: e.expand(image), workingDir: folder, env: make(map[string]string)}
+
+	if wm, _ := config["workspaceMount"].(string); wm != "" {
+		c.mounts = append(c.mounts, parseMount(e.expand(wm)))
+	} else {
+		c.mounts = append(c.mounts, mount{{"type", "bind"}, {"source", workspace}, {"target", folder}})
+	}
+	mounts, _ := config["mounts"].([]any)
+	for _, m := range mounts {
+		switch m := m.(type) {
+		case string:
+			c.mounts = append(c.mounts, parseMount(e.expand(m)))
+		case map[string]any:
+			expanded := objectMount(m)
+			for i := range expanded {
+				expanded[i].value = e.expand(expanded[i].value)
+			}
+			c.mounts = append(c.mounts, expanded)
+		}
+	}
+
+	ports, _ := config["forwardPorts"].([]any)
+	for _, p := range ports {
+		switch p := p.(type) {
+		case float64:
+			c.ports = append(c.ports, fmt.Sprintf("%d:%d", int(p), int(p)))
+		case int:
+			c.ports = append(c.ports, fmt.Sprintf("%d:%d", p, p))
+		case string:
+			c.warnings = append(c.warnings, fmt.Sprintf("forwardPorts %q refers to another service and is skipped", p))
+		}
+	}
+
+	envs, _ := config["containerEnv"].(map[string]any)
+	for k, v := range envs {
+		if s, ok := v.(string); ok {
+			c.env[k] = e.expand(s)
+		}
+	}
+
+	c.capAdd = stringSlice(config, "capAdd")
+	c.securityOpt = stringSlice(config, "securityOpt")
+	for _, arg := range stringSlice(config, "runArgs") {
+		c.runArgs = append(c.runArgs, e.expand(arg))
+	}
+	c.privileged, _ = config["privileged"].(bool)
+	c.init, _ = config["init"].(bool)
+
+	for _, s := range skippedKeys {
+		if _, ok := config[s.key]; ok {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s is skipped: %s", s.key, s.reason))
+		}
+	}
+	c.warnings = append(c.warnings, e.warnings...)
+	return c, nil
+}
+
+func stringSlice(config map[string]any, key string) []string {
+	items, _ := config[key].([]any)
+	var result []string
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// lookupEnv resolves ${localEnv:NAME:default} from the environment dcc runs
+// in, as the devcontainer CLI does.
+func lookupEnv(name, def string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,42 @@
+package feature
+
+import (
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// Add reads the devcontainer.json, adds a single feature to the features map,
+// and writes it back. Other configured features are kept. If the feature is
+// already configured (at any version), its entry is replaced and the existing
+// options are merged with the new ones, new values winning on conflict.
+func Add(workspaceFolder string, f FeatureConfig) error {
+	config, configPath, err := devcontainer.ReadConfig(workspaceFolder)
+	if err != nil {
+		return err
+	}
+
+	featuresMap, _ := config["features"].(map[string]any)
+	if featuresMap == nil {
+		featuresMap = make(map[string]any)
+	}
+
+	options := make(map[string]any)
+	for ref, opts := range featuresMap {
+		if devcontainer.FeatureID(ref) != devcontainer.FeatureID(f.OciRef) {
+			continue
+		}
+		if m, ok := opts.(map[string]any); ok {
+			for k, v := range m {
+				options[k] = v
+			}
+		}
+		delete(featuresMap, ref)
+	}
+	for k, v := range f.Options {
+		options[k] = v
+	}
+
+	featuresMap[f.OciRef] = options
+	config["features"] = featuresMap
+
+	return devcontainer.WriteConfig(configPath, config)
+}
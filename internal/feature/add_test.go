@@ -0,0 +1,47 @@
+package feature
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+func TestAddReplacesOtherVersions(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".devcontainer"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err := devcontainer.WriteConfig(devcontainer.DefaultConfigPath(dir), map[string]any{
+		"features": map[string]any{
+			"localhost:5000/features/tool:1":                     map[string]any{"version": "1.2"},
+			"localhost:5000/features/other":                      map[string]any{},
+			"ghcr.io/devcontainers/features/node@sha256:abcdef0": map[string]any{"nodeGypDependencies": false},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Add(dir, FeatureConfig{OciRef: "localhost:5000/features/tool:2", Options: map[string]any{"flavor": "slim"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Add(dir, FeatureConfig{OciRef: "ghcr.io/devcontainers/features/node:1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	config, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"localhost:5000/features/tool:2":        map[string]any{"version": "1.2", "flavor": "slim"},
+		"localhost:5000/features/other":         map[string]any{},
+		"ghcr.io/devcontainers/features/node:1": map[string]any{"nodeGypDependencies": false},
+	}
+	if !reflect.DeepEqual(config["features"], want) {
+		t.Errorf("features = %v, want %v", config["features"], want)
+	}
+}
@@ -0,0 +1,68 @@
+package feature
+
+import (
+	"sort"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// Dependency is a feature required, directly or transitively, by a selected
+// feature's dependsOn.
+type Dependency struct {
+	FeatureConfig
+	Chain []string // refs from the selected feature down to this one
+}
+
+// DependsOnFunc returns the dependsOn map declared by a feature.
+type DependsOnFunc func(ociRef string) (map[string]map[string]any, error)
+
+// ResolveDependencies walks the dependsOn graph from roots and returns the
+// required features missing from configured, in discovery order. Each feature
+// is visited once, so mutually dependent features cannot loop. Features whose
+// metadata can't be loaded are skipped.
+func ResolveDependencies(roots []string, configured []FeatureConfig, dependsOn DependsOnFunc) []Dependency {
+	visited := make(map[string]bool)
+	for _, f := range configured {
+		visited[devcontainer.FeatureID(f.OciRef)] = true
+	}
+
+	type node struct {
+		ref   string
+		chain []string
+	}
+	queue := make([]node, 0, len(roots))
+	for _, ref := range roots {
+		visited[devcontainer.FeatureID(ref)] = true
+		queue = append(queue, node{ref: ref, chain: []string{ref}})
+	}
+
+	var deps []Dependency
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		required, err := dependsOn(n.ref)
+		if err != nil {
+			continue
+		}
+		refs := make([]string, 0, len(required))
+		for ref := range required {
+			refs = append(refs, ref)
+		}
+		sort.Strings(refs)
+
+		for _, ref := range refs {
+			if visited[devcontainer.FeatureID(ref)] {
+				continue
+			}
+			visited[devcontainer.FeatureID(ref)] = true
+			chain := append(append([]string(nil), n.chain...), ref)
+			deps = append(deps, Dependency{
+				FeatureConfig: FeatureConfig{OciRef: ref, Options: required[ref]},
+				Chain:         chain,
+			})
+			queue = append(queue, node{ref: ref, chain: chain})
+		}
+	}
+	return deps
+}
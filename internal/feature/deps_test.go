@@ -0,0 +1,48 @@
+package feature
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestResolveDependencies(t *testing.T) {
+	const (
+		python = "ghcr.io/devcontainers/features/python:1"
+		node   = "ghcr.io/devcontainers/features/node:1"
+		utils  = "ghcr.io/devcontainers/features/common-utils:2"
+		git    = "ghcr.io/devcontainers/features/git:1"
+		a      = "ghcr.io/example/features/a:1"
+		b      = "ghcr.io/example/features/b:1"
+	)
+	graph := map[string]map[string]map[string]any{
+		python: {utils: {"installZsh": false}, git: {}},
+		utils:  {git: {}},
+		a:      {b: {}},
+		b:      {a: {}}, // cycle
+	}
+	dependsOn := func(ref string) (map[string]map[string]any, error) {
+		if ref == node {
+			return nil, errors.New("registry unreachable")
+		}
+		return graph[ref], nil
+	}
+
+	configured := []FeatureConfig{{OciRef: python}, {OciRef: node}, {OciRef: "ghcr.io/devcontainers/features/git:0.9"}}
+	deps := ResolveDependencies([]string{python, node}, configured, dependsOn)
+	if len(deps) != 1 {
+		t.Fatalf("deps = %+v, want only common-utils (git is configured at another version)", deps)
+	}
+	if deps[0].OciRef != utils || deps[0].Options["installZsh"] != false {
+		t.Errorf("dep = %+v", deps[0].FeatureConfig)
+	}
+	if want := []string{python, utils}; !reflect.DeepEqual(deps[0].Chain, want) {
+		t.Errorf("chain = %v, want %v", deps[0].Chain, want)
+	}
+
+	// Mutually dependent features terminate.
+	deps = ResolveDependencies([]string{a}, []FeatureConfig{{OciRef: a}}, dependsOn)
+	if len(deps) != 1 || deps[0].OciRef != b {
+		t.Errorf("cycle deps = %+v, want just b", deps)
+	}
+}
@@ -0,0 +1,51 @@
+package feature
+
+import "github.com/mochlast/devcontainer-companion/internal/devcontainer"
+
+// Feature notes explain why a feature is configured. They are stored in
+// customizations.dcc.notes, a namespace owned by dcc, keyed by the feature
+// ref without its version so they survive version changes.
+
+// Notes returns the configured notes by their key.
+func Notes(config map[string]any) map[string]string {
+	raw, _ := devcontainer.Customization(config, "dcc")["notes"].(map[string]any)
+	notes := make(map[string]string, len(raw))
+	for key, v := range raw {
+		if s, ok := v.(string); ok && s != "" {
+			notes[key] = s
+		}
+	}
+	return notes
+}
+
+// Note returns the note of the feature ref, at any version.
+func Note(config map[string]any, ref string) string {
+	for key, note := range Notes(config) {
+		if devcontainer.FeatureID(key) == devcontainer.FeatureID(ref) {
+			return note
+		}
+	}
+	return ""
+}
+
+// writeNotes replaces the notes with one per feature that has a note: the
+// FeatureConfig's, or else the one already configured. Notes of features
+// not in the list are dropped, and empty namespaces are removed.
+func writeNotes(config map[string]any, features []FeatureConfig) {
+	notes := make(map[string]any)
+	for _, f := range features {
+		note := f.Note
+		if note == "" {
+			note = Note(config, f.OciRef)
+		}
+		if note != "" {
+			notes[devcontainer.FeatureID(f.OciRef)] = note
+		}
+	}
+
+	if len(notes) == 0 {
+		devcontainer.SetCustomization(config, "dcc", "notes", nil)
+		return
+	}
+	devcontainer.SetCustomization(config, "dcc", "notes", notes)
+}
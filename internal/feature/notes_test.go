@@ -0,0 +1,103 @@
+package feature
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+func TestReplaceAllNotes(t *testing.T) {
+	const (
+		node   = "ghcr.io/devcontainers/features/node:1"
+		python = "ghcr.io/devcontainers/features/python:1"
+		git    = "ghcr.io/devcontainers/features/git:1"
+	)
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".devcontainer"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err := devcontainer.WriteConfig(devcontainer.DefaultConfigPath(dir), map[string]any{
+		"features": map[string]any{node: map[string]any{}, python: map[string]any{}},
+		"customizations": map[string]any{
+			"dcc": map[string]any{"notes": map[string]any{
+				"ghcr.io/devcontainers/features/node":   "frontend build",
+				"ghcr.io/devcontainers/features/python": "scripts",
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// node moves to another version, python is dropped, git is new.
+	err = ReplaceAll(dir, []FeatureConfig{
+		{OciRef: "ghcr.io/devcontainers/features/node:2"},
+		{OciRef: git, Note: "release tagging"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"ghcr.io/devcontainers/features/node": "frontend build",
+		"ghcr.io/devcontainers/features/git":  "release tagging",
+	}
+	if got := Notes(config); !reflect.DeepEqual(got, want) {
+		t.Errorf("notes = %v, want %v", got, want)
+	}
+	if got := Note(config, git); got != "release tagging" {
+		t.Errorf("Note(%s) = %q", git, got)
+	}
+
+	// Without notes the dcc namespace disappears.
+	if err := ReplaceAll(dir, []FeatureConfig{{OciRef: python}}); err != nil {
+		t.Fatal(err)
+	}
+	config, _, _ = devcontainer.ReadConfig(dir)
+	if _, ok := config["customizations"]; ok {
+		t.Errorf("customizations = %v, want removed", config["customizations"])
+	}
+}
+
+func TestRemoveNotes(t *testing.T) {
+	const (
+		node   = "ghcr.io/devcontainers/features/node:1"
+		python = "ghcr.io/devcontainers/features/python:1"
+	)
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, ".devcontainer"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err := devcontainer.WriteConfig(devcontainer.DefaultConfigPath(dir), map[string]any{
+		"features": map[string]any{node: "lts", python: map[string]any{"version": "3.12"}},
+		"customizations": map[string]any{
+			"dcc": map[string]any{"notes": map[string]any{
+				"ghcr.io/devcontainers/features/node":   "frontend build",
+				"ghcr.io/devcontainers/features/python": "scripts",
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Remove(dir, []string{python}); err != nil {
+		t.Fatal(err)
+	}
+	config, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]any{node: "lts"}; !reflect.DeepEqual(config["features"], want) {
+		t.Errorf("features = %v, want %v", config["features"], want)
+	}
+	if want := map[string]string{"ghcr.io/devcontainers/features/node": "frontend build"}; !reflect.DeepEqual(Notes(config), want) {
+		t.Errorf("Notes = %v, want %v", Notes(config), want)
+	}
+}
@@ -0,0 +1,78 @@
+package feature
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+// officialImagePrefixes are the registries of the images the official
+// templates (ghcr.io/devcontainers/templates/<id>) build on, named after the
+// template ID.
+var officialImagePrefixes = []string{
+	"mcr.microsoft.com/devcontainers/",
+	"mcr.microsoft.com/vscode/devcontainers/",
+}
+
+// templateTools lists features an official template's image ships beyond the
+// tool in its name. Every devcontainers image includes common-utils.
+var templateTools = map[string][]string{
+	"javascript-node": {"node"},
+	"typescript-node": {"node"},
+	"anaconda":        {"conda", "python"},
+	"miniconda":       {"conda", "python"},
+	"java":            {"java"},
+	"java-8":          {"java"},
+	"cpp":             {"cmake"},
+	"universal":       {"python", "node", "java", "dotnet", "php", "ruby", "go", "rust", "hugo", "conda", "git-lfs", "docker-in-docker", "sshd", "kubectl-helm-minikube"},
+}
+
+// OfficialTemplate returns the ID of the official template whose image
+// config uses, e.g. "python" for mcr.microsoft.com/devcontainers/python:3.12,
+// or "" for other images.
+func OfficialTemplate(image string) string {
+	for _, prefix := range officialImagePrefixes {
+		if name, ok := strings.CutPrefix(image, prefix); ok {
+			name, _, _ = strings.Cut(name, "@")
+			name, _, _ = strings.Cut(name, ":")
+			if name != "" && !strings.Contains(name, "/") {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// Redundancies returns the refs whose feature the template likely provides
+// already, matching the feature ID against templateTools and as a word in the
+// template's ID, name and description. tmpl may be nil if its metadata could
+// not be fetched. It is a heuristic: a feature may still be wanted, e.g. to
+// pin another version.
+func Redundancies(templateID string, tmpl *registry.TemplateDefinition, refs []string) []string {
+	provided := map[string]bool{"common-utils": true, templateID: true}
+	for _, id := range templateTools[templateID] {
+		provided[id] = true
+	}
+	text := templateID
+	if tmpl != nil {
+		text += " " + tmpl.Name + " " + tmpl.Description
+	}
+
+	var redundant []string
+	for _, ref := range refs {
+		id := path.Base(devcontainer.FeatureID(ref))
+		if provided[id] || mentions(text, id) {
+			redundant = append(redundant, ref)
+		}
+	}
+	return redundant
+}
+
+// mentions reports whether word appears in text on its own, so "go" matches
+// "Develop Go applications" but not "Google".
+func mentions(text, word string) bool {
+	return regexp.MustCompile(`(?i)(^|[^\w-])` + regexp.QuoteMeta(word) + `($|[^\w-])`).MatchString(text)
+}
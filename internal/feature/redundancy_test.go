@@ -0,0 +1,53 @@
+package feature
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+func TestOfficialTemplate(t *testing.T) {
+	tests := map[string]string{
+		"mcr.microsoft.com/devcontainers/python:1-3.12-bookworm": "python",
+		"mcr.microsoft.com/devcontainers/javascript-node":        "javascript-node",
+		"mcr.microsoft.com/vscode/devcontainers/go@sha256:abc":   "go",
+		"mcr.microsoft.com/devcontainers/base/extra:1":           "",
+		"python:3.12":                    "",
+		"ghcr.io/devcontainers/python:1": "",
+		"":                               "",
+	}
+	for image, want := range tests {
+		if got := OfficialTemplate(image); got != want {
+			t.Errorf("OfficialTemplate(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestRedundancies(t *testing.T) {
+	const (
+		python = "ghcr.io/devcontainers/features/python:1"
+		node   = "ghcr.io/devcontainers/features/node:1"
+		goFeat = "ghcr.io/devcontainers/features/go:1"
+		utils  = "ghcr.io/devcontainers/features/common-utils:2"
+		dind   = "ghcr.io/devcontainers/features/docker-in-docker:2"
+		git    = "ghcr.io/devcontainers/features/git:1"
+	)
+	refs := []string{python, node, goFeat, utils, dind, git}
+
+	tmpl := &registry.TemplateDefinition{ID: "python", Name: "Python 3", Description: "Develop Python 3 applications."}
+	if got, want := Redundancies("python", tmpl, refs), []string{python, utils}; !reflect.DeepEqual(got, want) {
+		t.Errorf("python = %v, want %v", got, want)
+	}
+
+	// Without metadata the template ID and the built-in mapping still apply.
+	if got, want := Redundancies("typescript-node", nil, refs), []string{node, utils}; !reflect.DeepEqual(got, want) {
+		t.Errorf("typescript-node = %v, want %v", got, want)
+	}
+
+	// Description words count, but not as part of a longer word or ID.
+	alpine := &registry.TemplateDefinition{ID: "alpine", Name: "Alpine", Description: "Simple Alpine container with Git installed. Google-free, docker-in-docker-ready."}
+	if got, want := Redundancies("alpine", alpine, refs), []string{utils, git}; !reflect.DeepEqual(got, want) {
+		t.Errorf("alpine = %v, want %v", got, want)
+	}
+}
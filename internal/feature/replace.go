@@ -8,10 +8,12 @@ import (
 type FeatureConfig struct {
 	OciRef  string
 	Options map[string]any
+	Note    string // why the feature is configured, see Notes
 }
 
 // ReplaceAll reads the devcontainer.json, replaces the entire features map,
-// and writes it back. Features not present in the given list are removed.
+// and writes it back. Features not present in the given list are removed,
+// together with their notes; the notes of the others are kept.
 func ReplaceAll(workspaceFolder string, features []FeatureConfig) error {
 	config, configPath, err := devcontainer.ReadConfig(workspaceFolder)
 	if err != nil {
@@ -28,6 +30,29 @@ func ReplaceAll(workspaceFolder string, features []FeatureConfig) error {
 	}
 
 	config["features"] = featuresMap
+	writeNotes(config, features)
+
+	return devcontainer.WriteConfig(configPath, config)
+}
+
+// Remove reads the devcontainer.json, removes the features configured as
+// refs together with their notes, and writes it back. Other features are
+// kept as they are.
+func Remove(workspaceFolder string, refs []string) error {
+	config, configPath, err := devcontainer.ReadConfig(workspaceFolder)
+	if err != nil {
+		return err
+	}
+
+	featuresMap, _ := config["features"].(map[string]any)
+	for _, ref := range refs {
+		delete(featuresMap, ref)
+	}
+	kept := make([]FeatureConfig, 0, len(featuresMap))
+	for ref := range featuresMap {
+		kept = append(kept, FeatureConfig{OciRef: ref})
+	}
+	writeNotes(config, kept)
 
 	return devcontainer.WriteConfig(configPath, config)
 }
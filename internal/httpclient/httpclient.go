@@ -0,0 +1,58 @@
+// Package httpclient provides the HTTP client shared by the catalog,
+// marketplace and registry packages.
+package httpclient
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout applies when DCC_HTTP_TIMEOUT is unset or invalid.
+const DefaultTimeout = 15 * time.Second
+
+var (
+	shared     *http.Client
+	sharedOnce sync.Once
+)
+
+// Default returns the shared client. It honors HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY, uses the timeout from Timeout and records its requests after
+// EnableProfile.
+func Default() *http.Client {
+	sharedOnce.Do(func() {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyFromEnvironment
+		shared = &http.Client{
+			Transport: profileTransport{base: transport},
+			Timeout:   Timeout(),
+		}
+	})
+	return shared
+}
+
+// Timeout returns the request timeout from DCC_HTTP_TIMEOUT, which accepts a
+// Go duration ("30s", "1m") or a number of seconds.
+func Timeout() time.Duration {
+	val := os.Getenv("DCC_HTTP_TIMEOUT")
+	if val == "" {
+		return DefaultTimeout
+	}
+	if d, err := time.ParseDuration(val); err == nil && d > 0 {
+		return d
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return DefaultTimeout
+}
+
+// IsTimeout reports whether err is a request timeout.
+func IsTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
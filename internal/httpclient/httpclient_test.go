@@ -0,0 +1,25 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeout(t *testing.T) {
+	tests := []struct {
+		env  string
+		want time.Duration
+	}{
+		{"", DefaultTimeout},
+		{"30s", 30 * time.Second},
+		{"45", 45 * time.Second},
+		{"bogus", DefaultTimeout},
+		{"-5s", DefaultTimeout},
+	}
+	for _, tt := range tests {
+		t.Setenv("DCC_HTTP_TIMEOUT", tt.env)
+		if got := Timeout(); got != tt.want {
+			t.Errorf("DCC_HTTP_TIMEOUT=%q: Timeout() = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
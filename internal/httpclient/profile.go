@@ -0,0 +1,156 @@
+package httpclient
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+)
+
+// Timing is a request made through Default while profiling.
+type Timing struct {
+	Op       string // kind of operation, see operation
+	Method   string
+	URL      string
+	Status   int // 0 if the request failed
+	Duration time.Duration
+}
+
+// profiler records the requests of Default; nil when not profiling.
+type profiler struct {
+	log     io.Writer
+	mu      sync.Mutex
+	timings []Timing
+}
+
+var active atomic.Pointer[profiler]
+
+// EnableProfile starts recording every request made through Default and
+// logs each one to log as it completes.
+func EnableProfile(log io.Writer) {
+	active.Store(&profiler{log: log})
+}
+
+// Timings returns the requests recorded since EnableProfile, in the order
+// they completed.
+func Timings() []Timing {
+	p := active.Load()
+	if p == nil {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Timing(nil), p.timings...)
+}
+
+// WriteProfile writes a table of the recorded requests per operation: how
+// many, their total and slowest duration, and how many failed.
+func WriteProfile(w io.Writer) {
+	timings := Timings()
+	type summary struct {
+		count, failed  int
+		total, slowest time.Duration
+	}
+	byOp := make(map[string]*summary)
+	var ops []string
+	var total time.Duration
+	for _, t := range timings {
+		s := byOp[t.Op]
+		if s == nil {
+			s = &summary{}
+			byOp[t.Op] = s
+			ops = append(ops, t.Op)
+		}
+		s.count++
+		s.total += t.Duration
+		s.slowest = max(s.slowest, t.Duration)
+		if t.Status == 0 || t.Status >= 400 {
+			s.failed++
+		}
+		total += t.Duration
+	}
+	// Where the time went first.
+	sort.SliceStable(ops, func(i, j int) bool { return byOp[ops[i]].total > byOp[ops[j]].total })
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "operation\trequests\ttotal\tslowest\tfailed")
+	for _, op := range ops {
+		s := byOp[op]
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\n", op, s.count, roundDuration(s.total), roundDuration(s.slowest), s.failed)
+	}
+	fmt.Fprintf(tw, "all\t%d\t%s\n", len(timings), roundDuration(total))
+	tw.Flush()
+}
+
+func (p *profiler) record(t Timing) {
+	p.mu.Lock()
+	p.timings = append(p.timings, t)
+	p.mu.Unlock()
+	status := fmt.Sprint(t.Status)
+	if t.Status == 0 {
+		status = "failed"
+	}
+	fmt.Fprintf(p.log, "profile: %-11s %7s  %s %s %s\n", t.Op, roundDuration(t.Duration), status, t.Method, t.URL)
+}
+
+// profileTransport times the requests of base while a profiler is active.
+type profileTransport struct {
+	base http.RoundTripper
+}
+
+func (t profileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p := active.Load()
+	if p == nil {
+		return t.base.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	timing := Timing{
+		Op:       operation(req),
+		Method:   req.Method,
+		URL:      req.URL.Redacted(),
+		Duration: time.Since(start),
+	}
+	if err == nil {
+		timing.Status = resp.StatusCode
+	}
+	p.record(timing)
+	return resp, err
+}
+
+// operation names what a request is for: the catalog, a registry token,
+// manifest, blob or tag list, a marketplace query, or "other" (READMEs and
+// the like).
+func operation(req *http.Request) string {
+	host, path := req.URL.Hostname(), req.URL.Path
+	switch {
+	case host == "containers.dev":
+		return "catalog"
+	case host == "marketplace.visualstudio.com" || host == "plugins.jetbrains.com" ||
+		strings.HasSuffix(host, ".gallery.vsassets.io"):
+		return "marketplace"
+	case strings.Contains(path, "/manifests/"):
+		return "manifest"
+	case strings.Contains(path, "/blobs/"):
+		return "blob"
+	case strings.HasSuffix(path, "/tags/list"):
+		return "tags"
+	case path == "/token":
+		return "token"
+	}
+	return "other"
+}
+
+// roundDuration rounds d for display: to microseconds below a millisecond,
+// else to milliseconds.
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond)
+	}
+	return d.Round(time.Millisecond)
+}
@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestProfile(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/blobs/") {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	var log bytes.Buffer
+	EnableProfile(&log)
+	t.Cleanup(func() { active.Store(nil) })
+
+	for _, path := range []string{"/v2/devcontainers/features/node/manifests/1", "/v2/devcontainers/features/node/blobs/sha256:abc", "/v2/devcontainers/features/go/manifests/1"} {
+		resp, err := Default().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	timings := Timings()
+	if len(timings) != 3 {
+		t.Fatalf("recorded %d requests, want 3", len(timings))
+	}
+	if timings[0].Op != "manifest" || timings[1].Op != "blob" || timings[1].Status != http.StatusNotFound {
+		t.Errorf("timings = %+v", timings)
+	}
+	if got := strings.Count(log.String(), "profile: "); got != 3 {
+		t.Errorf("logged %d lines, want 3:\n%s", got, log.String())
+	}
+
+	var table bytes.Buffer
+	WriteProfile(&table)
+	lines := strings.Split(strings.TrimSpace(table.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("table has %d lines, want header, 2 operations and all:\n%s", len(lines), table.String())
+	}
+	if fields := strings.Fields(lines[3]); fields[0] != "all" || fields[1] != "3" {
+		t.Errorf("all row = %q", lines[3])
+	}
+	for _, line := range lines[1:3] {
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "manifest":
+			if fields[1] != "2" || fields[4] != "0" {
+				t.Errorf("manifest row = %q", line)
+			}
+		case "blob":
+			if fields[1] != "1" || fields[4] != "1" {
+				t.Errorf("blob row = %q, want 1 failed", line)
+			}
+		default:
+			t.Errorf("unexpected row %q", line)
+		}
+	}
+}
+
+func TestProfileInactive(t *testing.T) {
+	if got := Timings(); got != nil {
+		t.Errorf("Timings() = %v without EnableProfile", got)
+	}
+}
+
+func TestOperation(t *testing.T) {
+	tests := map[string]string{
+		"https://containers.dev/features":                                          "catalog",
+		"https://marketplace.visualstudio.com/_apis/public/gallery/extensionquery": "marketplace",
+		"https://plugins.jetbrains.com/api/plugins/123":                            "marketplace",
+		"https://ms-python.gallery.vsassets.io/_apis/public/gallery/publisher/x":   "marketplace",
+		"https://ghcr.io/token?scope=repository:devcontainers/features/node:pull":  "token",
+		"https://ghcr.io/v2/devcontainers/features/node/manifests/1":               "manifest",
+		"https://ghcr.io/v2/devcontainers/features/node/blobs/sha256:abc":          "blob",
+		"https://ghcr.io/v2/devcontainers/features/node/tags/list":                 "tags",
+		"https://raw.githubusercontent.com/devcontainers/features/main/README.md":  "other",
+	}
+	for url, want := range tests {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := operation(req); got != want {
+			t.Errorf("operation(%s) = %q, want %q", url, got, want)
+		}
+	}
+}
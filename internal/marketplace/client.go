@@ -2,12 +2,14 @@ package marketplace
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
-	"time"
+
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
 )
 
 const galleryURL = "https://marketplace.visualstudio.com/_apis/public/gallery/extensionquery"
@@ -48,8 +50,31 @@ type Extension struct {
 	Description  string
 	InstallCount int64
 	Rating       float64
+	PreRelease   bool               // the newest version returned is a pre-release
+	Versions     []ExtensionVersion // newest first, as far as the query returned them
+}
+
+// ExtensionVersion is a published version of an extension.
+type ExtensionVersion struct {
+	Version    string
+	PreRelease bool
+	Platforms  []string // target platforms with their own package; nil if universal
 }
 
+// Query flags, see the IncludeLatest* flags of the gallery API.
+const (
+	searchFlags             = 0x192 // IncludeAssetUri | IncludeInstallationTargets | IncludeSharedAccounts | IncludeVersions | IncludeStatistics
+	flagLatestVersionOnly   = 0x200
+	flagLatestAndPrerelease = 0x10000 // the newest stable and the newest pre-release version
+	versionsFlags           = 0x11    // IncludeVersions | IncludeVersionProperties
+)
+
+// preReleaseProperty marks a pre-release version in its properties.
+const preReleaseProperty = "Microsoft.VisualStudio.Code.PreRelease"
+
+// maxExtensionVersions caps the versions FetchExtensionVersions returns.
+const maxExtensionVersions = 20
+
 // queryRequest is the JSON body for the marketplace API.
 type queryRequest struct {
 	Filters []queryFilter `json:"filters"`
@@ -97,7 +122,15 @@ type statisticResult struct {
 }
 
 type versionResult struct {
-	Files []fileResult `json:"files"`
+	Version        string           `json:"version"`
+	TargetPlatform string           `json:"targetPlatform"`
+	Properties     []propertyResult `json:"properties"`
+	Files          []fileResult     `json:"files"`
+}
+
+type propertyResult struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
 }
 
 type fileResult struct {
@@ -105,8 +138,12 @@ type fileResult struct {
 	Source    string `json:"source"`
 }
 
-// Search queries the VS Code Marketplace for extensions matching the given term.
-func Search(query string, pageSize int, sortBy SortBy) ([]Extension, error) {
+// Search queries the VS Code Marketplace for extensions matching the given
+// term, retrying transient failures (see MaxAttempts). onRetry may be nil.
+// With preRelease the newest version of an extension may be a pre-release.
+// Cancelling ctx aborts the request and any pending retry. Errors wrap
+// ErrSearchFailed.
+func Search(ctx context.Context, query string, pageSize int, sortBy SortBy, preRelease bool, onRetry RetryFunc) ([]Extension, error) {
 	if pageSize <= 0 {
 		pageSize = 20
 	}
@@ -128,13 +165,140 @@ func Search(query string, pageSize int, sortBy SortBy) ([]Extension, error) {
 				SortOrder: sortOrder,
 			},
 		},
-		Flags: 0x192, // IncludeAssetUri | IncludeInstallationTargets | IncludeSharedAccounts | IncludeVersions | IncludeStatistics
+		Flags: searchQueryFlags(preRelease),
+	}
+
+	extensions, err := withRetry(ctx, onRetry, func() ([]Extension, error) {
+		return doQuery(ctx, reqBody)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSearchFailed, err)
+	}
+	return extensions, nil
+}
+
+// searchQueryFlags returns the flags of a search, which only needs the
+// newest version of each extension.
+func searchQueryFlags(preRelease bool) int {
+	if preRelease {
+		return searchFlags | flagLatestAndPrerelease
+	}
+	return searchFlags | flagLatestVersionOnly
+}
+
+// FetchExtensionVersions returns the published versions of an extension
+// (publisher.name), newest first and at most maxExtensionVersions. Pre-releases
+// are left out unless preRelease is set. Transient failures are retried like
+// Search.
+func FetchExtensionVersions(ctx context.Context, extensionID string, preRelease bool) ([]ExtensionVersion, error) {
+	reqBody := queryRequest{
+		Filters: []queryFilter{
+			{
+				Criteria: []filterCriteria{
+					{FilterType: 7, Value: extensionID}, // 7 = extension name
+					{FilterType: 8, Value: "Microsoft.VisualStudio.Code"},
+				},
+				PageSize: 1,
+			},
+		},
+		Flags: versionsFlags,
+	}
+
+	extensions, err := withRetry(ctx, nil, func() ([]Extension, error) {
+		return doQuery(ctx, reqBody)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(extensions) == 0 {
+		return nil, fmt.Errorf("extension %q: %w", extensionID, ErrNotFound)
+	}
+
+	var versions []ExtensionVersion
+	for _, v := range extensions[0].Versions {
+		if v.PreRelease && !preRelease {
+			continue
+		}
+		versions = append(versions, v)
+		if len(versions) == maxExtensionVersions {
+			break
+		}
 	}
+	return versions, nil
+}
 
-	return doQuery(reqBody)
+// extensionVersions merges the per-platform entries the gallery returns for
+// one version, keeping the order.
+func extensionVersions(results []versionResult) []ExtensionVersion {
+	var versions []ExtensionVersion
+	index := make(map[string]int)
+	for _, r := range results {
+		if r.Version == "" {
+			continue
+		}
+		i, ok := index[r.Version]
+		if !ok {
+			i = len(versions)
+			index[r.Version] = i
+			versions = append(versions, ExtensionVersion{Version: r.Version})
+		}
+		v := &versions[i]
+		if r.TargetPlatform != "" {
+			v.Platforms = append(v.Platforms, r.TargetPlatform)
+		}
+		for _, p := range r.Properties {
+			if p.Key == preReleaseProperty && p.Value == "true" {
+				v.PreRelease = true
+			}
+		}
+	}
+	return versions
+}
+
+// SplitExtensionRef splits a customizations.vscode.extensions entry of the
+// form "publisher.name" or "publisher.name@version" into its ID and (possibly
+// empty) version.
+func SplitExtensionRef(ref string) (id, version string) {
+	if idx := strings.LastIndex(ref, "@"); idx != -1 {
+		return ref[:idx], ref[idx+1:]
+	}
+	return ref, ""
+}
+
+// FormatExtensionRef joins an extension ID and optional version into an
+// extensions entry, which VS Code installs at exactly that version.
+func FormatExtensionRef(id, version string) string {
+	if version == "" {
+		return id
+	}
+	return id + "@" + version
+}
+
+// DedupeExtensions drops the extensions entries naming an extension listed
+// before. IDs are compared case-insensitively, as the Marketplace does, so
+// the casing and version of the first entry win.
+func DedupeExtensions(refs []string) []string {
+	return dedupeRefs(refs, SplitExtensionRef)
+}
+
+// dedupeRefs keeps the first of the refs whose IDs, split off by split,
+// differ only in case.
+func dedupeRefs(refs []string, split func(string) (id, version string)) []string {
+	seen := make(map[string]bool, len(refs))
+	kept := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		id, _ := split(ref)
+		if key := strings.ToLower(id); !seen[key] {
+			seen[key] = true
+			kept = append(kept, ref)
+		}
+	}
+	return kept
 }
 
 // FetchReadme fetches the README/detail content for a given extension ID (publisher.name).
+// HTML READMEs are converted to markdown. Transient failures are retried
+// like Search.
 func FetchReadme(extensionID string) (string, error) {
 	parts := strings.SplitN(extensionID, ".", 2)
 	if len(parts) != 2 {
@@ -148,46 +312,47 @@ func FetchReadme(extensionID string) (string, error) {
 		publisher, publisher, name,
 	)
 
-	client := &http.Client{Timeout: 8 * time.Second}
-	resp, err := client.Get(url)
-	if err != nil {
-		return "", fmt.Errorf("fetching extension README: %w", err)
-	}
-	defer resp.Body.Close()
+	return withRetry(context.Background(), nil, func() (string, error) {
+		resp, err := httpclient.Default().Get(url)
+		if err != nil {
+			return "", fmt.Errorf("fetching extension README: %w", err)
+		}
+		defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("fetching extension README: status %d", resp.StatusCode)
-	}
+		if resp.StatusCode != http.StatusOK {
+			return "", &StatusError{Op: "fetching extension README", Code: resp.StatusCode}
+		}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", fmt.Errorf("reading extension README: %w", err)
-	}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", fmt.Errorf("reading extension README: %w", err)
+		}
 
-	return string(body), nil
+		return readmeMarkdown(string(body)), nil
+	})
 }
 
-func doQuery(reqBody queryRequest) ([]Extension, error) {
+func doQuery(ctx context.Context, reqBody queryRequest) ([]Extension, error) {
 	bodyBytes, err := json.Marshal(reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("marshaling request: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", galleryURL, bytes.NewReader(bodyBytes))
+	req, err := http.NewRequestWithContext(ctx, "POST", galleryURL, bytes.NewReader(bodyBytes))
 	if err != nil {
 		return nil, fmt.Errorf("creating request: %w", err)
 	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json;api-version=3.0-preview.1")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpclient.Default().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("querying marketplace: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("marketplace returned status %d", resp.StatusCode)
+		return nil, &StatusError{Op: "querying marketplace", Code: resp.StatusCode}
 	}
 
 	respBytes, err := io.ReadAll(resp.Body)
@@ -207,6 +372,10 @@ func doQuery(reqBody queryRequest) ([]Extension, error) {
 				ID:          fmt.Sprintf("%s.%s", ext.Publisher.Name, ext.Name),
 				DisplayName: ext.DisplayName,
 				Description: ext.Description,
+				Versions:    extensionVersions(ext.Versions),
+			}
+			if len(e.Versions) > 0 {
+				e.PreRelease = e.Versions[0].PreRelease
 			}
 			for _, stat := range ext.Statistics {
 				switch stat.Name {
@@ -0,0 +1,59 @@
+package marketplace
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestExtensionVersions(t *testing.T) {
+	preRelease := []propertyResult{{Key: preReleaseProperty, Value: "true"}}
+	results := []versionResult{
+		{Version: "2.1.0", TargetPlatform: "linux-x64", Properties: preRelease},
+		{Version: "2.1.0", TargetPlatform: "darwin-arm64", Properties: preRelease},
+		{Version: "2.0.0"},
+		{Version: ""},
+	}
+	want := []ExtensionVersion{
+		{Version: "2.1.0", PreRelease: true, Platforms: []string{"linux-x64", "darwin-arm64"}},
+		{Version: "2.0.0"},
+	}
+	if got := extensionVersions(results); !reflect.DeepEqual(got, want) {
+		t.Errorf("extensionVersions() = %+v, want %+v", got, want)
+	}
+}
+
+func TestSearchQueryFlags(t *testing.T) {
+	if got := searchQueryFlags(false); got&flagLatestVersionOnly == 0 || got&flagLatestAndPrerelease != 0 {
+		t.Errorf("stable search flags = %#x", got)
+	}
+	if got := searchQueryFlags(true); got&flagLatestAndPrerelease == 0 || got&flagLatestVersionOnly != 0 {
+		t.Errorf("pre-release search flags = %#x", got)
+	}
+}
+
+func TestSplitExtensionRef(t *testing.T) {
+	tests := []struct {
+		ref, id, version string
+	}{
+		{"golang.go", "golang.go", ""},
+		{"golang.go@0.41.0", "golang.go", "0.41.0"},
+	}
+	for _, tt := range tests {
+		id, version := SplitExtensionRef(tt.ref)
+		if id != tt.id || version != tt.version {
+			t.Errorf("SplitExtensionRef(%q) = (%q, %q), want (%q, %q)", tt.ref, id, version, tt.id, tt.version)
+		}
+		if got := FormatExtensionRef(id, version); got != tt.ref {
+			t.Errorf("FormatExtensionRef(%q, %q) = %q, want %q", id, version, got, tt.ref)
+		}
+	}
+}
+
+func TestDedupeExtensions(t *testing.T) {
+	refs := []string{"ms-python.Python@2024.2.0", "golang.go", "ms-python.python", "GoLang.Go@0.41.0"}
+	want := []string{"ms-python.Python@2024.2.0", "golang.go"}
+	if got := DedupeExtensions(refs); !slices.Equal(got, want) {
+		t.Errorf("DedupeExtensions = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,240 @@
+package marketplace
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	htmlBlockTag  = regexp.MustCompile(`(?i)<(html|body|div|p|h[1-6]|ul|ol|table|pre)[\s>]`)
+	htmlPreBlock  = regexp.MustCompile(`(?is)<pre.*?</pre>`)
+	markdownBlock = regexp.MustCompile("(?m)^(#{1,6} |```)")
+	blankLines    = regexp.MustCompile(`\n{3,}`)
+	spaceRun      = regexp.MustCompile(`\s+`)
+)
+
+// looksLikeHTML reports whether a README is an HTML document rather than
+// markdown. Markdown READMEs often embed HTML (centered logos, badges), so
+// content with markdown headings or code fences is treated as markdown.
+func looksLikeHTML(s string) bool {
+	if !htmlBlockTag.MatchString(s) {
+		return false
+	}
+	return !markdownBlock.MatchString(htmlPreBlock.ReplaceAllString(s, ""))
+}
+
+// readmeMarkdown converts an HTML README to markdown for glamour and returns
+// markdown unchanged.
+func readmeMarkdown(s string) string {
+	if !looksLikeHTML(s) {
+		return s
+	}
+	doc, err := html.Parse(strings.NewReader(s))
+	if err != nil {
+		return s
+	}
+	return cleanMarkdown(convertNode(doc))
+}
+
+// convertNode renders n and its children as markdown. Block elements are
+// surrounded by blank lines; cleanMarkdown squashes the excess.
+func convertNode(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return spaceRun.ReplaceAllString(n.Data, " ")
+	case html.ElementNode:
+	default:
+		return convertChildren(n)
+	}
+
+	switch n.Data {
+	case "script", "style", "head", "title":
+		return ""
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(n.Data[1] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + inlineText(n) + "\n\n"
+	case "p", "div", "section", "article", "header", "footer", "main", "details", "summary":
+		return "\n\n" + strings.TrimSpace(convertChildren(n)) + "\n\n"
+	case "br":
+		return "\n"
+	case "hr":
+		return "\n\n---\n\n"
+	case "pre":
+		return "\n\n```" + codeLanguage(n) + "\n" + strings.Trim(textContent(n), "\n") + "\n```\n\n"
+	case "code", "kbd", "samp":
+		if text := textContent(n); text != "" {
+			return "`" + text + "`"
+		}
+		return ""
+	case "strong", "b":
+		return wrapInline(n, "**")
+	case "em", "i":
+		return wrapInline(n, "*")
+	case "a":
+		text, href := inlineText(n), attr(n, "href")
+		if href == "" || strings.HasPrefix(href, "#") {
+			return text
+		}
+		if text == "" {
+			text = href
+		}
+		return fmt.Sprintf("[%s](%s)", text, href)
+	case "img":
+		if src := attr(n, "src"); src != "" {
+			return fmt.Sprintf("![%s](%s)", attr(n, "alt"), src)
+		}
+		return attr(n, "alt")
+	case "ul", "ol":
+		return "\n\n" + convertList(n) + "\n\n"
+	case "blockquote":
+		body := cleanMarkdown(convertChildren(n))
+		return "\n\n> " + strings.ReplaceAll(body, "\n", "\n> ") + "\n\n"
+	case "table":
+		return "\n\n" + convertTable(n) + "\n\n"
+	}
+	return convertChildren(n)
+}
+
+func convertChildren(n *html.Node) string {
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(convertNode(c))
+	}
+	return b.String()
+}
+
+// convertList renders the li children of a ul or ol, indenting nested content
+// under its item.
+func convertList(n *html.Node) string {
+	var lines []string
+	num := 1
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode || c.Data != "li" {
+			continue
+		}
+		marker := "- "
+		if n.Data == "ol" {
+			marker = fmt.Sprintf("%d. ", num)
+			num++
+		}
+		body := strings.ReplaceAll(cleanMarkdown(convertChildren(c)), "\n\n", "\n")
+		indent := strings.Repeat(" ", len(marker))
+		lines = append(lines, marker+strings.ReplaceAll(body, "\n", "\n"+indent))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// convertTable renders a table as a markdown table with the first row as its
+// header.
+func convertTable(n *html.Node) string {
+	var rows [][]string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "tr" {
+			var cells []string
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type == html.ElementNode && (c.Data == "td" || c.Data == "th") {
+					cells = append(cells, strings.ReplaceAll(inlineText(c), "|", `\|`))
+				}
+			}
+			rows = append(rows, cells)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, row := range rows {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+		if i == 0 {
+			b.WriteString("|" + strings.Repeat(" --- |", len(row)) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// inlineText renders n's children on a single line.
+func inlineText(n *html.Node) string {
+	return strings.TrimSpace(spaceRun.ReplaceAllString(convertChildren(n), " "))
+}
+
+func wrapInline(n *html.Node, marker string) string {
+	if text := inlineText(n); text != "" {
+		return marker + text + marker
+	}
+	return ""
+}
+
+// textContent returns the raw text below n, keeping whitespace.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.WriteString(textContent(c))
+	}
+	return b.String()
+}
+
+// codeLanguage reads a "language-x" or "lang-x" class from a pre element or
+// its code child.
+func codeLanguage(pre *html.Node) string {
+	nodes := []*html.Node{pre}
+	for c := pre.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.ElementNode && c.Data == "code" {
+			nodes = append(nodes, c)
+		}
+	}
+	for _, n := range nodes {
+		for _, class := range strings.Fields(attr(n, "class")) {
+			for _, prefix := range []string{"language-", "lang-"} {
+				if lang, ok := strings.CutPrefix(class, prefix); ok {
+					return lang
+				}
+			}
+		}
+	}
+	return ""
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// cleanMarkdown trims stray spaces left by HTML indentation outside code
+// fences and collapses runs of blank lines.
+func cleanMarkdown(s string) string {
+	lines := strings.Split(s, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			lines[i] = strings.TrimSpace(line)
+			continue
+		}
+		if inFence {
+			continue
+		}
+		line = strings.TrimRight(line, " ")
+		if strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "  ") {
+			line = line[1:]
+		}
+		lines[i] = line
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
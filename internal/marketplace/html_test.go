@@ -0,0 +1,51 @@
+package marketplace
+
+import "testing"
+
+func TestReadmeMarkdown(t *testing.T) {
+	in := `<div>
+  <h1>My Extension</h1>
+  <p>Adds <strong>syntax</strong> support. See <a href="https://example.com/docs">the docs</a>.</p>
+  <ul>
+    <li>Highlighting</li>
+    <li>Snippets
+      <ul><li>Nested</li></ul>
+    </li>
+  </ul>
+  <pre><code class="language-json">{
+  "a": 1
+}</code></pre>
+  <table><tr><th>Key</th><th>Default</th></tr><tr><td>x.enabled</td><td><code>true</code></td></tr></table>
+</div>`
+	want := "# My Extension\n\n" +
+		"Adds **syntax** support. See [the docs](https://example.com/docs).\n\n" +
+		"- Highlighting\n" +
+		"- Snippets\n" +
+		"  - Nested\n\n" +
+		"```json\n{\n  \"a\": 1\n}\n```\n\n" +
+		"| Key | Default |\n" +
+		"| --- | --- |\n" +
+		"| x.enabled | `true` |"
+
+	if got := readmeMarkdown(in); got != want {
+		t.Errorf("readmeMarkdown() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestLooksLikeHTML(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"<div><p>Hello</p></div>", true},
+		{"<p>Run:</p><pre>\n# install\nnpm i\n</pre>", true},
+		{"<p align=\"center\"><img src=\"logo.png\"></p>\n\n# Title\n\nText", false},
+		{"# Title\n\nPlain markdown", false},
+		{"Just text", false},
+	}
+	for _, tt := range tests {
+		if got := looksLikeHTML(tt.in); got != tt.want {
+			t.Errorf("looksLikeHTML(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
@@ -1,13 +1,16 @@
 package marketplace
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"strconv"
-	"time"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
 )
 
 const jetbrainsAPIBase = "https://plugins.jetbrains.com/api"
@@ -35,8 +38,20 @@ type jetbrainsPlugin struct {
 	Rating    float64 `json:"rating"`
 }
 
-// SearchPlugins queries the JetBrains Marketplace for plugins matching the given term.
-func SearchPlugins(query string, pageSize int) ([]Plugin, error) {
+// SearchPlugins queries the JetBrains Marketplace for plugins matching the
+// given term, retrying transient failures like Search. onRetry may be nil.
+// Errors wrap ErrSearchFailed.
+func SearchPlugins(ctx context.Context, query string, pageSize int, onRetry RetryFunc) ([]Plugin, error) {
+	plugins, err := withRetry(ctx, onRetry, func() ([]Plugin, error) {
+		return searchPlugins(ctx, query, pageSize)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSearchFailed, err)
+	}
+	return plugins, nil
+}
+
+func searchPlugins(ctx context.Context, query string, pageSize int) ([]Plugin, error) {
 	if pageSize <= 0 {
 		pageSize = 20
 	}
@@ -49,15 +64,18 @@ func SearchPlugins(query string, pageSize int) ([]Plugin, error) {
 
 	reqURL := fmt.Sprintf("%s/searchPlugins?%s", jetbrainsAPIBase, params.Encode())
 
-	client := &http.Client{Timeout: 8 * time.Second}
-	resp, err := client.Get(reqURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	resp, err := httpclient.Default().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("querying JetBrains marketplace: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("JetBrains marketplace returned status %d", resp.StatusCode)
+		return nil, &StatusError{Op: "querying JetBrains marketplace", Code: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -87,10 +105,8 @@ func SearchPlugins(query string, pageSize int) ([]Plugin, error) {
 	return plugins, nil
 }
 
-// FetchPluginReadme fetches the description/readme for a JetBrains plugin by its numeric ID.
-// The xmlId is used to look up the plugin first, then fetch its description.
-func FetchPluginReadme(xmlID string) (string, error) {
-	// First resolve xmlId to numeric ID
+// lookupPluginID resolves a plugin xmlId to its numeric marketplace ID.
+func lookupPluginID(client *http.Client, xmlID string) (int, error) {
 	params := url.Values{
 		"search":       {xmlID},
 		"max":          {"1"},
@@ -98,32 +114,41 @@ func FetchPluginReadme(xmlID string) (string, error) {
 	}
 	reqURL := fmt.Sprintf("%s/searchPlugins?%s", jetbrainsAPIBase, params.Encode())
 
-	client := &http.Client{Timeout: 8 * time.Second}
 	resp, err := client.Get(reqURL)
 	if err != nil {
-		return "", fmt.Errorf("looking up plugin: %w", err)
+		return 0, fmt.Errorf("looking up plugin: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("JetBrains API returned status %d", resp.StatusCode)
+		return 0, &StatusError{Op: "looking up plugin", Code: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("reading response: %w", err)
+		return 0, fmt.Errorf("reading response: %w", err)
 	}
 
 	var result jetbrainsSearchResponse
 	if err := json.Unmarshal(body, &result); err != nil {
-		return "", fmt.Errorf("parsing response: %w", err)
+		return 0, fmt.Errorf("parsing response: %w", err)
 	}
 
 	if len(result.Plugins) == 0 {
-		return "", fmt.Errorf("plugin %q not found", xmlID)
+		return 0, fmt.Errorf("plugin %q: %w", xmlID, ErrNotFound)
 	}
 
-	numericID := result.Plugins[0].ID
+	return result.Plugins[0].ID, nil
+}
+
+// FetchPluginReadme fetches the description/readme for a JetBrains plugin by its numeric ID.
+// The xmlId is used to look up the plugin first, then fetch its description.
+func FetchPluginReadme(xmlID string) (string, error) {
+	client := httpclient.Default()
+	numericID, err := lookupPluginID(client, xmlID)
+	if err != nil {
+		return "", err
+	}
 
 	// Fetch the full plugin description
 	descURL := fmt.Sprintf("%s/plugins/%d", jetbrainsAPIBase, numericID)
@@ -134,7 +159,7 @@ func FetchPluginReadme(xmlID string) (string, error) {
 	defer resp2.Body.Close()
 
 	if resp2.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("fetching plugin details: status %d", resp2.StatusCode)
+		return "", &StatusError{Op: "fetching plugin details", Code: resp2.StatusCode}
 	}
 
 	body2, err := io.ReadAll(resp2.Body)
@@ -151,8 +176,73 @@ func FetchPluginReadme(xmlID string) (string, error) {
 		return "", fmt.Errorf("parsing plugin details: %w", err)
 	}
 
-	// Description is HTML, but glamour can handle basic HTML via markdown rendering
-	// Return preview + description
-	content := fmt.Sprintf("# %s\n\n%s\n\n---\n\n%s", detail.Name, detail.Preview, detail.Description)
+	// Description is HTML; convert it so glamour renders it legibly
+	content := fmt.Sprintf("# %s\n\n%s\n\n---\n\n%s", detail.Name, detail.Preview, readmeMarkdown(detail.Description))
 	return content, nil
 }
+
+// FetchPluginVersions returns the most recent published versions of a
+// JetBrains plugin, newest first.
+func FetchPluginVersions(xmlID string, limit int) ([]string, error) {
+	if limit <= 0 {
+		limit = 20
+	}
+
+	client := httpclient.Default()
+	numericID, err := lookupPluginID(client, xmlID)
+	if err != nil {
+		return nil, err
+	}
+
+	reqURL := fmt.Sprintf("%s/plugins/%d/updates?page=1&size=%d", jetbrainsAPIBase, numericID, limit)
+	resp, err := client.Get(reqURL)
+	if err != nil {
+		return nil, fmt.Errorf("fetching plugin versions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Op: "fetching plugin versions", Code: resp.StatusCode}
+	}
+
+	var updates []struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&updates); err != nil {
+		return nil, fmt.Errorf("parsing plugin versions: %w", err)
+	}
+
+	versions := make([]string, 0, len(updates))
+	seen := make(map[string]bool)
+	for _, u := range updates {
+		if u.Version == "" || seen[u.Version] {
+			continue
+		}
+		seen[u.Version] = true
+		versions = append(versions, u.Version)
+	}
+	return versions, nil
+}
+
+// SplitPluginRef splits a customizations.jetbrains.plugins entry of the form
+// "xmlId" or "xmlId:version" into its ID and (possibly empty) version.
+func SplitPluginRef(ref string) (id, version string) {
+	if idx := strings.LastIndex(ref, ":"); idx != -1 {
+		return ref[:idx], ref[idx+1:]
+	}
+	return ref, ""
+}
+
+// FormatPluginRef joins a plugin ID and optional version into a plugins entry.
+func FormatPluginRef(id, version string) string {
+	if version == "" {
+		return id
+	}
+	return id + ":" + version
+}
+
+// DedupePlugins drops the plugins entries naming a plugin listed before,
+// comparing xmlIds case-insensitively like DedupeExtensions.
+func DedupePlugins(refs []string) []string {
+	return dedupeRefs(refs, SplitPluginRef)
+}
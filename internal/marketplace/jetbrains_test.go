@@ -0,0 +1,29 @@
+package marketplace
+
+import "testing"
+
+func TestSplitPluginRef(t *testing.T) {
+	tests := []struct {
+		ref, id, version string
+	}{
+		{"com.intellij.python", "com.intellij.python", ""},
+		{"com.intellij.python:241.14494.240", "com.intellij.python", "241.14494.240"},
+	}
+	for _, tt := range tests {
+		id, version := SplitPluginRef(tt.ref)
+		if id != tt.id || version != tt.version {
+			t.Errorf("SplitPluginRef(%q) = (%q, %q), want (%q, %q)", tt.ref, id, version, tt.id, tt.version)
+		}
+		if got := FormatPluginRef(id, version); got != tt.ref {
+			t.Errorf("FormatPluginRef(%q, %q) = %q, want %q", id, version, got, tt.ref)
+		}
+	}
+}
+
+func TestDedupePlugins(t *testing.T) {
+	refs := []string{"com.intellij.Python:241.1", "com.intellij.python", "org.rust.lang"}
+	got := DedupePlugins(refs)
+	if len(got) != 2 || got[0] != "com.intellij.Python:241.1" || got[1] != "org.rust.lang" {
+		t.Errorf("DedupePlugins = %v", got)
+	}
+}
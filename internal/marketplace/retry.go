@@ -0,0 +1,89 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
+)
+
+// MaxAttempts caps how often a marketplace request is tried. It is kept low
+// so a flaky marketplace cannot stack requests behind the pickers' debounced
+// search.
+const MaxAttempts = 3
+
+// retryDelay is the backoff before the first retry; it doubles each attempt.
+var retryDelay = 300 * time.Millisecond
+
+// RetryFunc is called before each retry with the attempt that just failed
+// (starting at 1) and its error.
+type RetryFunc func(attempt int, err error)
+
+// Errors of marketplace requests, for errors.Is.
+var (
+	ErrSearchFailed = errors.New("marketplace search failed")
+	ErrNotFound     = errors.New("not found in the marketplace")
+	ErrRateLimited  = errors.New("rate limited by the marketplace, try again shortly")
+)
+
+// StatusError reports an unexpected HTTP status from a marketplace API. It
+// matches ErrNotFound and ErrRateLimited by its status code.
+type StatusError struct {
+	Op   string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: status %d", e.Op, e.Code)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == http.StatusNotFound
+	case ErrRateLimited:
+		return e.Code == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// retryable reports whether err is worth another attempt: 5xx responses and
+// network errors. Timeouts are not retried, since each one already took the
+// full client timeout.
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= http.StatusInternalServerError
+	}
+	if httpclient.IsTimeout(err) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// withRetry runs fn up to MaxAttempts times with exponential backoff while it
+// fails with a retryable error. onRetry may be nil. Once ctx is done no
+// further attempt is made.
+func withRetry[T any](ctx context.Context, onRetry RetryFunc, fn func() (T, error)) (T, error) {
+	delay := retryDelay
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt == MaxAttempts || !retryable(err) || ctx.Err() != nil {
+			return result, err
+		}
+		if onRetry != nil {
+			onRetry(attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
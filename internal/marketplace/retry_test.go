@@ -0,0 +1,102 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWithRetry(t *testing.T) {
+	retryDelay = 0
+
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		wantHits int
+	}{
+		{"recovers from 5xx", []int{503, 502, 200}, false, 3},
+		{"gives up after MaxAttempts", []int{500, 500, 500, 200}, true, MaxAttempts},
+		{"does not retry 4xx", []int{404, 200}, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hits := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statuses[hits])
+				hits++
+			}))
+			defer srv.Close()
+
+			retries := 0
+			_, err := withRetry(context.Background(), func(int, error) { retries++ }, func() (int, error) {
+				resp, err := http.Get(srv.URL)
+				if err != nil {
+					return 0, err
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					return 0, &StatusError{Op: "test", Code: resp.StatusCode}
+				}
+				return resp.StatusCode, nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if hits != tt.wantHits {
+				t.Errorf("hits = %d, want %d", hits, tt.wantHits)
+			}
+			if retries != hits-1 {
+				t.Errorf("onRetry called %d times for %d hits", retries, hits)
+			}
+		})
+	}
+}
+
+func TestWithRetryCancelled(t *testing.T) {
+	retryDelay = time.Hour
+	t.Cleanup(func() { retryDelay = 300 * time.Millisecond })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, err := withRetry(ctx, func(int, error) { cancel() }, func() (int, error) {
+		calls++
+		return 0, &StatusError{Op: "test", Code: http.StatusBadGateway}
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Errorf("withRetry after cancel = %v with %d calls, want context.Canceled after 1", err, calls)
+	}
+}
+
+func TestRetryableNetworkError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	_, err := http.Get(url)
+	if err == nil || !retryable(err) {
+		t.Errorf("retryable(%v) = false, want true for a refused connection", err)
+	}
+	if retryable(errors.New("parsing response")) {
+		t.Error("retryable(plain error) = true, want false")
+	}
+}
+
+func TestStatusErrorIs(t *testing.T) {
+	wrap := func(code int) error {
+		return fmt.Errorf("%w: %w", ErrSearchFailed, &StatusError{Op: "querying marketplace", Code: code})
+	}
+	if err := wrap(http.StatusTooManyRequests); !errors.Is(err, ErrRateLimited) || !errors.Is(err, ErrSearchFailed) || errors.Is(err, ErrNotFound) {
+		t.Errorf("429: %v should match ErrRateLimited and ErrSearchFailed only", err)
+	}
+	if err := wrap(http.StatusNotFound); !errors.Is(err, ErrNotFound) || errors.Is(err, ErrRateLimited) {
+		t.Errorf("404: %v should match ErrNotFound", err)
+	}
+	if err := wrap(http.StatusBadGateway); errors.Is(err, ErrNotFound) || errors.Is(err, ErrRateLimited) {
+		t.Errorf("502: %v matches a status sentinel", err)
+	}
+}
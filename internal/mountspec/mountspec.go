@@ -0,0 +1,104 @@
+// Package mountspec reads and writes the mounts of devcontainer.json, given
+// in docker --mount syntax or as objects.
+package mountspec
+
+import "strings"
+
+// Spec is a parsed "type=bind,source=...,target=..." mount string or
+// {"type": ..., "source": ..., "target": ...} mount object.
+type Spec struct {
+	Type        string
+	Source      string
+	Target      string
+	Consistency string
+	Extra       []string       // unrecognized key=value parts, kept verbatim
+	Fields      map[string]any // other keys of a mount object, kept as is
+}
+
+// Parse parses a mount string in docker --mount syntax. Unknown parts
+// (e.g. readonly) are kept in Extra so they survive a round trip.
+func Parse(s string) Spec {
+	var m Spec
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, val, _ := strings.Cut(part, "=")
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "type":
+			m.Type = val
+		case "source", "src":
+			m.Source = val
+		case "target", "destination", "dst":
+			m.Target = val
+		case "consistency":
+			m.Consistency = val
+		default:
+			m.Extra = append(m.Extra, part)
+		}
+	}
+	return m
+}
+
+// String serializes the mount in the canonical order the spec uses.
+func (m Spec) String() string {
+	var parts []string
+	if m.Type != "" {
+		parts = append(parts, "type="+m.Type)
+	}
+	if m.Source != "" {
+		parts = append(parts, "source="+m.Source)
+	}
+	if m.Target != "" {
+		parts = append(parts, "target="+m.Target)
+	}
+	if m.Consistency != "" {
+		parts = append(parts, "consistency="+m.Consistency)
+	}
+	parts = append(parts, m.Extra...)
+	return strings.Join(parts, ",")
+}
+
+// FromObject reads a mount written in object form.
+func FromObject(obj map[string]any) Spec {
+	var m Spec
+	for key, val := range obj {
+		s, isString := val.(string)
+		switch {
+		case key == "type" && isString:
+			m.Type = s
+		case key == "source" && isString:
+			m.Source = s
+		case key == "target" && isString:
+			m.Target = s
+		case key == "consistency" && isString:
+			m.Consistency = s
+		default:
+			if m.Fields == nil {
+				m.Fields = make(map[string]any)
+			}
+			m.Fields[key] = val
+		}
+	}
+	return m
+}
+
+// Object serializes the mount in object form.
+func (m Spec) Object() map[string]any {
+	obj := make(map[string]any, len(m.Fields)+4)
+	for k, v := range m.Fields {
+		obj[k] = v
+	}
+	for key, val := range map[string]string{
+		"type":        m.Type,
+		"source":      m.Source,
+		"target":      m.Target,
+		"consistency": m.Consistency,
+	} {
+		if val != "" {
+			obj[key] = val
+		}
+	}
+	return obj
+}
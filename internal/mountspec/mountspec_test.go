@@ -0,0 +1,54 @@
+package mountspec
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"type=bind,source=${localWorkspaceFolder}/data,target=/data", "type=bind,source=${localWorkspaceFolder}/data,target=/data"},
+		{"source=cache,target=/root/.cache,type=volume", "type=volume,source=cache,target=/root/.cache"},
+		{"src=/tmp,dst=/tmp,type=bind,consistency=cached,readonly", "type=bind,source=/tmp,target=/tmp,consistency=cached,readonly"},
+	}
+	for _, tt := range tests {
+		if got := Parse(tt.in).String(); got != tt.want {
+			t.Errorf("Parse(%q).String() = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	m := Parse("type=volume,source=node_modules,target=/workspace/node_modules,consistency=delegated")
+	if m.Type != "volume" || m.Source != "node_modules" || m.Target != "/workspace/node_modules" || m.Consistency != "delegated" {
+		t.Errorf("unexpected parse result: %+v", m)
+	}
+	if len(m.Extra) != 0 {
+		t.Errorf("Extra = %v, want empty", m.Extra)
+	}
+}
+
+func TestMountObjectRoundTrip(t *testing.T) {
+	obj := map[string]any{
+		"type":     "volume",
+		"source":   "cache",
+		"target":   "/root/.cache",
+		"readonly": true, // not part of the spec; kept anyway
+	}
+	m := FromObject(obj)
+	if m.Type != "volume" || m.Source != "cache" || m.Target != "/root/.cache" {
+		t.Errorf("unexpected parse result: %+v", m)
+	}
+	if got := m.Object(); !reflect.DeepEqual(got, obj) {
+		t.Errorf("Object() = %v, want %v", got, obj)
+	}
+
+	// Edits keep the object form and the unknown keys.
+	m.Target = "/home/vscode/.cache"
+	want := map[string]any{"type": "volume", "source": "cache", "target": "/home/vscode/.cache", "readonly": true}
+	if got := m.Object(); !reflect.DeepEqual(got, want) {
+		t.Errorf("edited Object() = %v, want %v", got, want)
+	}
+}
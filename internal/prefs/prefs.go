@@ -0,0 +1,106 @@
+// Package prefs reads and writes user preferences stored in
+// ~/.config/dcc/config.json.
+package prefs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Menu ratio bounds. The ratio is the share of the terminal width given to
+// the left-hand menu or list column.
+const (
+	DefaultMenuRatio = 0.33
+	MinMenuRatio     = 0.2
+	MaxMenuRatio     = 0.6
+)
+
+// Prefs is the on-disk preferences file.
+type Prefs struct {
+	Layout Layout `json:"layout"`
+	Theme  string `json:"theme,omitempty"` // see ui.Themes
+
+	// SecretKeys replaces the words that mark a key's value as secret in
+	// previews (see ui.SetSecretKeys).
+	SecretKeys []string `json:"secretKeys,omitempty"`
+
+	// DefaultImage is the image of new configs (see
+	// template.SetDefaultImage).
+	DefaultImage string `json:"defaultImage,omitempty"`
+
+	// NoSchema leaves $schema out of new configs (see
+	// template.SetIncludeSchema).
+	NoSchema bool `json:"noSchema,omitempty"`
+
+	// Backups is how many timestamped backups of a config to keep before
+	// each write (see devcontainer.SetBackups); 0 keeps none unless
+	// --backup is given.
+	Backups int `json:"backups,omitempty"`
+}
+
+// Layout holds split-pane layout preferences.
+type Layout struct {
+	MenuRatio float64 `json:"menuRatio,omitempty"`
+}
+
+// Path returns the preferences file location.
+func Path() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "dcc", "config.json"), nil
+}
+
+// Load reads the preferences file. A missing file yields zero-value Prefs.
+func Load() (Prefs, error) {
+	var p Prefs
+	path, err := Path()
+	if err != nil {
+		return p, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return p, fmt.Errorf("reading preferences: %w", err)
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Save writes the preferences file, creating its directory if needed.
+func Save(p Prefs) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling preferences: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// MenuRatio returns the configured menu ratio clamped to
+// [MinMenuRatio, MaxMenuRatio], or DefaultMenuRatio when unset.
+func (p Prefs) MenuRatio() float64 {
+	if p.Layout.MenuRatio == 0 {
+		return DefaultMenuRatio
+	}
+	return ClampMenuRatio(p.Layout.MenuRatio)
+}
+
+// ClampMenuRatio limits r to [MinMenuRatio, MaxMenuRatio].
+func ClampMenuRatio(r float64) float64 {
+	return min(max(r, MinMenuRatio), MaxMenuRatio)
+}
@@ -0,0 +1,36 @@
+package prefs
+
+import "testing"
+
+func TestLoadSave(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	p, err := Load()
+	if err != nil {
+		t.Fatalf("Load without file: %v", err)
+	}
+	if got := p.MenuRatio(); got != DefaultMenuRatio {
+		t.Errorf("default MenuRatio = %v, want %v", got, DefaultMenuRatio)
+	}
+
+	p.Layout.MenuRatio = 0.45
+	if err := Save(p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := loaded.MenuRatio(); got != 0.45 {
+		t.Errorf("MenuRatio after save = %v, want 0.45", got)
+	}
+}
+
+func TestMenuRatioClamped(t *testing.T) {
+	for in, want := range map[float64]float64{0.05: MinMenuRatio, 0.9: MaxMenuRatio, 0.5: 0.5} {
+		p := Prefs{Layout: Layout{MenuRatio: in}}
+		if got := p.MenuRatio(); got != want {
+			t.Errorf("MenuRatio(%v) = %v, want %v", in, got, want)
+		}
+	}
+}
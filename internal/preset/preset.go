@@ -0,0 +1,125 @@
+// Package preset stores named devcontainer.json fragments under
+// ~/.config/dcc/presets/ for reuse across projects.
+package preset
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// projectKeys describe a single project and are left out of saved presets.
+var projectKeys = []string{"name"}
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Dir returns the presets directory.
+func Dir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "dcc", "presets"), nil
+}
+
+// Path returns the file of the named preset.
+func Path(name string) (string, error) {
+	if !validName.MatchString(name) {
+		return "", fmt.Errorf("invalid preset name %q: use letters, digits, '.', '-' and '_'", name)
+	}
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+// Save stores config as the named preset, replacing an existing one. baseline
+// is the file config was read from; its key order and comments are kept.
+func Save(name string, config map[string]any, baseline []byte) error {
+	path, err := Path(name)
+	if err != nil {
+		return err
+	}
+	fragment := make(map[string]any, len(config))
+	for k, v := range config {
+		fragment[k] = v
+	}
+	for _, k := range projectKeys {
+		delete(fragment, k)
+	}
+
+	var buf bytes.Buffer
+	if err := devcontainer.WriteConfigTo(&buf, fragment, baseline); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating presets directory: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("writing preset: %w", err)
+	}
+	return nil
+}
+
+// Load reads the named preset.
+func Load(name string) (map[string]any, error) {
+	path, err := Path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("preset %q not found (see dcc preset list)", name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading preset: %w", err)
+	}
+	var config map[string]any
+	if err := json.Unmarshal(devcontainer.ToJSON(data), &config); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return config, nil
+}
+
+// List returns the saved preset names, sorted.
+func List() ([]string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return nil, err
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("listing presets: %w", err)
+	}
+	names := make([]string, 0, len(paths))
+	for _, p := range paths {
+		names = append(names, strings.TrimSuffix(filepath.Base(p), ".json"))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Apply merges the named preset into the workspace config with
+// devcontainer.Merge, creating the config if there is none.
+func Apply(workspaceFolder, name string) error {
+	fragment, err := Load(name)
+	if err != nil {
+		return err
+	}
+	config := map[string]any{}
+	if devcontainer.Exists(workspaceFolder) {
+		if config, _, err = devcontainer.ReadConfig(workspaceFolder); err != nil {
+			return err
+		}
+	}
+	return devcontainer.WriteConfig(devcontainer.ConfigPath(workspaceFolder), devcontainer.Merge(config, fragment))
+}
@@ -0,0 +1,80 @@
+package preset
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+func TestSaveApply(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	source := []byte(`{
+  "name": "go-api",
+  // shared Go setup
+  "image": "mcr.microsoft.com/devcontainers/go:1",
+  "forwardPorts": [8080, 6060]
+}
+`)
+	config := map[string]any{"name": "go-api", "image": "mcr.microsoft.com/devcontainers/go:1", "forwardPorts": []any{8080.0, 6060.0}}
+	if err := Save("go-api", config, source); err != nil {
+		t.Fatal(err)
+	}
+	path, _ := Path("go-api")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), `"name"`) || !strings.Contains(string(data), "// shared Go setup") {
+		t.Errorf("saved preset = %s, want the comment kept and the name dropped", data)
+	}
+	if names, err := List(); err != nil || !reflect.DeepEqual(names, []string{"go-api"}) {
+		t.Errorf("List = %v, %v", names, err)
+	}
+
+	workspace := t.TempDir()
+	if err := devcontainer.WriteConfig(devcontainer.DefaultConfigPath(workspace), map[string]any{
+		"name":         "billing",
+		"forwardPorts": []any{3000.0, 8080.0},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := Apply(workspace, "go-api"); err != nil {
+		t.Fatal(err)
+	}
+	got, _, err := devcontainer.ReadConfig(workspace)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"name":         "billing",
+		"image":        "mcr.microsoft.com/devcontainers/go:1",
+		"forwardPorts": []any{3000.0, 8080.0, 6060.0},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("applied config = %v, want %v", got, want)
+	}
+}
+
+func TestPresetErrors(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	for _, name := range []string{"", "../etc", "a/b", ".hidden"} {
+		if _, err := Path(name); err == nil {
+			t.Errorf("Path(%q) accepted an invalid name", name)
+		}
+	}
+	if _, err := Load("missing"); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("Load(missing) = %v", err)
+	}
+	if names, err := List(); err != nil || len(names) != 0 {
+		t.Errorf("List without a presets directory = %v, %v", names, err)
+	}
+	dir, _ := Dir()
+	if filepath.Base(dir) != "presets" {
+		t.Errorf("Dir = %s", dir)
+	}
+}
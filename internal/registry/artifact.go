@@ -0,0 +1,80 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// createdAnnotation is the OCI annotation holding the artifact's creation time.
+const createdAnnotation = "org.opencontainers.image.created"
+
+// ArtifactInfo describes a published template or feature artifact.
+type ArtifactInfo struct {
+	Size      int64     // config and layers, in bytes
+	Published time.Time // zero unless the manifest or config records it
+}
+
+// artifactInfos caches FetchArtifactInfo results by ref for the process.
+var artifactInfos sync.Map
+
+// FetchArtifactInfo returns the size and, if the manifest or its config
+// records it, the publish date of ociRef. Results are cached in memory per
+// ref.
+func FetchArtifactInfo(ociRef string) (ArtifactInfo, error) {
+	if info, ok := artifactInfos.Load(ociRef); ok {
+		return info.(ArtifactInfo), nil
+	}
+
+	registry, repository, tag, err := ParseOciRef(ociRef)
+	if err != nil {
+		return ArtifactInfo{}, fmt.Errorf("parsing OCI ref: %w", err)
+	}
+	client := NewClient()
+	manifest, err := client.GetManifest(registry, repository, tag)
+	if err != nil {
+		return ArtifactInfo{}, fmt.Errorf("fetching manifest for %s: %w", ociRef, err)
+	}
+
+	info := artifactInfo(manifest)
+	if info.Published.IsZero() && manifest.Config.Digest != "" {
+		// Publishers that leave out the annotation may still record the
+		// time in the config, as image configs do. Without it the date
+		// stays unknown.
+		if config, err := client.GetBlob(registry, repository, manifest.Config.Digest); err == nil {
+			info.Published = configCreated(config)
+		}
+	}
+	artifactInfos.Store(ociRef, info)
+	return info, nil
+}
+
+// configCreated reads the created time of an artifact config blob, or
+// returns zero if it has none.
+func configCreated(config []byte) time.Time {
+	var c struct {
+		Created string `json:"created"`
+	}
+	if json.Unmarshal(config, &c) != nil {
+		return time.Time{}
+	}
+	created, err := time.Parse(time.RFC3339, c.Created)
+	if err != nil {
+		return time.Time{}
+	}
+	return created
+}
+
+// artifactInfo sums the blob sizes of a manifest and reads its creation
+// time.
+func artifactInfo(manifest *ociManifest) ArtifactInfo {
+	info := ArtifactInfo{Size: manifest.Config.Size}
+	for _, layer := range manifest.Layers {
+		info.Size += layer.Size
+	}
+	if created, err := time.Parse(time.RFC3339, manifest.Annotations[createdAnnotation]); err == nil {
+		info.Published = created
+	}
+	return info
+}
@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestArtifactInfo(t *testing.T) {
+	var manifest ociManifest
+	err := json.Unmarshal([]byte(`{
+		"config": {"mediaType": "application/vnd.devcontainers", "size": 2},
+		"layers": [
+			{"mediaType": "application/vnd.devcontainers.layer.v1+tar", "size": 10240},
+			{"mediaType": "application/vnd.devcontainers.layer.v1+tar", "size": 512}
+		],
+		"annotations": {"org.opencontainers.image.created": "2025-03-04T10:00:00Z"}
+	}`), &manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := artifactInfo(&manifest)
+	if info.Size != 10754 {
+		t.Errorf("Size = %d, want 10754", info.Size)
+	}
+	if want := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC); !info.Published.Equal(want) {
+		t.Errorf("Published = %v, want %v", info.Published, want)
+	}
+
+	// Without the annotation the date stays unknown.
+	delete(manifest.Annotations, createdAnnotation)
+	if info := artifactInfo(&manifest); !info.Published.IsZero() {
+		t.Errorf("Published = %v, want zero", info.Published)
+	}
+}
+
+func TestConfigCreated(t *testing.T) {
+	want := time.Date(2025, 3, 4, 10, 0, 0, 500000000, time.UTC)
+	if got := configCreated([]byte(`{"created": "2025-03-04T10:00:00.5Z", "architecture": "amd64"}`)); !got.Equal(want) {
+		t.Errorf("configCreated = %v, want %v", got, want)
+	}
+	for _, config := range []string{`{}`, `{"created": "yesterday"}`, `not json`} {
+		if got := configCreated([]byte(config)); !got.IsZero() {
+			t.Errorf("configCreated(%s) = %v, want zero", config, got)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mochlast/devcontainer-companion/internal/cachedir"
+)
+
+// DefaultMetadataTTL applies when DCC_OCI_CACHE_TTL is unset or invalid.
+const DefaultMetadataTTL = 24 * time.Hour
+
+// MetadataCacheTTL returns how long cached OCI metadata is fresh, from
+// DCC_OCI_CACHE_TTL, which accepts a Go duration ("6h", "30m") or a number
+// of seconds like catalog.CacheTTL's DCC_CACHE_TTL.
+func MetadataCacheTTL() time.Duration {
+	val := os.Getenv("DCC_OCI_CACHE_TTL")
+	if val == "" {
+		return DefaultMetadataTTL
+	}
+	if d, err := time.ParseDuration(val); err == nil && d > 0 {
+		return d
+	}
+	if secs, err := strconv.Atoi(val); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return DefaultMetadataTTL
+}
+
+// cachedMetadata is the on-disk form of fetched collection or item metadata.
+type cachedMetadata struct {
+	Collection *CollectionMetadata `json:"collection,omitempty"`
+	Template   *TemplateDefinition `json:"template,omitempty"`
+	Feature    *FeatureDefinition  `json:"feature,omitempty"`
+	FetchedAt  time.Time           `json:"fetchedAt"`
+}
+
+// metadataCachePath maps an OCI ref to oci/<registry>/<repository>/<tag>.json
+// in the cache directory (~/.cache/dcc by default, see cachedir.Dir).
+func metadataCachePath(ociRef string) (string, error) {
+	registry, repository, tag, err := ParseOciRef(ociRef)
+	if err != nil {
+		return "", err
+	}
+	dir, err := cachedir.Dir()
+	if err != nil {
+		return "", err
+	}
+	tag = strings.NewReplacer("/", "_", ":", "_").Replace(tag)
+	return filepath.Join(dir, "oci", registry, filepath.FromSlash(repository), tag+".json"), nil
+}
+
+// loadCachedMetadata loads cached metadata for an OCI ref. Unless allowStale
+// is set, entries older than MetadataCacheTTL are ignored.
+func loadCachedMetadata(ociRef string, allowStale bool) (*cachedMetadata, bool) {
+	path, err := metadataCachePath(ociRef)
+	if err != nil {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cachedMetadata
+	if err := json.Unmarshal(data, &cached); err != nil {
+		return nil, false
+	}
+
+	if !allowStale && time.Since(cached.FetchedAt) > MetadataCacheTTL() {
+		return nil, false
+	}
+
+	return &cached, true
+}
+
+// saveCachedMetadata writes metadata for an OCI ref to the disk cache.
+func saveCachedMetadata(ociRef string, cached cachedMetadata) error {
+	path, err := metadataCachePath(ociRef)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	cached.FetchedAt = time.Now()
+	data, err := json.MarshalIndent(cached, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling cache: %w", err)
+	}
+
+	return os.WriteFile(path, data, 0o644)
+}
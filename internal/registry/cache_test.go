@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMetadataCacheRoundTrip(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ref := "ghcr.io/devcontainers/features/node:1"
+	feat := &FeatureDefinition{ID: "node", Version: "1.6.0"}
+	if err := saveCachedMetadata(ref, cachedMetadata{Feature: feat}); err != nil {
+		t.Fatalf("saveCachedMetadata: %v", err)
+	}
+
+	cached, ok := loadCachedMetadata(ref, false)
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	if cached.Feature == nil || cached.Feature.Version != "1.6.0" {
+		t.Errorf("cached feature = %+v, want version 1.6.0", cached.Feature)
+	}
+
+	path, _ := metadataCachePath(ref)
+	if filepath.Base(path) != "1.json" {
+		t.Errorf("cache file = %s, want 1.json", path)
+	}
+}
+
+func TestMetadataCacheTTL(t *testing.T) {
+	tests := []struct {
+		env  string
+		want time.Duration
+	}{
+		{"", DefaultMetadataTTL},
+		{"1h", time.Hour},
+		{"600", 10 * time.Minute},
+		{"bogus", DefaultMetadataTTL},
+		{"0", DefaultMetadataTTL},
+	}
+	for _, tt := range tests {
+		t.Setenv("DCC_OCI_CACHE_TTL", tt.env)
+		if got := MetadataCacheTTL(); got != tt.want {
+			t.Errorf("DCC_OCI_CACHE_TTL=%q: MetadataCacheTTL() = %v, want %v", tt.env, got, tt.want)
+		}
+	}
+}
+
+func TestMetadataCacheExpiry(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	ref := "ghcr.io/devcontainers/features/go:1"
+	path, err := metadataCachePath(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := cachedMetadata{
+		Feature:   &FeatureDefinition{ID: "go"},
+		FetchedAt: time.Now().Add(-2 * MetadataCacheTTL()),
+	}
+	data, _ := json.Marshal(stale)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := loadCachedMetadata(ref, false); ok {
+		t.Error("expected expired entry to be ignored")
+	}
+	if _, ok := loadCachedMetadata(ref, true); !ok {
+		t.Error("expected expired entry to be returned when stale entries are allowed")
+	}
+}
@@ -1,33 +1,46 @@
 package registry
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"regexp"
 	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
 )
 
 // Client handles OCI registry HTTP interactions (token auth, manifests, blobs).
 type Client struct {
-	httpClient *http.Client
-	tokens     map[string]string // cache: "registry/repo" -> token
+	httpClient  *http.Client
+	auth        map[string]string // cache: "registry/repo" -> Authorization header
+	credentials func(registry string) (username, password string, ok bool)
 }
 
 // NewClient creates a new OCI registry client.
 func NewClient() *Client {
 	return &Client{
-		httpClient: &http.Client{},
-		tokens:     make(map[string]string),
+		httpClient:  httpclient.Default(),
+		auth:        make(map[string]string),
+		credentials: lookupCredentials,
 	}
 }
 
+// tokenResponse is the answer of a token realm. Docker Hub and GHCR name the
+// token "token", ACR and other OAuth2 realms "access_token".
 type tokenResponse struct {
-	Token string `json:"token"`
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
 }
 
 type ociManifest struct {
-	Layers []ociLayer `json:"layers"`
+	Config      ociLayer          `json:"config"`
+	Layers      []ociLayer        `json:"layers"`
+	Annotations map[string]string `json:"annotations"`
 }
 
 type ociLayer struct {
@@ -36,61 +49,124 @@ type ociLayer struct {
 	Size      int64  `json:"size"`
 }
 
-// GetToken fetches a bearer token for the given registry and repository.
-func (c *Client) GetToken(registry, repository string) (string, error) {
+// request sends a request for path (e.g. "manifests/latest") of an image
+// repository. Registries name where their tokens come from in the challenge
+// of an unauthorized request rather than agreeing on an endpoint (GHCR's
+// /token, Harbor's /service/token, ACR's /oauth2/token, auth.docker.io), so
+// the request is sent with the cached authorization, anonymously at first,
+// and repeated once with what the challenge asks for (see challengeAuth).
+func (c *Client) request(ctx context.Context, method, registry, repository, path, accept string) (*http.Response, error) {
+	target := fmt.Sprintf("https://%s/v2/%s/%s", registry, repository, path)
+	send := func(auth string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		return c.do(req)
+	}
+
 	key := registry + "/" + repository
-	if tok, ok := c.tokens[key]; ok {
-		return tok, nil
+	resp, err := send(c.auth[key])
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+
+	auth, err := c.challengeAuth(ctx, registry, repository, challenge)
+	if err != nil {
+		return nil, err
+	}
+	if c.auth == nil {
+		c.auth = make(map[string]string)
+	}
+	c.auth[key] = auth
+	return send(auth)
+}
+
+// challengeParam matches the key="value" pairs of a WWW-Authenticate header.
+var challengeParam = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// challengeAuth returns the Authorization header answering challenge, the
+// WWW-Authenticate header of an unauthorized request for repository: for
+// Bearer, a pull token from the challenge's realm and service, requested
+// with credentials from the Docker auth store (~/.docker/config.json) if
+// any and anonymously otherwise; for Basic, those credentials themselves.
+func (c *Client) challengeAuth(ctx context.Context, registry, repository, challenge string) (string, error) {
+	scheme, _, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	var username, password string
+	haveCredentials := false
+	if c.credentials != nil {
+		username, password, haveCredentials = c.credentials(registry)
 	}
 
-	// For ghcr.io, token endpoint is ghcr.io/token
-	tokenURL := fmt.Sprintf("https://%s/token?scope=repository:%s:pull", registry, repository)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if !haveCredentials {
+			return "", fmt.Errorf("%w: %s needs credentials, none found for it", ErrUnauthorized, registry)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("%w: unsupported challenge %q", ErrUnauthorized, challenge)
+	}
+
+	params := make(map[string]string)
+	for _, m := range challengeParam.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("%w: challenge without realm %q", ErrUnauthorized, challenge)
+	}
+
+	query := url.Values{"scope": {"repository:" + repository + ":pull"}}
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if haveCredentials {
+		req.SetBasicAuth(username, password)
+	}
 
-	resp, err := c.httpClient.Get(tokenURL)
+	resp, err := c.do(req)
 	if err != nil {
 		return "", fmt.Errorf("fetching token: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("token request failed (status %d): %s", resp.StatusCode, string(body))
+		return "", statusError("token request", resp)
 	}
 
 	var tr tokenResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
 		return "", fmt.Errorf("decoding token response: %w", err)
 	}
-
-	c.tokens[key] = tr.Token
-	return tr.Token, nil
+	if tr.Token == "" {
+		tr.Token = tr.AccessToken
+	}
+	return "Bearer " + tr.Token, nil
 }
 
 // GetManifest fetches the OCI manifest for a given repository and tag.
 func (c *Client) GetManifest(registry, repository, tag string) (*ociManifest, error) {
-	token, err := c.GetToken(registry, repository)
-	if err != nil {
-		return nil, err
-	}
-
-	url := fmt.Sprintf("https://%s/v2/%s/manifests/%s", registry, repository, tag)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Accept", "application/vnd.oci.image.manifest.v1+json")
-
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.request(context.Background(), "GET", registry, repository, "manifests/"+tag, "application/vnd.oci.image.manifest.v1+json")
 	if err != nil {
 		return nil, fmt.Errorf("fetching manifest: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("manifest request failed (status %d): %s", resp.StatusCode, string(body))
+		return nil, statusError("manifest request", resp)
 	}
 
 	var manifest ociManifest
@@ -103,31 +179,41 @@ func (c *Client) GetManifest(registry, repository, tag string) (*ociManifest, er
 
 // GetBlob fetches a blob from the OCI registry, following redirects.
 func (c *Client) GetBlob(registry, repository, digest string) ([]byte, error) {
-	token, err := c.GetToken(registry, repository)
+	resp, err := c.request(context.Background(), "GET", registry, repository, "blobs/"+digest, "")
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("fetching blob: %w", err)
 	}
+	defer resp.Body.Close()
 
-	url := fmt.Sprintf("https://%s/v2/%s/blobs/%s", registry, repository, digest)
-
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, err
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("blob request", resp)
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := c.httpClient.Do(req)
+	return io.ReadAll(resp.Body)
+}
+
+type tagList struct {
+	Tags []string `json:"tags"`
+}
+
+// ListTags fetches the tags published for a repository.
+func (c *Client) ListTags(registry, repository string) ([]string, error) {
+	resp, err := c.request(context.Background(), "GET", registry, repository, "tags/list", "")
 	if err != nil {
-		return nil, fmt.Errorf("fetching blob: %w", err)
+		return nil, fmt.Errorf("fetching tags: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("blob request failed (status %d): %s", resp.StatusCode, string(body))
+		return nil, statusError("tags request", resp)
 	}
 
-	return io.ReadAll(resp.Body)
+	var tl tagList
+	if err := json.NewDecoder(resp.Body).Decode(&tl); err != nil {
+		return nil, fmt.Errorf("decoding tags: %w", err)
+	}
+
+	return tl.Tags, nil
 }
 
 // ParseOciRef splits an OCI reference like "ghcr.io/devcontainers/templates/python:1"
@@ -148,7 +234,7 @@ func ParseOciRef(ociRef string) (registry, repository, tag string, err error) {
 	fullPath := parts[0]
 	slashIdx := strings.Index(fullPath, "/")
 	if slashIdx == -1 {
-		return "", "", "", fmt.Errorf("invalid OCI reference: %s", ociRef)
+		return "", "", "", fmt.Errorf("%w: %s", ErrInvalidRef, ociRef)
 	}
 
 	registry = fullPath[:slashIdx]
@@ -0,0 +1,60 @@
+package registry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientChallengeAuth(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/oauth2/token":
+			user, pass, ok := r.BasicAuth()
+			if !ok || user != "octo" || pass != "secret" || r.URL.Query().Get("scope") != "repository:bearer/app:pull" || r.URL.Query().Get("service") != "acr.test" {
+				http.Error(w, "bad token request", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"access_token": "oauth"}`)) //nolint:errcheck
+		case strings.HasPrefix(r.URL.Path, "/v2/bearer/"):
+			if r.Header.Get("Authorization") != "Bearer oauth" {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/oauth2/token",service="acr.test"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"tags": ["1"]}`)) //nolint:errcheck
+		case strings.HasPrefix(r.URL.Path, "/v2/basic/"):
+			if user, pass, ok := r.BasicAuth(); !ok || user != "octo" || pass != "secret" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"tags": ["2"]}`)) //nolint:errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	credentials := func(string) (string, string, bool) { return "octo", "secret", true }
+	c := &Client{httpClient: srv.Client(), auth: map[string]string{}, credentials: credentials}
+
+	// Bearer tokens come from the challenge's realm, not from /token.
+	if tags, err := c.ListTags(host, "bearer/app"); err != nil || len(tags) != 1 || tags[0] != "1" {
+		t.Errorf("bearer ListTags = %v, %v; want [1]", tags, err)
+	}
+
+	// A Basic challenge is answered with the stored credentials.
+	if tags, err := c.ListTags(host, "basic/app"); err != nil || len(tags) != 1 || tags[0] != "2" {
+		t.Errorf("basic ListTags = %v, %v; want [2]", tags, err)
+	}
+
+	// Without credentials a Basic challenge can't be answered.
+	anon := &Client{httpClient: srv.Client()}
+	if _, err := anon.ListTags(host, "basic/app"); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("anonymous basic ListTags err = %v, want ErrUnauthorized", err)
+	}
+}
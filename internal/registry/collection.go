@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -27,12 +28,16 @@ type TemplateDefinition struct {
 }
 
 // FeatureDefinition represents a feature within a collection.
+// DependsOn maps required features to their options; InstallsAfter only
+// orders features that are selected anyway.
 type FeatureDefinition struct {
-	ID          string                    `json:"id"`
-	Version     string                    `json:"version"`
-	Name        string                    `json:"name"`
-	Description string                    `json:"description"`
-	Options     map[string]OptionDefinition `json:"options"`
+	ID            string                      `json:"id"`
+	Version       string                      `json:"version"`
+	Name          string                      `json:"name"`
+	Description   string                      `json:"description"`
+	Options       map[string]OptionDefinition `json:"options"`
+	DependsOn     map[string]map[string]any   `json:"dependsOn,omitempty"`
+	InstallsAfter []string                    `json:"installsAfter,omitempty"`
 }
 
 // OptionDefinition represents an option for a template or feature.
@@ -42,6 +47,9 @@ type OptionDefinition struct {
 	Default     any      `json:"default"`
 	Proposals   []string `json:"proposals,omitempty"`
 	Enum        []string `json:"enum,omitempty"`
+	// EnumDescriptions describes the Enum values by position, as in JSON
+	// Schema's VS Code extension. Few features set it.
+	EnumDescriptions []string `json:"enumDescriptions,omitempty"`
 }
 
 // collectionCache caches fetched collection metadata.
@@ -52,22 +60,48 @@ var (
 
 // FetchCollectionMetadata fetches the devcontainer-collection.json for a given OCI reference.
 // The ociRef should point to any template/feature in the collection; the function
-// resolves the collection root automatically.
-func FetchCollectionMetadata(ociRef string) (*CollectionMetadata, error) {
+// resolves the collection root automatically. Results are cached on disk for
+// MetadataCacheTTL unless noCache is set.
+func FetchCollectionMetadata(ociRef string, noCache bool) (*CollectionMetadata, error) {
 	// Extract collection base from the OCI reference.
 	// e.g., "ghcr.io/devcontainers/templates/python:1" → collection is "ghcr.io/devcontainers/templates"
 	collectionBase := extractCollectionBase(ociRef)
+	collectionRef := collectionBase + ":latest"
 
-	collectionCacheMu.Lock()
-	if cached, ok := collectionCache[collectionBase]; ok {
+	if !noCache {
+		collectionCacheMu.Lock()
+		if cached, ok := collectionCache[collectionBase]; ok {
+			collectionCacheMu.Unlock()
+			return cached, nil
+		}
 		collectionCacheMu.Unlock()
-		return cached, nil
+
+		if cached, ok := loadCachedMetadata(collectionRef, false); ok && cached.Collection != nil {
+			return cached.Collection, nil
+		}
+	}
+
+	metadata, err := fetchCollectionMetadata(collectionBase, collectionRef)
+	if err != nil {
+		// Fallback to expired cache on error
+		if cached, ok := loadCachedMetadata(collectionRef, true); ok && cached.Collection != nil {
+			return cached.Collection, nil
+		}
+		return nil, err
 	}
+
+	collectionCacheMu.Lock()
+	collectionCache[collectionBase] = metadata
 	collectionCacheMu.Unlock()
 
+	_ = saveCachedMetadata(collectionRef, cachedMetadata{Collection: metadata})
+	return metadata, nil
+}
+
+func fetchCollectionMetadata(collectionBase, collectionRef string) (*CollectionMetadata, error) {
 	client := NewClient()
 
-	registry, repository, tag, err := ParseOciRef(collectionBase + ":latest")
+	registry, repository, tag, err := ParseOciRef(collectionRef)
 	if err != nil {
 		return nil, fmt.Errorf("parsing collection OCI ref: %w", err)
 	}
@@ -94,10 +128,6 @@ func FetchCollectionMetadata(ociRef string) (*CollectionMetadata, error) {
 			continue
 		}
 
-		collectionCacheMu.Lock()
-		collectionCache[collectionBase] = metadata
-		collectionCacheMu.Unlock()
-
 		return metadata, nil
 	}
 
@@ -106,7 +136,28 @@ func FetchCollectionMetadata(ociRef string) (*CollectionMetadata, error) {
 
 // FetchItemMetadata fetches metadata for a specific template or feature from its OCI reference.
 // It looks for devcontainer-template.json or devcontainer-feature.json in the item's OCI layers.
-func FetchItemMetadata(ociRef string) (*TemplateDefinition, *FeatureDefinition, error) {
+// Results are cached on disk for MetadataCacheTTL unless noCache is set.
+func FetchItemMetadata(ociRef string, noCache bool) (*TemplateDefinition, *FeatureDefinition, error) {
+	if !noCache {
+		if cached, ok := loadCachedMetadata(ociRef, false); ok && (cached.Template != nil || cached.Feature != nil) {
+			return cached.Template, cached.Feature, nil
+		}
+	}
+
+	tmpl, feat, err := fetchItemMetadata(ociRef)
+	if err != nil {
+		// Fallback to expired cache on error
+		if cached, ok := loadCachedMetadata(ociRef, true); ok && (cached.Template != nil || cached.Feature != nil) {
+			return cached.Template, cached.Feature, nil
+		}
+		return nil, nil, err
+	}
+
+	_ = saveCachedMetadata(ociRef, cachedMetadata{Template: tmpl, Feature: feat})
+	return tmpl, feat, nil
+}
+
+func fetchItemMetadata(ociRef string) (*TemplateDefinition, *FeatureDefinition, error) {
 	client := NewClient()
 
 	registry, repository, tag, err := ParseOciRef(ociRef)
@@ -160,6 +211,55 @@ func extractItemJSON(blob []byte) (*TemplateDefinition, *FeatureDefinition, erro
 
 // extractJSONFromArchive extracts a named JSON file from a tar or tgz blob.
 func extractJSONFromTgz[T any](blob []byte, filename string) (*T, error) {
+	data, err := extractFileFromTgz(blob, filename)
+	if err != nil {
+		return nil, err
+	}
+	var result T
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// extractFileFromTgz returns the contents of a named file in a tar or tgz blob.
+func extractFileFromTgz(blob []byte, filename string) ([]byte, error) {
+	var data []byte
+	err := walkTgz(blob, func(name string, r io.Reader) (bool, error) {
+		if name != filename {
+			return false, nil
+		}
+		var err error
+		data, err = io.ReadAll(r)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s not found in archive", filename)
+	}
+	return data, nil
+}
+
+// listTgzFiles returns the paths of the regular files in a tar or tgz blob,
+// sorted.
+func listTgzFiles(blob []byte) ([]string, error) {
+	var names []string
+	err := walkTgz(blob, func(name string, _ io.Reader) (bool, error) {
+		names = append(names, name)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// walkTgz calls fn with the path, without a leading ./, and contents of each
+// regular file in a tar or tgz blob until fn returns true or an error.
+func walkTgz(blob []byte, fn func(name string, r io.Reader) (bool, error)) error {
 	// Try gzip first, fall back to plain tar
 	var reader io.Reader
 	gzr, err := gzip.NewReader(bytes.NewReader(blob))
@@ -175,28 +275,21 @@ func extractJSONFromTgz[T any](blob []byte, filename string) (*T, error) {
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
 		}
 
 		// Match the filename (may be prefixed with ./)
 		name := strings.TrimPrefix(header.Name, "./")
-		if name == filename {
-			data, err := io.ReadAll(tr)
-			if err != nil {
-				return nil, err
-			}
-			var result T
-			if err := json.Unmarshal(data, &result); err != nil {
-				return nil, err
-			}
-			return &result, nil
+		if done, err := fn(name, tr); done || err != nil {
+			return err
 		}
 	}
-
-	return nil, fmt.Errorf("%s not found in archive", filename)
 }
 
 // extractCollectionBase extracts the collection base path from an OCI reference.
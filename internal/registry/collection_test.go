@@ -0,0 +1,86 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+)
+
+func makeTgz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractFileFromTgz(t *testing.T) {
+	blob := makeTgz(t, map[string]string{
+		"./README.md":                 "# Node\n",
+		"./devcontainer-feature.json": `{"id": "node", "version": "1.6.0"}`,
+	})
+
+	readme, err := extractFileFromTgz(blob, "README.md")
+	if err != nil {
+		t.Fatalf("extractFileFromTgz: %v", err)
+	}
+	if string(readme) != "# Node\n" {
+		t.Errorf("README = %q", readme)
+	}
+
+	_, feat, err := extractItemJSON(blob)
+	if err != nil || feat == nil || feat.ID != "node" {
+		t.Errorf("extractItemJSON = %+v, %v", feat, err)
+	}
+
+	if _, err := extractFileFromTgz(blob, "install.sh"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestListTgzFiles(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, h := range []*tar.Header{
+		{Name: "./devcontainer-template.json", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "./.devcontainer/", Typeflag: tar.TypeDir, Mode: 0o755},
+		{Name: "./.devcontainer/devcontainer.json", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "./.devcontainer/Dockerfile", Typeflag: tar.TypeReg, Mode: 0o644},
+	} {
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := listTgzFiles(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".devcontainer/Dockerfile", ".devcontainer/devcontainer.json", "devcontainer-template.json"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("listTgzFiles = %q, want %q", got, want)
+	}
+}
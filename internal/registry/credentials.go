@@ -0,0 +1,128 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// dockerConfig is the subset of ~/.docker/config.json used for registry auth.
+type dockerConfig struct {
+	Auths       map[string]dockerAuth `json:"auths"`
+	CredHelpers map[string]string     `json:"credHelpers"`
+	CredsStore  string                `json:"credsStore"`
+}
+
+type dockerAuth struct {
+	Auth     string `json:"auth"` // base64 "username:password"
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// credentialHelperOutput is what `docker-credential-<helper> get` prints.
+type credentialHelperOutput struct {
+	Username string `json:"Username"`
+	Secret   string `json:"Secret"`
+}
+
+// dockerConfigPath returns $DOCKER_CONFIG/config.json or ~/.docker/config.json.
+func dockerConfigPath() (string, error) {
+	if dir := os.Getenv("DOCKER_CONFIG"); dir != "" {
+		return filepath.Join(dir, "config.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".docker", "config.json"), nil
+}
+
+// lookupCredentials finds credentials for a registry host in the Docker auth
+// store. A per-registry credHelpers entry wins over auths; the global
+// credsStore is consulted last. ok is false when no credentials are found.
+func lookupCredentials(registry string) (username, password string, ok bool) {
+	path, err := dockerConfigPath()
+	if err != nil {
+		return "", "", false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", false
+	}
+
+	var cfg dockerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return "", "", false
+	}
+
+	if helper, found := cfg.CredHelpers[registry]; found {
+		if u, p, err := runCredentialHelper(helper, registry); err == nil {
+			return u, p, true
+		}
+	}
+
+	for key, entry := range cfg.Auths {
+		if normalizeRegistryHost(key) != registry {
+			continue
+		}
+		if u, p, found := entry.credentials(); found {
+			return u, p, true
+		}
+	}
+
+	if cfg.CredsStore != "" {
+		if u, p, err := runCredentialHelper(cfg.CredsStore, registry); err == nil {
+			return u, p, true
+		}
+	}
+
+	return "", "", false
+}
+
+func (a dockerAuth) credentials() (username, password string, ok bool) {
+	if a.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+		if err != nil {
+			return "", "", false
+		}
+		username, password, ok = strings.Cut(string(decoded), ":")
+		return username, password, ok
+	}
+	if a.Username != "" {
+		return a.Username, a.Password, true
+	}
+	return "", "", false
+}
+
+// runCredentialHelper asks docker-credential-<helper> for the registry's credentials.
+func runCredentialHelper(helper, registry string) (username, password string, err error) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(registry)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return "", "", fmt.Errorf("running credential helper %s: %w", helper, err)
+	}
+
+	var creds credentialHelperOutput
+	if err := json.Unmarshal(out.Bytes(), &creds); err != nil {
+		return "", "", fmt.Errorf("decoding credential helper output: %w", err)
+	}
+	return creds.Username, creds.Secret, nil
+}
+
+// normalizeRegistryHost reduces an auths key like "https://index.docker.io/v1/"
+// to its host.
+func normalizeRegistryHost(key string) string {
+	key = strings.TrimPrefix(key, "https://")
+	key = strings.TrimPrefix(key, "http://")
+	if idx := strings.Index(key, "/"); idx != -1 {
+		key = key[:idx]
+	}
+	return key
+}
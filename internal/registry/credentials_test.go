@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeDockerConfig(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "config.json"), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dir)
+}
+
+func TestLookupCredentialsBase64Auth(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+	writeDockerConfig(t, `{"auths": {"https://registry.example.com/v1/": {"auth": "`+auth+`"}}}`)
+
+	user, pass, ok := lookupCredentials("registry.example.com")
+	if !ok || user != "alice" || pass != "s3cret" {
+		t.Errorf("lookupCredentials = (%q, %q, %v), want (alice, s3cret, true)", user, pass, ok)
+	}
+}
+
+func TestLookupCredentialsMissing(t *testing.T) {
+	writeDockerConfig(t, `{"auths": {"other.example.com": {"auth": "eDp5"}}}`)
+
+	if _, _, ok := lookupCredentials("ghcr.io"); ok {
+		t.Error("expected no credentials for unknown registry")
+	}
+}
+
+func TestLookupCredentialsFailingHelperFallsBack(t *testing.T) {
+	auth := base64.StdEncoding.EncodeToString([]byte("bob:pw"))
+	writeDockerConfig(t, `{
+		"credHelpers": {"registry.example.com": "dcc-test-missing-helper"},
+		"auths": {"registry.example.com": {"auth": "`+auth+`"}}
+	}`)
+
+	user, pass, ok := lookupCredentials("registry.example.com")
+	if !ok || user != "bob" || pass != "pw" {
+		t.Errorf("lookupCredentials = (%q, %q, %v), want (bob, pw, true)", user, pass, ok)
+	}
+}
@@ -0,0 +1,46 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Errors of registry requests, for errors.Is. See also ErrRateLimited.
+var (
+	ErrNotFound     = errors.New("not found in the registry")
+	ErrUnauthorized = errors.New("access denied by the registry")
+	ErrInvalidRef   = errors.New("invalid OCI reference")
+)
+
+// StatusError reports an unexpected HTTP status from a registry. It matches
+// ErrNotFound, ErrUnauthorized and ErrRateLimited by its status code.
+type StatusError struct {
+	Op   string // e.g. "manifest request"
+	Code int
+	Body string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s failed (status %d): %s", e.Op, e.Code, e.Body)
+}
+
+func (e *StatusError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.Code == http.StatusNotFound
+	case ErrUnauthorized:
+		return e.Code == http.StatusUnauthorized || e.Code == http.StatusForbidden
+	case ErrRateLimited:
+		return e.Code == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// statusError reads the body of an unexpected response into a StatusError.
+func statusError(op string, resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	return &StatusError{Op: op, Code: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+}
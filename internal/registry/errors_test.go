@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestStatusErrorIs(t *testing.T) {
+	tests := []struct {
+		code int
+		want error
+	}{
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusForbidden, ErrUnauthorized},
+		{http.StatusTooManyRequests, ErrRateLimited},
+	}
+	sentinels := []error{ErrNotFound, ErrUnauthorized, ErrRateLimited}
+	for _, tt := range tests {
+		err := fmt.Errorf("fetching: %w", &StatusError{Op: "manifest request", Code: tt.code})
+		for _, s := range sentinels {
+			if got := errors.Is(err, s); got != (s == tt.want) {
+				t.Errorf("status %d: errors.Is(%v) = %v", tt.code, s, got)
+			}
+		}
+	}
+
+	var statusErr *StatusError
+	err := fmt.Errorf("fetching: %w", &StatusError{Op: "blob request", Code: http.StatusBadGateway, Body: "bad gateway"})
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusBadGateway {
+		t.Fatalf("errors.As = %v", statusErr)
+	}
+	for _, s := range sentinels {
+		if errors.Is(err, s) {
+			t.Errorf("status 502 matches %v", s)
+		}
+	}
+	if want := "blob request failed (status 502): bad gateway"; statusErr.Error() != want {
+		t.Errorf("Error() = %q, want %q", statusErr.Error(), want)
+	}
+}
+
+func TestParseOciRefInvalid(t *testing.T) {
+	if _, _, _, err := ParseOciRef("node:1"); !errors.Is(err, ErrInvalidRef) {
+		t.Errorf("err = %v, want ErrInvalidRef", err)
+	}
+	if _, _, _, err := ParseOciRef("ghcr.io/devcontainers/features/node:1"); err != nil {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// manifestAccept asks for an image index when the image has one, and for
+// the plain manifest otherwise.
+var manifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+// dockerHub is the registry of image names without a registry host.
+const dockerHub = "registry-1.docker.io"
+
+// Platform is an OS/architecture an image is built for.
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+func (p Platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// HostPlatform is the platform containers run as on this machine. Docker
+// Desktop runs Linux containers on macOS and Windows too, so only the
+// architecture comes from the host.
+func HostPlatform() Platform {
+	return Platform{OS: "linux", Architecture: runtime.GOARCH}
+}
+
+// imageManifest is an image index or a single-platform image manifest.
+type imageManifest struct {
+	Manifests []struct {
+		Platform *Platform `json:"platform"`
+	} `json:"manifests"`
+	Config ociLayer `json:"config"`
+}
+
+// ParseImageRef splits a container image reference like "ubuntu:22.04" or
+// "mcr.microsoft.com/devcontainers/base:ubuntu" into registry, repository
+// and tag (or digest), filling in Docker Hub and its library/ namespace the
+// way docker pull does.
+func ParseImageRef(image string) (registry, repository, reference string, err error) {
+	name, reference := image, "latest"
+	digest := false
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, reference, digest = name[:i], name[i+1:], true
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		if !digest {
+			reference = name[i+1:]
+		}
+		name = name[:i]
+	}
+	if name == "" || reference == "" || strings.ContainsAny(image, "$ \t") {
+		return "", "", "", fmt.Errorf("%w: %s", ErrInvalidRef, image)
+	}
+
+	registry, repository = dockerHub, name
+	if i := strings.Index(name, "/"); i >= 0 {
+		if host := name[:i]; strings.ContainsAny(host, ".:") || host == "localhost" {
+			registry, repository = host, name[i+1:]
+		}
+	}
+	if registry == "docker.io" || registry == "index.docker.io" {
+		registry = dockerHub
+	}
+	if registry == dockerHub && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry, repository, reference, nil
+}
+
+// GetPlatforms returns the platforms an image is published for: those of
+// its image index, or the one in the config of a single-platform manifest.
+func (c *Client) GetPlatforms(registry, repository, reference string) ([]Platform, error) {
+	resp, err := c.request(context.Background(), "GET", registry, repository, "manifests/"+reference, manifestAccept)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, statusError("manifest request", resp)
+	}
+
+	var manifest imageManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	if len(manifest.Manifests) > 0 {
+		var platforms []Platform
+		for _, m := range manifest.Manifests {
+			// Attestation manifests are listed as unknown/unknown.
+			if m.Platform == nil || m.Platform.OS == "unknown" {
+				continue
+			}
+			platforms = append(platforms, *m.Platform)
+		}
+		return platforms, nil
+	}
+	if manifest.Config.Digest == "" {
+		return nil, nil
+	}
+
+	blob, err := c.request(context.Background(), "GET", registry, repository, "blobs/"+manifest.Config.Digest, "")
+	if err != nil {
+		return nil, fmt.Errorf("fetching image config: %w", err)
+	}
+	defer blob.Body.Close()
+
+	if blob.StatusCode != http.StatusOK {
+		return nil, statusError("image config request", blob)
+	}
+
+	var platform Platform
+	if err := json.NewDecoder(blob.Body).Decode(&platform); err != nil {
+		return nil, fmt.Errorf("decoding image config: %w", err)
+	}
+	if platform.OS == "" || platform.Architecture == "" {
+		return nil, nil
+	}
+	return []Platform{platform}, nil
+}
+
+// FetchImagePlatforms returns the platforms image is published for, or nil
+// if its registry doesn't say.
+func FetchImagePlatforms(image string) ([]Platform, error) {
+	registry, repository, reference, err := ParseImageRef(image)
+	if err != nil {
+		return nil, err
+	}
+	platforms, err := NewClient().GetPlatforms(registry, repository, reference)
+	if err != nil {
+		return nil, fmt.Errorf("fetching platforms for %s: %w", image, err)
+	}
+	return platforms, nil
+}
+
+// SupportsPlatform reports whether platforms include one matching host's OS
+// and architecture. A nil list means any platform.
+func SupportsPlatform(platforms []Platform, host Platform) bool {
+	if len(platforms) == 0 {
+		return true
+	}
+	for _, p := range platforms {
+		if p.OS == host.OS && p.Architecture == host.Architecture {
+			return true
+		}
+	}
+	return false
+}
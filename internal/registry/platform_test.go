@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetPlatforms(t *testing.T) {
+	var accept string
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:library/ubuntu:pull" || r.URL.Query().Get("service") != "registry.test" {
+				http.Error(w, "bad scope", http.StatusBadRequest)
+				return
+			}
+			w.Write([]byte(`{"token": "hub"}`)) //nolint:errcheck
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/v2/library/") && r.Header.Get("Authorization") != "Bearer hub" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+srv.URL+`/token",service="registry.test",scope="repository:library/ubuntu:pull"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		accept = r.Header.Get("Accept")
+		switch r.URL.Path {
+		case "/v2/library/ubuntu/manifests/22.04":
+			w.Write([]byte(`{"manifests": [
+				{"platform": {"os": "linux", "architecture": "amd64"}},
+				{"platform": {"os": "linux", "architecture": "arm", "variant": "v7"}},
+				{"platform": {"os": "unknown", "architecture": "unknown"}}
+			]}`)) //nolint:errcheck
+		case "/v2/example/legacy/manifests/1":
+			w.Write([]byte(`{"config": {"digest": "sha256:cfg"}, "layers": [{"digest": "sha256:abc"}]}`)) //nolint:errcheck
+		case "/v2/example/legacy/blobs/sha256:cfg":
+			w.Write([]byte(`{"architecture": "amd64", "os": "linux", "config": {}}`)) //nolint:errcheck
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	c := &Client{httpClient: srv.Client(), auth: map[string]string{}}
+
+	// The token comes from the realm of the registry's challenge.
+	platforms, err := c.GetPlatforms(host, "library/ubuntu", "22.04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Platform{{OS: "linux", Architecture: "amd64"}, {OS: "linux", Architecture: "arm", Variant: "v7"}}
+	if !reflect.DeepEqual(platforms, want) {
+		t.Errorf("platforms = %v, want %v", platforms, want)
+	}
+	if !strings.Contains(accept, "image.index") {
+		t.Errorf("Accept = %q, want the image index type", accept)
+	}
+
+	// A single-platform image names its platform in the image config.
+	platforms, err = c.GetPlatforms(host, "example/legacy", "1")
+	if want := []Platform{{OS: "linux", Architecture: "amd64"}}; err != nil || !reflect.DeepEqual(platforms, want) {
+		t.Errorf("single manifest = %v, %v; want %v", platforms, err, want)
+	}
+}
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		image, registry, repository, reference string
+	}{
+		{"ubuntu", "registry-1.docker.io", "library/ubuntu", "latest"},
+		{"ubuntu:22.04", "registry-1.docker.io", "library/ubuntu", "22.04"},
+		{"docker.io/bitnami/node:20", "registry-1.docker.io", "bitnami/node", "20"},
+		{"mcr.microsoft.com/devcontainers/base:ubuntu", "mcr.microsoft.com", "devcontainers/base", "ubuntu"},
+		{"localhost:5000/app", "localhost:5000", "app", "latest"},
+		{"node:20@sha256:abc", "registry-1.docker.io", "library/node", "sha256:abc"},
+	}
+	for _, tt := range tests {
+		registry, repository, reference, err := ParseImageRef(tt.image)
+		if err != nil || registry != tt.registry || repository != tt.repository || reference != tt.reference {
+			t.Errorf("ParseImageRef(%q) = %q, %q, %q, %v", tt.image, registry, repository, reference, err)
+		}
+	}
+	if _, _, _, err := ParseImageRef("node:${VARIANT}"); err == nil {
+		t.Error("an image with a build argument should not parse")
+	}
+}
+
+func TestSupportsPlatform(t *testing.T) {
+	arm := Platform{OS: "linux", Architecture: "arm64"}
+	if !SupportsPlatform(nil, arm) {
+		t.Error("an image of unknown platforms should support every platform")
+	}
+	amd := []Platform{{OS: "linux", Architecture: "amd64"}}
+	if SupportsPlatform(amd, arm) {
+		t.Error("amd64-only should not support arm64")
+	}
+	if !SupportsPlatform(append(amd, Platform{OS: "linux", Architecture: "arm64", Variant: "v8"}), arm) {
+		t.Error("arm64/v8 should support arm64")
+	}
+}
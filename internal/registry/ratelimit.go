@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrRateLimited is returned when the registry keeps answering 429 after
+// dcc has waited as long as maxRateLimitWait allows.
+var ErrRateLimited = errors.New("rate limited by registry, try again shortly")
+
+// maxRateLimitWait caps the total time one request waits out 429 responses.
+var maxRateLimitWait = 20 * time.Second
+
+// rateLimitDelay is the wait after a 429 without a usable Retry-After
+// header; it doubles with each further 429.
+var rateLimitDelay = time.Second
+
+// sleep waits for d, or until ctx is done.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// do sends req, retrying while the registry answers 429 Too Many Requests.
+// Retry-After is honored when present. The waits end early, with the
+// context's error, once the context of req is done. Registry requests carry
+// no body, so req can be sent again as is.
+func (c *Client) do(req *http.Request) (*http.Response, error) {
+	var waited time.Duration
+	delay := rateLimitDelay
+	for {
+		resp, err := c.httpClient.Do(req)
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+			return resp, err
+		}
+		resp.Body.Close()
+
+		wait, ok := retryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok {
+			wait = delay
+			delay *= 2
+		}
+		if waited+wait > maxRateLimitWait {
+			return nil, fmt.Errorf("%s: %w", req.URL.Host, ErrRateLimited)
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		waited += wait
+	}
+}
+
+// retryAfter parses a Retry-After header, given either in seconds or as an
+// HTTP date.
+func retryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
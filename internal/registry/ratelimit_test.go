@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestClientRateLimit(t *testing.T) {
+	var slept []time.Duration
+	defaultSleep := sleep
+	sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = defaultSleep })
+
+	limited := 2
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limited > 0 {
+			limited--
+			if limited == 1 {
+				w.Header().Set("Retry-After", "3")
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok")) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	c := &Client{httpClient: srv.Client()}
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	resp, err := c.do(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("do = %v, %v; want 200 after waiting", resp, err)
+	}
+	resp.Body.Close()
+	// Retry-After first, then the default backoff.
+	if want := []time.Duration{3 * time.Second, rateLimitDelay}; len(slept) != 2 || slept[0] != want[0] || slept[1] != want[1] {
+		t.Errorf("waited %v, want %v", slept, want)
+	}
+
+	// A registry that stays limited gives up once the wait cap is reached.
+	slept = nil
+	limited = 1000
+	if _, err := c.do(req); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("err = %v, want ErrRateLimited", err)
+	}
+	var total time.Duration
+	for _, d := range slept {
+		total += d
+	}
+	if total > maxRateLimitWait {
+		t.Errorf("waited %v in total, cap is %v", total, maxRateLimitWait)
+	}
+}
+
+func TestClientRateLimitCancel(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	// The wait for Retry-After ends with the request's context.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	c := &Client{httpClient: srv.Client()}
+	start := time.Now()
+	if _, err := c.do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want the context's error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("do returned after %v, want right after the deadline", elapsed)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{"Wed, 01 Jan 2025 12:00:10 GMT", 10 * time.Second, true},
+		{"Wed, 01 Jan 2025 11:59:00 GMT", 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := retryAfter(tt.header, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v; want %v, %v", tt.header, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+)
+
+// FetchItemReadme returns the README.md bundled in a template or feature's
+// OCI layer. It is a fallback for items whose source isn't hosted on GitHub.
+func FetchItemReadme(ociRef string) (string, error) {
+	return FetchItemFile(ociRef, "README.md")
+}
+
+// FetchInstallScript returns the install.sh a feature runs while the image
+// is built, so it can be reviewed before the feature is added.
+func FetchInstallScript(ociRef string) (string, error) {
+	return FetchItemFile(ociRef, "install.sh")
+}
+
+// FetchItemFile returns a file from a template or feature's OCI layer.
+func FetchItemFile(ociRef, filename string) (string, error) {
+	client := NewClient()
+
+	registry, repository, tag, err := ParseOciRef(ociRef)
+	if err != nil {
+		return "", fmt.Errorf("parsing OCI ref: %w", err)
+	}
+
+	manifest, err := client.GetManifest(registry, repository, tag)
+	if err != nil {
+		return "", fmt.Errorf("fetching manifest for %s: %w", ociRef, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		blob, err := client.GetBlob(registry, repository, layer.Digest)
+		if err != nil {
+			continue
+		}
+		if data, err := extractFileFromTgz(blob, filename); err == nil {
+			return string(data), nil
+		}
+	}
+
+	return "", fmt.Errorf("no %s in %s", filename, ociRef)
+}
+
+// templateMetadataFiles are the files of a template's layer that describe
+// it rather than being copied into the workspace on apply.
+var templateMetadataFiles = []string{"devcontainer-template.json", "README.md", "NOTES.md"}
+
+// FetchTemplateFiles returns the paths, relative to the workspace, of the
+// files applying the template at ociRef adds, sorted.
+func FetchTemplateFiles(ociRef string) ([]string, error) {
+	client := NewClient()
+
+	registry, repository, tag, err := ParseOciRef(ociRef)
+	if err != nil {
+		return nil, fmt.Errorf("parsing OCI ref: %w", err)
+	}
+
+	manifest, err := client.GetManifest(registry, repository, tag)
+	if err != nil {
+		return nil, fmt.Errorf("fetching manifest for %s: %w", ociRef, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		blob, err := client.GetBlob(registry, repository, layer.Digest)
+		if err != nil {
+			continue
+		}
+		names, err := listTgzFiles(blob)
+		if err != nil || !slices.Contains(names, "devcontainer-template.json") {
+			continue
+		}
+		return slices.DeleteFunc(names, func(name string) bool {
+			return slices.Contains(templateMetadataFiles, name)
+		}), nil
+	}
+
+	return nil, fmt.Errorf("no template files in %s", ociRef)
+}
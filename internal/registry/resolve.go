@@ -0,0 +1,36 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// HeadManifest checks that the manifest of repository:tag exists with a HEAD
+// request, without downloading it.
+func (c *Client) HeadManifest(ctx context.Context, registry, repository, tag string) error {
+	resp, err := c.request(ctx, "HEAD", registry, repository, "manifests/"+tag, manifestAccept)
+	if err != nil {
+		return fmt.Errorf("fetching manifest: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError("manifest request", resp)
+	}
+	return nil
+}
+
+// CheckResolves reports whether ociRef still resolves. An unknown repository
+// or tag fails with an error matching ErrNotFound; other errors mean the
+// registry couldn't be asked, e.g. when offline.
+func CheckResolves(ctx context.Context, ociRef string) error {
+	registry, repository, tag, err := ParseOciRef(ociRef)
+	if err != nil {
+		return err
+	}
+	if err := NewClient().HeadManifest(ctx, registry, repository, tag); err != nil {
+		return fmt.Errorf("resolving %s: %w", ociRef, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FetchVersionTags returns the version tags published for a template or
+// feature, newest first. Floating tags sort ahead of the releases they track
+// (e.g. "1", "1.6", "1.6.2", "1.6.1", "1.5"), so the first entry is the
+// latest major. Non-numeric tags such as "latest" are dropped.
+func FetchVersionTags(ociRef string) ([]string, error) {
+	registry, repository, _, err := ParseOciRef(ociRef)
+	if err != nil {
+		return nil, fmt.Errorf("parsing OCI ref: %w", err)
+	}
+
+	tags, err := NewClient().ListTags(registry, repository)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags for %s: %w", ociRef, err)
+	}
+	return sortVersionTags(tags), nil
+}
+
+// sortVersionTags keeps the numeric dot-separated tags and sorts them newest
+// first, with shorter (floating) tags before longer ones on a shared prefix.
+func sortVersionTags(tags []string) []string {
+	type parsed struct {
+		tag   string
+		parts []int
+	}
+	var versions []parsed
+	for _, tag := range tags {
+		if parts, ok := parseVersionTag(tag); ok {
+			versions = append(versions, parsed{tag, parts})
+		}
+	}
+
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, b := versions[i].parts, versions[j].parts
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] > b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+
+	result := make([]string, len(versions))
+	for i, v := range versions {
+		result[i] = v.tag
+	}
+	return result
+}
+
+func parseVersionTag(tag string) ([]int, bool) {
+	fields := strings.Split(tag, ".")
+	if len(fields) > 3 {
+		return nil, false
+	}
+	parts := make([]int, len(fields))
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, false
+		}
+		parts[i] = n
+	}
+	return parts, true
+}
@@ -0,0 +1,14 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSortVersionTags(t *testing.T) {
+	tags := []string{"1.5", "latest", "1", "1.6.1", "2", "1.6", "1.6.2", "2.0.0", "dev", "1.10.0"}
+	want := []string{"2", "2.0.0", "1", "1.10.0", "1.6", "1.6.2", "1.6.1", "1.5"}
+	if got := sortVersionTags(tags); !reflect.DeepEqual(got, want) {
+		t.Errorf("sortVersionTags = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,15 @@
+// Package shellquote quotes words for a POSIX shell.
+package shellquote
+
+import "strings"
+
+// safe are the characters a shell word can have without quoting.
+const safe = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,"
+
+// Quote single-quotes s unless it consists only of shell-safe characters.
+func Quote(s string) string {
+	if s != "" && strings.Trim(s, safe) == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
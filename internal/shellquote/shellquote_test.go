@@ -0,0 +1,18 @@
+package shellquote
+
+import "testing"
+
+func TestQuote(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"type=bind,source=/a", "type=bind,source=/a"},
+		{"it's", `'it'\''s'`},
+		{"", "''"},
+		{"a b", "'a b'"},
+		{"$HOME", "'$HOME'"},
+	}
+	for _, tt := range tests {
+		if got := Quote(tt.in); got != tt.want {
+			t.Errorf("Quote(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
@@ -4,11 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os/exec"
+	"regexp"
 )
 
 // Apply runs `devcontainer templates apply` to apply a template to a workspace folder.
 // Uses cd instead of -w flag to work around a bug in the VS Code CLI (v0.442.0)
-// where -w causes "paths[1] must be of type string" TypeError.
+// where -w causes "paths[1] must be of type string" TypeError. It isn't known
+// which release fixed it, so the workaround applies to every version.
 func Apply(workspaceFolder string, ociRef string, options map[string]any) error {
 	if err := checkDevcontainerCLI(); err != nil {
 		return err
@@ -47,8 +49,9 @@ func checkDevcontainerCLI() error {
 
 // CLIInfo describes the available devcontainer CLI capabilities.
 type CLIInfo struct {
-	Installed bool // devcontainer binary found in PATH
-	HasOpen   bool // supports 'devcontainer open' (VS Code CLI)
+	Installed bool   // devcontainer binary found in PATH
+	HasOpen   bool   // supports 'devcontainer open' (VS Code CLI)
+	Version   string // semver from 'devcontainer --version', empty if unparseable
 }
 
 // DetectCLI probes the installed devcontainer CLI and returns its capabilities.
@@ -56,7 +59,7 @@ func DetectCLI() CLIInfo {
 	if checkDevcontainerCLI() != nil {
 		return CLIInfo{}
 	}
-	info := CLIInfo{Installed: true}
+	info := CLIInfo{Installed: true, Version: cliVersion()}
 
 	// 'devcontainer open --help' exits 0 only on the VS Code-installed CLI.
 	// The npm @devcontainers/cli doesn't have the 'open' subcommand.
@@ -67,7 +70,35 @@ func DetectCLI() CLIInfo {
 	return info
 }
 
+// DetectCLIVia probes a devcontainer CLI started by command, e.g. over ssh
+// on a remote host. HasOpen is left to the caller.
+func DetectCLIVia(command func(args ...string) *exec.Cmd) CLIInfo {
+	out, err := command("--version").Output()
+	if err != nil {
+		return CLIInfo{}
+	}
+	return CLIInfo{Installed: true, Version: parseCLIVersion(string(out))}
+}
+
 // IsDevcontainerCLIAvailable returns true if any devcontainer CLI is in PATH.
 func IsDevcontainerCLIAvailable() bool {
 	return checkDevcontainerCLI() == nil
 }
+
+var semverPattern = regexp.MustCompile(`\d+\.\d+\.\d+`)
+
+// cliVersion returns the installed CLI's version, or "" if it can't be
+// determined.
+func cliVersion() string {
+	out, err := exec.Command("devcontainer", "--version").Output()
+	if err != nil {
+		return ""
+	}
+	return parseCLIVersion(string(out))
+}
+
+// parseCLIVersion extracts the first MAJOR.MINOR.PATCH version from the
+// output of 'devcontainer --version'.
+func parseCLIVersion(output string) string {
+	return semverPattern.FindString(output)
+}
@@ -0,0 +1,17 @@
+package template
+
+import "testing"
+
+func TestParseCLIVersion(t *testing.T) {
+	tests := map[string]string{
+		"0.442.0\n":               "0.442.0",
+		"devcontainer 0.71.0":     "0.71.0",
+		"Dev Containers CLI: 1.2": "",
+		"":                        "",
+	}
+	for in, want := range tests {
+		if got := parseCLIVersion(in); got != want {
+			t.Errorf("parseCLIVersion(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package template
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// baseDir returns the directory the config each template apply produced is
+// kept in as the common ancestor of the next apply: template-base in
+// $XDG_STATE_HOME/dcc, else ~/.local/state/dcc. Unlike caches, bases can't
+// be fetched again, so they don't live in the cache directory.
+func baseDir() (string, error) {
+	if xdg := os.Getenv("XDG_STATE_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "dcc", "template-base"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "state", "dcc", "template-base"), nil
+}
+
+// basePath returns the file recording the base of the config at key, a
+// config path.
+func basePath(key string) (string, error) {
+	dir, err := baseDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])+".json"), nil
+}
+
+// SaveBase records config, as a template generated it, as the base of the
+// config at key for the next apply.
+func SaveBase(key string, config map[string]any) error {
+	path, err := basePath(key)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("encoding template base: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating template base directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing template base: %w", err)
+	}
+	return nil
+}
+
+// LoadBase returns what the last template applied to the config at key
+// generated, or nil if none is recorded.
+func LoadBase(key string) map[string]any {
+	path, err := basePath(key)
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var config map[string]any
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	return config
+}
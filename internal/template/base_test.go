@@ -0,0 +1,31 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestBase(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", "")
+
+	if got := LoadBase("/work/a/.devcontainer/devcontainer.json"); got != nil {
+		t.Fatalf("LoadBase before SaveBase = %v, want nil", got)
+	}
+	config := map[string]any{"image": "mcr.microsoft.com/devcontainers/go:1", "forwardPorts": []any{8080.0}}
+	if err := SaveBase("/work/a/.devcontainer/devcontainer.json", config); err != nil {
+		t.Fatal(err)
+	}
+	if got := LoadBase("/work/a/.devcontainer/devcontainer.json"); !reflect.DeepEqual(got, config) {
+		t.Errorf("LoadBase = %v, want %v", got, config)
+	}
+	if entries, err := os.ReadDir(filepath.Join(home, ".local", "state", "dcc", "template-base")); err != nil || len(entries) != 1 {
+		t.Errorf("state directory = %v, %v; want the base", entries, err)
+	}
+	if got := LoadBase("/work/b/.devcontainer/devcontainer.json"); got != nil {
+		t.Errorf("LoadBase of another config = %v, want nil", got)
+	}
+}
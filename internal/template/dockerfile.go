@@ -0,0 +1,45 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// StarterBase is an image offered as the FROM line of a scaffolded Dockerfile.
+type StarterBase struct {
+	Name  string
+	Image string
+}
+
+// StarterBases are the images offered when scaffolding a Dockerfile.
+var StarterBases = []StarterBase{
+	{"Ubuntu", "mcr.microsoft.com/devcontainers/base:ubuntu"},
+	{"Debian", "mcr.microsoft.com/devcontainers/base:debian"},
+	{"Alpine", "mcr.microsoft.com/devcontainers/base:alpine"},
+	{"Python", "mcr.microsoft.com/devcontainers/python:3"},
+	{"Node.js", "mcr.microsoft.com/devcontainers/javascript-node:22"},
+	{"Go", "mcr.microsoft.com/devcontainers/go:1"},
+}
+
+// StarterDockerfile returns a minimal Dockerfile building on image.
+func StarterDockerfile(image string) string {
+	install := "RUN apt-get update && export DEBIAN_FRONTEND=noninteractive \\\n#     && apt-get -y install --no-install-recommends <packages>"
+	if strings.Contains(image, "alpine") {
+		install = "RUN apk add --no-cache <packages>"
+	}
+	return fmt.Sprintf("FROM %s\n\n# Install additional OS packages:\n# %s\n", image, install)
+}
+
+// ScaffoldDockerfile writes a StarterDockerfile for image to path unless a
+// file exists there already. It reports whether it wrote one.
+func ScaffoldDockerfile(path, image string) (bool, error) {
+	if _, err := devcontainer.ReadFile(path); err == nil {
+		return false, nil
+	}
+	if err := devcontainer.WriteFile(path, []byte(StarterDockerfile(image))); err != nil {
+		return false, fmt.Errorf("writing Dockerfile: %w", err)
+	}
+	return true, nil
+}
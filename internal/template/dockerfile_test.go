@@ -0,0 +1,33 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScaffoldDockerfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".devcontainer", "Dockerfile")
+
+	wrote, err := ScaffoldDockerfile(path, "mcr.microsoft.com/devcontainers/base:alpine")
+	if err != nil || !wrote {
+		t.Fatalf("ScaffoldDockerfile = %v, %v", wrote, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "FROM mcr.microsoft.com/devcontainers/base:alpine\n") || !strings.Contains(string(data), "apk add") {
+		t.Errorf("Dockerfile =\n%s", data)
+	}
+
+	// An existing Dockerfile is never overwritten.
+	if err := os.WriteFile(path, []byte("FROM scratch\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wrote, err = ScaffoldDockerfile(path, "mcr.microsoft.com/devcontainers/base:ubuntu")
+	if err != nil || wrote {
+		t.Errorf("ScaffoldDockerfile over an existing file = %v, %v", wrote, err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "FROM scratch\n" {
+		t.Errorf("existing Dockerfile changed to\n%s", data)
+	}
+}
@@ -1,33 +1,87 @@
 package template
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
 )
 
-// CreateEmpty creates a minimal .devcontainer/devcontainer.json in the given workspace folder.
-func CreateEmpty(workspaceFolder string, projectName string) error {
-	devcontainerDir := filepath.Join(workspaceFolder, ".devcontainer")
-	if err := os.MkdirAll(devcontainerDir, 0o755); err != nil {
-		return fmt.Errorf("creating .devcontainer directory: %w", err)
+// FallbackImage is the image of new configs when no default image is set.
+const FallbackImage = "mcr.microsoft.com/devcontainers/base:ubuntu"
+
+// configuredImage is the defaultImage preference, see SetDefaultImage.
+var configuredImage string
+
+// SetDefaultImage sets the image of new configs from the preferences file;
+// DCC_DEFAULT_IMAGE takes precedence over it. "" restores FallbackImage.
+func SetDefaultImage(image string) {
+	configuredImage = image
+}
+
+// includeSchema is whether new configs get $schema, see SetIncludeSchema.
+var includeSchema = true
+
+// SetIncludeSchema sets whether CreateEmpty adds $schema pointing at the
+// official schema (on by default; the noSchema preference turns it off).
+func SetIncludeSchema(include bool) {
+	includeSchema = include
+}
+
+// DefaultImage returns the image CreateEmpty uses: DCC_DEFAULT_IMAGE, else
+// the configured one, else FallbackImage. It fails if the chosen value isn't
+// an image reference.
+func DefaultImage() (string, error) {
+	image, source := os.Getenv("DCC_DEFAULT_IMAGE"), "DCC_DEFAULT_IMAGE"
+	if image == "" {
+		image, source = configuredImage, "defaultImage"
+	}
+	if image == "" {
+		return FallbackImage, nil
 	}
+	if err := checkImageRef(image); err != nil {
+		return "", fmt.Errorf("%s: %w", source, err)
+	}
+	return image, nil
+}
 
-	config := map[string]any{
-		"name":  projectName,
-		"image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+// checkImageRef rejects values that can't be an image reference.
+func checkImageRef(image string) error {
+	switch {
+	case strings.TrimSpace(image) == "":
+		return fmt.Errorf("image reference is empty")
+	case strings.ContainsFunc(image, func(r rune) bool { return r <= ' ' }):
+		return fmt.Errorf("image reference %q contains whitespace", image)
+	case strings.HasPrefix(image, ":") || strings.HasPrefix(image, "@") || strings.HasSuffix(image, ":") || strings.HasSuffix(image, "@"):
+		return fmt.Errorf("image reference %q lacks a name or tag", image)
 	}
+	return nil
+}
 
-	data, err := json.MarshalIndent(config, "", "  ")
+// CreateEmpty creates a minimal devcontainer.json in the given workspace folder,
+// at the location resolved by devcontainer.ConfigPath, with $schema unless
+// SetIncludeSchema turned it off.
+func CreateEmpty(workspaceFolder string, projectName string) error {
+	config, err := EmptyConfig(projectName)
 	if err != nil {
-		return fmt.Errorf("marshaling config: %w", err)
+		return err
 	}
+	return devcontainer.WriteConfig(devcontainer.ConfigPath(workspaceFolder), config)
+}
 
-	configPath := filepath.Join(devcontainerDir, "devcontainer.json")
-	if err := os.WriteFile(configPath, append(data, '\n'), 0o644); err != nil {
-		return fmt.Errorf("writing devcontainer.json: %w", err)
+// EmptyConfig returns the config CreateEmpty writes.
+func EmptyConfig(projectName string) (map[string]any, error) {
+	image, err := DefaultImage()
+	if err != nil {
+		return nil, err
 	}
-
-	return nil
+	config := map[string]any{
+		"name":  projectName,
+		"image": image,
+	}
+	if includeSchema {
+		config[devcontainer.SchemaKey] = devcontainer.SchemaURL
+	}
+	return config, nil
 }
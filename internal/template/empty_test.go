@@ -0,0 +1,81 @@
+package template
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+func TestDefaultImage(t *testing.T) {
+	defer SetDefaultImage("")
+	tests := []struct {
+		env, configured string
+		want            string
+		wantErr         string
+	}{
+		{"", "", FallbackImage, ""},
+		{"", "mirror.example.com/base:debian", "mirror.example.com/base:debian", ""},
+		{"alpine:3.20", "mirror.example.com/base:debian", "alpine:3.20", ""},
+		{"  ", "", "", "DCC_DEFAULT_IMAGE: image reference is empty"},
+		{"", "base image", "", "defaultImage: image reference \"base image\" contains whitespace"},
+		{"alpine:", "", "", "lacks a name or tag"},
+	}
+	for _, tt := range tests {
+		t.Setenv("DCC_DEFAULT_IMAGE", tt.env)
+		SetDefaultImage(tt.configured)
+		got, err := DefaultImage()
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("env %q, configured %q: err = %v, want %q", tt.env, tt.configured, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("env %q, configured %q: DefaultImage() = %q, %v; want %q", tt.env, tt.configured, got, err, tt.want)
+		}
+	}
+}
+
+func TestCreateEmptyUsesDefaultImage(t *testing.T) {
+	t.Setenv("DCC_DEFAULT_IMAGE", "mirror.example.com/devcontainers/base:debian")
+	dir := t.TempDir()
+	if err := CreateEmpty(dir, "proj"); err != nil {
+		t.Fatal(err)
+	}
+	config, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config["image"] != "mirror.example.com/devcontainers/base:debian" || config["name"] != "proj" {
+		t.Errorf("config = %v", config)
+	}
+}
+
+func TestCreateEmptySchema(t *testing.T) {
+	dir := t.TempDir()
+	if err := CreateEmpty(dir, "proj"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := devcontainer.ReadFile(devcontainer.ConfigPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{\n  \"$schema\": \"" + devcontainer.SchemaURL + "\",\n"; !strings.HasPrefix(string(data), want) {
+		t.Errorf("config doesn't start with $schema:\n%s", data)
+	}
+
+	SetIncludeSchema(false)
+	defer SetIncludeSchema(true)
+	dir = t.TempDir()
+	if err := CreateEmpty(dir, "proj"); err != nil {
+		t.Fatal(err)
+	}
+	config, _, err := devcontainer.ReadConfig(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := config[devcontainer.SchemaKey]; ok {
+		t.Errorf("$schema written with SetIncludeSchema(false): %v", config)
+	}
+}
@@ -0,0 +1,93 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+// templateMetadataFiles describe a template and are not copied into the workspace.
+var templateMetadataFiles = map[string]bool{
+	"devcontainer-template.json": true,
+	"README.md":                  true,
+	"NOTES.md":                   true,
+}
+
+// LoadLocal reads devcontainer-template.json from a template folder.
+func LoadLocal(templateDir string) (*registry.TemplateDefinition, error) {
+	data, err := os.ReadFile(filepath.Join(templateDir, "devcontainer-template.json"))
+	if err != nil {
+		return nil, fmt.Errorf("reading template metadata: %w", err)
+	}
+	var def registry.TemplateDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, fmt.Errorf("parsing template metadata: %w", err)
+	}
+	return &def, nil
+}
+
+// ApplyLocal applies a template folder (e.g. src/<template> in a Git repo) the
+// way `devcontainer templates apply` does for OCI templates: its files are
+// copied into the workspace folder with ${templateOption:<name>} replaced by
+// the given option values, falling back to the option defaults.
+func ApplyLocal(workspaceFolder, templateDir string, options map[string]any) error {
+	def, err := LoadLocal(templateDir)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]string, len(def.Options))
+	for name, opt := range def.Options {
+		if opt.Default != nil {
+			values[name] = fmt.Sprint(opt.Default)
+		}
+	}
+	for name, v := range options {
+		values[name] = fmt.Sprint(v)
+	}
+	pairs := make([]string, 0, 2*len(values))
+	for name, v := range values {
+		pairs = append(pairs, "${templateOption:"+name+"}", v)
+	}
+	replacer := strings.NewReplacer(pairs...)
+
+	return filepath.WalkDir(templateDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(templateDir, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel == d.Name() && templateMetadataFiles[rel] {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("reading template file: %w", err)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("reading template file: %w", err)
+		}
+		dest := filepath.Join(workspaceFolder, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return fmt.Errorf("creating directory: %w", err)
+		}
+		if err := os.WriteFile(dest, []byte(replacer.Replace(string(data))), info.Mode().Perm()); err != nil {
+			return fmt.Errorf("writing %s: %w", rel, err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,51 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApplyLocal(t *testing.T) {
+	src := t.TempDir()
+	files := map[string]string{
+		"devcontainer-template.json": `{"id": "py", "options": {
+			"version": {"type": "string", "default": "3.12"},
+			"user": {"type": "string", "default": "vscode"}
+		}}`,
+		"README.md":                       "# py",
+		"NOTES.md":                        "notes",
+		".devcontainer/devcontainer.json": `{"image": "python:${templateOption:version}", "remoteUser": "${templateOption:user}"}`,
+		".devcontainer/README.md":         "kept",
+	}
+	for name, content := range files {
+		path := filepath.Join(src, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dest := t.TempDir()
+	if err := ApplyLocal(dest, src, map[string]any{"version": "3.11"}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dest, ".devcontainer", "devcontainer.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"image": "python:3.11", "remoteUser": "vscode"}`; string(data) != want {
+		t.Errorf("devcontainer.json = %s, want %s", data, want)
+	}
+	for _, name := range []string{"devcontainer-template.json", "README.md", "NOTES.md"} {
+		if _, err := os.Stat(filepath.Join(dest, name)); err == nil {
+			t.Errorf("metadata file %s was copied", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, ".devcontainer", "README.md")); err != nil {
+		t.Errorf("nested README.md not copied: %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// appPortValues returns the entries of appPort, whichever of its forms it
+// is written in: a number, a string or an array of both.
+func appPortValues(config map[string]any) []any {
+	values, ok := config["appPort"].([]any)
+	if !ok {
+		if v, set := config["appPort"]; set {
+			values = []any{v}
+		}
+	}
+	return values
+}
+
+// appPortText renders an appPort entry as the editor shows it.
+func appPortText(v any) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.Itoa(int(v))
+	case int:
+		return strconv.Itoa(v)
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// readAppPorts returns appPort as a list of port strings.
+func readAppPorts(config map[string]any) []string {
+	values := appPortValues(config)
+	ports := make([]string, 0, len(values))
+	for _, v := range values {
+		ports = append(ports, appPortText(v))
+	}
+	return ports
+}
+
+// writeAppPorts stores ports in appPort. Entries whose text is unchanged
+// keep the value they had, so a quoted "3000" stays a string; new numeric
+// ones are written as numbers. A single port is written on its own unless
+// appPort was an array, so the config keeps its form.
+func writeAppPorts(config map[string]any, ports []string) {
+	_, wasArray := config["appPort"].([]any)
+	existing := make(map[string]any)
+	for _, v := range appPortValues(config) {
+		existing[appPortText(v)] = v
+	}
+	values := make([]any, len(ports))
+	for i, p := range ports {
+		if v, ok := existing[p]; ok {
+			values[i] = v
+		} else if n, err := strconv.Atoi(p); err == nil {
+			values[i] = n
+		} else {
+			values[i] = p
+		}
+	}
+	switch {
+	case len(values) == 0:
+		delete(config, "appPort")
+	case len(values) == 1 && !wasArray:
+		config["appPort"] = values[0]
+	default:
+		config["appPort"] = values
+	}
+}
+
+// checkAppPort accepts what docker run -p publishes: a container port,
+// host:container or ip:host:container, optionally with /tcp or /udp.
+func checkAppPort(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return fmt.Errorf("port is required")
+	}
+	spec, proto, _ := strings.Cut(s, "/")
+	if proto != "" && proto != "tcp" && proto != "udp" {
+		return fmt.Errorf("unknown protocol %q: use tcp or udp", proto)
+	}
+	parts := strings.Split(spec, ":")
+	if len(parts) > 3 {
+		return fmt.Errorf("use port, host:container or ip:host:container")
+	}
+	if !isPortNumber(parts[len(parts)-1]) {
+		return fmt.Errorf("%q is not a container port", parts[len(parts)-1])
+	}
+	// Only ip:host:container may leave the host port to Docker.
+	if len(parts) >= 2 {
+		if host := parts[len(parts)-2]; (host != "" || len(parts) == 2) && !isPortNumber(host) {
+			return fmt.Errorf("%q is not a host port", host)
+		}
+	}
+	if len(parts) == 3 && net.ParseIP(parts[0]) == nil {
+		return fmt.Errorf("%q is not an IP address", parts[0])
+	}
+	return nil
+}
+
+// isPortNumber reports whether s is a port number from 1 to 65535.
+func isPortNumber(s string) bool {
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 1 && n <= 65535
+}
+
+// editAppPortField edits appPort like the forwarded ports: a list where each
+// port can be edited or removed and new ones added.
+func editAppPortField(config map[string]any) (bool, error) {
+	ports := readAppPorts(config)
+	changed := false
+
+	const (
+		choiceAdd  = -1
+		choiceDone = -2
+	)
+
+	for {
+		opts := make([]huh.Option[int], 0, len(ports)+2)
+		for i, p := range ports {
+			opts = append(opts, huh.NewOption(p, i))
+		}
+		opts = append(opts, huh.NewOption("+ Add port", choiceAdd))
+		opts = append(opts, huh.NewOption("Done", choiceDone))
+
+		choice := choiceDone
+		if len(ports) == 0 {
+			choice = choiceAdd
+		}
+		form := NewForm(huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("App Port").
+				Description("Published on the host like docker run -p, even where the tool can't forward ports.\nPrefer Forward Ports, which needs no publishing and no fixed host port.").
+				Options(opts...).
+				Value(&choice),
+		))
+		if err := form.Run(); err != nil {
+			return false, fmt.Errorf("editing appPort: %w", err)
+		}
+
+		switch choice {
+		case choiceDone:
+			if changed {
+				writeAppPorts(config, ports)
+			}
+			return changed, nil
+
+		case choiceAdd:
+			port, remove, err := runAppPortForm("", false)
+			if err != nil {
+				return false, err
+			}
+			if !remove && port != "" {
+				ports = append(ports, port)
+				changed = true
+			}
+
+		default:
+			port, remove, err := runAppPortForm(ports[choice], true)
+			if err != nil {
+				return false, err
+			}
+			switch {
+			case remove:
+				ports = append(ports[:choice], ports[choice+1:]...)
+				changed = true
+			case port != ports[choice]:
+				ports[choice] = port
+				changed = true
+			}
+		}
+	}
+}
+
+// runAppPortForm edits one appPort entry, offering to remove an existing
+// one. Returns the port and whether to remove it.
+func runAppPortForm(port string, existing bool) (string, bool, error) {
+	remove := false
+	fields := []huh.Field{
+		huh.NewInput().
+			Title("Port").
+			Description("Container port, host:container or ip:host:container (e.g. 3000, 8080:80, 127.0.0.1:5432:5432)").
+			Value(&port).
+			Validate(func(s string) error {
+				if remove {
+					return nil
+				}
+				return checkAppPort(s)
+			}),
+	}
+	if existing {
+		fields = append([]huh.Field{
+			huh.NewConfirm().Title("Remove this port?").Value(&remove),
+		}, fields...)
+	}
+
+	form := NewForm(huh.NewGroup(fields...))
+	if err := form.Run(); err != nil {
+		return port, false, fmt.Errorf("editing port: %w", err)
+	}
+	return strings.TrimSpace(port), remove, nil
+}
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadWriteAppPorts(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     any
+		ports  []string
+		edited []string
+		want   any
+	}{
+		{"number", float64(3000), []string{"3000"}, []string{"3001"}, 3001},
+		{"string", "8080:80", []string{"8080:80"}, []string{"8080:80", "3000"}, []any{"8080:80", 3000}},
+		{"array", []any{float64(3000), "127.0.0.1:5432:5432"}, []string{"3000", "127.0.0.1:5432:5432"}, []string{"3000"}, []any{float64(3000)}},
+		{"quoted number", []any{"3000", "4000"}, []string{"3000", "4000"}, []string{"3000", "5000"}, []any{"3000", 5000}},
+		{"removed", float64(3000), []string{"3000"}, nil, nil},
+	}
+	for _, tt := range tests {
+		config := map[string]any{"appPort": tt.in}
+		if got := readAppPorts(config); !reflect.DeepEqual(got, tt.ports) {
+			t.Errorf("%s: readAppPorts = %q, want %q", tt.name, got, tt.ports)
+		}
+		writeAppPorts(config, tt.edited)
+		if got, set := config["appPort"]; !reflect.DeepEqual(got, tt.want) || set != (tt.want != nil) {
+			t.Errorf("%s: appPort = %#v, want %#v", tt.name, got, tt.want)
+		}
+	}
+
+	if got := readAppPorts(map[string]any{}); len(got) != 0 {
+		t.Errorf("readAppPorts without appPort = %q", got)
+	}
+}
+
+func TestCheckAppPort(t *testing.T) {
+	for _, ok := range []string{"3000", "8080:80", "127.0.0.1:5432:5432", "127.0.0.1::80", "53:53/udp"} {
+		if err := checkAppPort(ok); err != nil {
+			t.Errorf("checkAppPort(%q) = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"", "web", "8080:", "1:2:3:4", "80/sctp", "70000", "abc:80", ":80", "0:80", "localhost:8080:80", "127.0.0.1:web:80"} {
+		if err := checkAppPort(bad); err == nil {
+			t.Errorf("checkAppPort(%q) accepted", bad)
+		}
+	}
+}
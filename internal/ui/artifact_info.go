@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+// artifactInfoDelay is how long an item stays highlighted before its
+// artifact info is fetched, so scrolling past items doesn't fetch them all.
+const artifactInfoDelay = 400 * time.Millisecond
+
+// artifactInfoDueMsg fires artifactInfoDelay after ref was highlighted.
+type artifactInfoDueMsg struct {
+	ref string
+}
+
+// artifactInfoFetchedMsg carries the artifact info of ref.
+type artifactInfoFetchedMsg struct {
+	ref  string
+	info registry.ArtifactInfo
+	err  error
+}
+
+// artifactInfos fetches the size and publish date of the highlighted picker
+// item in the background and keeps them by ref for the preview.
+type artifactInfos struct {
+	highlighted string
+	infos       map[string]registry.ArtifactInfo
+}
+
+func newArtifactInfos() artifactInfos {
+	return artifactInfos{infos: make(map[string]registry.ArtifactInfo)}
+}
+
+// highlight notes that ref is highlighted now and returns the timer after
+// which its info is fetched, unless it is known already.
+func (a *artifactInfos) highlight(ref string) tea.Cmd {
+	if ref == "" || ref == a.highlighted {
+		return nil
+	}
+	a.highlighted = ref
+	if _, ok := a.infos[ref]; ok {
+		return nil
+	}
+	return tick(artifactInfoDelay, func(time.Time) tea.Msg {
+		return artifactInfoDueMsg{ref: ref}
+	})
+}
+
+// handle processes the messages of highlight and the fetch it starts.
+func (a *artifactInfos) handle(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case artifactInfoDueMsg:
+		if _, ok := a.infos[msg.ref]; ok || msg.ref != a.highlighted {
+			return nil
+		}
+		return func() tea.Msg {
+			info, err := registry.FetchArtifactInfo(msg.ref)
+			return artifactInfoFetchedMsg{ref: msg.ref, info: info, err: err}
+		}
+	case artifactInfoFetchedMsg:
+		// A failed fetch is kept as unknown rather than retried.
+		a.infos[msg.ref] = msg.info
+	}
+	return nil
+}
+
+// artifactSummary describes the artifact info of ref for the preview title,
+// e.g. "12.4 KB · published 2025-03-04", or "" if it isn't known.
+func artifactSummary(infos map[string]registry.ArtifactInfo, ref string) string {
+	info, ok := infos[ref]
+	if !ok || info.Size == 0 {
+		return ""
+	}
+	label := formatSize(info.Size)
+	if !info.Published.IsZero() {
+		label += " · published " + info.Published.Format(time.DateOnly)
+	}
+	return label
+}
+
+// formatSize formats a byte count with a binary unit, e.g. 12.4 KB.
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	size, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if size < unit {
+			break
+		}
+		size, suffix = size/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", size, suffix)
+}
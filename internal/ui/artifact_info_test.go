@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{512, "512 B"},
+		{12698, "12.4 KB"},
+		{3 << 20, "3.0 MB"},
+		{5 << 30, "5.0 GB"},
+	}
+	for _, tt := range tests {
+		if got := formatSize(tt.n); got != tt.want {
+			t.Errorf("formatSize(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestArtifactSummary(t *testing.T) {
+	infos := map[string]registry.ArtifactInfo{
+		"a:1": {Size: 2048, Published: time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)},
+		"b:1": {Size: 2048},
+		"c:1": {}, // failed fetch
+	}
+	tests := map[string]string{
+		"a:1": "2.0 KB · published 2025-03-04",
+		"b:1": "2.0 KB",
+		"c:1": "",
+		"d:1": "",
+	}
+	for ref, want := range tests {
+		if got := artifactSummary(infos, ref); got != want {
+			t.Errorf("artifactSummary(%s) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestArtifactInfosFetchAfterDelay(t *testing.T) {
+	a := newArtifactInfos()
+	if a.highlight("a:1") == nil {
+		t.Fatal("highlighting a new ref should start the delay")
+	}
+	if a.highlight("a:1") != nil {
+		t.Error("a ref still highlighted should not restart the delay")
+	}
+
+	// Scrolled on before the delay passed: nothing is fetched.
+	a.highlight("b:1")
+	if a.handle(artifactInfoDueMsg{ref: "a:1"}) != nil {
+		t.Error("a ref no longer highlighted was fetched")
+	}
+	if a.handle(artifactInfoDueMsg{ref: "b:1"}) == nil {
+		t.Error("the highlighted ref was not fetched")
+	}
+
+	a.handle(artifactInfoFetchedMsg{ref: "b:1", info: registry.ArtifactInfo{Size: 10}})
+	a.highlight("a:1")
+	if a.highlight("b:1") != nil || a.handle(artifactInfoDueMsg{ref: "b:1"}) != nil {
+		t.Error("a known ref was fetched again")
+	}
+}
+
+func TestPreviewShowsArtifactInfo(t *testing.T) {
+	m := newFeaturePicker(pickerEntries, nil, "")
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 30})
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'?'}})
+	ref := FormatFeatureOciRef(&pickerEntries[0])
+	model, _ = model.Update(artifactInfoFetchedMsg{ref: ref, info: registry.ArtifactInfo{Size: 2048}})
+	m = model.(featurePickerModel)
+
+	if !strings.Contains(m.preview.View(), "2.0 KB") {
+		t.Errorf("preview does not show the size:\n%s", m.preview.View())
+	}
+	if strings.Contains(m.list.View(), "2.0 KB") {
+		t.Error("the list item description still shows the size")
+	}
+}
+
+func TestPickersKeepHighlightState(t *testing.T) {
+	ref := FormatFeatureOciRef(&pickerEntries[0])
+	model, _ := newFeaturePicker(pickerEntries, nil, "").Update(tea.WindowSizeMsg{Width: 80, Height: 20})
+	if m := model.(featurePickerModel); m.artifacts.highlighted != ref || m.preview.prefetchKey == "" {
+		t.Errorf("feature picker: highlighted = %q, prefetchKey = %q; want the state of %s", m.artifacts.highlighted, m.preview.prefetchKey, ref)
+	}
+
+	// The empty template comes first.
+	ref = FormatOciRefWithVersion(&pickerEntries[0])
+	model, _ = newTemplatePicker(pickerEntries, t.TempDir()).Update(tea.KeyMsg{Type: tea.KeyDown})
+	if m := model.(templatePickerModel); m.artifacts.highlighted != ref || m.preview.prefetchKey == "" {
+		t.Errorf("template picker: highlighted = %q, prefetchKey = %q; want the state of %s", m.artifacts.highlighted, m.preview.prefetchKey, ref)
+	}
+}
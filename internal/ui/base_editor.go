@@ -0,0 +1,392 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/template"
+)
+
+// Base kinds for the mutually exclusive image / Dockerfile / Compose setups.
+const (
+	baseImage      = "image"
+	baseDockerfile = "dockerfile"
+	baseCompose    = "compose"
+)
+
+// defaultComposeWorkspaceFolder is the workspaceFolder dcc suggests for
+// Compose configs, where the key is required.
+const defaultComposeWorkspaceFolder = "/workspaces/${localWorkspaceFolderBasename}"
+
+// baseSpec describes how the container is created.
+type baseSpec struct {
+	Kind            string
+	Image           string
+	Dockerfile      string
+	Context         string
+	Args            map[string]string
+	ComposeFiles    []string
+	Service         string
+	WorkspaceFolder string
+}
+
+// baseKeys are the top-level keys owned by the base setting.
+var baseKeys = []string{"image", "build", "dockerFile", "context", "dockerComposeFile", "service", "runServices", "workspaceFolder"}
+
+// readBase detects the current base from config.
+func readBase(config map[string]any) baseSpec {
+	if files := composeFiles(config["dockerComposeFile"]); len(files) > 0 {
+		return baseSpec{
+			Kind:            baseCompose,
+			ComposeFiles:    files,
+			Service:         getString(config, "service"),
+			WorkspaceFolder: getString(config, "workspaceFolder"),
+		}
+	}
+
+	build, _ := config["build"].(map[string]any)
+	dockerfile, _ := build["dockerfile"].(string)
+	if dockerfile == "" {
+		dockerfile = getString(config, "dockerFile") // legacy top-level form
+	}
+	if dockerfile != "" {
+		spec := baseSpec{Kind: baseDockerfile, Dockerfile: dockerfile}
+		if spec.Context, _ = build["context"].(string); spec.Context == "" {
+			spec.Context = getString(config, "context")
+		}
+		if args, ok := build["args"].(map[string]any); ok {
+			spec.Args = make(map[string]string, len(args))
+			for k, v := range args {
+				spec.Args[k] = fmt.Sprint(v)
+			}
+		}
+		return spec
+	}
+
+	return baseSpec{Kind: baseImage, Image: getString(config, "image")}
+}
+
+func composeFiles(v any) []string {
+	switch files := v.(type) {
+	case string:
+		if files != "" {
+			return []string{files}
+		}
+	case []any:
+		var out []string
+		for _, f := range files {
+			if s, ok := f.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// applyBase writes spec to config and removes the keys of the other base
+// kinds, so the result never mixes image, Dockerfile and Compose settings.
+// workspaceFolder is dropped when leaving Compose, where it was required.
+func applyBase(config map[string]any, spec baseSpec) {
+	wasCompose := readBase(config).Kind == baseCompose
+	build, _ := config["build"].(map[string]any)
+
+	for _, k := range baseKeys {
+		if k == "workspaceFolder" && !wasCompose && spec.Kind != baseCompose {
+			continue
+		}
+		if k == "runServices" && spec.Kind == baseCompose {
+			continue
+		}
+		delete(config, k)
+	}
+
+	switch spec.Kind {
+	case baseImage:
+		setString(config, "image", spec.Image)
+
+	case baseDockerfile:
+		// Keep other build properties (target, cacheFrom, options, ...).
+		if build == nil {
+			build = make(map[string]any)
+		}
+		build["dockerfile"] = spec.Dockerfile
+		if spec.Context != "" {
+			build["context"] = spec.Context
+		} else {
+			delete(build, "context")
+		}
+		if len(spec.Args) > 0 {
+			args := make(map[string]any, len(spec.Args))
+			for k, v := range spec.Args {
+				args[k] = v
+			}
+			build["args"] = args
+		} else {
+			delete(build, "args")
+		}
+		config["build"] = build
+
+	case baseCompose:
+		if len(spec.ComposeFiles) == 1 {
+			config["dockerComposeFile"] = spec.ComposeFiles[0]
+		} else {
+			files := make([]any, len(spec.ComposeFiles))
+			for i, f := range spec.ComposeFiles {
+				files[i] = f
+			}
+			config["dockerComposeFile"] = files
+		}
+		config["service"] = spec.Service
+		config["workspaceFolder"] = spec.WorkspaceFolder
+		// workspaceMount only applies to image and Dockerfile configs.
+		delete(config, "workspaceMount")
+	}
+}
+
+// editBaseField asks for the base kind and then its details. configDir is
+// the folder of devcontainer.json, where a missing Dockerfile can be
+// scaffolded.
+func editBaseField(config map[string]any, configDir string) (bool, error) {
+	before := readBase(config)
+	spec := before
+
+	kindForm := NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Base").
+			Description("How the container is created. Keys of the other kinds are removed.").
+			Options(
+				huh.NewOption("Image reference", baseImage),
+				huh.NewOption("Dockerfile", baseDockerfile),
+				huh.NewOption("Docker Compose", baseCompose),
+			).
+			Value(&spec.Kind),
+	))
+	if err := kindForm.Run(); err != nil {
+		return false, fmt.Errorf("editing base: %w", err)
+	}
+
+	var fields []huh.Field
+	var args, files string
+	switch spec.Kind {
+	case baseImage:
+		fields = append(fields, huh.NewInput().
+			Title("Image").
+			Description("e.g. mcr.microsoft.com/devcontainers/base:ubuntu").
+			Validate(requiredValue("image")).
+			Value(&spec.Image))
+
+	case baseDockerfile:
+		if spec.Dockerfile == "" {
+			spec.Dockerfile = "Dockerfile"
+		}
+		args = joinArgs(spec.Args)
+		fields = append(fields,
+			huh.NewInput().
+				Title("Dockerfile").
+				Description("Path relative to devcontainer.json").
+				Validate(requiredValue("dockerfile")).
+				Value(&spec.Dockerfile),
+			huh.NewInput().
+				Title("Context").
+				Description("Build context relative to devcontainer.json (blank for the default)").
+				Value(&spec.Context),
+			huh.NewText().
+				Title("Build Args").
+				Description("KEY=VALUE per line (# for comments)").
+				Validate(checkEnvLines).
+				Value(&args),
+		)
+
+	case baseCompose:
+		files = strings.Join(spec.ComposeFiles, ", ")
+		if files == "" {
+			files = "docker-compose.yml"
+		}
+		if spec.WorkspaceFolder == "" {
+			spec.WorkspaceFolder = defaultComposeWorkspaceFolder
+		}
+		fields = append(fields,
+			huh.NewInput().
+				Title("Compose Files").
+				Description("Comma-separated, relative to devcontainer.json").
+				Validate(requiredValue("compose file")).
+				Value(&files),
+			huh.NewInput().
+				Title("Service").
+				Description("The compose service dcc should connect to").
+				Validate(requiredValue("service")).
+				Value(&spec.Service),
+			huh.NewInput().
+				Title("Workspace Folder").
+				Description("Path of the workspace inside the container").
+				Validate(requiredValue("workspace folder")).
+				Value(&spec.WorkspaceFolder),
+		)
+	}
+
+	if err := NewForm(huh.NewGroup(fields...)).Run(); err != nil {
+		return false, fmt.Errorf("editing base: %w", err)
+	}
+
+	spec.Image = strings.TrimSpace(spec.Image)
+	spec.Dockerfile = strings.TrimSpace(spec.Dockerfile)
+	spec.Context = strings.TrimSpace(spec.Context)
+	spec.Service = strings.TrimSpace(spec.Service)
+	spec.WorkspaceFolder = strings.TrimSpace(spec.WorkspaceFolder)
+	if spec.Kind == baseDockerfile {
+		spec.Args = parseArgs(args)
+	}
+	if spec.Kind == baseCompose {
+		spec.ComposeFiles = nil
+		for _, f := range strings.Split(files, ",") {
+			if f = strings.TrimSpace(f); f != "" {
+				spec.ComposeFiles = append(spec.ComposeFiles, f)
+			}
+		}
+	}
+
+	if spec.Kind == baseDockerfile {
+		if err := offerStarterDockerfile(filepath.Join(configDir, spec.Dockerfile), before.Image); err != nil {
+			return false, err
+		}
+	}
+
+	if baseEqual(before, spec) {
+		return false, nil
+	}
+	applyBase(config, spec)
+	return true, nil
+}
+
+// offerStarterDockerfile offers to create a missing Dockerfile at path from
+// one of the starter bases, or from image, the image configured before.
+func offerStarterDockerfile(path, image string) error {
+	if _, err := devcontainer.ReadFile(path); err == nil {
+		return nil
+	}
+
+	var options []huh.Option[string]
+	if image != "" {
+		options = append(options, huh.NewOption("Current image ("+image+")", image))
+	}
+	for _, b := range template.StarterBases {
+		if b.Image != image {
+			options = append(options, huh.NewOption(fmt.Sprintf("%s (%s)", b.Name, b.Image), b.Image))
+		}
+	}
+	options = append(options, huh.NewOption("Don't create one, I'll write it", ""))
+
+	from := options[0].Value
+	form := NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Create " + filepath.Base(path) + "?").
+			Description(path + " doesn't exist yet. Start it FROM:").
+			Options(options...).
+			Value(&from),
+	))
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("scaffolding Dockerfile: %w", err)
+	}
+	if from == "" {
+		return nil
+	}
+	_, err := template.ScaffoldDockerfile(path, from)
+	return err
+}
+
+// editBuildArgsField edits build.args of a Dockerfile base.
+func editBuildArgsField(config map[string]any) (bool, error) {
+	spec := readBase(config)
+	if spec.Kind != baseDockerfile {
+		form := NewForm(huh.NewGroup(
+			huh.NewNote().Title("Build Args").Description("Build args are passed to a Dockerfile build.\nSwitch the Base to a Dockerfile first."),
+		))
+		if err := form.Run(); err != nil {
+			return false, fmt.Errorf("editing build args: %w", err)
+		}
+		return false, nil
+	}
+
+	val := joinArgs(spec.Args)
+	before := val
+	form := NewForm(huh.NewGroup(
+		huh.NewText().
+			Title("Build Args").
+			Description("KEY=VALUE per line, available as ARG in the Dockerfile (# for comments)").
+			Validate(checkEnvLines).
+			Value(&val),
+	))
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("editing build args: %w", err)
+	}
+
+	if strings.TrimSpace(val) == strings.TrimSpace(before) {
+		return false, nil
+	}
+	writeBuildArgs(config, parseArgs(val))
+	return true, nil
+}
+
+// writeBuildArgs stores args in build.args, removing the key when empty.
+// The legacy top-level dockerFile form is moved into build like applyBase does.
+func writeBuildArgs(config map[string]any, args map[string]string) {
+	spec := readBase(config)
+	spec.Args = args
+	applyBase(config, spec)
+}
+
+func requiredValue(name string) func(string) error {
+	return func(s string) error {
+		if strings.TrimSpace(s) == "" {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+}
+
+func joinArgs(args map[string]string) string {
+	keys := make([]string, 0, len(args))
+	for k := range args {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = k + "=" + args[k]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func parseArgs(val string) map[string]string {
+	args := make(map[string]string)
+	for _, line := range strings.Split(val, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			args[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return args
+}
+
+func baseEqual(a, b baseSpec) bool {
+	if a.Kind != b.Kind || a.Image != b.Image || a.Dockerfile != b.Dockerfile ||
+		a.Context != b.Context || a.Service != b.Service || a.WorkspaceFolder != b.WorkspaceFolder ||
+		strings.Join(a.ComposeFiles, "\x00") != strings.Join(b.ComposeFiles, "\x00") ||
+		len(a.Args) != len(b.Args) {
+		return false
+	}
+	for k, v := range a.Args {
+		if b.Args[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadBase(t *testing.T) {
+	tests := []struct {
+		config map[string]any
+		want   baseSpec
+	}{
+		{map[string]any{"image": "ubuntu"}, baseSpec{Kind: baseImage, Image: "ubuntu"}},
+		{
+			map[string]any{"build": map[string]any{"dockerfile": "Dockerfile", "context": "..", "args": map[string]any{"V": "1"}}},
+			baseSpec{Kind: baseDockerfile, Dockerfile: "Dockerfile", Context: "..", Args: map[string]string{"V": "1"}},
+		},
+		{map[string]any{"dockerFile": "Dockerfile"}, baseSpec{Kind: baseDockerfile, Dockerfile: "Dockerfile"}},
+		{
+			map[string]any{"dockerComposeFile": []any{"a.yml", "b.yml"}, "service": "app", "workspaceFolder": "/w"},
+			baseSpec{Kind: baseCompose, ComposeFiles: []string{"a.yml", "b.yml"}, Service: "app", WorkspaceFolder: "/w"},
+		},
+	}
+	for _, tt := range tests {
+		if got := readBase(tt.config); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("readBase(%v) = %+v, want %+v", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestApplyBaseComposeToImage(t *testing.T) {
+	config := map[string]any{
+		"name":              "app",
+		"dockerComposeFile": "docker-compose.yml",
+		"service":           "app",
+		"runServices":       []any{"db"},
+		"workspaceFolder":   "/workspaces/app",
+	}
+	applyBase(config, baseSpec{Kind: baseImage, Image: "ubuntu"})
+
+	want := map[string]any{"name": "app", "image": "ubuntu"}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %v, want %v", config, want)
+	}
+}
+
+func TestApplyBaseImageToDockerfile(t *testing.T) {
+	config := map[string]any{
+		"image":           "ubuntu",
+		"workspaceFolder": "/src",
+		"build":           map[string]any{"target": "dev"},
+	}
+	applyBase(config, baseSpec{Kind: baseDockerfile, Dockerfile: "Dockerfile", Args: map[string]string{"V": "1"}})
+
+	want := map[string]any{
+		"workspaceFolder": "/src",
+		"build":           map[string]any{"target": "dev", "dockerfile": "Dockerfile", "args": map[string]any{"V": "1"}},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %v, want %v", config, want)
+	}
+}
+
+func TestApplyBaseToCompose(t *testing.T) {
+	config := map[string]any{"image": "ubuntu", "workspaceMount": "source=.,target=/w,type=bind"}
+	applyBase(config, baseSpec{Kind: baseCompose, ComposeFiles: []string{"a.yml", "b.yml"}, Service: "app", WorkspaceFolder: "/w"})
+
+	want := map[string]any{
+		"dockerComposeFile": []any{"a.yml", "b.yml"},
+		"service":           "app",
+		"workspaceFolder":   "/w",
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %v, want %v", config, want)
+	}
+}
+
+func TestWriteBuildArgs(t *testing.T) {
+	config := map[string]any{
+		"build": map[string]any{"dockerfile": "Dockerfile", "target": "dev", "args": map[string]any{"OLD": "1"}},
+	}
+	writeBuildArgs(config, map[string]string{"NODE_VERSION": "22"})
+	want := map[string]any{
+		"build": map[string]any{"dockerfile": "Dockerfile", "target": "dev", "args": map[string]any{"NODE_VERSION": "22"}},
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("config = %v, want %v", config, want)
+	}
+
+	writeBuildArgs(config, nil)
+	if _, ok := config["build"].(map[string]any)["args"]; ok {
+		t.Errorf("empty args kept: %v", config)
+	}
+}
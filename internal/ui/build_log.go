@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// jsonLogLine is a line of devcontainer CLI output under --log-format json,
+// or the result object the CLI prints last in either format.
+type jsonLogLine struct {
+	Text        string `json:"text"`
+	Message     string `json:"message"`
+	Level       any    `json:"level"` // a number (5 is error) or a name
+	Outcome     string `json:"outcome"`
+	Description string `json:"description"`
+}
+
+// parseJSONLogLine decodes a JSON log line into its text, split into lines,
+// and whether its level or outcome marks an error. ok is false for plain
+// text.
+func parseJSONLogLine(line string) (lines []string, isError, ok bool) {
+	if !strings.HasPrefix(line, "{") {
+		return nil, false, false
+	}
+	var entry jsonLogLine
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		return nil, false, false
+	}
+
+	text := entry.Text
+	if text == "" {
+		text = entry.Message
+	}
+	switch level := entry.Level.(type) {
+	case float64:
+		isError = level >= 5
+	case string:
+		isError = strings.EqualFold(level, "error")
+	}
+	if entry.Outcome != "" {
+		isError = entry.Outcome == "error"
+		if entry.Description != "" {
+			text = entry.Description
+		}
+	}
+
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines, isError, true
+}
+
+// isBuildErrorLine reports whether a plain-text output line reports the
+// failure, as BuildKit and the devcontainer CLI word it.
+func isBuildErrorLine(line string) bool {
+	return strings.Contains(line, "ERROR:") ||
+		strings.Contains(line, "did not complete successfully") ||
+		strings.Contains(line, "exit code:")
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilterBuildOutput(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    string
+	}{
+		{"build-output.txt", `#7 ERROR: process "/bin/sh -c /tmp/dev-container-features/install.sh" did not complete successfully: exit code: 6
+ERROR: failed to solve: process "/bin/sh -c /tmp/dev-container-features/install.sh" did not complete successfully: exit code: 6
+An error occurred building the image.
+
+exit status 1`},
+		{"build-output.jsonl", `#7 ERROR: process "/bin/sh -c /tmp/dev-container-features/install.sh" did not complete successfully: exit code: 6
+Error: Command failed: docker buildx build --load -f /tmp/devcontainercli/Dockerfile-with-features -t vsc-api-features .
+Exit code 1
+An error occurred building the image.
+
+exit status 1`},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join("testdata", tt.fixture))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filterBuildOutput(string(data), errors.New("exit status 1")); got != tt.want {
+			t.Errorf("%s: filterBuildOutput =\n%s\nwant\n%s", tt.fixture, got, tt.want)
+		}
+	}
+}
+
+func TestParseJSONLogLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		lines   []string
+		isError bool
+		ok      bool
+	}{
+		{`{"type":"text","level":3,"text":"Container started"}`, []string{"Container started"}, false, true},
+		{`{"type":"text","level":5,"text":"Error: boom"}`, []string{"Error: boom"}, true, true},
+		{`{"level":"ERROR","message":"boom"}`, []string{"boom"}, true, true},
+		{`{"outcome":"success","containerId":"abc"}`, nil, false, true},
+		{"#7 ERROR: failed", nil, false, false},
+		{"{not json", nil, false, false},
+	}
+	for _, tt := range tests {
+		lines, isError, ok := parseJSONLogLine(tt.line)
+		if len(lines) != len(tt.lines) || (len(lines) > 0 && lines[0] != tt.lines[0]) || isError != tt.isError || ok != tt.ok {
+			t.Errorf("parseJSONLogLine(%s) = %q, %v, %v; want %q, %v, %v", tt.line, lines, isError, ok, tt.lines, tt.isError, tt.ok)
+		}
+	}
+}
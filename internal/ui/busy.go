@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// busyIndicator animates a busy label with a spinner and the time elapsed
+// since the work started. Its ticks re-render the view, so the elapsed
+// time stays current.
+type busyIndicator struct {
+	spinner spinner.Model
+	started time.Time
+}
+
+func newBusyIndicator() busyIndicator {
+	return busyIndicator{
+		spinner: spinner.New(spinner.WithSpinner(spinner.MiniDot)),
+		started: time.Now(),
+	}
+}
+
+// start resets the elapsed time and returns the command that starts the
+// spinner ticking.
+func (b *busyIndicator) start() tea.Cmd {
+	b.started = time.Now()
+	return b.spinner.Tick
+}
+
+// update advances the spinner and schedules its next tick.
+func (b busyIndicator) update(msg spinner.TickMsg) (busyIndicator, tea.Cmd) {
+	var cmd tea.Cmd
+	b.spinner, cmd = b.spinner.Update(msg)
+	return b, cmd
+}
+
+// view renders label after the spinner frame, followed by the elapsed time.
+func (b busyIndicator) view(label string) string {
+	return previewBusyStyle.Render(b.spinner.View()+" "+label) +
+		previewHintStyle.Render(formatElapsed(time.Since(b.started)))
+}
+
+// formatElapsed formats d in whole seconds, e.g. "42s" or "1m05s".
+func formatElapsed(d time.Duration) string {
+	secs := int(d / time.Second)
+	if secs < 60 {
+		return fmt.Sprintf("%ds", secs)
+	}
+	return fmt.Sprintf("%dm%02ds", secs/60, secs%60)
+}
@@ -0,0 +1,211 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// codespacesMachine is a Codespaces machine type. Codespaces picks the
+// smallest machine that meets hostRequirements, so a machine type is set
+// through them.
+type codespacesMachine struct {
+	name string
+	spec hostRequirementsSpec
+}
+
+var codespacesMachines = []codespacesMachine{
+	{"2-core", hostRequirementsSpec{CPUs: "2", Memory: "8gb", Storage: "32gb"}},
+	{"4-core", hostRequirementsSpec{CPUs: "4", Memory: "16gb", Storage: "32gb"}},
+	{"8-core", hostRequirementsSpec{CPUs: "8", Memory: "32gb", Storage: "64gb"}},
+	{"16-core", hostRequirementsSpec{CPUs: "16", Memory: "64gb", Storage: "128gb"}},
+	{"32-core", hostRequirementsSpec{CPUs: "32", Memory: "128gb", Storage: "128gb"}},
+}
+
+// codespacesSpec holds customizations.codespaces and the machine type as
+// typed.
+type codespacesSpec struct {
+	Repositories string // one "owner/repo contents=write ..." per line
+	OpenFiles    string // comma-separated paths
+	Machine      string // name of a codespacesMachines entry, "" for custom
+}
+
+// readCodespaces formats customizations.codespaces for editing.
+func readCodespaces(config map[string]any) codespacesSpec {
+	codespaces := devcontainer.Customization(config, "codespaces")
+	return codespacesSpec{
+		Repositories: formatRepositories(codespaces),
+		OpenFiles:    joinCSV(codespaces, "openFiles"),
+		Machine:      machineFor(readHostRequirements(config)),
+	}
+}
+
+// writeCodespaces stores spec, removing blank keys. A machine type replaces
+// the cpus, memory and storage of hostRequirements.
+func writeCodespaces(config map[string]any, spec codespacesSpec) {
+	repos, _ := parseRepositories(spec.Repositories)
+	if len(repos) > 0 {
+		devcontainer.SetCustomization(config, "codespaces", "repositories", repos)
+	} else {
+		devcontainer.SetCustomization(config, "codespaces", "repositories", nil)
+	}
+
+	var files []any
+	for _, f := range strings.Split(spec.OpenFiles, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			files = append(files, f)
+		}
+	}
+	if len(files) > 0 {
+		devcontainer.SetCustomization(config, "codespaces", "openFiles", files)
+	} else {
+		devcontainer.SetCustomization(config, "codespaces", "openFiles", nil)
+	}
+
+	for _, m := range codespacesMachines {
+		if m.name == spec.Machine {
+			writeHostRequirements(config, m.spec)
+		}
+	}
+}
+
+// machineFor returns the machine type whose hostRequirements spec has, or "".
+func machineFor(spec hostRequirementsSpec) string {
+	spec = normalizeHostRequirements(spec)
+	for _, m := range codespacesMachines {
+		if spec == m.spec {
+			return m.name
+		}
+	}
+	return ""
+}
+
+// formatRepositories lists the repositories of a codespaces namespace one
+// per line, sorted, with their permissions: either "write-all"/"read-all"
+// or name=level pairs.
+func formatRepositories(codespaces map[string]any) string {
+	repos, _ := codespaces["repositories"].(map[string]any)
+	names := make([]string, 0, len(repos))
+	for name := range repos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	lines := make([]string, 0, len(names))
+	for _, name := range names {
+		repo, _ := repos[name].(map[string]any)
+		line := []string{name}
+		switch perms := repo["permissions"].(type) {
+		case string:
+			line = append(line, perms)
+		case map[string]any:
+			keys := make([]string, 0, len(perms))
+			for k := range perms {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				line = append(line, fmt.Sprintf("%s=%v", k, perms[k]))
+			}
+		}
+		lines = append(lines, strings.Join(line, " "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseRepositories parses the repository lines of formatRepositories into
+// the repositories object. Blank lines and # comments are skipped.
+func parseRepositories(text string) (map[string]any, error) {
+	repos := make(map[string]any)
+	for i, line := range strings.Split(text, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		name := fields[0]
+		if owner, repo, ok := strings.Cut(name, "/"); !ok || owner == "" || repo == "" {
+			return nil, fmt.Errorf("line %d: %q is not owner/repo", i+1, name)
+		}
+		if len(fields) == 1 {
+			return nil, fmt.Errorf("line %d: give permissions for %s, e.g. contents=read", i+1, name)
+		}
+		if len(fields) == 2 && (fields[1] == "write-all" || fields[1] == "read-all") {
+			repos[name] = map[string]any{"permissions": fields[1]}
+			continue
+		}
+		perms := make(map[string]any, len(fields)-1)
+		for _, field := range fields[1:] {
+			key, level, ok := strings.Cut(field, "=")
+			if !ok || key == "" {
+				return nil, fmt.Errorf("line %d: %q is not permission=level", i+1, field)
+			}
+			if level != "read" && level != "write" {
+				return nil, fmt.Errorf("line %d: %s must be read or write", i+1, key)
+			}
+			perms[key] = level
+		}
+		repos[name] = map[string]any{"permissions": perms}
+	}
+	return repos, nil
+}
+
+// normalizeCodespaces formats spec the way readCodespaces would after
+// writing it, so edits that only change spacing or order compare equal.
+func normalizeCodespaces(spec codespacesSpec) codespacesSpec {
+	config := make(map[string]any)
+	writeCodespaces(config, spec)
+	normalized := readCodespaces(config)
+	normalized.Machine = spec.Machine
+	return normalized
+}
+
+func checkRepositories(text string) error {
+	_, err := parseRepositories(text)
+	return err
+}
+
+// editCodespacesField edits customizations.codespaces: the permissions the
+// codespace gets on other repositories, the files it opens and, through
+// hostRequirements, its machine type.
+func editCodespacesField(config map[string]any) (bool, error) {
+	before := readCodespaces(config)
+	spec := before
+
+	machines := []huh.Option[string]{huh.NewOption("custom (keep host requirements)", "")}
+	for _, m := range codespacesMachines {
+		label := fmt.Sprintf("%s · %s RAM · %s disk", m.name, m.spec.Memory, m.spec.Storage)
+		machines = append(machines, huh.NewOption(label, m.name))
+	}
+
+	form := NewForm(huh.NewGroup(
+		huh.NewNote().
+			Title("Codespaces").
+			Description("Settings only GitHub Codespaces reads, in customizations.codespaces.\nClear a field to remove its key."),
+		huh.NewText().
+			Title("Repositories").
+			Description("owner/repo with write-all, read-all or permission=level pairs, one per line\nlevels are read or write, e.g. my-org/shared contents=read pull_requests=write").
+			Validate(checkRepositories).
+			Value(&spec.Repositories),
+		huh.NewInput().
+			Title("Open Files").
+			Description("Comma-separated paths opened when the codespace starts, e.g. README.md").
+			Value(&spec.OpenFiles),
+		huh.NewSelect[string]().
+			Title("Machine Type").
+			Description("Sets hostRequirements; Codespaces picks the smallest machine that meets them").
+			Options(machines...).
+			Value(&spec.Machine),
+	))
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("editing codespaces: %w", err)
+	}
+
+	if normalizeCodespaces(spec) == before {
+		return false, nil
+	}
+	writeCodespaces(config, spec)
+	return true, nil
+}
@@ -0,0 +1,92 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCodespacesRoundTrip(t *testing.T) {
+	config := map[string]any{
+		"customizations": map[string]any{
+			"codespaces": map[string]any{
+				"repositories": map[string]any{
+					"my-org/shared": map[string]any{"permissions": map[string]any{"pull_requests": "write", "contents": "read"}},
+					"my-org/docs":   map[string]any{"permissions": "read-all"},
+				},
+				"openFiles":                     []any{"README.md", "src/main.go"},
+				"disableAutomaticConfiguration": true,
+			},
+		},
+		"hostRequirements": map[string]any{"cpus": 4, "memory": "16gb", "storage": "32gb", "gpu": true},
+	}
+
+	spec := readCodespaces(config)
+	want := codespacesSpec{
+		Repositories: "my-org/docs read-all\nmy-org/shared contents=read pull_requests=write",
+		OpenFiles:    "README.md, src/main.go",
+		Machine:      "4-core",
+	}
+	if spec != want {
+		t.Fatalf("readCodespaces = %+v, want %+v", spec, want)
+	}
+
+	spec.Repositories = "my-org/shared contents=write"
+	spec.OpenFiles = ""
+	spec.Machine = "8-core"
+	writeCodespaces(config, spec)
+	wantConfig := map[string]any{
+		"customizations": map[string]any{
+			"codespaces": map[string]any{
+				"repositories": map[string]any{
+					"my-org/shared": map[string]any{"permissions": map[string]any{"contents": "write"}},
+				},
+				"disableAutomaticConfiguration": true,
+			},
+		},
+		"hostRequirements": map[string]any{"cpus": 8, "memory": "32gb", "storage": "64gb", "gpu": true},
+	}
+	if !reflect.DeepEqual(config, wantConfig) {
+		t.Errorf("after write = %v, want %v", config, wantConfig)
+	}
+}
+
+func TestCodespacesCustomMachineKeepsHostRequirements(t *testing.T) {
+	config := map[string]any{"hostRequirements": map[string]any{"cpus": 6}}
+	spec := readCodespaces(config)
+	if spec.Machine != "" {
+		t.Fatalf("Machine = %q, want custom", spec.Machine)
+	}
+	spec.OpenFiles = "README.md"
+	writeCodespaces(config, spec)
+	if got := config["hostRequirements"]; !reflect.DeepEqual(got, map[string]any{"cpus": 6}) {
+		t.Errorf("hostRequirements = %v, want unchanged", got)
+	}
+}
+
+func TestParseRepositories(t *testing.T) {
+	repos, err := parseRepositories("# shared code\nmy-org/shared  contents=read\n\nmy-org/docs write-all\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{
+		"my-org/shared": map[string]any{"permissions": map[string]any{"contents": "read"}},
+		"my-org/docs":   map[string]any{"permissions": "write-all"},
+	}
+	if !reflect.DeepEqual(repos, want) {
+		t.Errorf("parseRepositories = %v, want %v", repos, want)
+	}
+
+	for _, bad := range []string{"shared contents=read", "my-org/shared", "my-org/shared contents", "my-org/shared contents=admin"} {
+		if _, err := parseRepositories(bad); err == nil {
+			t.Errorf("parseRepositories(%q) should fail", bad)
+		}
+	}
+}
+
+func TestNormalizeCodespaces(t *testing.T) {
+	before := codespacesSpec{Repositories: "a/b contents=read issues=write", OpenFiles: "README.md, go.mod"}
+	edited := codespacesSpec{Repositories: "  a/b issues=write contents=read\n", OpenFiles: "README.md,go.mod"}
+	if got := normalizeCodespaces(edited); got != before {
+		t.Errorf("normalizeCodespaces = %+v, want %+v", got, before)
+	}
+}
@@ -0,0 +1,196 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// Shapes a lifecycle command can take in devcontainer.json.
+const (
+	commandString = "string" // run by a shell
+	commandArgs   = "args"   // ["cmd", "arg", ...], run without a shell
+	commandMap    = "map"    // {"name": command, ...}, run in parallel
+)
+
+// commandShape reports the shape of an existing command value; anything
+// unset or unrecognized counts as a string.
+func commandShape(v any) string {
+	switch v.(type) {
+	case []any:
+		return commandArgs
+	case map[string]any:
+		return commandMap
+	}
+	return commandString
+}
+
+// commandText renders v for editing in the given shape: a string as is, an
+// argument list one argument per line, a named map as name=command lines.
+// Array commands inside a map are written as JSON arrays to keep their form.
+func commandText(v any, shape string) string {
+	switch shape {
+	case commandArgs:
+		switch v := v.(type) {
+		case []any:
+			return strings.Join(argStrings(v), "\n")
+		case string:
+			return strings.Join(strings.Fields(v), "\n")
+		}
+
+	case commandMap:
+		m, _ := v.(map[string]any)
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines := make([]string, len(names))
+		for i, name := range names {
+			cmd := m[name]
+			if args, ok := cmd.([]any); ok {
+				data, _ := json.Marshal(argStrings(args))
+				lines[i] = name + "=" + string(data)
+			} else {
+				lines[i] = name + "=" + fmt.Sprint(cmd)
+			}
+		}
+		return strings.Join(lines, "\n")
+
+	default:
+		switch v := v.(type) {
+		case string:
+			return v
+		case []any:
+			return strings.Join(argStrings(v), " ")
+		}
+	}
+	return ""
+}
+
+func argStrings(args []any) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = fmt.Sprint(a)
+	}
+	return out
+}
+
+// parseCommand turns edited text back into a command value of the given
+// shape. Empty input yields nil, which removes the key.
+func parseCommand(text, shape string) (any, error) {
+	switch shape {
+	case commandArgs:
+		var args []any
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				args = append(args, line)
+			}
+		}
+		if len(args) == 0 {
+			return nil, nil
+		}
+		return args, nil
+
+	case commandMap:
+		cmds := make(map[string]any)
+		for i, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			name, cmd, ok := strings.Cut(line, "=")
+			name, cmd = strings.TrimSpace(name), strings.TrimSpace(cmd)
+			if !ok || name == "" || cmd == "" {
+				return nil, fmt.Errorf("line %d: expected name=command", i+1)
+			}
+			if _, dup := cmds[name]; dup {
+				return nil, fmt.Errorf("line %d: %s is defined twice", i+1, name)
+			}
+			var args []string
+			if strings.HasPrefix(cmd, "[") && json.Unmarshal([]byte(cmd), &args) == nil {
+				list := make([]any, len(args))
+				for j, a := range args {
+					list[j] = a
+				}
+				cmds[name] = list
+				continue
+			}
+			cmds[name] = cmd
+		}
+		if len(cmds) == 0 {
+			return nil, nil
+		}
+		return cmds, nil
+
+	default:
+		if text = strings.TrimSpace(text); text == "" {
+			return nil, nil
+		}
+		return text, nil
+	}
+}
+
+// editCommandField edits a lifecycle command in the shape the user picks,
+// defaulting to the shape already in the config.
+func editCommandField(config map[string]any, key, title, desc string) (bool, error) {
+	before := config[key]
+	shape := commandShape(before)
+
+	shapeForm := NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title(title).
+			Description(desc).
+			Options(
+				huh.NewOption("Single command (run by a shell)", commandString),
+				huh.NewOption("Command with arguments (no shell)", commandArgs),
+				huh.NewOption("Named commands (run in parallel)", commandMap),
+			).
+			Value(&shape),
+	))
+	if err := shapeForm.Run(); err != nil {
+		return false, fmt.Errorf("editing %s: %w", key, err)
+	}
+
+	val := commandText(before, shape)
+	var field huh.Field
+	switch shape {
+	case commandArgs:
+		field = huh.NewText().
+			Title(title).
+			Description("One argument per line, the command first (e.g. npm, then install)").
+			Value(&val)
+	case commandMap:
+		field = huh.NewText().
+			Title(title).
+			Description(`name=command per line; a JSON array runs without a shell (e.g. deps=["npm", "ci"])`).
+			Validate(func(s string) error {
+				_, err := parseCommand(s, commandMap)
+				return err
+			}).
+			Value(&val)
+	default:
+		field = huh.NewInput().Title(title).Description(desc).Value(&val)
+	}
+	if err := NewForm(huh.NewGroup(field)).Run(); err != nil {
+		return false, fmt.Errorf("editing %s: %w", key, err)
+	}
+
+	after, err := parseCommand(val, shape)
+	if err != nil {
+		return false, err
+	}
+	if reflect.DeepEqual(before, after) {
+		return false, nil
+	}
+	if after == nil {
+		delete(config, key)
+	} else {
+		config[key] = after
+	}
+	return true, nil
+}
@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCommandRoundTrip(t *testing.T) {
+	tests := []any{
+		"npm install && npm run build",
+		[]any{"npm", "install", "--no-audit"},
+		map[string]any{
+			"server": "npm start",
+			"deps":   []any{"npm", "ci"},
+		},
+	}
+	for _, v := range tests {
+		shape := commandShape(v)
+		got, err := parseCommand(commandText(v, shape), shape)
+		if err != nil || !reflect.DeepEqual(got, v) {
+			t.Errorf("round trip of %#v = %#v, %v", v, got, err)
+		}
+	}
+}
+
+func TestCommandText(t *testing.T) {
+	// Switching shapes carries the command over where it can.
+	if got := commandText("npm run dev", commandArgs); got != "npm\nrun\ndev" {
+		t.Errorf("string as args = %q", got)
+	}
+	if got := commandText([]any{"make", "test"}, commandString); got != "make test" {
+		t.Errorf("args as string = %q", got)
+	}
+	if got := commandText([]any{"make"}, commandMap); got != "" {
+		t.Errorf("args as map = %q, want empty", got)
+	}
+}
+
+func TestParseCommandMap(t *testing.T) {
+	if _, err := parseCommand("build=make\nbuild=make all", commandMap); err == nil {
+		t.Error("expected an error for a duplicate name")
+	}
+	if _, err := parseCommand("just a command", commandMap); err == nil {
+		t.Error("expected an error for a line without name=")
+	}
+	got, err := parseCommand("# comment\n\nlint = npm run lint\n", commandMap)
+	if want := map[string]any{"lint": "npm run lint"}; err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("parseCommand = %#v, %v; want %#v", got, err, want)
+	}
+	if got, _ := parseCommand("  \n", commandArgs); got != nil {
+		t.Errorf("empty args = %#v, want nil", got)
+	}
+}
@@ -2,8 +2,12 @@ package ui
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"path/filepath"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -20,20 +24,30 @@ type settingKey string
 
 const (
 	skName             settingKey = "name"
+	skBase             settingKey = "base"
 	skRemoteUser       settingKey = "remoteUser"
+	skContainerUser    settingKey = "containerUser"
 	skShutdownAction   settingKey = "shutdownAction"
 	skInit             settingKey = "init"
 	skPrivileged       settingKey = "privileged"
 	skForwardPorts     settingKey = "forwardPorts"
+	skAppPort          settingKey = "appPort"
+	skOnCreateCmd      settingKey = "onCreateCommand"
+	skUpdateContentCmd settingKey = "updateContentCommand"
 	skPostCreateCmd    settingKey = "postCreateCommand"
 	skPostStartCmd     settingKey = "postStartCommand"
 	skPostAttachCmd    settingKey = "postAttachCommand"
 	skWaitFor          settingKey = "waitFor"
+	skBuildArgs        settingKey = "buildArgs"
 	skContainerEnv     settingKey = "containerEnv"
 	skRemoteEnv        settingKey = "remoteEnv"
 	skMounts           settingKey = "mounts"
 	skCapAdd           settingKey = "capAdd"
 	skRunArgs          settingKey = "runArgs"
+	skHostReqs         settingKey = "hostRequirements"
+	skWorkspace        settingKey = "workspace"
+	skCodespaces       settingKey = "codespaces"
+	skFeatures         settingKey = "features"
 	skBack             settingKey = "back"
 )
 
@@ -48,6 +62,14 @@ func (i settingsMenuItem) FilterValue() string { return i.label }
 func (i settingsMenuItem) Title() string       { return i.label }
 func (i settingsMenuItem) Description() string { return i.description }
 
+// settingsSectionItem heads the items of a section in the settings list. It
+// can't be selected: the cursor skips it.
+type settingsSectionItem struct {
+	title string
+}
+
+func (i settingsSectionItem) FilterValue() string { return "" }
+
 type settingsDelegate struct{}
 
 func (d settingsDelegate) Height() int                             { return 2 }
@@ -55,6 +77,12 @@ func (d settingsDelegate) Spacing() int                            { return 0 }
 func (d settingsDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
 
 func (d settingsDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	maxW := m.Width()
+	if section, ok := listItem.(settingsSectionItem); ok {
+		header := lipgloss.NewStyle().PaddingLeft(2).Bold(true).Faint(true).Render(section.title)
+		fmt.Fprintf(w, "\n%s", truncateWidth(header, maxW))
+		return
+	}
 	item, ok := listItem.(settingsMenuItem)
 	if !ok {
 		return
@@ -67,15 +95,14 @@ func (d settingsDelegate) Render(w io.Writer, m list.Model, index int, listItem
 
 	title := item.label
 	if isSelected {
-		titleStyle = titleStyle.Bold(true).Foreground(lipgloss.Color("170"))
-		descStyle = descStyle.Foreground(lipgloss.Color("170"))
+		titleStyle = titleStyle.Bold(true).Foreground(theme.Accent)
+		descStyle = descStyle.Foreground(theme.Accent)
 		title = "> " + title
 	} else {
 		title = "  " + title
 	}
 
-	maxW := m.Width()
-	fmt.Fprintf(w, "%s\n%s", titleStyle.MaxWidth(maxW).Render(title), descStyle.MaxWidth(maxW).Render(item.description))
+	fmt.Fprintf(w, "%s\n%s", truncateWidth(titleStyle.Render(title), maxW), truncateWidth(descStyle.Render(item.description), maxW))
 }
 
 type settingsModel struct {
@@ -90,35 +117,43 @@ type settingsModel struct {
 
 var settingsItems = []settingsMenuItem{
 	{skName, "Name", "Display name for this devcontainer", "General"},
-	{skRemoteUser, "Remote User", "User for tool connections (e.g. vscode)", "General"},
+	{skBase, "Base", "Image, Dockerfile or Docker Compose", "General"},
+	{skBuildArgs, "Build Args", "KEY=VALUE per line, passed to the Dockerfile build", "General"},
+	{skRemoteUser, "Remote User", "User tools and terminals connect as (e.g. vscode)", "General"},
+	{skContainerUser, "Container User", "User the container itself runs as; Remote User defaults to it", "General"},
 	{skShutdownAction, "Shutdown Action", "What to do when the IDE closes", "General"},
 	{skInit, "Init Process", "Enable tini init for proper signal handling", "General"},
 	{skPrivileged, "Privileged", "Needed for Docker-in-Docker", "General"},
-	{skForwardPorts, "Forward Ports", "Comma-separated (e.g. 3000, 5432, 8080)", "Ports"},
+	{skForwardPorts, "Forward Ports", "Ports with labels and protocol (portsAttributes)", "Ports"},
+	{skAppPort, "App Port", "Published like docker run -p, unlike forwarded ports (legacy)", "Ports"},
+	{skOnCreateCmd, "On-Create Command", "Runs once when the container is created (prebuild)", "Lifecycle"},
+	{skUpdateContentCmd, "Update-Content Command", "Runs after creation and on content updates (prebuild)", "Lifecycle"},
 	{skPostCreateCmd, "Post-Create Command", "Runs once after container creation", "Lifecycle"},
 	{skPostStartCmd, "Post-Start Command", "Runs on every container start", "Lifecycle"},
 	{skPostAttachCmd, "Post-Attach Command", "Runs on every IDE attach", "Lifecycle"},
 	{skWaitFor, "Wait For", "Which command to wait for before connecting", "Lifecycle"},
 	{skContainerEnv, "Container Env", "KEY=VALUE per line, set on container", "Environment"},
 	{skRemoteEnv, "Remote Env", "KEY=VALUE per line, set for tools", "Environment"},
-	{skMounts, "Mounts", "One per line, ${localWorkspaceFolder} for portability", "Advanced"},
+	{skMounts, "Mounts", "Bind mounts and volumes, edited one at a time", "Advanced"},
 	{skCapAdd, "Linux Capabilities", "Comma-separated (e.g. SYS_PTRACE)", "Advanced"},
 	{skRunArgs, "Docker Run Args", "Comma-separated extra arguments", "Advanced"},
+	{skHostReqs, "Host Requirements", "Minimum cpus, memory and storage", "Advanced"},
+	{skWorkspace, "Workspace Mount", "workspaceMount and workspaceFolder", "Advanced"},
+	{skCodespaces, "Codespaces", "Repository permissions, files to open, machine type", "Customizations"},
+	{skFeatures, "Feature Options", "Edit options of configured features", "Features"},
 	{skBack, "Back", "Return to hub", ""},
 }
 
 func newSettingsModel(config map[string]any) settingsModel {
-	items := make([]list.Item, len(settingsItems))
-	for i, s := range settingsItems {
-		items[i] = s
-	}
+	items := settingsListItems()
 
 	l := list.New(items, settingsDelegate{}, 30, 20)
 	l.Title = "Edit Settings"
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
 	l.SetShowHelp(false)
-	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170")).MarginLeft(2)
+	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).MarginLeft(2)
+	l.Select(1) // below the first section header
 
 	return settingsModel{
 		list:   l,
@@ -126,6 +161,57 @@ func newSettingsModel(config map[string]any) settingsModel {
 	}
 }
 
+// settingsListItems lists settingsItems with a header above each section.
+func settingsListItems() []list.Item {
+	var items []list.Item
+	section := ""
+	for _, s := range settingsItems {
+		if s.section != section && s.section != "" {
+			items = append(items, settingsSectionItem{title: s.section})
+		}
+		section = s.section
+		items = append(items, s)
+	}
+	return items
+}
+
+// jumpSection selects the first item of the next section, or with dir -1
+// of the previous one. Items without a section, like Back, count as one.
+func (m *settingsModel) jumpSection(dir int) {
+	items := m.list.Items()
+	var starts []int
+	section := ""
+	for i, item := range items {
+		if s, ok := item.(settingsMenuItem); ok && (len(starts) == 0 || s.section != section) {
+			starts = append(starts, i)
+			section = s.section
+		}
+	}
+	// The section holding the cursor is the last one starting at or above it.
+	current := 0
+	for i, start := range starts {
+		if start <= m.list.Index() {
+			current = i
+		}
+	}
+	if next := current + dir; next >= 0 && next < len(starts) {
+		m.list.Select(starts[next])
+	}
+}
+
+// skipSectionHeader moves the cursor off a section header, on in the
+// direction it was moving, or down at the top of the list.
+func (m *settingsModel) skipSectionHeader(from int) {
+	if _, ok := m.list.SelectedItem().(settingsSectionItem); !ok {
+		return
+	}
+	if m.list.Index() < from && m.list.Index() > 0 {
+		m.list.CursorUp()
+	} else if m.list.Index() < len(m.list.Items())-1 {
+		m.list.CursorDown()
+	}
+}
+
 func (m settingsModel) Init() tea.Cmd { return nil }
 
 func (m settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -148,16 +234,24 @@ func (m settingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.quitting = true
 				return m, tea.Quit
 			}
+		case "]":
+			m.jumpSection(1)
+			return m, nil
+		case "[":
+			m.jumpSection(-1)
+			return m, nil
 		}
 	}
 
+	from := m.list.Index()
 	var cmd tea.Cmd
 	m.list, cmd = m.list.Update(msg)
+	m.skipSectionHeader(from)
 	return m, cmd
 }
 
 func (m *settingsModel) applyLayout() {
-	menuW := max(m.width/3, 30)
+	menuW := max(splitWidth(m.width), 30)
 	previewW := m.width - menuW
 
 	m.list.SetWidth(menuW)
@@ -174,15 +268,28 @@ func (m settingsModel) renderPreview() string {
 			Render("No settings configured yet")
 	}
 
-	// Show only the settings-relevant keys (not features/customizations)
+	// Show only the settings-relevant keys, of customizations only the
+	// namespaces edited here
 	preview := make(map[string]any)
+	if codespaces := devcontainer.Customization(m.config, "codespaces"); len(codespaces) > 0 {
+		preview["customizations"] = map[string]any{"codespaces": codespaces}
+	}
 	for _, item := range settingsItems {
 		key := string(item.key)
-		if key == "back" {
+		if key == "back" || item.key == skCodespaces {
 			continue
 		}
-		if v, ok := m.config[key]; ok {
-			preview[key] = v
+		keys := []string{key}
+		switch item.key {
+		case skBase:
+			keys = baseKeys
+		case skWorkspace:
+			keys = workspaceKeys
+		}
+		for _, k := range keys {
+			if v, ok := m.config[k]; ok {
+				preview[k] = v
+			}
 		}
 	}
 
@@ -203,19 +310,19 @@ func (m settingsModel) View() string {
 		return ""
 	}
 
-	menuW := max(m.width/3, 30)
+	menuW := max(splitWidth(m.width), 30)
 	previewW := m.width - menuW
 
 	menuView := "\n" + m.list.View()
 	menuClipped := lipgloss.NewStyle().Width(menuW).MaxWidth(menuW).Render(menuView)
 
 	previewTitle := lipgloss.NewStyle().
-		Bold(true).Foreground(lipgloss.Color("170")).PaddingLeft(1).
+		Bold(true).Foreground(theme.Accent).PaddingLeft(1).
 		Render("Current Settings")
 
 	previewBody := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("170")).
+		BorderForeground(theme.Accent).
 		Width(previewW - 2).Height(m.height - 4).
 		Render(m.viewport.View())
 
@@ -226,8 +333,7 @@ func (m settingsModel) View() string {
 // showSettingsMenu displays the settings submenu and returns which setting to edit.
 func showSettingsMenu(config map[string]any) (settingKey, error) {
 	m := newSettingsModel(config)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	final, err := p.Run()
+	final, err := runProgram(m)
 	if err != nil {
 		return skBack, fmt.Errorf("running settings menu: %w", err)
 	}
@@ -252,7 +358,7 @@ func EditCustomizations(absFolder string) error {
 			return nil
 		}
 
-		changed, err := editSetting(config, key)
+		changed, err := editSetting(config, key, configPath)
 		if err != nil {
 			return err
 		}
@@ -266,12 +372,18 @@ func EditCustomizations(absFolder string) error {
 
 // editSetting opens a small huh form for a single setting. Returns true if the
 // config was modified.
-func editSetting(config map[string]any, key settingKey) (bool, error) {
+func editSetting(config map[string]any, key settingKey, configPath string) (bool, error) {
 	switch key {
 	case skName:
 		return editStringField(config, "name", "Name", "Display name for this devcontainer")
+	case skBase:
+		return editBaseField(config, filepath.Dir(configPath))
+	case skBuildArgs:
+		return editBuildArgsField(config)
 	case skRemoteUser:
-		return editStringField(config, "remoteUser", "Remote User", "User for tool connections (e.g. vscode)")
+		return editStringField(config, "remoteUser", "Remote User", "User tools, terminals and lifecycle commands connect as (e.g. vscode); defaults to Container User")
+	case skContainerUser:
+		return editStringField(config, "containerUser", "Container User", "User all processes in the container run as, including its entrypoint; unlike Remote User it can't change without a rebuild")
 	case skShutdownAction:
 		return editSelectField(config, "shutdownAction", "Shutdown Action",
 			[]string{"none", "stopContainer"}, "none")
@@ -281,26 +393,40 @@ func editSetting(config map[string]any, key settingKey) (bool, error) {
 		return editBoolField(config, "privileged", "Privileged", "Needed for Docker-in-Docker")
 	case skForwardPorts:
 		return editPortsField(config)
+	case skAppPort:
+		return editAppPortField(config)
+	case skOnCreateCmd:
+		return editCommandField(config, "onCreateCommand", "On-Create Command", "Runs once when the container is created, first of the lifecycle commands; prebuilds cache its result")
+	case skUpdateContentCmd:
+		return editCommandField(config, "updateContentCommand", "Update-Content Command", "Runs after On-Create and again when new content is available; prebuilds cache its result")
 	case skPostCreateCmd:
-		return editStringField(config, "postCreateCommand", "Post-Create Command", "Runs once after container creation (e.g. npm install)")
+		return editCommandField(config, "postCreateCommand", "Post-Create Command", "Runs once after container creation (e.g. npm install)")
 	case skPostStartCmd:
-		return editStringField(config, "postStartCommand", "Post-Start Command", "Runs on every container start")
+		return editCommandField(config, "postStartCommand", "Post-Start Command", "Runs on every container start")
 	case skPostAttachCmd:
-		return editStringField(config, "postAttachCommand", "Post-Attach Command", "Runs on every IDE attach")
+		return editCommandField(config, "postAttachCommand", "Post-Attach Command", "Runs on every IDE attach")
 	case skWaitFor:
 		return editSelectField(config, "waitFor", "Wait For",
-			[]string{"updateContentCommand", "postCreateCommand", "postStartCommand", "postAttachCommand"},
+			[]string{"onCreateCommand", "updateContentCommand", "postCreateCommand", "postStartCommand", "postAttachCommand"},
 			"updateContentCommand")
 	case skContainerEnv:
-		return editEnvField(config, "containerEnv", "Container Env", "KEY=VALUE per line, set on Docker container")
+		return editEnvField(config, "containerEnv", "Container Env", "KEY=VALUE per line, set on Docker container (# for comments)")
 	case skRemoteEnv:
-		return editEnvField(config, "remoteEnv", "Remote Env", "KEY=VALUE per line, set for tool processes")
+		return editEnvField(config, "remoteEnv", "Remote Env", "KEY=VALUE per line, set for tool processes (# for comments)")
 	case skMounts:
-		return editTextSliceField(config, "mounts", "Mounts", "One per line, use ${localWorkspaceFolder} for portability")
+		return editMountsField(config)
 	case skCapAdd:
 		return editCSVField(config, "capAdd", "Linux Capabilities", "Comma-separated (e.g. SYS_PTRACE)")
 	case skRunArgs:
 		return editCSVField(config, "runArgs", "Docker Run Args", "Comma-separated extra docker run arguments")
+	case skHostReqs:
+		return editHostRequirementsField(config)
+	case skWorkspace:
+		return editWorkspaceField(config)
+	case skCodespaces:
+		return editCodespacesField(config)
+	case skFeatures:
+		return editFeatureOptions(config)
 	}
 	return false, nil
 }
@@ -311,7 +437,7 @@ func editStringField(config map[string]any, key, title, desc string) (bool, erro
 	val := getString(config, key)
 	before := val
 
-	form := huh.NewForm(huh.NewGroup(
+	form := NewForm(huh.NewGroup(
 		huh.NewInput().Title(title).Description(desc).Value(&val),
 	))
 	if err := form.Run(); err != nil {
@@ -330,7 +456,7 @@ func editBoolField(config map[string]any, key, title, desc string) (bool, error)
 	val := getBool(config, key)
 	before := val
 
-	form := huh.NewForm(huh.NewGroup(
+	form := NewForm(huh.NewGroup(
 		huh.NewConfirm().Title(title).Description(desc).Value(&val),
 	))
 	if err := form.Run(); err != nil {
@@ -356,7 +482,7 @@ func editSelectField(config map[string]any, key, title string, options []string,
 		opts[i] = huh.NewOption(o, o)
 	}
 
-	form := huh.NewForm(huh.NewGroup(
+	form := NewForm(huh.NewGroup(
 		huh.NewSelect[string]().Title(title).Options(opts...).Value(&val),
 	))
 	if err := form.Run(); err != nil {
@@ -370,33 +496,16 @@ func editSelectField(config map[string]any, key, title string, options []string,
 	return true, nil
 }
 
-func editPortsField(config map[string]any) (bool, error) {
-	val := joinPorts(config)
-	before := val
-
-	form := huh.NewForm(huh.NewGroup(
-		huh.NewInput().
-			Title("Forward Ports").
-			Description("Comma-separated (e.g. 3000, 5432, 8080)").
-			Value(&val),
-	))
-	if err := form.Run(); err != nil {
-		return false, fmt.Errorf("editing forwardPorts: %w", err)
-	}
-
-	if strings.TrimSpace(val) == strings.TrimSpace(before) {
-		return false, nil
-	}
-	parsePorts(config, "forwardPorts", val)
-	return true, nil
-}
-
 func editEnvField(config map[string]any, key, title, desc string) (bool, error) {
 	val := joinEnv(config, key)
 	before := val
 
-	form := huh.NewForm(huh.NewGroup(
-		huh.NewText().Title(title).Description(desc).Value(&val),
+	form := NewForm(huh.NewGroup(
+		huh.NewText().
+			Title(title).
+			DescriptionFunc(func() string { return envDescription(desc, val) }, &val).
+			Validate(checkEnvLines).
+			Value(&val),
 	))
 	if err := form.Run(); err != nil {
 		return false, fmt.Errorf("editing %s: %w", key, err)
@@ -413,7 +522,7 @@ func editTextSliceField(config map[string]any, key, title, desc string) (bool, e
 	val := joinSlice(config, key)
 	before := val
 
-	form := huh.NewForm(huh.NewGroup(
+	form := NewForm(huh.NewGroup(
 		huh.NewText().Title(title).Description(desc).Value(&val),
 	))
 	if err := form.Run(); err != nil {
@@ -431,7 +540,7 @@ func editCSVField(config map[string]any, key, title, desc string) (bool, error)
 	val := joinCSV(config, key)
 	before := val
 
-	form := huh.NewForm(huh.NewGroup(
+	form := NewForm(huh.NewGroup(
 		huh.NewInput().Title(title).Description(desc).Value(&val),
 	))
 	if err := form.Run(); err != nil {
@@ -445,6 +554,103 @@ func editCSVField(config map[string]any, key, title, desc string) (bool, error)
 	return true, nil
 }
 
+// editFeatureOptions lets the user pick a configured feature and edit its
+// stored option values in place. No metadata is fetched: the form is built from
+// the values already in the config, plus a field for adding new options.
+func editFeatureOptions(config map[string]any) (bool, error) {
+	features, _ := config["features"].(map[string]any)
+	if len(features) == 0 {
+		form := NewForm(huh.NewGroup(
+			huh.NewNote().Title("Feature Options").Description("No features configured yet.\nAdd features from the hub first."),
+		))
+		if err := form.Run(); err != nil {
+			return false, fmt.Errorf("editing features: %w", err)
+		}
+		return false, nil
+	}
+
+	refs := make([]string, 0, len(features))
+	for ref := range features {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	ref := refs[0]
+	refOpts := make([]huh.Option[string], len(refs))
+	for i, r := range refs {
+		refOpts[i] = huh.NewOption(r, r)
+	}
+	pick := NewForm(huh.NewGroup(
+		huh.NewSelect[string]().Title("Feature").Options(refOpts...).Value(&ref),
+	))
+	if err := pick.Run(); err != nil {
+		return false, fmt.Errorf("editing features: %w", err)
+	}
+
+	existing, _ := features[ref].(map[string]any)
+	keys := make([]string, 0, len(existing))
+	for k := range existing {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	stringVals := make(map[string]*string)
+	boolVals := make(map[string]*bool)
+	var fields []huh.Field
+	for _, k := range keys {
+		if b, ok := existing[k].(bool); ok {
+			val := b
+			boolVals[k] = &val
+			fields = append(fields, huh.NewConfirm().Title(k).Value(&val))
+			continue
+		}
+		val := defaultToString(existing[k])
+		stringVals[k] = &val
+		fields = append(fields, huh.NewInput().Title(k).Description("Clear to remove").Value(&val))
+	}
+	added := ""
+	fields = append(fields, huh.NewText().
+		Title("Add options").
+		Description("KEY=VALUE per line").
+		Validate(checkOptionLines).
+		Value(&added))
+
+	form := NewForm(huh.NewGroup(fields...).Title(ref))
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("editing %s: %w", ref, err)
+	}
+
+	updated := make(map[string]any, len(existing))
+	for k, ptr := range boolVals {
+		updated[k] = *ptr
+	}
+	for k, ptr := range stringVals {
+		val := strings.TrimSpace(*ptr)
+		if val == "" {
+			continue
+		}
+		// Keep numbers numeric if they were numeric before.
+		if _, wasNum := existing[k].(float64); wasNum {
+			if n, err := strconv.ParseFloat(val, 64); err == nil {
+				updated[k] = n
+				continue
+			}
+		}
+		updated[k] = val
+	}
+	for _, line := range strings.Split(added, "\n") {
+		if k, v, ok := strings.Cut(strings.TrimSpace(line), "="); ok && strings.TrimSpace(k) != "" {
+			updated[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+
+	if reflect.DeepEqual(updated, existing) || (len(updated) == 0 && len(existing) == 0) {
+		return false, nil
+	}
+	features[ref] = updated
+	return true, nil
+}
+
 // --- config read helpers ---
 
 func getString(config map[string]any, key string) string {
@@ -457,20 +663,6 @@ func getBool(config map[string]any, key string) bool {
 	return b
 }
 
-func joinPorts(config map[string]any) string {
-	ports, _ := config["forwardPorts"].([]any)
-	parts := make([]string, 0, len(ports))
-	for _, p := range ports {
-		switch v := p.(type) {
-		case float64:
-			parts = append(parts, strconv.Itoa(int(v)))
-		default:
-			parts = append(parts, fmt.Sprintf("%v", v))
-		}
-	}
-	return strings.Join(parts, ", ")
-}
-
 func joinEnv(config map[string]any, key string) string {
 	env, _ := config[key].(map[string]any)
 	lines := make([]string, 0, len(env))
@@ -528,28 +720,60 @@ func setDefault(config map[string]any, key, val, defaultVal string) {
 	}
 }
 
-func parsePorts(config map[string]any, key, val string) {
-	if strings.TrimSpace(val) == "" {
-		delete(config, key)
-		return
+// checkEnvLines reports KEY=VALUE lines that are malformed, repeat a key or
+// leave a ${...} variable reference unterminated. Blank lines and lines
+// starting with # are ignored.
+func checkEnvLines(val string) error {
+	var problems []string
+	firstLine := make(map[string]int)
+	for i, line := range strings.Split(val, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		k, _, ok := strings.Cut(line, "=")
+		k = strings.TrimSpace(k)
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("line %d: missing '=' in %q", i+1, line))
+		case k == "":
+			problems = append(problems, fmt.Sprintf("line %d: empty key in %q", i+1, line))
+		case firstLine[k] > 0:
+			problems = append(problems, fmt.Sprintf("line %d: duplicate key %s (first on line %d)", i+1, k, firstLine[k]))
+		default:
+			firstLine[k] = i + 1
+			if ref := unterminatedEnvReference(line); ref != "" {
+				problems = append(problems, fmt.Sprintf("line %d: unterminated %s", i+1, ref))
+			}
+		}
 	}
-	var ports []any
-	for _, p := range strings.Split(val, ",") {
-		p = strings.TrimSpace(p)
-		if p == "" {
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// checkOptionLines reports lines of added feature options that aren't
+// KEY=VALUE or have an empty key. Blank lines are ignored.
+func checkOptionLines(val string) error {
+	var problems []string
+	for i, line := range strings.Split(val, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
 			continue
 		}
-		if n, err := strconv.Atoi(p); err == nil {
-			ports = append(ports, n)
-		} else {
-			ports = append(ports, p)
+		k, _, ok := strings.Cut(line, "=")
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("line %d: missing '=' in %q", i+1, line))
+		case strings.TrimSpace(k) == "":
+			problems = append(problems, fmt.Sprintf("line %d: empty key in %q", i+1, line))
 		}
 	}
-	if len(ports) == 0 {
-		delete(config, key)
-	} else {
-		config[key] = ports
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
 	}
+	return nil
 }
 
 func parseEnv(config map[string]any, key, val string) {
@@ -560,7 +784,7 @@ func parseEnv(config map[string]any, key, val string) {
 	env := make(map[string]any)
 	for _, line := range strings.Split(val, "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
+		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
 		if parts := strings.SplitN(line, "=", 2); len(parts) == 2 {
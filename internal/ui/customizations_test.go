@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+func TestCheckEnvLines(t *testing.T) {
+	if err := checkEnvLines("# comment\nFOO=bar\n\nPATH=/usr/bin:$PATH\nEMPTY="); err != nil {
+		t.Errorf("valid block rejected: %v", err)
+	}
+
+	err := checkEnvLines("PATH=/bin\njust some text\nPATH=/usr/bin\n=value")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"line 2: missing '='", "line 3: duplicate key PATH (first on line 1)", "line 4: empty key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+}
+
+func TestParseEnvSkipsComments(t *testing.T) {
+	config := map[string]any{}
+	parseEnv(config, "containerEnv", "# set by dcc\nFOO = bar")
+	env, _ := config["containerEnv"].(map[string]any)
+	if len(env) != 1 || env["FOO"] != "bar" {
+		t.Errorf("containerEnv = %v, want map[FOO:bar]", env)
+	}
+}
+
+func TestLifecycleSettingsOrder(t *testing.T) {
+	// The Lifecycle section lists the commands in the order they run.
+	want := []settingKey{skOnCreateCmd, skUpdateContentCmd, skPostCreateCmd, skPostStartCmd, skPostAttachCmd, skWaitFor}
+	var got []settingKey
+	for _, item := range settingsItems {
+		if item.section == "Lifecycle" {
+			got = append(got, item.key)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Lifecycle items = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Lifecycle items = %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+func TestSettingsSectionNavigation(t *testing.T) {
+	m := newSettingsModel(nil)
+	selected := func() settingKey {
+		t.Helper()
+		item, ok := m.list.SelectedItem().(settingsMenuItem)
+		if !ok {
+			t.Fatalf("cursor on %T", m.list.SelectedItem())
+		}
+		return item.key
+	}
+	press := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "up":
+				msg = tea.KeyMsg{Type: tea.KeyUp}
+			case "down":
+				msg = tea.KeyMsg{Type: tea.KeyDown}
+			}
+			model, _ := m.Update(msg)
+			m = model.(settingsModel)
+		}
+	}
+
+	if got := selected(); got != skName {
+		t.Fatalf("initial selection = %s, want the first setting", got)
+	}
+	press("up")
+	if got := selected(); got != skName {
+		t.Errorf("up at the top selected %s", got)
+	}
+	press("]")
+	if got := selected(); got != skForwardPorts {
+		t.Errorf("] selected %s, want the Ports section", got)
+	}
+	press("]", "down")
+	if got := selected(); got != skUpdateContentCmd {
+		t.Errorf("] then down selected %s", got)
+	}
+	press("[")
+	if got := selected(); got != skForwardPorts {
+		t.Errorf("[ selected %s, want the Ports section", got)
+	}
+	// The cursor skips section headers in both directions.
+	press("up")
+	if got := selected(); got != skPrivileged {
+		t.Errorf("up from Forward Ports selected %s", got)
+	}
+	press("down")
+	if got := selected(); got != skForwardPorts {
+		t.Errorf("down from Privileged selected %s", got)
+	}
+	press("[", "[")
+	if got := selected(); got != skName {
+		t.Errorf("[ past the first section selected %s", got)
+	}
+}
+
+func TestSettingsListSectionHeaders(t *testing.T) {
+	var headers []string
+	for _, item := range settingsListItems() {
+		if h, ok := item.(settingsSectionItem); ok {
+			headers = append(headers, h.title)
+		}
+	}
+	want := "General Ports Lifecycle Environment Advanced Customizations Features"
+	if got := strings.Join(headers, " "); got != want {
+		t.Errorf("section headers = %s, want %s", got, want)
+	}
+}
+
+func TestCheckOptionLines(t *testing.T) {
+	if err := checkOptionLines("version=lts\n\n  installYarn = true\nempty="); err != nil {
+		t.Errorf("valid lines rejected: %v", err)
+	}
+
+	err := checkOptionLines("version=lts\nnodeGypDependencies\n=true")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{`line 2: missing '=' in "nodeGypDependencies"`, "line 3: empty key"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q should contain %q", err, want)
+		}
+	}
+}
+
+func TestSettingsWritesKeepUnknownKeys(t *testing.T) {
+	const existing = `{
+  "name": "api",
+  "image": "mcr.microsoft.com/devcontainers/go:1",
+  "overrideCommand": false,
+  "userEnvProbe": "loginInteractiveShell",
+  "x-acme": {
+    "owner": "platform-team",
+    "tier": 2
+  },
+  "customizations": {
+    "acme-ide": {
+      "theme": "dark"
+    }
+  }
+}
+`
+	edits := map[string]func(config map[string]any){
+		"string":   func(c map[string]any) { setString(c, "remoteUser", "vscode") },
+		"bool":     func(c map[string]any) { setBool(c, "init", true) },
+		"select":   func(c map[string]any) { setDefault(c, "shutdownAction", "none", "stopContainer") },
+		"env":      func(c map[string]any) { parseEnv(c, "containerEnv", "GOFLAGS=-mod=mod") },
+		"lines":    func(c map[string]any) { parseLines(c, "runArgs", "--cap-add=SYS_PTRACE") },
+		"csv":      func(c map[string]any) { parseCSV(c, "otherPortsAttributes", "ignore") },
+		"ports":    func(c map[string]any) { writePorts(c, nil, []portSpec{{Port: "3000", Label: "web"}}) },
+		"app port": func(c map[string]any) { writeAppPorts(c, []string{"8080"}) },
+		"vscode settings": func(c map[string]any) {
+			setVSCodeSettings(c, map[string]any{"go.useLanguageServer": true})
+		},
+	}
+	for name, edit := range edits {
+		t.Run(name, func(t *testing.T) {
+			path := devcontainer.DefaultConfigPath(t.TempDir())
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(existing), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			config, _, err := devcontainer.ReadConfig(filepath.Dir(filepath.Dir(path)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			edit(config)
+			if err := devcontainer.WriteConfig(path, config); err != nil {
+				t.Fatal(err)
+			}
+
+			written, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, snippet := range []string{
+				`"overrideCommand": false`,
+				`"userEnvProbe": "loginInteractiveShell"`,
+				"\"x-acme\": {\n    \"owner\": \"platform-team\",\n    \"tier\": 2\n  }",
+				"\"acme-ide\": {\n      \"theme\": \"dark\"\n    }",
+			} {
+				if !strings.Contains(string(written), snippet) {
+					t.Errorf("%s lost or rewritten:\n%s", snippet, written)
+				}
+			}
+		})
+	}
+}
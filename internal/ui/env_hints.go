@@ -0,0 +1,105 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// envVariables are the ${...} forms proposed while typing an env value.
+var envVariables = []string{
+	"${localEnv:VAR}",
+	"${localEnv:VAR:default}",
+	"${containerEnv:VAR}",
+	"${localWorkspaceFolder}",
+	"${localWorkspaceFolderBasename}",
+	"${containerWorkspaceFolder}",
+	"${containerWorkspaceFolderBasename}",
+	"${devcontainerId}",
+}
+
+// knownEnvVariables are the variable names devcontainer.json resolves. env is
+// the legacy alias of localEnv.
+var knownEnvVariables = map[string]bool{
+	"localEnv":                         true,
+	"env":                              true,
+	"containerEnv":                     true,
+	"localWorkspaceFolder":             true,
+	"localWorkspaceFolderBasename":     true,
+	"containerWorkspaceFolder":         true,
+	"containerWorkspaceFolderBasename": true,
+	"devcontainerId":                   true,
+}
+
+var envReference = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// envDescription extends desc with variable proposals for the ${ expression
+// being typed on the last line, or with warnings about unknown variables.
+func envDescription(desc, val string) string {
+	lines := strings.Split(val, "\n")
+	if hint := envProposals(lines[len(lines)-1]); hint != "" {
+		return desc + "\n" + hint
+	}
+	if warnings := unknownEnvVariables(val); len(warnings) > 0 {
+		return desc + "\nWarning: " + strings.Join(warnings, "; ")
+	}
+	return desc + "\nReference variables with ${localEnv:VAR}, ${containerEnv:VAR} or ${localWorkspaceFolder}"
+}
+
+// envProposals lists the variable forms matching an unterminated ${ at the
+// end of line, e.g. "${cont" proposes ${containerEnv:VAR} and friends.
+func envProposals(line string) string {
+	start := strings.LastIndex(line, "${")
+	if start == -1 || strings.Contains(line[start:], "}") {
+		return ""
+	}
+	partial := strings.ToLower(line[start+2:])
+	if name, _, ok := strings.Cut(partial, ":"); ok {
+		partial = name + ":"
+	}
+
+	var matches []string
+	for _, v := range envVariables {
+		if strings.HasPrefix(strings.ToLower(v[2:]), partial) {
+			matches = append(matches, v)
+		}
+	}
+	if len(matches) == 0 {
+		return "No matching variable; close the expression with }"
+	}
+	return "Variables: " + strings.Join(matches, "  ")
+}
+
+// unknownEnvVariables reports ${...} references devcontainer.json does not
+// resolve. They are kept as written, so this only warns.
+func unknownEnvVariables(val string) []string {
+	var warnings []string
+	for i, line := range strings.Split(val, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, m := range envReference.FindAllStringSubmatch(line, -1) {
+			name, _, _ := strings.Cut(m[1], ":")
+			if !knownEnvVariables[name] {
+				warnings = append(warnings, fmt.Sprintf("line %d: unknown variable %s", i+1, m[0]))
+			}
+		}
+	}
+	return warnings
+}
+
+// unterminatedEnvReference returns the first ${ in line without a closing },
+// or "" if every expression is closed.
+func unterminatedEnvReference(line string) string {
+	for rest := line; ; {
+		start := strings.Index(rest, "${")
+		if start == -1 {
+			return ""
+		}
+		end := strings.Index(rest[start:], "}")
+		if end == -1 {
+			return rest[start:]
+		}
+		rest = rest[start+end+1:]
+	}
+}
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEnvProposals(t *testing.T) {
+	tests := []struct {
+		line string
+		want []string
+		none bool
+	}{
+		{line: "HOME=${cont", want: []string{"${containerEnv:VAR}", "${containerWorkspaceFolder}"}},
+		{line: "HOME=${localEnv:HO", want: []string{"${localEnv:VAR}", "${localEnv:VAR:default}"}},
+		{line: "HOME=${localEnv:HOME}", none: true},
+		{line: "HOME=/root", none: true},
+	}
+	for _, tt := range tests {
+		got := envProposals(tt.line)
+		if tt.none {
+			if got != "" {
+				t.Errorf("envProposals(%q) = %q, want none", tt.line, got)
+			}
+			continue
+		}
+		for _, w := range tt.want {
+			if !strings.Contains(got, w) {
+				t.Errorf("envProposals(%q) = %q, missing %s", tt.line, got, w)
+			}
+		}
+		if strings.Contains(got, "${devcontainerId}") {
+			t.Errorf("envProposals(%q) = %q proposes a non-matching variable", tt.line, got)
+		}
+	}
+}
+
+func TestUnknownEnvVariables(t *testing.T) {
+	val := "A=${localEnv:HOME}\n# B=${nope}\nC=${env:X}/${bogus:Y}"
+	got := unknownEnvVariables(val)
+	if len(got) != 1 || got[0] != "line 3: unknown variable ${bogus:Y}" {
+		t.Errorf("unknownEnvVariables() = %q", got)
+	}
+}
+
+func TestCheckEnvLinesVariables(t *testing.T) {
+	if err := checkEnvLines("PATH=${containerEnv:PATH}:/opt/bin\nWS=${localWorkspaceFolder}"); err != nil {
+		t.Errorf("variable references rejected: %v", err)
+	}
+	err := checkEnvLines("HOME=${localEnv:HOME")
+	if err == nil || !strings.Contains(err.Error(), "line 1: unterminated ${localEnv:HOME") {
+		t.Errorf("err = %v, want unterminated reference", err)
+	}
+}
@@ -1,8 +1,11 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -11,6 +14,7 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
 	"github.com/mochlast/devcontainer-companion/internal/marketplace"
 )
 
@@ -31,7 +35,11 @@ func (i extensionItem) Description() string {
 	if i.ext.Rating == 0 {
 		rating = "-"
 	}
-	return fmt.Sprintf("%s  %s installs  ★ %s", i.ext.ID, installs, rating)
+	desc := fmt.Sprintf("%s  %s installs  ★ %s", i.ext.ID, installs, rating)
+	if i.ext.PreRelease {
+		desc += "  pre-release"
+	}
+	return desc
 }
 
 func formatInstallCount(n int64) string {
@@ -48,6 +56,7 @@ func formatInstallCount(n int64) string {
 // extensionDelegate renders extension items with selection checkboxes.
 type extensionDelegate struct {
 	selectedItems map[string]bool
+	versions      map[string]string // pinned versions by extension ID
 }
 
 func (d extensionDelegate) Height() int                             { return 2 }
@@ -61,6 +70,9 @@ func (d extensionDelegate) Render(w io.Writer, m list.Model, index int, listItem
 	}
 
 	title := item.Title()
+	if v := d.versions[item.ext.ID]; v != "" {
+		title += " @" + v
+	}
 	desc := item.Description()
 
 	isActive := index == m.Index()
@@ -75,23 +87,34 @@ func (d extensionDelegate) Render(w io.Writer, m list.Model, index int, listItem
 	descStyle := lipgloss.NewStyle().PaddingLeft(6).Faint(true)
 
 	if isActive {
-		titleStyle = titleStyle.Bold(true).Foreground(lipgloss.Color("170"))
-		descStyle = descStyle.Foreground(lipgloss.Color("170"))
+		titleStyle = titleStyle.Bold(true).Foreground(theme.Accent)
+		descStyle = descStyle.Foreground(theme.Accent)
 		title = fmt.Sprintf("> %s %s", checkbox, title)
 	} else {
 		title = fmt.Sprintf("  %s %s", checkbox, title)
 	}
 
 	maxW := m.Width()
-	fmt.Fprintf(w, "%s\n%s", titleStyle.MaxWidth(maxW).Render(title), descStyle.MaxWidth(maxW).Render(desc))
+	fmt.Fprintf(w, "%s\n%s", truncateWidth(titleStyle.Render(title), maxW), truncateWidth(descStyle.Render(desc), maxW))
 }
 
 // searchResultMsg carries marketplace search results back to the model.
+// gen is the searchGen the search was started for.
 type searchResultMsg struct {
 	extensions []marketplace.Extension
 	err        error
-	query      string
-	sortBy     marketplace.SortBy
+	gen        int
+}
+
+// searchTickMsg fires once the search debounce has elapsed.
+type searchTickMsg struct {
+	gen int
+}
+
+// searchRetryMsg reports that a search failed and is being retried. updates
+// delivers the rest of that search's messages (see streamSearch).
+type searchRetryMsg struct {
+	updates <-chan tea.Msg
 }
 
 // extReadmeFetchedMsg carries the result of an async extension README fetch.
@@ -101,31 +124,65 @@ type extReadmeFetchedMsg struct {
 	err         error
 }
 
+// extVersionsMsg carries the published versions of an extension.
+type extVersionsMsg struct {
+	extensionID string
+	versions    []marketplace.ExtensionVersion
+	err         error
+}
+
+// extensionVersionPicker is the side panel for pinning an extension version.
+type extensionVersionPicker struct {
+	active      bool
+	loading     bool
+	extensionID string
+	versions    []marketplace.ExtensionVersion // choices; index 0 is "latest" (unpinned)
+	cursor      int
+	errMsg      string
+}
+
 // extensionPickerModel is the bubbletea model for extension picking with live search.
 type extensionPickerModel struct {
 	list          list.Model
 	selectedItems map[string]bool
+	versions      map[string]string // pinned versions by extension ID
+	versionPick   extensionVersionPicker
+	preRelease    bool // search and list pre-release versions
 	confirmed     bool
 	quitting      bool
 	width         int
 	height        int
 	searchInput   string
 	searching     bool
+	retrying      bool
+	searchErr     string
+	searchUpdates <-chan tea.Msg     // messages of the search in flight
+	searchGen     int                // bumped per keystroke; older ticks and results are stale
+	cancelSearch  context.CancelFunc // aborts the request in flight
 	lastQuery     string
+	showInstalled bool        // list shows only checked items
+	reordering    bool        // list shows checked items in write order; J/K move them
+	savedItems    []list.Item // search results hidden while showInstalled or reordering
+	order         []string    // write order of checked items (see finalOrder)
+	customOrder   bool        // keep order instead of sorting alphabetically
 	sortIndex     int
 	sortOptions   []marketplace.SortOption
 	preview       readmePreview
 }
 
-func newExtensionPicker(preSelected map[string]bool) extensionPickerModel {
+func newExtensionPicker(preSelected map[string]bool, pinned map[string]string) extensionPickerModel {
 	selectedItems := make(map[string]bool)
 	if preSelected != nil {
 		for k, v := range preSelected {
 			selectedItems[k] = v
 		}
 	}
+	versions := make(map[string]string)
+	for k, v := range pinned {
+		versions[k] = v
+	}
 
-	delegate := extensionDelegate{selectedItems: selectedItems}
+	delegate := extensionDelegate{selectedItems: selectedItems, versions: versions}
 
 	// Show pre-selected extensions as initial items (with ID as display name)
 	var initialItems []list.Item
@@ -150,7 +207,7 @@ func newExtensionPicker(preSelected map[string]bool) extensionPickerModel {
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(false) // We handle search ourselves
 	l.SetShowHelp(true)
-	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170")).MarginLeft(2)
+	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).MarginLeft(2)
 
 	l.KeyMap.ShowFullHelp = key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "help"))
 	l.KeyMap.CloseFullHelp = key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "close help"))
@@ -159,12 +216,17 @@ func newExtensionPicker(preSelected map[string]bool) extensionPickerModel {
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "details")),
 			key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "sort")),
+			key.NewBinding(key.WithKeys("ctrl+p"), key.WithHelp("ctrl+p", "pin version")),
+			key.NewBinding(key.WithKeys("ctrl+r"), key.WithHelp("ctrl+r", "pre-releases")),
+			key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "selected only")),
+			key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "reorder")),
 		}
 	}
 
 	return extensionPickerModel{
 		list:          l,
 		selectedItems: selectedItems,
+		versions:      versions,
 		sortOptions:   marketplace.SortOptions(),
 		sortIndex:     0,
 		preview:       newReadmePreview(),
@@ -176,8 +238,8 @@ func (m extensionPickerModel) Init() tea.Cmd {
 }
 
 func (m *extensionPickerModel) applyLayout() {
-	if m.preview.visible {
-		listW := m.width / 3
+	if m.preview.visible || m.versionPick.active {
+		listW := splitWidth(m.width)
 		m.list.SetWidth(listW)
 		m.list.SetHeight(m.height - 4)
 		m.preview.SetSize(m.width-listW, m.height-2)
@@ -202,13 +264,41 @@ func (m extensionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.applyLayout()
 		return m, nil
 
+	case searchTickMsg:
+		if msg.gen != m.searchGen {
+			return m, nil // superseded by a later keystroke
+		}
+		return m, m.runSearch()
+
+	case searchRetryMsg:
+		if msg.updates != m.searchUpdates {
+			return m, nil
+		}
+		m.retrying = true
+		return m, waitForSearch(msg.updates)
+
 	case searchResultMsg:
+		if msg.gen != m.searchGen {
+			return m, nil
+		}
+		if m.cancelSearch != nil {
+			m.cancelSearch() // done; releases the context
+			m.cancelSearch = nil
+		}
 		m.searching = false
-		if msg.err != nil || msg.query != m.lastQuery || msg.sortBy != m.currentSortBy() {
+		m.retrying = false
+		if msg.err != nil {
+			m.searchErr = searchErrorText(msg.err)
 			return m, nil
 		}
+		m.searchErr = ""
 		items := make([]list.Item, 0, len(msg.extensions))
 		for _, ext := range msg.extensions {
+			if old, ok := adoptCanonicalID(m.selectedItems, m.versions, ext.ID); ok {
+				if i := slices.Index(m.order, old); i >= 0 {
+					m.order[i] = ext.ID
+				}
+			}
 			items = append(items, extensionItem{ext: ext})
 		}
 		// Pin selected items to the top of results
@@ -219,17 +309,21 @@ func (m extensionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return iSel && !jSel
 			})
 		}
+		if m.showInstalled || m.reordering {
+			m.savedItems = items
+			return m, nil
+		}
 		m.list.SetItems(items)
-		m.list.SetDelegate(extensionDelegate{selectedItems: m.selectedItems})
+		m.list.SetDelegate(extensionDelegate{selectedItems: m.selectedItems, versions: m.versions})
 		return m, nil
 
 	case extReadmeFetchedMsg:
-		if msg.extensionID != m.preview.sourceURL {
+		if msg.extensionID != m.preview.key {
 			return m, nil
 		}
 		m.preview.loading = false
 		if msg.err != nil {
-			m.preview.errMsg = fmt.Sprintf("Error: %s", msg.err)
+			m.preview.errMsg = fetchErrorText(msg.err)
 			m.preview.viewport.SetContent(m.preview.errMsg)
 			return m, nil
 		}
@@ -238,7 +332,29 @@ func (m extensionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.preview.viewport.GotoTop()
 		return m, nil
 
+	case extVersionsMsg:
+		if !m.versionPick.active || msg.extensionID != m.versionPick.extensionID {
+			return m, nil
+		}
+		m.versionPick.loading = false
+		if msg.err != nil {
+			m.versionPick.errMsg = fetchErrorText(msg.err)
+			return m, nil
+		}
+		m.versionPick.versions = append([]marketplace.ExtensionVersion{{}}, msg.versions...)
+		m.versionPick.cursor = 0
+		for i, v := range m.versionPick.versions {
+			if v.Version == m.versions[msg.extensionID] {
+				m.versionPick.cursor = i
+			}
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.versionPick.active {
+			return m.updateVersionPick(msg)
+		}
+
 		// When preview is open, handle preview-specific keys first
 		if m.preview.visible {
 			switch msg.String() {
@@ -257,22 +373,30 @@ func (m extensionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		}
 
+		if m.reordering {
+			return m.updateReorder(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			m.quitting = true
 			return m, tea.Quit
 
+		case "ctrl+o":
+			m.toggleReorderView()
+			return m, nil
+
 		case "?":
 			if item, ok := m.list.SelectedItem().(extensionItem); ok {
 				extID := item.ext.ID
-				if m.preview.visible && m.preview.sourceURL == extID {
+				if m.preview.visible && m.preview.key == extID {
 					m.preview.Close()
 					m.applyLayout()
 					return m, nil
 				}
 				m.preview.visible = true
 				m.preview.loading = true
-				m.preview.sourceURL = extID
+				m.preview.key = extID
 				m.preview.errMsg = ""
 				m.preview.viewport.SetContent("Loading extension details...")
 				m.applyLayout()
@@ -280,6 +404,23 @@ func (m extensionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
+		case "ctrl+f":
+			m.toggleInstalledView()
+			return m, nil
+
+		case "ctrl+p":
+			if item, ok := m.list.SelectedItem().(extensionItem); ok {
+				extID := item.ext.ID
+				m.versionPick = extensionVersionPicker{active: true, loading: true, extensionID: extID}
+				m.applyLayout()
+				return m, fetchExtensionVersionsCmd(extID, m.preRelease)
+			}
+			return m, nil
+
+		case "ctrl+r":
+			m.preRelease = !m.preRelease
+			return m, m.forceSearch()
+
 		case "tab":
 			m.sortIndex = (m.sortIndex + 1) % len(m.sortOptions)
 			// Re-trigger search with new sort
@@ -298,21 +439,29 @@ func (m extensionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if item, ok := m.list.SelectedItem().(extensionItem); ok {
 				id := item.ext.ID
 				m.selectedItems[id] = !m.selectedItems[id]
-				m.list.SetDelegate(extensionDelegate{selectedItems: m.selectedItems})
+				m.list.SetDelegate(extensionDelegate{selectedItems: m.selectedItems, versions: m.versions})
 			}
 			return m, nil
 
 		case "backspace":
 			if len(m.searchInput) > 0 {
+				if m.showInstalled {
+					m.toggleInstalledView()
+				}
 				m.searchInput = m.searchInput[:len(m.searchInput)-1]
 				return m, m.triggerSearch()
 			}
 			return m, nil
 
 		case "esc":
+			if m.showInstalled {
+				m.toggleInstalledView()
+				return m, nil
+			}
 			if m.searchInput != "" {
 				m.searchInput = ""
 				m.lastQuery = ""
+				m.stopSearch()
 				// Restore pre-selected items view
 				var items []list.Item
 				for id, checked := range m.selectedItems {
@@ -338,6 +487,9 @@ func (m extensionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		default:
 			// Printable characters go to search input
 			if len(msg.Runes) > 0 {
+				if m.showInstalled {
+					m.toggleInstalledView()
+				}
 				m.searchInput += string(msg.Runes)
 				return m, m.triggerSearch()
 			}
@@ -354,34 +506,262 @@ func (m extensionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// triggerSearch returns a debounced search command.
+// finalOrder returns the checked extension IDs in write order: the known
+// order first, then any newly checked IDs. Without a custom order the result
+// is sorted alphabetically.
+func (m extensionPickerModel) finalOrder() []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range m.order {
+		if m.selectedItems[id] && !seen[id] {
+			ids = append(ids, id)
+			seen[id] = true
+		}
+	}
+	var added []string
+	for id, checked := range m.selectedItems {
+		if checked && !seen[id] {
+			added = append(added, id)
+		}
+	}
+	sort.Strings(added)
+	ids = append(ids, added...)
+	if !m.customOrder {
+		sort.Strings(ids)
+	}
+	return ids
+}
+
+// finalRefs returns finalOrder with pinned versions as "publisher.name@version".
+func (m extensionPickerModel) finalRefs() []string {
+	ids := m.finalOrder()
+	for i, id := range ids {
+		ids[i] = marketplace.FormatExtensionRef(id, m.versions[id])
+	}
+	return ids
+}
+
+// toggleReorderView switches to a list of the checked extensions in write
+// order, where J/K move the highlighted one. Closing it restores the search
+// results.
+func (m *extensionPickerModel) toggleReorderView() {
+	if m.reordering {
+		m.reordering = false
+		m.list.SetItems(m.savedItems)
+		m.savedItems = nil
+		return
+	}
+	if m.showInstalled {
+		m.toggleInstalledView()
+	}
+
+	known := make(map[string]marketplace.Extension)
+	for _, it := range m.list.Items() {
+		if ei, ok := it.(extensionItem); ok {
+			known[ei.ext.ID] = ei.ext
+		}
+	}
+
+	m.order = m.finalOrder()
+	items := make([]list.Item, 0, len(m.order))
+	for _, id := range m.order {
+		ext, ok := known[id]
+		if !ok {
+			ext = marketplace.Extension{ID: id, DisplayName: id, Description: "(currently installed)"}
+		}
+		items = append(items, extensionItem{ext: ext})
+	}
+
+	m.savedItems = m.list.Items()
+	m.reordering = true
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+}
+
+// updateReorder handles keys while the reorder view is open. Typing doesn't
+// search here, so J/K are free to move items.
+func (m extensionPickerModel) updateReorder(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch key := msg.String(); key {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "enter":
+		m.confirmed = true
+		m.quitting = true
+		return m, tea.Quit
+	case "ctrl+o", "esc":
+		m.toggleReorderView()
+		return m, nil
+	default:
+		if delta := reorderDelta(key); delta != 0 {
+			items := m.list.Items()
+			i := m.list.Index()
+			j := moveItem(items, i, delta)
+			if j != i {
+				moveItem(m.order, i, delta)
+				m.customOrder = true
+				m.list.SetItems(items)
+				m.list.Select(j)
+			}
+			return m, nil
+		}
+		// Other printable keys (including the list's q) are ignored here.
+		if len(msg.Runes) > 0 && key != "j" && key != "k" {
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+// toggleInstalledView switches between the search results and a view of only
+// the checked extensions, so selections can be reviewed and pruned in bulk.
+// Unchecked items stay visible until the view is closed.
+func (m *extensionPickerModel) toggleInstalledView() {
+	if m.showInstalled {
+		m.showInstalled = false
+		m.list.SetItems(m.savedItems)
+		m.savedItems = nil
+		return
+	}
+
+	known := make(map[string]marketplace.Extension)
+	for _, it := range m.list.Items() {
+		if ei, ok := it.(extensionItem); ok {
+			known[ei.ext.ID] = ei.ext
+		}
+	}
+
+	var items []list.Item
+	for id, checked := range m.selectedItems {
+		if !checked {
+			continue
+		}
+		ext, ok := known[id]
+		if !ok {
+			ext = marketplace.Extension{ID: id, DisplayName: id, Description: "(currently installed)"}
+		}
+		items = append(items, extensionItem{ext: ext})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].(extensionItem).ext.ID < items[j].(extensionItem).ext.ID
+	})
+
+	m.savedItems = m.list.Items()
+	m.showInstalled = true
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+}
+
+// searchErrorText turns a marketplace search error into a short status line.
+func searchErrorText(err error) string {
+	switch {
+	case httpclient.IsTimeout(err):
+		return fmt.Sprintf("Search timed out after %s (set DCC_HTTP_TIMEOUT to change)", httpclient.Timeout())
+	case errors.Is(err, marketplace.ErrRateLimited):
+		return "Rate limited by the marketplace, try again shortly"
+	}
+	return "Search failed: " + strings.TrimPrefix(err.Error(), marketplace.ErrSearchFailed.Error()+": ")
+}
+
+// adoptCanonicalID renames the selected entry matching id case-insensitively
+// to id, the casing the marketplace returned, and moves its pinned version
+// along. It returns the old ID and whether an entry was renamed.
+func adoptCanonicalID(selected map[string]bool, versions map[string]string, id string) (string, bool) {
+	if _, ok := selected[id]; ok {
+		return "", false
+	}
+	for old, checked := range selected {
+		if !strings.EqualFold(old, id) {
+			continue
+		}
+		delete(selected, old)
+		selected[id] = checked
+		if version, ok := versions[old]; ok {
+			delete(versions, old)
+			versions[id] = version
+		}
+		return old, true
+	}
+	return "", false
+}
+
+// streamSearch runs search in the background and returns a channel with its
+// retry notices followed by its result, plus a command reading the first one.
+func streamSearch(search func(onRetry marketplace.RetryFunc) tea.Msg) (<-chan tea.Msg, tea.Cmd) {
+	// Buffered for every retry notice plus the result, so a search nobody
+	// listens to any more never blocks.
+	updates := make(chan tea.Msg, marketplace.MaxAttempts)
+	go func() {
+		updates <- search(func(int, error) {
+			updates <- searchRetryMsg{updates: updates}
+		})
+	}()
+	return updates, waitForSearch(updates)
+}
+
+// waitForSearch delivers the next message of a streamed search.
+func waitForSearch(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
+// stopSearch cancels the request in flight and makes pending ticks and
+// results stale, so at most one search runs and only the latest is shown.
+func (m *extensionPickerModel) stopSearch() {
+	m.searchGen++
+	if m.cancelSearch != nil {
+		m.cancelSearch()
+		m.cancelSearch = nil
+	}
+	m.searchUpdates = nil
+	m.searching = false
+	m.retrying = false
+}
+
+// triggerSearch returns a debounced search command. Only the tick of the
+// latest keystroke starts a request.
 func (m *extensionPickerModel) triggerSearch() tea.Cmd {
+	m.stopSearch()
 	query := strings.TrimSpace(m.searchInput)
 	if query == "" {
 		return nil
 	}
 	m.lastQuery = query
 	m.searching = true
-	sortBy := m.currentSortBy()
-	return tea.Tick(300*time.Millisecond, func(_ time.Time) tea.Msg {
-		exts, err := marketplace.Search(query, 20, sortBy)
-		return searchResultMsg{extensions: exts, err: err, query: query, sortBy: sortBy}
+	gen := m.searchGen
+	return tick(300*time.Millisecond, func(_ time.Time) tea.Msg {
+		return searchTickMsg{gen: gen}
 	})
 }
 
 // forceSearch re-triggers the current search immediately (used when sort changes).
 func (m *extensionPickerModel) forceSearch() tea.Cmd {
+	m.stopSearch()
 	query := strings.TrimSpace(m.searchInput)
 	if query == "" {
 		return nil
 	}
 	m.lastQuery = query
 	m.searching = true
-	sortBy := m.currentSortBy()
-	return func() tea.Msg {
-		exts, err := marketplace.Search(query, 20, sortBy)
-		return searchResultMsg{extensions: exts, err: err, query: query, sortBy: sortBy}
-	}
+	return m.runSearch()
+}
+
+// runSearch queries the marketplace for lastQuery.
+func (m *extensionPickerModel) runSearch() tea.Cmd {
+	query, sortBy, preRelease, gen := m.lastQuery, m.currentSortBy(), m.preRelease, m.searchGen
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSearch = cancel
+	m.retrying = false
+	var cmd tea.Cmd
+	m.searchUpdates, cmd = streamSearch(func(onRetry marketplace.RetryFunc) tea.Msg {
+		exts, err := marketplace.Search(ctx, query, 20, sortBy, preRelease, onRetry)
+		return searchResultMsg{extensions: exts, err: err, gen: gen}
+	})
+	return cmd
 }
 
 // fetchExtensionReadmeCmd returns a tea.Cmd that fetches an extension README asynchronously.
@@ -396,19 +776,123 @@ func fetchExtensionReadmeCmd(extensionID string) tea.Cmd {
 	}
 }
 
+// updateVersionPick handles keys while the version panel is open. Enter pins
+// the highlighted version (or unpins for "latest") and selects the extension.
+func (m extensionPickerModel) updateVersionPick(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	vp := &m.versionPick
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "ctrl+p":
+		vp.active = false
+		m.applyLayout()
+	case "up", "k":
+		if vp.cursor > 0 {
+			vp.cursor--
+		}
+	case "down", "j":
+		if vp.cursor < len(vp.versions)-1 {
+			vp.cursor++
+		}
+	case "enter":
+		if vp.loading || len(vp.versions) == 0 {
+			return m, nil
+		}
+		if v := vp.versions[vp.cursor].Version; v != "" {
+			m.versions[vp.extensionID] = v
+		} else {
+			delete(m.versions, vp.extensionID)
+		}
+		m.selectedItems[vp.extensionID] = true
+		m.list.SetDelegate(extensionDelegate{selectedItems: m.selectedItems, versions: m.versions})
+		vp.active = false
+		m.applyLayout()
+	}
+	return m, nil
+}
+
+func fetchExtensionVersionsCmd(extensionID string, preRelease bool) tea.Cmd {
+	return func() tea.Msg {
+		versions, err := marketplace.FetchExtensionVersions(context.Background(), extensionID, preRelease)
+		return extVersionsMsg{extensionID: extensionID, versions: versions, err: err}
+	}
+}
+
+// extensionVersionLabel describes a version choice, e.g.
+// "1.2.0 (pre-release) · linux-x64, darwin-arm64".
+func extensionVersionLabel(v marketplace.ExtensionVersion) string {
+	if v.Version == "" {
+		return "latest (unpinned)"
+	}
+	label := v.Version
+	if v.PreRelease {
+		label += " (pre-release)"
+	}
+	if len(v.Platforms) > 0 {
+		label += " · " + strings.Join(v.Platforms, ", ")
+	}
+	return label
+}
+
+// versionPickView renders the version panel in place of the README preview.
+func (m extensionPickerModel) versionPickView(width, height int) string {
+	vp := m.versionPick
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).PaddingLeft(1)
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Accent).
+		Width(width - 2).
+		Height(height - 3)
+
+	var b strings.Builder
+	switch {
+	case vp.loading:
+		b.WriteString("Loading versions...")
+	case vp.errMsg != "":
+		b.WriteString(vp.errMsg)
+	default:
+		activeStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+		for i, v := range vp.versions {
+			label := extensionVersionLabel(v)
+			if i == vp.cursor {
+				b.WriteString(activeStyle.Render("> "+label) + "\n")
+			} else {
+				b.WriteString("  " + label + "\n")
+			}
+		}
+	}
+
+	title := titleStyle.Render("Pin version: " + vp.extensionID)
+	return lipgloss.JoinVertical(lipgloss.Left, title, borderStyle.Render(b.String()))
+}
+
 func (m extensionPickerModel) View() string {
 	if m.quitting {
 		return ""
 	}
 
-	accentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+	accentStyle := lipgloss.NewStyle().Foreground(theme.Accent)
 	faintStyle := lipgloss.NewStyle().Faint(true)
 
 	// Search line
 	searchLine := accentStyle.Render(fmt.Sprintf("  Search: %s", m.searchInput))
 	searchLine += faintStyle.Render("_")
-	if m.searching {
+	if m.retrying {
+		searchLine += searchErrStyle.Render("  Search failed, retrying...")
+	} else if m.searching {
 		searchLine += accentStyle.Render("  Searching...")
+	} else if m.searchErr != "" {
+		searchLine += searchErrStyle.Render("  " + m.searchErr)
+	}
+	if m.showInstalled {
+		searchLine += accentStyle.Render("  Showing selected only (ctrl+f to go back)")
+	}
+	if m.reordering {
+		searchLine += accentStyle.Render("  Reordering: J/K move, ctrl+o to go back")
+	}
+	if m.preRelease {
+		searchLine += accentStyle.Render("  Including pre-releases (ctrl+r)")
 	}
 
 	// Sort indicator
@@ -440,8 +924,14 @@ func (m extensionPickerModel) View() string {
 
 	listView := "\n" + searchLine + "\n" + sortLabel + "\n" + m.list.View() + status
 
+	if m.versionPick.active {
+		listW := splitWidth(m.width)
+		clipped := lipgloss.NewStyle().Width(listW).MaxWidth(listW).Render(listView)
+		return lipgloss.JoinHorizontal(lipgloss.Top, clipped, m.versionPickView(m.width-listW, m.height-2))
+	}
+
 	if m.preview.visible {
-		listW := m.width / 3
+		listW := splitWidth(m.width)
 		clipped := lipgloss.NewStyle().Width(listW).MaxWidth(listW).Render(listView)
 		return lipgloss.JoinHorizontal(lipgloss.Top, clipped, m.preview.View())
 	}
@@ -449,26 +939,37 @@ func (m extensionPickerModel) View() string {
 	return listView
 }
 
-// PickExtensions shows a multi-select extension picker with live marketplace search.
-func PickExtensions(preSelected map[string]bool) ([]string, error) {
-	m := newExtensionPicker(preSelected)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	finalModel, err := p.Run()
+// PickExtensions shows a multi-select extension picker with live marketplace
+// search. existing is the configured list; the result is sorted alphabetically
+// unless the user reorders it (ctrl+o) or existing was already in a custom order.
+// Entries pinned as "publisher.name@version" keep their version unless it is
+// changed in the picker (ctrl+p).
+func PickExtensions(existing []string) ([]string, error) {
+	existing = marketplace.DedupeExtensions(existing)
+	preSelected := make(map[string]bool, len(existing))
+	pinned := make(map[string]string)
+	ids := make([]string, len(existing))
+	for i, ref := range existing {
+		id, version := marketplace.SplitExtensionRef(ref)
+		ids[i] = id
+		preSelected[id] = true
+		if version != "" {
+			pinned[id] = version
+		}
+	}
+	m := newExtensionPicker(preSelected, pinned)
+	m.order = ids
+	m.customOrder = !sort.StringsAreSorted(ids)
+	finalModel, err := runProgram(m)
 	if err != nil {
 		return nil, fmt.Errorf("running extension picker: %w", err)
 	}
 
 	result := finalModel.(extensionPickerModel)
+	result.stopSearch()
 	if !result.confirmed {
 		return nil, ErrPickerCancelled
 	}
 
-	var selected []string
-	for id, checked := range result.selectedItems {
-		if checked {
-			selected = append(selected, id)
-		}
-	}
-
-	return selected, nil
+	return result.finalRefs(), nil
 }
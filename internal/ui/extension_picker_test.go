@@ -0,0 +1,227 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/mochlast/devcontainer-companion/internal/marketplace"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+func TestExtensionPickerInstalledView(t *testing.T) {
+	m := newExtensionPicker(map[string]bool{"ms-python.python": true}, nil)
+	results := []list.Item{
+		extensionItem{ext: marketplace.Extension{ID: "golang.go", DisplayName: "Go"}},
+		extensionItem{ext: marketplace.Extension{ID: "ms-python.python", DisplayName: "Python"}},
+	}
+	m.list.SetItems(results)
+	m.selectedItems["golang.go"] = true
+
+	m.toggleInstalledView()
+	items := m.list.Items()
+	if len(items) != 2 {
+		t.Fatalf("installed view has %d items, want 2", len(items))
+	}
+	if got := items[1].(extensionItem).ext.DisplayName; got != "Python" {
+		t.Errorf("installed view should keep search metadata, got display name %q", got)
+	}
+
+	// Unchecking keeps the item visible until the view is closed.
+	m.selectedItems["golang.go"] = false
+	m.toggleInstalledView()
+	if m.showInstalled {
+		t.Error("second toggle should close the installed view")
+	}
+	if len(m.list.Items()) != len(results) {
+		t.Errorf("search results not restored: got %d items", len(m.list.Items()))
+	}
+}
+
+func TestExtensionPickerReorder(t *testing.T) {
+	m := newExtensionPicker(map[string]bool{"a.one": true, "b.two": true}, nil)
+	m.order = []string{"a.one", "b.two"}
+	m.selectedItems["c.three"] = true
+
+	if got := m.finalOrder(); !reflect.DeepEqual(got, []string{"a.one", "b.two", "c.three"}) {
+		t.Fatalf("default order = %v, want alphabetical", got)
+	}
+
+	m.toggleReorderView()
+	m.list.Select(2)
+	next, _ := m.updateReorder(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("K")})
+	m = next.(extensionPickerModel)
+	if m.list.Index() != 1 {
+		t.Errorf("cursor should follow the moved item, got index %d", m.list.Index())
+	}
+
+	want := []string{"a.one", "c.three", "b.two"}
+	if got := m.finalOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("reordered = %v, want %v", got, want)
+	}
+
+	// Unchecked items drop out; newly checked ones are appended.
+	m.selectedItems["a.one"] = false
+	m.selectedItems["d.four"] = true
+	want = []string{"c.three", "b.two", "d.four"}
+	if got := m.finalOrder(); !reflect.DeepEqual(got, want) {
+		t.Errorf("after toggles = %v, want %v", got, want)
+	}
+}
+
+func TestExtensionPickerSearchCancellation(t *testing.T) {
+	m := newExtensionPicker(nil, nil)
+	m.searchInput = "py"
+	m.triggerSearch()
+	stale := m.searchGen
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSearch = cancel // as if the "py" search were in flight
+
+	m.searchInput = "pyt"
+	m.triggerSearch()
+	if ctx.Err() == nil {
+		t.Error("a new keystroke should cancel the request in flight")
+	}
+	if !m.searching {
+		t.Error("searching should stay set while the new search is debounced")
+	}
+
+	updated, cmd := m.Update(searchTickMsg{gen: stale})
+	if cmd != nil {
+		t.Error("a stale debounce tick started a search")
+	}
+	m = updated.(extensionPickerModel)
+
+	results := []marketplace.Extension{{ID: "ms-python.python", DisplayName: "Python"}}
+	updated, _ = m.Update(searchResultMsg{extensions: results, gen: stale})
+	m = updated.(extensionPickerModel)
+	if len(m.list.Items()) != 0 || !m.searching {
+		t.Errorf("stale result was shown: %d items, searching=%v", len(m.list.Items()), m.searching)
+	}
+
+	updated, _ = m.Update(searchResultMsg{extensions: results, gen: m.searchGen})
+	m = updated.(extensionPickerModel)
+	if len(m.list.Items()) != 1 || m.searching {
+		t.Errorf("current result not shown: %d items, searching=%v", len(m.list.Items()), m.searching)
+	}
+}
+
+func TestExtensionPickerPinVersion(t *testing.T) {
+	m := newExtensionPicker(map[string]bool{"golang.go": true}, map[string]string{"golang.go": "0.40.0"})
+	m.list.SetItems([]list.Item{
+		extensionItem{ext: marketplace.Extension{ID: "golang.go", DisplayName: "Go"}},
+		extensionItem{ext: marketplace.Extension{ID: "ms-python.python", DisplayName: "Python"}},
+	})
+
+	// The panel preselects the pinned version.
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = updated.(extensionPickerModel)
+	versions := []marketplace.ExtensionVersion{{Version: "0.42.0", PreRelease: true}, {Version: "0.41.0"}, {Version: "0.40.0"}}
+	updated, _ = m.Update(extVersionsMsg{extensionID: "golang.go", versions: versions})
+	m = updated.(extensionPickerModel)
+	if !m.versionPick.active || m.versionPick.cursor != 3 {
+		t.Fatalf("version panel: active=%v cursor=%d, want the pinned 0.40.0", m.versionPick.active, m.versionPick.cursor)
+	}
+
+	// Moving to "latest" and confirming unpins.
+	for range 3 {
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+		m = updated.(extensionPickerModel)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(extensionPickerModel)
+	if _, ok := m.versions["golang.go"]; ok || m.versionPick.active {
+		t.Errorf("latest kept the pin: %v", m.versions)
+	}
+
+	// Pinning an unselected extension selects it.
+	m.list.Select(1)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = updated.(extensionPickerModel)
+	updated, _ = m.Update(extVersionsMsg{extensionID: "ms-python.python", versions: []marketplace.ExtensionVersion{{Version: "2024.2.1"}}})
+	m = updated.(extensionPickerModel)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m = updated.(extensionPickerModel)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(extensionPickerModel)
+
+	want := []string{"golang.go", "ms-python.python@2024.2.1"}
+	if got := m.finalRefs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("finalRefs() = %v, want %v", got, want)
+	}
+}
+
+func TestExtensionPickerPreReleaseToggle(t *testing.T) {
+	m := newExtensionPicker(nil, nil)
+	m.searchInput = "go"
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlR})
+	m = updated.(extensionPickerModel)
+	if !m.preRelease || !m.searching || cmd == nil {
+		t.Errorf("ctrl+r: preRelease=%v searching=%v cmd=%v, want a new search", m.preRelease, m.searching, cmd != nil)
+	}
+	m.stopSearch()
+
+	item := extensionItem{ext: marketplace.Extension{ID: "golang.go", PreRelease: true}}
+	if !strings.Contains(item.Description(), "pre-release") {
+		t.Errorf("description %q doesn't mark the pre-release", item.Description())
+	}
+}
+
+func TestFetchErrorText(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("fetching: %w", &registry.StatusError{Op: "manifest request", Code: 404}), "Not found"},
+		{fmt.Errorf("fetching: %w", &registry.StatusError{Op: "token request", Code: 403}), "Access denied"},
+		{&marketplace.StatusError{Op: "plugin details", Code: 429}, "Rate limited"},
+		{fmt.Errorf("decoding manifest: unexpected EOF"), "Error: decoding manifest"},
+	}
+	for _, tt := range tests {
+		if got := fetchErrorText(tt.err); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("fetchErrorText(%v) = %q, want prefix %q", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestSearchErrorText(t *testing.T) {
+	err := fmt.Errorf("%w: %w", marketplace.ErrSearchFailed, &marketplace.StatusError{Op: "querying marketplace", Code: 502})
+	if got, want := searchErrorText(err), "Search failed: querying marketplace: status 502"; got != want {
+		t.Errorf("searchErrorText = %q, want %q", got, want)
+	}
+	err = fmt.Errorf("%w: %w", marketplace.ErrSearchFailed, &marketplace.StatusError{Op: "querying marketplace", Code: 429})
+	if got := searchErrorText(err); !strings.HasPrefix(got, "Rate limited") {
+		t.Errorf("searchErrorText = %q, want a rate limit notice", got)
+	}
+}
+
+func TestExtensionPickerAdoptsCanonicalCase(t *testing.T) {
+	m := newExtensionPicker(map[string]bool{"MS-Python.Python": true, "golang.go": true}, map[string]string{"MS-Python.Python": "2024.2.0"})
+	m.order = []string{"golang.go", "MS-Python.Python"}
+	m.customOrder = true
+
+	results := []marketplace.Extension{{ID: "ms-python.python", DisplayName: "Python"}}
+	updated, _ := m.Update(searchResultMsg{extensions: results, gen: m.searchGen})
+	m = updated.(extensionPickerModel)
+
+	if m.selectedItems["MS-Python.Python"] || !m.selectedItems["ms-python.python"] {
+		t.Errorf("selection not renamed to the marketplace casing: %v", m.selectedItems)
+	}
+	// Toggling the result now unchecks the configured extension instead of
+	// adding a second one.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updated.(extensionPickerModel)
+	if got := m.finalRefs(); !reflect.DeepEqual(got, []string{"golang.go"}) {
+		t.Errorf("finalRefs after untoggling = %v, want [golang.go]", got)
+	}
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}})
+	m = updated.(extensionPickerModel)
+	if got, want := m.finalRefs(), []string{"golang.go", "ms-python.python@2024.2.0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("finalRefs = %v, want %v", got, want)
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mochlast/devcontainer-companion/internal/catalog"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
 )
 
 // ErrPickerCancelled is returned when the user quits a picker with q or Ctrl+C.
@@ -59,15 +61,28 @@ func (d featureDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 	descStyle := lipgloss.NewStyle().PaddingLeft(6).Faint(true)
 
 	if isActive {
-		titleStyle = titleStyle.Bold(true).Foreground(lipgloss.Color("170"))
-		descStyle = descStyle.Foreground(lipgloss.Color("170"))
+		titleStyle = titleStyle.Bold(true).Foreground(theme.Accent)
+		descStyle = descStyle.Foreground(theme.Accent)
+	}
+
+	titleMatches, descMatches := catalogMatches(m, index, item.entry.Name)
+	title = highlightMatches(title, titleMatches, titleStyle)
+	desc = highlightMatches(desc, descMatches, descStyle)
+	if item.entry.Recent {
+		desc += recentBadge
+	}
+	if item.entry.Offline {
+		desc += offlineBadge
+	}
+
+	if isActive {
 		title = fmt.Sprintf("> %s %s", checkbox, title)
 	} else {
 		title = fmt.Sprintf("  %s %s", checkbox, title)
 	}
 
 	maxW := m.Width()
-	fmt.Fprintf(w, "%s\n%s", titleStyle.MaxWidth(maxW).Render(title), descStyle.MaxWidth(maxW).Render(desc))
+	fmt.Fprintf(w, "%s\n%s", truncateWidth(titleStyle.Render(title), maxW), truncateWidth(descStyle.Render(desc), maxW))
 }
 
 // featurePickerModel is the bubbletea model for multi-select feature picking.
@@ -77,11 +92,15 @@ type featurePickerModel struct {
 	confirmed     bool
 	quitting      bool
 	preview       readmePreview
+	filter        catalogFilter
+	artifacts     artifactInfos
+	notice        string // see staleNotice
+	baseImage     string // see platformNotice
 	width         int
 	height        int
 }
 
-func newFeaturePicker(entries []catalog.CatalogEntry, preSelected map[string]bool) featurePickerModel {
+func newFeaturePicker(entries []catalog.CatalogEntry, preSelected map[string]bool, baseImage string) featurePickerModel {
 	items := make([]list.Item, 0, len(entries))
 	for _, e := range entries {
 		items = append(items, featureItem{entry: e})
@@ -103,6 +122,7 @@ func newFeaturePicker(entries []catalog.CatalogEntry, preSelected map[string]boo
 		})
 	}
 
+	artifacts := newArtifactInfos()
 	delegate := featureDelegate{selectedItems: selectedItems}
 
 	l := list.New(items, delegate, 80, 20)
@@ -111,7 +131,7 @@ func newFeaturePicker(entries []catalog.CatalogEntry, preSelected map[string]boo
 	l.SetFilteringEnabled(true)
 	l.Filter = officialFirstFilterFunc(items)
 	l.SetShowHelp(true)
-	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170")).MarginLeft(2)
+	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).MarginLeft(2)
 
 	// Rebind help toggle from ? to h to free ? for README preview
 	l.KeyMap.ShowFullHelp = key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "help"))
@@ -121,33 +141,62 @@ func newFeaturePicker(entries []catalog.CatalogEntry, preSelected map[string]boo
 	l.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "README")),
+			key.NewBinding(key.WithKeys("ctrl+s"), key.WithHelp("ctrl+s", "install.sh")),
 		}
 	}
+	l.AdditionalFullHelpKeys = catalogFilterKeys
 
 	return featurePickerModel{
 		list:          l,
 		selectedItems: selectedItems,
 		preview:       newReadmePreview(),
+		filter:        newCatalogFilter(items, l.Title),
+		artifacts:     artifacts,
+		notice:        staleNotice(entries),
+		baseImage:     baseImage,
 	}
 }
 
 func (m featurePickerModel) Init() tea.Cmd {
-	return nil
+	if m.baseImage == "" {
+		return nil
+	}
+	return fetchPlatformsCmd(m.baseImage)
 }
 
 func (m *featurePickerModel) applyLayout() {
 	if m.preview.visible {
-		listW := m.width / 3
+		listW := splitWidth(m.width)
 		m.list.SetWidth(listW)
-		m.list.SetHeight(m.height - 2)
+		m.list.SetHeight(m.height - 2 - extraNoticeLines(m.notice, listW))
 		m.preview.SetSize(m.width-listW, m.height-2)
 	} else {
 		m.list.SetWidth(m.width)
-		m.list.SetHeight(m.height - 2)
+		m.list.SetHeight(m.height - 2 - extraNoticeLines(m.notice, m.width))
 	}
 }
 
+// Update handles msg, shows the artifact info of the highlighted feature in
+// the preview and then starts its artifact info fetch and README prefetch.
 func (m featurePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	picker := model.(featurePickerModel)
+	if picker.quitting {
+		return picker, cmd
+	}
+	ref, sourceURL := "", ""
+	if item, ok := picker.list.SelectedItem().(featureItem); ok {
+		ref, sourceURL = FormatFeatureOciRef(&item.entry), item.entry.SourceURL
+	}
+	picker.preview.SetInfo(artifactSummary(picker.artifacts.infos, ref))
+	// highlight and Prefetch update picker, so they run before it is
+	// returned.
+	highlight := picker.artifacts.highlight(ref)
+	prefetch := picker.preview.Prefetch(sourceURL, ref)
+	return picker, tea.Batch(cmd, highlight, prefetch)
+}
+
+func (m featurePickerModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -159,6 +208,16 @@ func (m featurePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.preview.HandleFetchResult(msg)
 		return m, nil
 
+	case platformsFetchedMsg:
+		m.preview.SetNotice(platformNotice(msg.image, msg.platforms, registry.HostPlatform()))
+		return m, nil
+
+	case artifactInfoDueMsg, artifactInfoFetchedMsg:
+		return m, m.artifacts.handle(msg)
+
+	case readmePrefetchDueMsg, readmePrefetchedMsg:
+		return m, m.preview.HandlePrefetch(msg)
+
 	case tea.KeyMsg:
 		// Don't intercept keys when already filtering
 		if m.list.FilterState() == list.Filtering {
@@ -167,14 +226,36 @@ func (m featurePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.String() {
 		case "?":
-			sourceURL := ""
+			sourceURL, ociRef := "", ""
 			if item, ok := m.list.SelectedItem().(featureItem); ok {
 				sourceURL = item.entry.SourceURL
+				ociRef = FormatFeatureOciRef(&item.entry)
+			}
+			cmd := m.preview.Toggle(sourceURL, ociRef)
+			m.applyLayout()
+			return m, cmd
+
+		case "ctrl+s":
+			// Show the script the feature runs at build time, for review.
+			item, ok := m.list.SelectedItem().(featureItem)
+			if !ok {
+				return m, nil
 			}
-			cmd := m.preview.Toggle(sourceURL)
+			cmd := m.preview.ToggleScript(FormatFeatureOciRef(&item.entry))
 			m.applyLayout()
 			return m, cmd
 
+		case "ctrl+t", "ctrl+a":
+			if m.preview.visible {
+				return m, nil
+			}
+			if msg.String() == "ctrl+t" {
+				m.filter.nextCategory()
+			} else {
+				m.filter.toggleMaintainer(m.list.SelectedItem())
+			}
+			return m, m.filter.apply(&m.list)
+
 		case "esc":
 			if m.preview.visible {
 				m.preview.Close()
@@ -245,13 +326,13 @@ func (m featurePickerModel) View() string {
 	if count > 0 {
 		status = lipgloss.NewStyle().
 			MarginLeft(2).
-			Foreground(lipgloss.Color("170")).
+			Foreground(theme.Accent).
 			Render(fmt.Sprintf("\n  %d feature(s) selected", count))
 	}
 
-	listView := "\n" + m.list.View() + status
+	listView := wrapNotice(m.notice, m.list.Width()) + "\n" + m.list.View() + status
 	if m.preview.visible {
-		listW := m.width / 3
+		listW := splitWidth(m.width)
 		clipped := lipgloss.NewStyle().Width(listW).MaxWidth(listW).Render(listView)
 		return lipgloss.JoinHorizontal(lipgloss.Top, clipped, m.preview.View())
 	}
@@ -261,19 +342,19 @@ func (m featurePickerModel) View() string {
 // PickFeatures shows a multi-select fuzzy-finder for devcontainer features.
 // Returns the selected CatalogEntries.
 func PickFeatures(entries []catalog.CatalogEntry) ([]catalog.CatalogEntry, error) {
-	return PickFeaturesWithSelection(entries, nil)
+	return PickFeaturesWithSelection(entries, nil, "")
 }
 
 // PickFeaturesWithSelection shows a multi-select fuzzy-finder for devcontainer features
 // with optional pre-selected entries. The preSelected map keys are unversioned OCI refs.
-func PickFeaturesWithSelection(entries []catalog.CatalogEntry, preSelected map[string]bool) ([]catalog.CatalogEntry, error) {
+// A baseImage, if known, is checked for a build for the host platform.
+func PickFeaturesWithSelection(entries []catalog.CatalogEntry, preSelected map[string]bool, baseImage string) ([]catalog.CatalogEntry, error) {
 	if len(entries) == 0 {
 		return nil, nil
 	}
 
-	m := newFeaturePicker(entries, preSelected)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	finalModel, err := p.Run()
+	m := newFeaturePicker(entries, preSelected, baseImage)
+	finalModel, err := runProgram(m)
 	if err != nil {
 		return nil, fmt.Errorf("running feature picker: %w", err)
 	}
@@ -284,7 +365,8 @@ func PickFeaturesWithSelection(entries []catalog.CatalogEntry, preSelected map[s
 	}
 
 	var selected []catalog.CatalogEntry
-	for _, item := range result.list.Items() {
+	// Selections hidden by a category or maintainer filter still count.
+	for _, item := range result.filter.all {
 		if fi, ok := item.(featureItem); ok && result.selectedItems[fi.entry.OciRef] {
 			selected = append(selected, fi.entry)
 		}
@@ -293,6 +375,35 @@ func PickFeaturesWithSelection(entries []catalog.CatalogEntry, preSelected map[s
 	return selected, nil
 }
 
+// platformsFetchedMsg carries the platforms published for the base image.
+type platformsFetchedMsg struct {
+	image     string
+	platforms []registry.Platform
+}
+
+// fetchPlatformsCmd looks up the platforms of image. Lookup failures show
+// no warning.
+func fetchPlatformsCmd(image string) tea.Cmd {
+	return func() tea.Msg {
+		platforms, _ := registry.FetchImagePlatforms(image)
+		return platformsFetchedMsg{image: image, platforms: platforms}
+	}
+}
+
+// platformNotice warns when the base image the features install into has
+// no build for host, so the build would fail late. Features themselves are
+// platform independent artifacts; their install scripts fail on the image.
+func platformNotice(image string, platforms []registry.Platform, host registry.Platform) string {
+	if registry.SupportsPlatform(platforms, host) {
+		return ""
+	}
+	names := make([]string, len(platforms))
+	for i, p := range platforms {
+		names[i] = p.String()
+	}
+	return fmt.Sprintf("⚠ %s has no %s build (only %s)", image, host, strings.Join(names, ", "))
+}
+
 // FormatFeatureOciRef creates a versioned OCI reference for a feature.
 func FormatFeatureOciRef(entry *catalog.CatalogEntry) string {
 	ref := entry.OciRef
@@ -301,3 +412,88 @@ func FormatFeatureOciRef(entry *catalog.CatalogEntry) string {
 	}
 	return ref
 }
+
+// ConfirmFeatureRemoval asks before dropping previously configured features.
+// It returns true if the user confirms the removal.
+func ConfirmFeatureRemoval(removed []string) (bool, error) {
+	var desc strings.Builder
+	for _, ref := range removed {
+		desc.WriteString("  - " + ref + "\n")
+	}
+
+	confirmed := false
+	form := NewForm(huh.NewGroup(
+		huh.NewConfirm().
+			Title(fmt.Sprintf("Remove %d configured feature(s)?", len(removed))).
+			Description(strings.TrimRight(desc.String(), "\n")).
+			Affirmative("Remove").
+			Negative("Cancel").
+			Value(&confirmed),
+	))
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("confirming feature removal: %w", err)
+	}
+	return confirmed, nil
+}
+
+// ConfirmFeatureDependencies asks before adding features required by the
+// selection. chains lists one dependency chain per required feature.
+// It returns true if the user wants them added.
+func ConfirmFeatureDependencies(chains []string) (bool, error) {
+	var desc strings.Builder
+	for _, chain := range chains {
+		desc.WriteString("  - " + chain + "\n")
+	}
+
+	confirmed := true
+	form := NewForm(huh.NewGroup(
+		huh.NewConfirm().
+			Title(fmt.Sprintf("Add %d required feature(s)?", len(chains))).
+			Description(strings.TrimRight(desc.String(), "\n")).
+			Affirmative("Add").
+			Negative("Skip").
+			Value(&confirmed),
+	))
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("confirming feature dependencies: %w", err)
+	}
+	return confirmed, nil
+}
+
+// PromptFeatureNote asks why a newly added feature is needed. The note is
+// optional; a blank answer adds none.
+func PromptFeatureNote(name string) (string, error) {
+	var note string
+	form := NewForm(huh.NewGroup(
+		huh.NewInput().
+			Title(fmt.Sprintf("Why add %s? (optional)", name)).
+			Description("Saved in customizations.dcc.notes and shown in the preview. Leave blank to skip.").
+			Value(&note),
+	))
+	if err := form.Run(); err != nil {
+		return "", fmt.Errorf("asking for a feature note: %w", err)
+	}
+	return strings.TrimSpace(note), nil
+}
+
+// WarnRedundantFeatures notes selected features the template likely provides
+// already. It only informs; the selection is kept either way.
+func WarnRedundantFeatures(template string, refs []string) error {
+	var desc strings.Builder
+	for _, ref := range refs {
+		desc.WriteString("  - " + ref + "\n")
+	}
+	desc.WriteString("\nThey may be redundant. Keep them to pin another version or options.")
+
+	form := NewForm(huh.NewGroup(
+		huh.NewNote().
+			Title(fmt.Sprintf("The %s template may already provide %d selected feature(s)", template, len(refs))).
+			Description(desc.String()).
+			Next(true).
+			NextLabel("Continue"),
+	))
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("showing feature warning: %w", err)
+	}
+	return nil
+}
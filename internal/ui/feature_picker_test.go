@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mochlast/devcontainer-companion/internal/catalog"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+// runScripted makes the next programs read keys from the script.
+func runScripted(t *testing.T, keys ...string) {
+	t.Helper()
+	scriptKeys(keys...)
+	t.Cleanup(func() { scriptKeys() })
+}
+
+var pickerEntries = []catalog.CatalogEntry{
+	{Name: "Node.js", OciRef: "ghcr.io/devcontainers/features/node"},
+	{Name: "Python", OciRef: "ghcr.io/devcontainers/features/python"},
+	{Name: "Docker in Docker", OciRef: "ghcr.io/devcontainers/features/docker-in-docker"},
+}
+
+func TestPickFeaturesToggle(t *testing.T) {
+	// Toggle Node.js on, then Python on and off again, then Docker via search.
+	runScripted(t, "space", "down", "space", "space", "docker", "enter", "space", "enter")
+	selected, err := PickFeaturesWithSelection(pickerEntries, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range selected {
+		names = append(names, e.Name)
+	}
+	if len(names) != 2 || names[0] != "Node.js" || names[1] != "Docker in Docker" {
+		t.Errorf("selected %v, want [Node.js Docker in Docker]", names)
+	}
+}
+
+func TestPickFeaturesPreSelectedAndCancel(t *testing.T) {
+	runScripted(t, "down", "space", "enter")
+	preSelected := map[string]bool{"ghcr.io/devcontainers/features/python": true}
+	selected, err := PickFeaturesWithSelection(pickerEntries, preSelected, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Python is pinned first, so "down" lands on Node.js.
+	var refs []string
+	for _, e := range selected {
+		refs = append(refs, e.OciRef)
+	}
+	want := "ghcr.io/devcontainers/features/python, ghcr.io/devcontainers/features/node"
+	if strings.Join(refs, ", ") != want {
+		t.Errorf("selected %v, want %s", refs, want)
+	}
+
+	runScripted(t, "space", "q")
+	if _, err := PickFeaturesWithSelection(pickerEntries, nil, ""); !errors.Is(err, ErrPickerCancelled) {
+		t.Errorf("err = %v, want ErrPickerCancelled", err)
+	}
+}
+
+func TestPickOrderScripted(t *testing.T) {
+	runScripted(t, "J", "enter")
+	order, ok, err := PickOrder("Order", []string{"a", "b", "c"})
+	if err != nil || !ok || len(order) != 3 || order[0] != 1 || order[1] != 0 {
+		t.Errorf("PickOrder = %v, %v, %v; want [1 0 2]", order, ok, err)
+	}
+}
+
+func TestPlatformNotice(t *testing.T) {
+	host := registry.Platform{OS: "linux", Architecture: "arm64"}
+	if got := platformNotice("ubuntu", nil, host); got != "" {
+		t.Errorf("image of unknown platforms = %q, want no notice", got)
+	}
+	amd := []registry.Platform{{OS: "linux", Architecture: "amd64"}}
+	got := platformNotice("example/amd64-only", amd, host)
+	if !strings.Contains(got, "example/amd64-only") || !strings.Contains(got, "linux/arm64") || !strings.Contains(got, "linux/amd64") {
+		t.Errorf("amd64-only image = %q", got)
+	}
+
+	// The base image notice stays while other features are previewed.
+	s390x := []registry.Platform{{OS: "linux", Architecture: "s390x"}}
+	m := newFeaturePicker(pickerEntries, nil, "example/s390x-only")
+	model, _ := m.Update(platformsFetchedMsg{image: "example/s390x-only", platforms: s390x})
+	m = model.(featurePickerModel)
+	m.preview.Toggle("", "ghcr.io/example/features/tool:1")
+	m.preview.Toggle("", "ghcr.io/example/features/other:1")
+	if m.preview.notice == "" {
+		t.Error("base image notice was dropped")
+	}
+}
+
+func TestPreviewInstallScript(t *testing.T) {
+	const ref = "ghcr.io/devcontainers-extra/features/bun:1"
+	p := newReadmePreview()
+	p.SetSize(60, 20)
+
+	if p.ToggleScript(ref) == nil {
+		t.Fatal("opening install.sh returned no fetch command")
+	}
+	if p.title != "install.sh" || !p.loading {
+		t.Errorf("title = %q, loading = %v", p.title, p.loading)
+	}
+	// A repeated toggle while loading is ignored.
+	if p.ToggleScript(ref); !p.visible {
+		t.Error("toggle while loading closed the preview")
+	}
+
+	p.HandleFetchResult(readmeFetchedMsg{key: p.key, content: "```sh\nset -e\ncurl -fsSL https://bun.sh/install | bash\n```\n"})
+	if !strings.Contains(p.viewport.View(), "curl -fsSL") {
+		t.Errorf("script not rendered:\n%s", p.viewport.View())
+	}
+
+	// Backticks in the script don't end the block early.
+	script := "cat > README.md <<'EOF'\n```sh\nmake\n```\nEOF\n"
+	if got := codeBlock("sh", script); !strings.HasPrefix(got, "````sh\n") || !strings.HasSuffix(got, "\nEOF\n````\n") {
+		t.Errorf("codeBlock = %q, want a four-backtick fence", got)
+	}
+
+	// The README of the same feature is a different item.
+	if p.Toggle("", ref) == nil || p.title != "README Preview" {
+		t.Errorf("README toggle: title = %q", p.title)
+	}
+}
+
+func TestPickFeaturesCategoryFilter(t *testing.T) {
+	entries := []catalog.CatalogEntry{
+		{Name: "Docker in Docker", Maintainer: "Dev Container Spec Maintainers", OciRef: "ghcr.io/devcontainers/features/docker-in-docker", Category: "Containers"},
+		{Name: "Node.js", Maintainer: "Dev Container Spec Maintainers", OciRef: "ghcr.io/devcontainers/features/node", Category: "Languages"},
+		{Name: "Deno", Maintainer: "someone", OciRef: "ghcr.io/someone/features/deno", Category: "Languages"},
+	}
+	// Select Docker, narrow to Languages (Node.js first) and select it, then
+	// narrow to someone's features and select Deno.
+	runScripted(t, "space", "ctrl+t", "space", "down", "ctrl+a", "space", "enter")
+	selected, err := PickFeaturesWithSelection(entries, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range selected {
+		names = append(names, e.Name)
+	}
+	if strings.Join(names, ", ") != "Docker in Docker, Node.js, Deno" {
+		t.Errorf("selected %v, want all three including the filtered-out Docker", names)
+	}
+}
+
+func TestPickerFitsStaleNotice(t *testing.T) {
+	entries := make([]catalog.CatalogEntry, 0, 20)
+	for i := range 20 {
+		entries = append(entries, catalog.CatalogEntry{Name: fmt.Sprintf("Tool %d", i), OciRef: fmt.Sprintf("ghcr.io/example/features/tool-%d", i), Stale: true})
+	}
+	m := newFeaturePicker(entries, nil, "")
+	model, _ := m.Update(tea.WindowSizeMsg{Width: 90, Height: 20})
+	m = model.(featurePickerModel)
+	m.preview.Toggle("", "ghcr.io/example/features/tool-0:1")
+	m.applyLayout()
+
+	// The notice wraps in the narrow list column of the split view.
+	if extraNoticeLines(m.notice, m.list.Width()) == 0 {
+		t.Fatal("notice did not wrap; the test needs a narrower column")
+	}
+	column := wrapNotice(m.notice, m.list.Width()) + "\n" + m.list.View()
+	if got := lipgloss.Height(column); got > 20 {
+		t.Errorf("list column is %d lines high, want at most the window's 20", got)
+	}
+}
@@ -1,38 +1,215 @@
 package ui
 
 import (
+	"slices"
 	"sort"
+	"strings"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/mochlast/devcontainer-companion/internal/catalog"
 )
 
 // ociRefItem is implemented by list items backed by a catalog entry.
 type ociRefItem interface {
 	ociRef() string
+	recent() bool
+	catalogEntry() (catalog.CatalogEntry, bool)
 }
 
 func (i templateItem) ociRef() string { return i.entry.OciRef }
 func (i featureItem) ociRef() string  { return i.entry.OciRef }
+func (i templateItem) recent() bool   { return i.entry.Recent }
+func (i featureItem) recent() bool    { return i.entry.Recent }
+
+// catalogEntry returns the entry behind the item; the empty template has none.
+func (i templateItem) catalogEntry() (catalog.CatalogEntry, bool) { return i.entry, !i.isEmpty }
+func (i featureItem) catalogEntry() (catalog.CatalogEntry, bool)  { return i.entry, true }
+
+// catalogFilter narrows a picker to one category and/or maintainer, on top
+// of the fuzzy filter. Ctrl+T cycles the category, Ctrl+A toggles the
+// maintainer of the highlighted entry.
+type catalogFilter struct {
+	all        []list.Item
+	title      string // list title without the active filters
+	category   string
+	maintainer string
+}
+
+func newCatalogFilter(items []list.Item, title string) catalogFilter {
+	return catalogFilter{all: items, title: title}
+}
+
+// active reports whether the filter hides anything.
+func (f catalogFilter) active() bool { return f.category != "" || f.maintainer != "" }
+
+// nextCategory cycles All → each of catalog.Categories → All.
+func (f *catalogFilter) nextCategory() {
+	i := slices.Index(catalog.Categories, f.category)
+	if i+1 < len(catalog.Categories) {
+		f.category = catalog.Categories[i+1]
+	} else {
+		f.category = ""
+	}
+}
+
+// toggleMaintainer narrows to the maintainer of selected, or clears the
+// maintainer filter when one is set.
+func (f *catalogFilter) toggleMaintainer(selected list.Item) {
+	if f.maintainer != "" {
+		f.maintainer = ""
+		return
+	}
+	if r, ok := selected.(ociRefItem); ok {
+		if e, ok := r.catalogEntry(); ok {
+			f.maintainer = e.Maintainer
+		}
+	}
+}
+
+// items returns the items that pass the filter. Items without an entry
+// (the empty template) are only shown unfiltered.
+func (f catalogFilter) items() []list.Item {
+	if !f.active() {
+		return f.all
+	}
+	var items []list.Item
+	for _, item := range f.all {
+		r, ok := item.(ociRefItem)
+		if !ok {
+			continue
+		}
+		e, ok := r.catalogEntry()
+		if !ok {
+			continue
+		}
+		if f.category != "" && e.Category != f.category {
+			continue
+		}
+		if f.maintainer != "" && !strings.EqualFold(e.Maintainer, f.maintainer) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// apply shows the filtered items in l and names the active filters in its
+// title.
+func (f catalogFilter) apply(l *list.Model) tea.Cmd {
+	items := f.items()
+	l.Filter = officialFirstFilterFunc(items)
+	title := f.title
+	if f.category != "" {
+		title += " · " + f.category
+	}
+	if f.maintainer != "" {
+		title += " · by " + f.maintainer
+	}
+	l.Title = title
+	cmd := l.SetItems(items)
+	l.ResetSelected()
+	return cmd
+}
+
+// catalogFilterKeys are the help entries for the catalogFilter keys.
+func catalogFilterKeys() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("ctrl+t"), key.WithHelp("ctrl+t", "category")),
+		key.NewBinding(key.WithKeys("ctrl+a"), key.WithHelp("ctrl+a", "maintainer")),
+	}
+}
 
 // officialFirstFilterFunc returns a list.FilterFunc that fuzzy-matches like the
-// default filter but sorts official devcontainers entries (ghcr.io/devcontainers/)
-// to the top of the results.
+// default filter but sorts recently used entries, then entries of preferred
+// orgs (DCC_PREFERRED_ORGS), then official devcontainers entries
+// (ghcr.io/devcontainers/), to the top of the results.
 func officialFirstFilterFunc(items []list.Item) list.FilterFunc {
 	return func(term string, targets []string) []list.Rank {
 		ranks := list.DefaultFilter(term, targets)
 		sort.SliceStable(ranks, func(i, j int) bool {
-			iOfficial := isOfficialItem(items[ranks[i].Index])
-			jOfficial := isOfficialItem(items[ranks[j].Index])
-			return iOfficial && !jOfficial
+			return itemTier(items[ranks[i].Index]) < itemTier(items[ranks[j].Index])
 		})
 		return ranks
 	}
 }
 
-func isOfficialItem(item list.Item) bool {
-	if r, ok := item.(ociRefItem); ok {
-		return catalog.IsOfficial(r.ociRef())
+// itemTier orders filter results: recent, preferred, official, then
+// everything else.
+func itemTier(item list.Item) int {
+	r, ok := item.(ociRefItem)
+	switch {
+	case !ok:
+		return 3
+	case r.recent():
+		return 0
+	}
+	return 1 + catalog.SourceRank(r.ociRef())
+}
+
+// staleNotice is shown in place of the blank line above a picker whose
+// entries are fallback data because the live catalog didn't parse.
+func staleNotice(entries []catalog.CatalogEntry) string {
+	if !catalog.IsStale(entries) {
+		return ""
+	}
+	return lipgloss.NewStyle().Foreground(theme.Warning).MarginLeft(2).
+		Render("⚠ containers.dev catalog format changed; using cached data — please update dcc")
+}
+
+// wrapNotice wraps a staleNotice to a list column width wide.
+func wrapNotice(notice string, width int) string {
+	if notice == "" || width <= 0 {
+		return notice
+	}
+	return lipgloss.NewStyle().Width(width).Render(notice)
+}
+
+// extraNoticeLines is the number of lines notice wraps to beyond the blank
+// line it replaces, which the list below gives up.
+func extraNoticeLines(notice string, width int) int {
+	return lipgloss.Height(wrapNotice(notice, width)) - 1
+}
+
+// offlineBadge is appended to the description of entries that come from the
+// bundled catalog snapshot rather than a live fetch.
+const offlineBadge = "  · offline snapshot"
+
+// recentBadge marks entries pinned to the top because they were used recently.
+const recentBadge = "  · recent"
+
+// catalogMatches returns the fuzzy-match positions for the item at index,
+// split into the title (Name) and description (Maintainer  OciRef) indexes.
+// Matches are computed against CatalogEntry.FilterValue ("Name Maintainer").
+func catalogMatches(m list.Model, index int, name string) (title, desc []int) {
+	if m.FilterState() == list.Unfiltered {
+		return nil, nil
+	}
+	return splitCatalogMatches(m.MatchesForItem(index), name)
+}
+
+func splitCatalogMatches(matches []int, name string) (title, desc []int) {
+	nameLen := len([]rune(name))
+	for _, i := range matches {
+		switch {
+		case i < nameLen:
+			title = append(title, i)
+		case i > nameLen:
+			desc = append(desc, i-nameLen-1)
+		}
+	}
+	return title, desc
+}
+
+// highlightMatches underlines and bolds the runes of s at the given indexes,
+// rendering the rest with base.
+func highlightMatches(s string, indexes []int, base lipgloss.Style) string {
+	if len(indexes) == 0 {
+		return s
 	}
-	return false
+	unmatched := base.Inline(true)
+	matched := unmatched.Underline(true).Bold(true)
+	return lipgloss.StyleRunes(s, indexes, matched, unmatched)
 }
@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/mochlast/devcontainer-companion/internal/catalog"
+)
+
+func TestSplitCatalogMatches(t *testing.T) {
+	// FilterValue is "Node.js Dev Container Spec Maintainers"; the description
+	// starts with the maintainer.
+	title, desc := splitCatalogMatches([]int{0, 1, 7, 8, 9}, "Node.js")
+	if want := []int{0, 1}; !reflect.DeepEqual(title, want) {
+		t.Errorf("title = %v, want %v", title, want)
+	}
+	if want := []int{0, 1}; !reflect.DeepEqual(desc, want) {
+		t.Errorf("desc = %v, want %v", desc, want)
+	}
+}
+
+func TestOfficialFirstFilterFuncRecent(t *testing.T) {
+	items := []list.Item{
+		featureItem{entry: catalog.CatalogEntry{Name: "node community", OciRef: "ghcr.io/someone/features/node"}},
+		featureItem{entry: catalog.CatalogEntry{Name: "node", OciRef: "ghcr.io/devcontainers/features/node"}},
+		featureItem{entry: catalog.CatalogEntry{Name: "node lts", OciRef: "ghcr.io/other/features/node", Recent: true}},
+	}
+	targets := make([]string, len(items))
+	for i, item := range items {
+		targets[i] = item.FilterValue()
+	}
+
+	var order []int
+	for _, r := range officialFirstFilterFunc(items)("node", targets) {
+		order = append(order, r.Index)
+	}
+	if want := []int{2, 1, 0}; !reflect.DeepEqual(order, want) {
+		t.Errorf("result order = %v, want %v (recent, official, other)", order, want)
+	}
+}
+
+func TestCatalogFilter(t *testing.T) {
+	items := []list.Item{templateItem{isEmpty: true}}
+	for _, e := range []catalog.CatalogEntry{
+		{Name: "Go", Maintainer: "A", Category: "Languages"},
+		{Name: "Postgres", Maintainer: "B", Category: "Databases"},
+		{Name: "Rust", Maintainer: "b", Category: "Languages"},
+	} {
+		items = append(items, templateItem{entry: e})
+	}
+	f := newCatalogFilter(items, "Select")
+	titles := func() string {
+		var t []string
+		for _, item := range f.items() {
+			t = append(t, item.(templateItem).Title())
+		}
+		return strings.Join(t, ", ")
+	}
+
+	if got := titles(); got != emptyTemplateName+", Go, Postgres, Rust" {
+		t.Errorf("unfiltered = %s", got)
+	}
+	f.nextCategory()
+	if f.category != "Languages" || titles() != "Go, Rust" {
+		t.Errorf("category %q shows %s, want Languages with Go, Rust", f.category, titles())
+	}
+	f.toggleMaintainer(items[3])
+	if titles() != "Rust" {
+		t.Errorf("maintainer %q shows %s, want Rust", f.maintainer, titles())
+	}
+	f.category = ""
+	if titles() != "Postgres, Rust" {
+		t.Errorf("maintainer filter matches case-insensitively, got %s", titles())
+	}
+	f.toggleMaintainer(items[1])
+	if f.maintainer != "" {
+		t.Errorf("second toggle kept maintainer %q", f.maintainer)
+	}
+
+	for range len(catalog.Categories) + 1 {
+		f.nextCategory()
+	}
+	if f.category != "" {
+		t.Errorf("cycling through all categories ends at %q, want All", f.category)
+	}
+}
+
+func TestOfficialFirstFilterFuncPreferredOrgs(t *testing.T) {
+	t.Setenv("DCC_PREFERRED_ORGS", "ghcr.io/mycorp")
+	items := []list.Item{
+		featureItem{entry: catalog.CatalogEntry{Name: "node", OciRef: "ghcr.io/devcontainers/features/node"}},
+		featureItem{entry: catalog.CatalogEntry{Name: "node corp", OciRef: "ghcr.io/mycorp/features/node"}},
+		featureItem{entry: catalog.CatalogEntry{Name: "node lts", OciRef: "ghcr.io/other/features/node", Recent: true}},
+	}
+	targets := make([]string, len(items))
+	for i, item := range items {
+		targets[i] = item.FilterValue()
+	}
+
+	var order []int
+	for _, r := range officialFirstFilterFunc(items)("node", targets) {
+		order = append(order, r.Index)
+	}
+	if want := []int{2, 1, 0}; !reflect.DeepEqual(order, want) {
+		t.Errorf("result order = %v, want %v (recent, preferred, official)", order, want)
+	}
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// hostSize matches the sizes hostRequirements accepts, e.g. "8gb" or "512 MB".
+var hostSize = regexp.MustCompile(`(?i)^(\d+)\s*([kmgt]b)$`)
+
+// hostRequirementsSpec holds the editable hostRequirements fields as typed.
+type hostRequirementsSpec struct {
+	CPUs    string
+	Memory  string
+	Storage string
+}
+
+// readHostRequirements formats hostRequirements for editing.
+func readHostRequirements(config map[string]any) hostRequirementsSpec {
+	req, _ := config["hostRequirements"].(map[string]any)
+	var spec hostRequirementsSpec
+	if cpus, ok := req["cpus"]; ok {
+		spec.CPUs = fmt.Sprint(cpus)
+	}
+	if memory, ok := req["memory"]; ok {
+		spec.Memory = fmt.Sprint(memory)
+	}
+	if storage, ok := req["storage"]; ok {
+		spec.Storage = fmt.Sprint(storage)
+	}
+	return spec
+}
+
+// writeHostRequirements stores spec in hostRequirements. Blank fields are
+// removed, other keys (gpu) are kept, and an empty object is deleted.
+func writeHostRequirements(config map[string]any, spec hostRequirementsSpec) {
+	req, _ := config["hostRequirements"].(map[string]any)
+	if req == nil {
+		req = make(map[string]any)
+	}
+	if cpus := strings.TrimSpace(spec.CPUs); cpus != "" {
+		n, _ := strconv.Atoi(cpus)
+		req["cpus"] = n
+	} else {
+		delete(req, "cpus")
+	}
+	for key, val := range map[string]string{"memory": spec.Memory, "storage": spec.Storage} {
+		if size := formatHostSize(val); size != "" {
+			req[key] = size
+		} else {
+			delete(req, key)
+		}
+	}
+
+	if len(req) == 0 {
+		delete(config, "hostRequirements")
+		return
+	}
+	config["hostRequirements"] = req
+}
+
+// formatHostSize normalizes a size to the lowercase form without spaces,
+// e.g. "8 GB" to "8gb". Invalid or blank input yields "".
+func formatHostSize(s string) string {
+	m := hostSize.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return ""
+	}
+	return m[1] + strings.ToLower(m[2])
+}
+
+func checkHostCPUs(s string) error {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	if n, err := strconv.Atoi(s); err != nil || n < 1 {
+		return fmt.Errorf("cpus must be a whole number of at least 1")
+	}
+	return nil
+}
+
+func checkHostSize(s string) error {
+	if strings.TrimSpace(s) == "" || formatHostSize(s) != "" {
+		return nil
+	}
+	return fmt.Errorf("use a number with kb, mb, gb or tb, e.g. 8gb")
+}
+
+// editHostRequirementsField edits the minimum cpus, memory and storage.
+func editHostRequirementsField(config map[string]any) (bool, error) {
+	before := readHostRequirements(config)
+	spec := before
+
+	form := NewForm(huh.NewGroup(
+		huh.NewNote().
+			Title("Host Requirements").
+			Description("Minimum machine for this container, honored by Codespaces and some orchestrators.\nClear all fields to remove the requirements."),
+		huh.NewInput().
+			Title("CPUs").
+			Description("Minimum number of cores, e.g. 4").
+			Validate(checkHostCPUs).
+			Value(&spec.CPUs),
+		huh.NewInput().
+			Title("Memory").
+			Description("Minimum RAM, e.g. 8gb").
+			Validate(checkHostSize).
+			Value(&spec.Memory),
+		huh.NewInput().
+			Title("Storage").
+			Description("Minimum disk space, e.g. 32gb").
+			Validate(checkHostSize).
+			Value(&spec.Storage),
+	))
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("editing host requirements: %w", err)
+	}
+
+	if normalizeHostRequirements(spec) == normalizeHostRequirements(before) {
+		return false, nil
+	}
+	writeHostRequirements(config, spec)
+	return true, nil
+}
+
+// normalizeHostRequirements makes specs comparable regardless of
+// spacing and unit case.
+func normalizeHostRequirements(spec hostRequirementsSpec) hostRequirementsSpec {
+	return hostRequirementsSpec{
+		CPUs:    strings.TrimSpace(spec.CPUs),
+		Memory:  formatHostSize(spec.Memory),
+		Storage: formatHostSize(spec.Storage),
+	}
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWriteHostRequirements(t *testing.T) {
+	config := map[string]any{
+		"hostRequirements": map[string]any{"cpus": 2.0, "memory": "4gb", "gpu": "optional"},
+	}
+	spec := readHostRequirements(config)
+	if want := (hostRequirementsSpec{CPUs: "2", Memory: "4gb"}); spec != want {
+		t.Fatalf("readHostRequirements = %+v, want %+v", spec, want)
+	}
+
+	writeHostRequirements(config, hostRequirementsSpec{CPUs: "4", Memory: "8 GB", Storage: "32gb"})
+	want := map[string]any{"cpus": 4, "memory": "8gb", "storage": "32gb", "gpu": "optional"}
+	if got := config["hostRequirements"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("hostRequirements = %v, want %v", got, want)
+	}
+
+	// Clearing the fields keeps gpu; without it the object goes away.
+	writeHostRequirements(config, hostRequirementsSpec{})
+	if got := config["hostRequirements"]; !reflect.DeepEqual(got, map[string]any{"gpu": "optional"}) {
+		t.Errorf("after clearing = %v, want only gpu", got)
+	}
+	delete(config["hostRequirements"].(map[string]any), "gpu")
+	writeHostRequirements(config, hostRequirementsSpec{})
+	if _, ok := config["hostRequirements"]; ok {
+		t.Error("empty hostRequirements should be deleted")
+	}
+}
+
+func TestHostRequirementsValidation(t *testing.T) {
+	for _, s := range []string{"", "8gb", "512MB", "1 tb", "64kb"} {
+		if err := checkHostSize(s); err != nil {
+			t.Errorf("checkHostSize(%q) = %v", s, err)
+		}
+	}
+	for _, s := range []string{"8", "8g", "gb", "1.5gb", "8 gigabytes"} {
+		if err := checkHostSize(s); err == nil {
+			t.Errorf("checkHostSize(%q) accepted a size without a recognized unit", s)
+		}
+	}
+	for s, ok := range map[string]bool{"": true, "4": true, "0": false, "-2": false, "two": false} {
+		if err := checkHostCPUs(s); (err == nil) != ok {
+			t.Errorf("checkHostCPUs(%q) = %v", s, err)
+		}
+	}
+}
@@ -1,20 +1,28 @@
 package ui
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"os/exec"
+	"reflect"
 	"strings"
+	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/feature"
 	"github.com/mochlast/devcontainer-companion/internal/template"
 )
 
 // HubAction represents the action selected from the hub menu.
-// Build and Open are handled within the hub TUI and never returned to the caller.
+// Build, Up, Open and Edit are handled within the hub TUI and never returned to the caller.
 type HubAction string
 
 const (
@@ -23,26 +31,41 @@ const (
 	HubActionExtensions     HubAction = "extensions"
 	HubActionPlugins        HubAction = "plugins"
 	HubActionCustomizations HubAction = "customizations"
+	HubActionVSCodeSettings HubAction = "vscode-settings"
+	HubActionPreset         HubAction = "preset"
 	HubActionBuild          HubAction = "build"
+	HubActionUp             HubAction = "up"
 	HubActionOpen           HubAction = "open"
+	HubActionEdit           HubAction = "edit"
 	HubActionExit           HubAction = "exit"
 )
 
 // HubCallbacks provides functions for actions handled within the hub TUI.
 type HubCallbacks struct {
-	Build   func(noCache bool) (string, error)
-	Open    func() (string, error)
-	Preload func(action HubAction) (any, error) // loads data before exiting for a sub-flow
+	Build         func(ctx context.Context, noCache bool, onLine func(string)) (string, error) // onLine receives output as it arrives
+	CheckFeatures func(ctx context.Context, config map[string]any) []string                    // features that don't resolve, checked before Build
+	Up            func(ctx context.Context) (containerID, output string, err error)
+	Open          func(ctx context.Context) (string, error)
+	Edit          func() (*exec.Cmd, error)           // editor command for the raw config file
+	Reload        func() (map[string]any, error)      // re-reads the config after editing
+	Undo          func() (map[string]any, error)      // restores the config before the last change
+	Preload       func(action HubAction) (any, error) // loads data before exiting for a sub-flow
+	Migrate       func() (map[string]any, error)      // moves deprecated keys to their current place
+	ToggleSchema  func() (map[string]any, error)      // adds $schema, or removes it if set
+	DryRun        bool                                // Build/Up/Open return the command line instead of running it
 }
 
 // shortcutActions maps single-key shortcuts to their hub actions.
-// CLI-dependent items (b/o) are added dynamically.
+// CLI-dependent items (b/u/o) are added dynamically.
 var shortcutActions = map[string]HubAction{
 	"t": HubActionTemplate,
 	"f": HubActionFeatures,
 	"e": HubActionExtensions,
 	"j": HubActionPlugins,
 	"c": HubActionCustomizations,
+	"s": HubActionVSCodeSettings,
+	"p": HubActionPreset,
+	"E": HubActionEdit,
 }
 
 type hubMenuItem struct {
@@ -75,22 +98,58 @@ func (d hubMenuDelegate) Render(w io.Writer, m list.Model, index int, listItem l
 
 	title := item.label
 	if isSelected {
-		titleStyle = titleStyle.Bold(true).Foreground(lipgloss.Color("170"))
-		descStyle = descStyle.Foreground(lipgloss.Color("170"))
+		titleStyle = titleStyle.Bold(true).Foreground(theme.Accent)
+		descStyle = descStyle.Foreground(theme.Accent)
 		title = fmt.Sprintf("> [%s] %s", item.key, title)
 	} else {
 		title = fmt.Sprintf("  [%s] %s", item.key, title)
 	}
 
 	maxW := m.Width()
-	fmt.Fprintf(w, "%s\n%s", titleStyle.MaxWidth(maxW).Render(title), descStyle.MaxWidth(maxW).Render(item.description))
+	fmt.Fprintf(w, "%s\n%s", truncateWidth(titleStyle.Render(title), maxW), truncateWidth(descStyle.Render(item.description), maxW))
 }
 
 // cmdResultMsg is sent when an async command (build/open) completes.
 type cmdResultMsg struct {
-	kind    string // "build" or "open"
-	success bool
-	detail  string
+	kind      string // "build", "up", "open", "dry-run", "edit", "copy" or "preload"
+	success   bool
+	cancelled bool // a build, up or open was cancelled with x or Ctrl+C
+	detail    string
+}
+
+// cancellingLabel is the busy label after x or Ctrl+C until the command exits.
+const cancellingLabel = "Cancelling..."
+
+// cancelledLabels are the result titles of cancelled commands by kind.
+var cancelledLabels = map[string]string{
+	"build": "Build cancelled",
+	"up":    "Start cancelled",
+	"open":  "Open cancelled",
+}
+
+// buildOutputMsg carries a line of output from a running build.
+type buildOutputMsg struct {
+	line string
+}
+
+// maxBuildLogLines caps the build output kept for the live preview.
+const maxBuildLogLines = 500
+
+// resultExpiredMsg dismisses a transient result overlay.
+type resultExpiredMsg struct {
+	result *cmdResultMsg
+}
+
+// editorDoneMsg is sent when the external editor exits.
+type editorDoneMsg struct {
+	err error
+}
+
+// featureCheckMsg carries the features that don't resolve, found before a
+// build.
+type featureCheckMsg struct {
+	unresolved []string
+	cancelled  bool
 }
 
 // preloadDoneMsg is sent when a preload operation completes.
@@ -110,7 +169,13 @@ type hubModel struct {
 	action         HubAction
 	busy           bool
 	busyLabel      string
+	indicator      busyIndicator
+	buildLog       []string
+	buildUpdates   <-chan tea.Msg
+	cancel         context.CancelFunc // cancels the running build, up or open
+	unresolved     []string           // features that don't resolve; Build waits for y/n
 	result         *cmdResultMsg
+	search         previewSearch
 	preloadedData  any
 	quitting       bool
 	width          int
@@ -134,6 +199,8 @@ func newHubMenuList(projectName string, cli template.CLIInfo, dirty bool) list.M
 		hubMenuItem{key: "e", label: "VS Code Extensions", description: "Search & select VS Code extensions", action: HubActionExtensions},
 		hubMenuItem{key: "j", label: "JetBrains Plugins", description: "Search & select JetBrains plugins", action: HubActionPlugins},
 		hubMenuItem{key: "c", label: "Edit Settings", description: "Edit remoteUser, ports, commands, env", action: HubActionCustomizations},
+		hubMenuItem{key: "s", label: "VS Code Settings", description: "Edit customizations.vscode.settings as JSON", action: HubActionVSCodeSettings},
+		hubMenuItem{key: "p", label: "Apply Preset", description: "Merge a saved preset into the config", action: HubActionPreset},
 	}
 
 	if cli.Installed {
@@ -143,6 +210,7 @@ func newHubMenuList(projectName string, cli template.CLIInfo, dirty bool) list.M
 		}
 		items = append(items,
 			hubMenuItem{key: "b", label: "Build", description: buildDesc, action: HubActionBuild},
+			hubMenuItem{key: "u", label: "Start Container", description: "devcontainer up, for SSH or docker exec", action: HubActionUp},
 		)
 	}
 
@@ -153,6 +221,7 @@ func newHubMenuList(projectName string, cli template.CLIInfo, dirty bool) list.M
 	}
 
 	items = append(items,
+		hubMenuItem{key: "E", label: "Edit in $EDITOR", description: "Open devcontainer.json in your editor", action: HubActionEdit},
 		hubMenuItem{key: "q", label: "Exit", description: "Exit dcc", action: HubActionExit},
 	)
 
@@ -161,14 +230,14 @@ func newHubMenuList(projectName string, cli template.CLIInfo, dirty bool) list.M
 	l.SetShowStatusBar(false)
 	l.SetFilteringEnabled(false)
 	l.SetShowHelp(false)
-	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170")).MarginLeft(2)
+	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).MarginLeft(2)
 	return l
 }
 
 // renderHubLayout renders the standard hub split-pane layout: menu on the left,
 // preview content on the right inside a bordered box.
 func renderHubLayout(menuList list.Model, previewContent, previewTitle string, width, height int) string {
-	menuW := max(width/3, 30)
+	menuW := max(splitWidth(width), 30)
 	previewW := width - menuW
 
 	menuView := "\n" + menuList.View()
@@ -176,13 +245,13 @@ func renderHubLayout(menuList list.Model, previewContent, previewTitle string, w
 
 	title := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("170")).
+		Foreground(theme.Accent).
 		PaddingLeft(1).
 		Render(previewTitle)
 
 	body := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("170")).
+		BorderForeground(theme.Accent).
 		Width(previewW - 2).
 		Height(height - 4).
 		Render(previewContent)
@@ -200,6 +269,7 @@ func newHubModel(projectName string, config map[string]any, cli template.CLIInfo
 	}
 	if cli.Installed {
 		actions["b"] = HubActionBuild
+		actions["u"] = HubActionUp
 	}
 	if cli.HasOpen {
 		actions["o"] = HubActionOpen
@@ -212,6 +282,8 @@ func newHubModel(projectName string, config map[string]any, cli template.CLIInfo
 		dirty:     dirty,
 		callbacks: cb,
 		actions:   actions,
+		search:    newPreviewSearch(),
+		indicator: newBusyIndicator(),
 	}
 }
 
@@ -224,20 +296,58 @@ func (m hubModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Preload failed — return to idle
 			m.busy = false
 			m.result = &cmdResultMsg{kind: "preload", success: false, detail: msg.err.Error()}
-			m.viewport.SetContent(m.renderPreview())
+			m.refreshPreview()
 			return m, nil
 		}
 		m.preloadedData = msg.value
 		m.quitting = true
 		return m, tea.Quit
 
+	case editorDoneMsg:
+		return m.finishEdit(msg.err), nil
+
+	case resultExpiredMsg:
+		if m.result == msg.result {
+			m.result = nil
+			m.refreshPreview()
+		}
+		return m, nil
+
+	case buildOutputMsg:
+		m.buildLog = append(m.buildLog, msg.line)
+		if len(m.buildLog) > maxBuildLogLines {
+			m.buildLog = m.buildLog[len(m.buildLog)-maxBuildLogLines:]
+		}
+		m.refreshPreview()
+		m.viewport.GotoBottom()
+		return m, waitForBuild(m.buildUpdates)
+
+	case featureCheckMsg:
+		return m.finishFeatureCheck(msg)
+
+	case spinner.TickMsg:
+		if !m.busy {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.indicator, cmd = m.indicator.update(msg)
+		m.refreshPreview()
+		return m, cmd
+
 	case cmdResultMsg:
+		if m.cancel != nil {
+			m.cancel()
+			m.cancel = nil
+		}
 		m.busy = false
+		m.buildLog = nil
+		m.buildUpdates = nil
 		m.result = &msg
 		if msg.kind == "build" && msg.success {
 			m.dirty = false
 		}
-		m.viewport.SetContent(m.renderPreview())
+		m.refreshPreview()
+		m.viewport.GotoTop()
 		return m, nil
 
 	case tea.WindowSizeMsg:
@@ -247,20 +357,69 @@ func (m hubModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 
 	case tea.KeyMsg:
-		// Ignore all input while a command is running.
+		// While a command is running, only cancelling it is possible.
 		if m.busy {
+			if key := msg.String(); (key == "x" || key == "ctrl+c") && m.cancel != nil {
+				m.cancel()
+				m.busyLabel = cancellingLabel
+				m.refreshPreview()
+			}
+			return m, nil
+		}
+
+		// Build anyway with unresolved features on y; any other key
+		// drops the build.
+		if m.unresolved != nil {
+			m.unresolved = nil
+			if msg.String() == "y" {
+				return m.runBuild()
+			}
+			m.refreshPreview()
 			return m, nil
 		}
 
 		// Dismiss result overlay on any key press.
 		if m.result != nil {
 			m.result = nil
-			m.viewport.SetContent(m.renderPreview())
+			m.refreshPreview()
 			return m, nil
 		}
 
+		if m.search.editing {
+			var cmd tea.Cmd
+			m.search, cmd = m.search.update(msg)
+			m.refreshPreview()
+			m.scrollToMatch()
+			return m, cmd
+		}
+
 		key := msg.String()
 
+		if key == "/" {
+			var cmd tea.Cmd
+			m.search, cmd = m.search.start()
+			m.refreshPreview()
+			return m, cmd
+		}
+
+		if m.search.active() {
+			switch key {
+			case "n", "N":
+				delta := 1
+				if key == "N" {
+					delta = -1
+				}
+				m.search = m.search.step(delta)
+				m.refreshPreview()
+				m.scrollToMatch()
+				return m, nil
+			case "esc":
+				m.search = newPreviewSearch()
+				m.refreshPreview()
+				return m, nil
+			}
+		}
+
 		if key == "q" || key == "ctrl+c" {
 			m.action = HubActionExit
 			m.quitting = true
@@ -275,6 +434,32 @@ func (m hubModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.dispatchAction(item.action)
 		}
 
+		if key == "<" || key == ">" {
+			delta := -menuRatioStep
+			if key == ">" {
+				delta = menuRatioStep
+			}
+			_ = nudgeMenuRatio(delta) // the new split still applies if saving fails
+			m.applyLayout()
+			return m, nil
+		}
+
+		if key == "y" {
+			return m.copyConfig()
+		}
+
+		if key == "z" {
+			return m.undo()
+		}
+
+		if key == "m" && len(devcontainer.Deprecations(m.config)) > 0 {
+			return m.migrate()
+		}
+
+		if key == "$" && len(m.config) > 0 {
+			return m.toggleSchema()
+		}
+
 		if action, ok := m.actions[key]; ok {
 			return m.dispatchAction(action)
 		}
@@ -285,15 +470,19 @@ func (m hubModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-// dispatchAction handles the selected action. Build and Open run async within
+// dispatchAction handles the selected action. Build, Up and Open run async within
 // the TUI. Actions with a Preload callback start loading before exiting.
 // All other actions exit immediately.
 func (m hubModel) dispatchAction(action HubAction) (tea.Model, tea.Cmd) {
 	switch action {
 	case HubActionBuild:
 		return m.startBuild()
+	case HubActionUp:
+		return m.startUp()
 	case HubActionOpen:
 		return m.startOpen()
+	case HubActionEdit:
+		return m.startEdit()
 	default:
 		m.action = action
 		// If a preload callback exists, run it before exiting.
@@ -301,18 +490,59 @@ func (m hubModel) dispatchAction(action HubAction) (tea.Model, tea.Cmd) {
 			preloadFn := m.callbacks.Preload
 			m.busy = true
 			m.busyLabel = "Loading..."
-			m.viewport.SetContent(m.renderPreview())
-			return m, func() tea.Msg {
+			tick := m.indicator.start()
+			m.refreshPreview()
+			return m, tea.Batch(tick, func() tea.Msg {
 				val, err := preloadFn(action)
 				return preloadDoneMsg{value: val, err: err}
-			}
+			})
 		}
 		m.quitting = true
 		return m, tea.Quit
 	}
 }
 
+// startBuild first checks that the features resolve, unless running dry,
+// and asks before building with ones that don't.
 func (m hubModel) startBuild() (tea.Model, tea.Cmd) {
+	if m.callbacks.CheckFeatures == nil || m.callbacks.DryRun {
+		return m.runBuild()
+	}
+	m.busy = true
+	m.busyLabel = "Checking features..."
+	m.result = nil
+	tick := m.indicator.start()
+	m.refreshPreview()
+	checkFn := m.callbacks.CheckFeatures
+	config := m.config
+	ctx := m.cancellable()
+	return m, tea.Batch(tick, func() tea.Msg {
+		unresolved := checkFn(ctx, config)
+		return featureCheckMsg{unresolved: unresolved, cancelled: ctx.Err() != nil}
+	})
+}
+
+// finishFeatureCheck builds right away if every feature resolved, otherwise
+// lists the ones that didn't and waits for y/n.
+func (m hubModel) finishFeatureCheck(msg featureCheckMsg) (tea.Model, tea.Cmd) {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+	m.busy = false
+	switch {
+	case msg.cancelled:
+		m.result = &cmdResultMsg{kind: "build", cancelled: true}
+	case len(msg.unresolved) == 0:
+		return m.runBuild()
+	default:
+		m.unresolved = msg.unresolved
+	}
+	m.refreshPreview()
+	return m, nil
+}
+
+func (m hubModel) runBuild() (tea.Model, tea.Cmd) {
 	m.busy = true
 	m.busyLabel = "Building devcontainer..."
 	noCache := m.dirty
@@ -320,29 +550,87 @@ func (m hubModel) startBuild() (tea.Model, tea.Cmd) {
 		m.busyLabel = "Rebuilding devcontainer (no cache)..."
 	}
 	m.result = nil
-	m.viewport.SetContent(m.renderPreview())
+	m.buildLog = nil
+	tick := m.indicator.start()
+	m.refreshPreview()
 	buildFn := m.callbacks.Build
-	return m, func() tea.Msg {
-		output, err := buildFn(noCache)
+	dryRun := m.callbacks.DryRun
+	ctx := m.cancellable()
+	updates := make(chan tea.Msg, 64)
+	m.buildUpdates = updates
+	go func() {
+		output, err := buildFn(ctx, noCache, func(line string) {
+			updates <- buildOutputMsg{line: line}
+		})
+		switch {
+		case ctx.Err() != nil:
+			updates <- cmdResultMsg{kind: "build", cancelled: true}
+		case err != nil:
+			updates <- cmdResultMsg{kind: "build", success: false, detail: filterBuildOutput(output, err)}
+		case dryRun:
+			updates <- cmdResultMsg{kind: "dry-run", success: true, detail: output}
+		default:
+			updates <- cmdResultMsg{kind: "build", success: true}
+		}
+	}()
+	return m, tea.Batch(tick, waitForBuild(updates))
+}
+
+// cancellable returns the context of a command started now, which x and
+// Ctrl+C cancel while the hub is busy.
+func (m *hubModel) cancellable() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	return ctx
+}
+
+// waitForBuild delivers the next output line or the result of a build.
+func waitForBuild(updates <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-updates
+	}
+}
+
+func (m hubModel) startUp() (tea.Model, tea.Cmd) {
+	m.busy = true
+	m.busyLabel = "Starting devcontainer..."
+	m.result = nil
+	tick := m.indicator.start()
+	m.refreshPreview()
+	upFn := m.callbacks.Up
+	ctx := m.cancellable()
+	return m, tea.Batch(tick, func() tea.Msg {
+		containerID, output, err := upFn(ctx)
+		if ctx.Err() != nil {
+			return cmdResultMsg{kind: "up", cancelled: true}
+		}
 		if err != nil {
 			return cmdResultMsg{
-				kind:    "build",
+				kind:    "up",
 				success: false,
 				detail:  filterBuildOutput(output, err),
 			}
 		}
-		return cmdResultMsg{kind: "build", success: true}
-	}
+		if m.callbacks.DryRun {
+			return cmdResultMsg{kind: "dry-run", success: true, detail: output}
+		}
+		return cmdResultMsg{kind: "up", success: true, detail: containerID}
+	})
 }
 
 func (m hubModel) startOpen() (tea.Model, tea.Cmd) {
 	m.busy = true
 	m.busyLabel = "Opening in VS Code..."
 	m.result = nil
-	m.viewport.SetContent(m.renderPreview())
+	tick := m.indicator.start()
+	m.refreshPreview()
 	openFn := m.callbacks.Open
-	return m, func() tea.Msg {
-		output, err := openFn()
+	ctx := m.cancellable()
+	return m, tea.Batch(tick, func() tea.Msg {
+		output, err := openFn(ctx)
+		if ctx.Err() != nil {
+			return cmdResultMsg{kind: "open", cancelled: true}
+		}
 		if err != nil {
 			return cmdResultMsg{
 				kind:    "open",
@@ -350,8 +638,169 @@ func (m hubModel) startOpen() (tea.Model, tea.Cmd) {
 				detail:  fmt.Sprintf("%s\n%v", strings.TrimSpace(output), err),
 			}
 		}
+		if m.callbacks.DryRun {
+			return cmdResultMsg{kind: "dry-run", success: true, detail: output}
+		}
 		return cmdResultMsg{kind: "open", success: true}
+	})
+}
+
+// startEdit hands the terminal to the user's editor. The hub resumes and
+// reloads the config when the editor exits.
+func (m hubModel) startEdit() (tea.Model, tea.Cmd) {
+	if m.callbacks.Edit == nil {
+		return m, nil
+	}
+	cmd, err := m.callbacks.Edit()
+	if err != nil {
+		m.result = &cmdResultMsg{kind: "edit", success: false, detail: err.Error()}
+		m.refreshPreview()
+		return m, nil
+	}
+	m.result = nil
+	return m, tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editorDoneMsg{err: err}
+	})
+}
+
+// finishEdit reloads the config after the editor exits. If the file no longer
+// parses, the previous config is kept and the error is shown.
+func (m hubModel) finishEdit(editErr error) hubModel {
+	switch {
+	case editErr != nil:
+		m.result = &cmdResultMsg{kind: "edit", success: false, detail: fmt.Sprintf("editor exited: %v", editErr)}
+	case m.callbacks.Reload != nil:
+		config, err := m.callbacks.Reload()
+		if err != nil {
+			m.result = &cmdResultMsg{kind: "edit", success: false, detail: err.Error()}
+			break
+		}
+		if !reflect.DeepEqual(config, m.config) {
+			m.dirty = true
+		}
+		m.config = config
+	}
+	m.refreshPreview()
+	return m
+}
+
+// clipboardWrite is swapped out in tests.
+var clipboardWrite = clipboard.WriteAll
+
+// copyResultTTL is how long brief confirmations such as "Copied to clipboard"
+// stay up.
+const copyResultTTL = 2 * time.Second
+
+// copyConfig copies the previewed JSON to the system clipboard. Success is
+// confirmed briefly; failures (e.g. no clipboard on a headless system) stay
+// until dismissed.
+func (m hubModel) copyConfig() (tea.Model, tea.Cmd) {
+	if len(m.config) == 0 {
+		m.result = &cmdResultMsg{kind: "copy", success: false, detail: "There is no devcontainer.json to copy yet."}
+		m.refreshPreview()
+		return m, nil
+	}
+	data, err := json.MarshalIndent(m.config, "", "  ")
+	if err == nil {
+		err = clipboardWrite(string(data))
+	}
+	if err != nil {
+		detail := err.Error()
+		if clipboard.Unsupported {
+			detail = "No clipboard is available. On Linux, install xclip, xsel or wl-clipboard."
+		}
+		m.result = &cmdResultMsg{kind: "copy", success: false, detail: detail}
+		m.refreshPreview()
+		return m, nil
+	}
+
+	result := &cmdResultMsg{kind: "copy", success: true}
+	m.result = result
+	m.refreshPreview()
+	return m, tick(copyResultTTL, func(time.Time) tea.Msg {
+		return resultExpiredMsg{result: result}
+	})
+}
+
+// undo reverts the last change made in this session. Success is confirmed
+// briefly like a copy; having nothing to undo stays until dismissed.
+func (m hubModel) undo() (tea.Model, tea.Cmd) {
+	if m.callbacks.Undo == nil {
+		return m, nil
+	}
+	config, err := m.callbacks.Undo()
+	if err != nil {
+		m.result = &cmdResultMsg{kind: "undo", success: false, detail: err.Error()}
+		m.refreshPreview()
+		return m, nil
+	}
+	m.config = config
+	m.dirty = true
+
+	result := &cmdResultMsg{kind: "undo", success: true}
+	m.result = result
+	m.refreshPreview()
+	return m, tick(copyResultTTL, func(time.Time) tea.Msg {
+		return resultExpiredMsg{result: result}
+	})
+}
+
+// migrate moves the deprecated keys shown in the preview to their current
+// place, with a brief confirmation like copyConfig.
+func (m hubModel) migrate() (tea.Model, tea.Cmd) {
+	if m.callbacks.Migrate == nil {
+		return m, nil
+	}
+	config, err := m.callbacks.Migrate()
+	if err != nil {
+		m.result = &cmdResultMsg{kind: "migrate", success: false, detail: err.Error()}
+		m.refreshPreview()
+		return m, nil
 	}
+	m.config = config
+	m.dirty = true
+
+	result := &cmdResultMsg{kind: "migrate", success: true}
+	m.result = result
+	m.refreshPreview()
+	return m, tick(copyResultTTL, func(time.Time) tea.Msg {
+		return resultExpiredMsg{result: result}
+	})
+}
+
+// toggleSchema adds $schema to the config or removes it, with a brief
+// confirmation like migrate.
+func (m hubModel) toggleSchema() (tea.Model, tea.Cmd) {
+	if m.callbacks.ToggleSchema == nil {
+		return m, nil
+	}
+	config, err := m.callbacks.ToggleSchema()
+	if err != nil {
+		m.result = &cmdResultMsg{kind: "schema", success: false, detail: err.Error()}
+		m.refreshPreview()
+		return m, nil
+	}
+	m.config = config
+	m.dirty = true
+
+	detail := "Removed $schema"
+	if _, ok := config[devcontainer.SchemaKey]; ok {
+		detail = "Added $schema for editor completion"
+	}
+	result := &cmdResultMsg{kind: "schema", success: true, detail: detail}
+	m.result = result
+	m.refreshPreview()
+	return m, tick(copyResultTTL, func(time.Time) tea.Msg {
+		return resultExpiredMsg{result: result}
+	})
+}
+
+// shortContainerID abbreviates a full container ID the way docker ps does.
+func shortContainerID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
 }
 
 // filterBuildOutput extracts relevant error lines from devcontainer build output.
@@ -362,9 +811,18 @@ func filterBuildOutput(output string, err error) string {
 		if line == "" {
 			continue
 		}
-		if strings.Contains(line, "ERROR:") ||
-			strings.Contains(line, "did not complete successfully") ||
-			strings.Contains(line, "exit code:") {
+		// JSON log lines carry their level; raw output inside them still
+		// needs the plain-text check.
+		if lines, isError, ok := parseJSONLogLine(line); ok {
+			for _, l := range lines {
+				if isError || isBuildErrorLine(l) {
+					b.WriteString(l)
+					b.WriteString("\n")
+				}
+			}
+			continue
+		}
+		if isBuildErrorLine(line) {
 			b.WriteString(line)
 			b.WriteString("\n")
 		}
@@ -374,7 +832,7 @@ func filterBuildOutput(output string, err error) string {
 }
 
 func (m *hubModel) applyLayout() {
-	menuW := max(m.width/3, 30)
+	menuW := max(splitWidth(m.width), 30)
 	previewW := m.width - menuW
 
 	m.list.SetWidth(menuW)
@@ -382,21 +840,48 @@ func (m *hubModel) applyLayout() {
 
 	m.viewport.Width = previewW - 4
 	m.viewport.Height = m.height - 4
-	m.viewport.SetContent(m.renderPreview())
+	m.refreshPreview()
 }
 
-var (
-	previewSuccessStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("10")).PaddingLeft(1).PaddingTop(1)
-	previewWarnStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")).PaddingLeft(1).PaddingTop(1)
-	previewBusyStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170")).PaddingLeft(1).PaddingTop(1)
-	previewHintStyle    = lipgloss.NewStyle().Faint(true).PaddingLeft(1)
-	previewDetailStyle  = lipgloss.NewStyle().PaddingLeft(1).Foreground(lipgloss.Color("9"))
-)
+// refreshPreview renders the preview into the viewport, marking the
+// matches of an active search.
+func (m *hubModel) refreshPreview() {
+	content := m.renderPreview()
+	if m.search.active() && !m.busy && m.result == nil && m.unresolved == nil {
+		content, m.search = m.search.highlight(content)
+	}
+	m.viewport.SetContent(content)
+}
+
+// scrollToMatch scrolls the current search match into the upper third of
+// the preview.
+func (m *hubModel) scrollToMatch() {
+	if line := m.search.line(); line >= 0 {
+		m.viewport.SetYOffset(line - m.viewport.Height/3)
+	}
+}
 
 func (m hubModel) renderPreview() string {
-	// Busy state: show spinner-like message.
+	// Busy state: the spinner, label and elapsed time.
 	if m.busy {
-		return previewBusyStyle.Render("⏳ " + m.busyLabel)
+		status := m.indicator.view(m.busyLabel)
+		if m.cancel != nil && m.busyLabel != cancellingLabel {
+			status += previewHintStyle.Render("  (x to cancel)")
+		}
+		if len(m.buildLog) == 0 {
+			return status
+		}
+		// Live build output, tailed by the viewport.
+		line := previewHintStyle.MaxWidth(m.viewport.Width)
+		sections := []string{status, ""}
+		for _, l := range m.buildLog {
+			sections = append(sections, line.Render(l))
+		}
+		return strings.Join(sections, "\n")
+	}
+
+	if m.unresolved != nil {
+		return m.renderUnresolved()
 	}
 
 	// Command result overlay.
@@ -410,21 +895,21 @@ func (m hubModel) renderPreview() string {
 	if !m.cli.Installed {
 		warn := lipgloss.NewStyle().
 			Bold(true).
-			Foreground(lipgloss.Color("1")).
+			Foreground(theme.Error).
 			PaddingLeft(1).PaddingTop(1)
 		hint := lipgloss.NewStyle().
 			Faint(true).
 			PaddingLeft(1)
 		sections = append(sections,
 			warn.Render("⚠ devcontainer CLI not installed"),
-			hint.Render("Build and Open require the devcontainer CLI."),
+			hint.Render("Build, Up and Open require the devcontainer CLI."),
 			hint.Render("Install via VS Code: Cmd+Shift+P →"),
 			hint.Render("  \"Dev Containers: Install devcontainer CLI\""),
 			"",
 		)
 	} else if !m.cli.HasOpen {
 		hint := lipgloss.NewStyle().
-			Foreground(lipgloss.Color("11")).
+			Foreground(theme.Warning).
 			PaddingLeft(1).PaddingTop(1)
 		detail := lipgloss.NewStyle().
 			Faint(true).
@@ -438,32 +923,98 @@ func (m hubModel) renderPreview() string {
 		)
 	}
 
+	if m.cli.Version != "" {
+		sections = append(sections, previewHintStyle.Render("devcontainer CLI v"+m.cli.Version))
+	}
+
 	if len(m.config) == 0 {
 		sections = append(sections,
 			lipgloss.NewStyle().Faint(true).PaddingLeft(1).PaddingTop(1).
 				Render("No devcontainer.json yet\n\nSelect Set Template to get started"),
 		)
 	} else {
+		issues := devcontainer.Validate(m.config)
+		if len(issues) > 0 {
+			sections = append(sections, previewWarnStyle.Render(fmt.Sprintf("⚠ %d schema issue(s)", len(issues))))
+			for _, issue := range issues {
+				sections = append(sections, previewHintStyle.Render(issue.Error()))
+			}
+			sections = append(sections, "")
+		}
+
+		if deprecated := devcontainer.Deprecations(m.config); len(deprecated) > 0 && m.callbacks.Migrate != nil {
+			sections = append(sections, previewWarnStyle.Render(fmt.Sprintf("⚠ %d deprecated key(s), press m to migrate", len(deprecated))))
+			for _, d := range deprecated {
+				move := d.Key + " → " + d.Replacement
+				if d.Replacement == "" {
+					move = d.Key + " is no longer used and will be removed"
+				}
+				sections = append(sections, previewHintStyle.Render(move))
+			}
+			sections = append(sections, "")
+		}
+
 		data, err := json.MarshalIndent(m.config, "", "  ")
 		if err != nil {
 			sections = append(sections, fmt.Sprintf("Error: %v", err))
 		} else {
-			sections = append(sections, colorizeJSON(string(data)))
+			sections = append(sections, colorizeJSONMarked(string(data), issueKeyPaths(issues), featureNotes(m.config)))
 		}
 	}
 
 	return strings.Join(sections, "\n")
 }
 
+// renderUnresolved asks whether to build with features that don't resolve.
+func (m hubModel) renderUnresolved() string {
+	sections := []string{
+		previewWarnStyle.Render(fmt.Sprintf("⚠ %d feature(s) not found in their registry", len(m.unresolved))),
+		"",
+	}
+	for _, ref := range m.unresolved {
+		sections = append(sections, previewDetailStyle.Render(ref))
+	}
+	sections = append(sections,
+		"",
+		previewHintStyle.Render("The version may have been deleted, or the ref has a typo."),
+		previewHintStyle.Render("The build will likely fail. Build anyway? (y/n)"),
+	)
+	return strings.Join(sections, "\n")
+}
+
 func (m hubModel) renderResult() string {
 	var sections []string
 
-	if m.result.success {
+	if m.result.cancelled {
+		sections = append(sections, previewWarnStyle.Render("⚠ "+cancelledLabels[m.result.kind]))
+	} else if m.result.success {
 		switch m.result.kind {
 		case "build":
 			sections = append(sections, previewSuccessStyle.Render("✓ Devcontainer built successfully"))
+		case "up":
+			sections = append(sections,
+				previewSuccessStyle.Render("✓ Devcontainer running"),
+				"",
+				previewHintStyle.Render("Container: "+m.result.detail),
+				previewHintStyle.Render("Attach with: docker exec -it "+shortContainerID(m.result.detail)+" bash"),
+			)
 		case "open":
 			sections = append(sections, previewSuccessStyle.Render("✓ VS Code opened"))
+		case "copy":
+			sections = append(sections, previewSuccessStyle.Render("✓ Copied to clipboard"))
+		case "undo":
+			sections = append(sections, previewSuccessStyle.Render("✓ Undid the last change"))
+		case "migrate":
+			sections = append(sections, previewSuccessStyle.Render("✓ Migrated deprecated keys"))
+		case "schema":
+			sections = append(sections, previewSuccessStyle.Render("✓ "+m.result.detail))
+		case "dry-run":
+			sections = append(sections,
+				previewSuccessStyle.Render("Dry run — nothing was executed"),
+				"",
+				previewHintStyle.Render("Command:"),
+				lipgloss.NewStyle().PaddingLeft(1).Render(m.result.detail),
+			)
 		}
 	} else {
 		switch m.result.kind {
@@ -476,12 +1027,50 @@ func (m hubModel) renderResult() string {
 				previewHintStyle.Render("This usually means a feature's install script failed."),
 				previewHintStyle.Render("Try removing the problematic feature and rebuilding."),
 			)
+		case "up":
+			sections = append(sections,
+				previewWarnStyle.Render("⚠ Failed to start devcontainer"),
+				"",
+				previewDetailStyle.Render(m.result.detail),
+			)
 		case "open":
 			sections = append(sections,
 				previewWarnStyle.Render("⚠ Failed to open VS Code"),
 				"",
 				previewDetailStyle.Render(m.result.detail),
 			)
+		case "edit":
+			sections = append(sections,
+				previewWarnStyle.Render("⚠ devcontainer.json could not be reloaded"),
+				"",
+				previewDetailStyle.Render(m.result.detail),
+				"",
+				previewHintStyle.Render("The previous config is still shown. Press E to fix the file."),
+			)
+		case "copy":
+			sections = append(sections,
+				previewWarnStyle.Render("⚠ Could not copy to clipboard"),
+				"",
+				previewDetailStyle.Render(m.result.detail),
+			)
+		case "undo":
+			sections = append(sections,
+				previewWarnStyle.Render("⚠ Could not undo"),
+				"",
+				previewDetailStyle.Render(m.result.detail),
+			)
+		case "migrate":
+			sections = append(sections,
+				previewWarnStyle.Render("⚠ Could not migrate deprecated keys"),
+				"",
+				previewDetailStyle.Render(m.result.detail),
+			)
+		case "schema":
+			sections = append(sections,
+				previewWarnStyle.Render("⚠ Could not change $schema"),
+				"",
+				previewDetailStyle.Render(m.result.detail),
+			)
 		}
 	}
 
@@ -493,16 +1082,19 @@ func (m hubModel) View() string {
 	if m.quitting {
 		return ""
 	}
-	return renderHubLayout(m.list, m.viewport.View(), "devcontainer.json", m.width, m.height)
+	title := "devcontainer.json"
+	if m.search.active() {
+		title += "  " + m.search.title()
+	}
+	return renderHubLayout(m.list, m.viewport.View(), title, m.width, m.height)
 }
 
 // ShowHub displays the hub dashboard and returns the selected action.
-// Build and Open are handled within the TUI and never returned.
+// Build, Up, Open and Edit are handled within the TUI and never returned.
 // Returns the selected action, the updated dirty flag, and any preloaded data.
 func ShowHub(projectName string, config map[string]any, cli template.CLIInfo, dirty bool, cb HubCallbacks) (HubAction, bool, any, error) {
 	m := newHubModel(projectName, config, cli, dirty, cb)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	final, err := p.Run()
+	final, err := runProgram(m)
 	if err != nil {
 		return HubActionExit, dirty, nil, fmt.Errorf("running hub: %w", err)
 	}
@@ -512,16 +1104,17 @@ func ShowHub(projectName string, config map[string]any, cli template.CLIInfo, di
 
 // --- JSON colorization ---
 
-var (
-	jsonKeyStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("12"))
-	jsonStrStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("10"))
-	jsonNumStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("13"))
-	jsonBoolStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("11"))
-	jsonNullStyle = lipgloss.NewStyle().Faint(true)
-)
-
 func colorizeJSON(s string) string {
+	return colorizeJSONMarked(s, nil, nil)
+}
+
+// colorizeJSONMarked colorizes 2-space indented JSON and renders the keys
+// whose dotted path is in marked in red. Keys whose path is in notes get the
+// note as a trailing comment. String values of secret-looking keys
+// (isSecretKey) are masked.
+func colorizeJSONMarked(s string, marked map[string]bool, notes map[string]string) string {
 	var b strings.Builder
+	var path []string
 	for i, line := range strings.Split(s, "\n") {
 		if i > 0 {
 			b.WriteString("\n")
@@ -531,14 +1124,31 @@ func colorizeJSON(s string) string {
 		indent := line[:len(line)-len(trimmed)]
 
 		if idx := strings.Index(trimmed, `":`); idx >= 0 {
-			parts := strings.SplitN(trimmed, ":", 2)
-			if len(parts) == 2 {
-				b.WriteString(indent)
-				b.WriteString(jsonKeyStyle.Render(strings.TrimSpace(parts[0])))
-				b.WriteString(": ")
-				b.WriteString(colorizeValue(strings.TrimSpace(parts[1])))
-				continue
+			keyToken := trimmed[:idx+1]
+			depth := max(len(indent)/2, 1)
+			for len(path) < depth-1 {
+				path = append(path, "")
 			}
+			var key string
+			json.Unmarshal([]byte(keyToken), &key) //nolint:errcheck
+			path = append(path[:depth-1], key)
+
+			style := jsonKeyStyle
+			if marked[joinKeyPath(path)] {
+				style = jsonBadStyle
+			}
+			b.WriteString(indent)
+			b.WriteString(style.Render(keyToken))
+			b.WriteString(": ")
+			val := strings.TrimSpace(trimmed[idx+2:])
+			if isSecretKey(key) {
+				val = maskSecret(val)
+			}
+			b.WriteString(colorizeValue(val))
+			if note := notes[joinKeyPath(path)]; note != "" {
+				b.WriteString(jsonNoteStyle.Render("  // " + note))
+			}
+			continue
 		}
 
 		b.WriteString(indent)
@@ -547,6 +1157,56 @@ func colorizeJSON(s string) string {
 	return b.String()
 }
 
+// featureNotes returns the note of each configured feature by the dotted
+// key path of its entry, for colorizeJSONMarked.
+func featureNotes(config map[string]any) map[string]string {
+	features, _ := config["features"].(map[string]any)
+	notes := make(map[string]string)
+	for ref := range features {
+		if note := feature.Note(config, ref); note != "" {
+			notes["features."+ref] = note
+		}
+	}
+	return notes
+}
+
+// joinKeyPath joins the non-empty segments of a key path with dots.
+// Empty segments stand for array elements.
+func joinKeyPath(path []string) string {
+	var parts []string
+	for _, p := range path {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// issueKeyPaths converts validation error paths into the dotted key paths
+// used by colorizeJSONMarked, dropping array indices.
+func issueKeyPaths(issues []devcontainer.ValidationError) map[string]bool {
+	if len(issues) == 0 {
+		return nil
+	}
+	paths := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		p := issue.Path
+		for {
+			open := strings.Index(p, "[")
+			if open < 0 {
+				break
+			}
+			end := strings.Index(p[open:], "]")
+			if end < 0 {
+				break
+			}
+			p = p[:open] + p[open+end+1:]
+		}
+		paths[p] = true
+	}
+	return paths
+}
+
 func colorizeValue(val string) string {
 	bare := strings.TrimRight(val, ",")
 	switch {
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/mochlast/devcontainer-companion/internal/registry"
@@ -51,6 +52,7 @@ type hubFormModel struct {
 	menuList   list.Model
 	phase      formPhase
 	loadLabel  string
+	indicator  busyIndicator
 	startLoad  tea.Cmd // runs LoadFn, set during construction
 	form       *huh.Form
 	formTitle  string
@@ -66,7 +68,8 @@ type hubFormModel struct {
 }
 
 func (m hubFormModel) Init() tea.Cmd {
-	return m.startLoad
+	// The indicator started timing when the model was built.
+	return tea.Batch(m.indicator.spinner.Tick, m.startLoad)
 }
 
 func (m hubFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -82,13 +85,13 @@ func (m hubFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		for i, f := range fields {
 			groups[i] = huh.NewGroup(f)
 		}
-		m.form = huh.NewForm(groups...)
+		m.form = NewForm(groups...)
 		m.formTitle = msg.title
 		m.stringVals = stringVals
 		m.boolVals = boolVals
 		m.phase = formPhaseForm
 		// Size the form to the preview panel
-		menuW := max(m.width/3, 30)
+		menuW := max(splitWidth(m.width), 30)
 		previewW := m.width - menuW - 6
 		initCmd := m.form.Init()
 		sizeMsg := tea.WindowSizeMsg{Width: previewW, Height: m.height - 6}
@@ -99,10 +102,18 @@ func (m hubFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case formPostDoneMsg:
 		return m, tea.Quit
 
+	case spinner.TickMsg:
+		if m.phase == formPhaseForm {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.indicator, cmd = m.indicator.update(msg)
+		return m, cmd
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
-		menuW := max(m.width/3, 30)
+		menuW := max(splitWidth(m.width), 30)
 		m.menuList.SetWidth(menuW)
 		m.menuList.SetHeight(m.height - 2)
 		if m.phase == formPhaseForm && m.form != nil {
@@ -138,13 +149,13 @@ func (m hubFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Run post-action if configured
 			if m.postFn != nil {
 				m.phase = formPhasePost
-				m.menuList.SetWidth(max(m.width/3, 30))
+				m.menuList.SetWidth(max(splitWidth(m.width), 30))
 				postFn := m.postFn
 				results := m.results
-				return m, func() tea.Msg {
+				return m, tea.Batch(m.indicator.start(), func() tea.Msg {
 					err := postFn(results)
 					return formPostDoneMsg{err: err}
-				}
+				})
 			}
 			return m, tea.Quit
 		}
@@ -164,7 +175,7 @@ func (m hubFormModel) View() string {
 
 	switch m.phase {
 	case formPhaseLoading:
-		content = previewBusyStyle.Render("⏳ " + m.loadLabel)
+		content = m.indicator.view(m.loadLabel)
 		title = "devcontainer.json"
 	case formPhaseForm:
 		if m.form != nil {
@@ -172,7 +183,7 @@ func (m hubFormModel) View() string {
 		}
 		title = m.formTitle
 	case formPhasePost:
-		content = previewBusyStyle.Render("⏳ " + m.postLabel)
+		content = m.indicator.view(m.postLabel)
 		title = "devcontainer.json"
 	}
 
@@ -190,6 +201,7 @@ func ShowHubForm(ctx HubContext, cfg FormConfig) (map[string]any, error) {
 		menuList:  l,
 		phase:     formPhaseLoading,
 		loadLabel: cfg.LoadLabel,
+		indicator: newBusyIndicator(),
 		postLabel: cfg.PostLabel,
 		postFn:    cfg.PostFn,
 		startLoad: func() tea.Msg {
@@ -198,8 +210,7 @@ func ShowHubForm(ctx HubContext, cfg FormConfig) (map[string]any, error) {
 		},
 	}
 
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	final, err := p.Run()
+	final, err := runProgram(m)
 	if err != nil {
 		return nil, fmt.Errorf("running form: %w", err)
 	}
@@ -246,6 +257,7 @@ func buildOptionFields(options map[string]registry.OptionDefinition) (map[string
 			boolVals[key] = &val
 			fields = append(fields, huh.NewConfirm().
 				Title(fieldTitle).
+				Description(optionHint(opt)).
 				Value(boolVals[key]))
 
 		default:
@@ -258,14 +270,10 @@ func buildOptionFields(options map[string]registry.OptionDefinition) (map[string
 				}
 				stringVals[key] = &val
 
-				enumOpts := make([]huh.Option[string], len(opt.Enum))
-				for i, e := range opt.Enum {
-					enumOpts[i] = huh.NewOption(e, e)
-				}
-
 				fields = append(fields, huh.NewSelect[string]().
 					Title(fieldTitle).
-					Options(enumOpts...).
+					Description(optionHint(opt)).
+					Options(enumOptions(opt, defaultStr)...).
 					Value(stringVals[key]))
 
 			} else {
@@ -274,10 +282,15 @@ func buildOptionFields(options map[string]registry.OptionDefinition) (map[string
 
 				input := huh.NewInput().
 					Title(fieldTitle).
+					Description(optionHint(opt)).
 					Value(stringVals[key])
 
+				// The default is prefilled; the placeholder brings it back to
+				// mind once the field is cleared.
 				if len(opt.Proposals) > 0 {
 					input = input.Placeholder(strings.Join(opt.Proposals, ", "))
+				} else if defaultStr != "" {
+					input = input.Placeholder(defaultStr)
 				}
 
 				fields = append(fields, input)
@@ -0,0 +1,349 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/template"
+)
+
+func TestHubFinishEdit(t *testing.T) {
+	old := map[string]any{"name": "old"}
+	var reloaded map[string]any
+	var reloadErr error
+	cb := HubCallbacks{
+		Reload: func() (map[string]any, error) { return reloaded, reloadErr },
+	}
+
+	// A parse error keeps the previous config and shows the error.
+	reloadErr = errors.New("parsing devcontainer.json: bad")
+	m := newHubModel("proj", old, template.CLIInfo{}, false, cb).finishEdit(nil)
+	if m.config["name"] != "old" {
+		t.Errorf("config replaced on parse error: %v", m.config)
+	}
+	if m.result == nil || m.result.kind != "edit" || m.result.success {
+		t.Errorf("result = %+v, want failed edit", m.result)
+	}
+
+	// A successful reload replaces the config and marks it dirty.
+	reloaded, reloadErr = map[string]any{"name": "new"}, nil
+	m = newHubModel("proj", old, template.CLIInfo{}, false, cb).finishEdit(nil)
+	if m.config["name"] != "new" || !m.dirty || m.result != nil {
+		t.Errorf("after reload: config=%v dirty=%v result=%+v", m.config, m.dirty, m.result)
+	}
+}
+
+func TestHubCopyConfig(t *testing.T) {
+	var copied string
+	var copyErr error
+	clipboardWrite = func(s string) error { copied = s; return copyErr }
+	t.Cleanup(func() { clipboardWrite = clipboard.WriteAll })
+
+	config := map[string]any{"name": "proj", "image": "ubuntu"}
+	model, cmd := newHubModel("proj", config, template.CLIInfo{}, false, HubCallbacks{}).copyConfig()
+	m := model.(hubModel)
+	want := "{\n  \"image\": \"ubuntu\",\n  \"name\": \"proj\"\n}"
+	if copied != want {
+		t.Errorf("copied %q, want %q", copied, want)
+	}
+	if m.result == nil || m.result.kind != "copy" || !m.result.success || cmd == nil {
+		t.Errorf("result = %+v, cmd = %v; want transient copy success", m.result, cmd)
+	}
+
+	// The confirmation expires unless another result replaced it.
+	expired, _ := m.Update(resultExpiredMsg{result: m.result})
+	if expired.(hubModel).result != nil {
+		t.Error("copy confirmation not dismissed after expiry")
+	}
+
+	copyErr = errors.New("exec: \"xclip\": executable file not found in $PATH")
+	model, _ = newHubModel("proj", config, template.CLIInfo{}, false, HubCallbacks{}).copyConfig()
+	if r := model.(hubModel).result; r == nil || r.success {
+		t.Errorf("result = %+v, want copy failure", r)
+	}
+}
+
+func TestHubUndo(t *testing.T) {
+	var undoErr error
+	cb := HubCallbacks{
+		Undo: func() (map[string]any, error) { return map[string]any{"name": "before"}, undoErr },
+	}
+
+	model, cmd := newHubModel("proj", map[string]any{"name": "after"}, template.CLIInfo{}, false, cb).undo()
+	m := model.(hubModel)
+	if m.config["name"] != "before" || !m.dirty {
+		t.Errorf("after undo: config=%v dirty=%v", m.config, m.dirty)
+	}
+	if m.result == nil || m.result.kind != "undo" || !m.result.success || cmd == nil {
+		t.Errorf("result = %+v, cmd = %v; want transient undo success", m.result, cmd)
+	}
+
+	undoErr = errors.New("nothing to undo in this session")
+	model, _ = newHubModel("proj", map[string]any{"name": "after"}, template.CLIInfo{}, false, cb).undo()
+	m = model.(hubModel)
+	if m.config["name"] != "after" || m.dirty || m.result == nil || m.result.success {
+		t.Errorf("failed undo: config=%v dirty=%v result=%+v", m.config, m.dirty, m.result)
+	}
+}
+
+func TestHubMigrate(t *testing.T) {
+	legacy := map[string]any{"name": "proj", "extensions": []any{"golang.go"}}
+	migrated := map[string]any{"name": "proj", "customizations": map[string]any{
+		"vscode": map[string]any{"extensions": []any{"golang.go"}},
+	}}
+	cb := HubCallbacks{
+		Migrate: func() (map[string]any, error) { return migrated, nil },
+	}
+
+	m := newHubModel("proj", legacy, template.CLIInfo{}, false, cb)
+	if preview := m.renderPreview(); !strings.Contains(preview, "press m to migrate") ||
+		!strings.Contains(preview, "extensions → customizations.vscode.extensions") {
+		t.Errorf("preview lacks the migration offer:\n%s", preview)
+	}
+
+	model, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")})
+	m = model.(hubModel)
+	if !reflect.DeepEqual(m.config, migrated) || !m.dirty {
+		t.Errorf("after m: config=%v dirty=%v", m.config, m.dirty)
+	}
+	if m.result == nil || m.result.kind != "migrate" || !m.result.success || cmd == nil {
+		t.Errorf("result = %+v, cmd = %v; want transient migrate success", m.result, cmd)
+	}
+
+	// Without deprecated keys, m does nothing.
+	m.result = nil
+	if model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("m")}); model.(hubModel).result != nil {
+		t.Error("m migrated a config without deprecated keys")
+	}
+}
+
+func TestHubToggleSchema(t *testing.T) {
+	config := map[string]any{"name": "proj"}
+	cb := HubCallbacks{
+		ToggleSchema: func() (map[string]any, error) {
+			devcontainer.ToggleSchema(config)
+			return config, nil
+		},
+	}
+	m := newHubModel("proj", config, template.CLIInfo{}, false, cb)
+
+	model, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("$")})
+	m = model.(hubModel)
+	if m.config[devcontainer.SchemaKey] != devcontainer.SchemaURL || !m.dirty || !strings.Contains(m.renderPreview(), "Added $schema") {
+		t.Fatalf("after $: config=%v\n%s", m.config, m.renderPreview())
+	}
+	m.result = nil
+	model, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("$")})
+	if m = model.(hubModel); !strings.Contains(m.renderPreview(), "Removed $schema") {
+		t.Errorf("second $ didn't remove $schema:\n%s", m.renderPreview())
+	}
+}
+
+// nextMsg runs cmd and returns its message, skipping the busy spinner's
+// tick when cmd is batched with it.
+func nextMsg(cmd tea.Cmd) tea.Msg {
+	msg := cmd()
+	batch, ok := msg.(tea.BatchMsg)
+	if !ok {
+		return msg
+	}
+	for _, c := range batch {
+		if msg := c(); msg != nil {
+			if _, tick := msg.(spinner.TickMsg); !tick {
+				return msg
+			}
+		}
+	}
+	return nil
+}
+
+func TestHubBuildStreamsOutput(t *testing.T) {
+	cb := HubCallbacks{
+		Build: func(_ context.Context, noCache bool, onLine func(string)) (string, error) {
+			onLine("#1 pulling image")
+			onLine("ERROR: failed to solve")
+			return "#1 pulling image\nERROR: failed to solve\n", errors.New("exit status 1")
+		},
+	}
+	model, cmd := newHubModel("proj", nil, template.CLIInfo{}, false, cb).startBuild()
+	for i := 0; i < 2; i++ {
+		model, cmd = model.Update(nextMsg(cmd))
+		if m := model.(hubModel); !m.busy || len(m.buildLog) != i+1 {
+			t.Fatalf("after line %d: busy=%v log=%q", i+1, m.busy, m.buildLog)
+		}
+	}
+	if got := model.(hubModel).renderPreview(); !strings.Contains(got, "#1 pulling image") {
+		t.Errorf("busy preview does not show the build output:\n%s", got)
+	}
+
+	model, _ = model.Update(nextMsg(cmd))
+	m := model.(hubModel)
+	if m.busy || m.result == nil || m.result.success || !strings.Contains(m.result.detail, "ERROR: failed to solve") {
+		t.Errorf("after build: busy=%v result=%+v", m.busy, m.result)
+	}
+	if m.buildLog != nil {
+		t.Errorf("build log kept after the result: %q", m.buildLog)
+	}
+}
+
+func TestHubCancelBuild(t *testing.T) {
+	cb := HubCallbacks{
+		Build: func(ctx context.Context, noCache bool, onLine func(string)) (string, error) {
+			<-ctx.Done()
+			return "", errors.New("signal: interrupt")
+		},
+	}
+	model, cmd := newHubModel("proj", nil, template.CLIInfo{}, false, cb).startBuild()
+	if got := model.(hubModel).renderPreview(); !strings.Contains(got, "x to cancel") {
+		t.Errorf("busy preview lacks the cancel hint:\n%s", got)
+	}
+
+	// Other keys are still ignored while busy.
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+	if m := model.(hubModel); !m.busy || m.busyLabel == cancellingLabel {
+		t.Fatalf("q while busy: busy=%v label=%q", m.busy, m.busyLabel)
+	}
+
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	if m := model.(hubModel); m.busyLabel != cancellingLabel {
+		t.Errorf("busy label after x = %q", m.busyLabel)
+	}
+	model, _ = model.Update(nextMsg(cmd))
+	m := model.(hubModel)
+	if m.busy || m.result == nil || !m.result.cancelled || m.cancel != nil {
+		t.Fatalf("after cancel: busy=%v result=%+v", m.busy, m.result)
+	}
+	if got := m.renderPreview(); !strings.Contains(got, "Build cancelled") {
+		t.Errorf("preview after cancel:\n%s", got)
+	}
+}
+
+func TestHubBuildChecksFeatures(t *testing.T) {
+	builds := 0
+	cb := HubCallbacks{
+		CheckFeatures: func(ctx context.Context, config map[string]any) []string {
+			return []string{"ghcr.io/devcontainers/features/nod:1"}
+		},
+		Build: func(ctx context.Context, noCache bool, onLine func(string)) (string, error) {
+			builds++
+			return "", nil
+		},
+	}
+	config := map[string]any{"features": map[string]any{"ghcr.io/devcontainers/features/nod:1": map[string]any{}}}
+
+	// Any key but y drops the build.
+	model, cmd := newHubModel("proj", config, template.CLIInfo{}, false, cb).startBuild()
+	model, _ = model.Update(nextMsg(cmd))
+	m := model.(hubModel)
+	if m.busy || !strings.Contains(m.renderPreview(), "features/nod:1") {
+		t.Fatalf("unresolved feature not shown: busy=%v\n%s", m.busy, m.renderPreview())
+	}
+	model, _ = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	if m := model.(hubModel); m.busy || m.unresolved != nil || builds != 0 {
+		t.Fatalf("n: busy=%v unresolved=%v builds=%d", m.busy, m.unresolved, builds)
+	}
+
+	// y builds anyway.
+	model, cmd = model.(hubModel).startBuild()
+	model, _ = model.Update(nextMsg(cmd))
+	model, cmd = model.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	if m := model.(hubModel); !m.busy || cmd == nil {
+		t.Fatalf("y did not start the build: busy=%v", m.busy)
+	}
+	model, _ = model.Update(nextMsg(cmd))
+	if m := model.(hubModel); builds != 1 || m.result == nil || !m.result.success {
+		t.Errorf("after y: builds=%d result=%+v", builds, m.result)
+	}
+
+	// Without unresolved features the build starts right after the check.
+	cb.CheckFeatures = func(context.Context, map[string]any) []string { return nil }
+	model, cmd = newHubModel("proj", config, template.CLIInfo{}, false, cb).startBuild()
+	model, cmd = model.Update(nextMsg(cmd))
+	if m := model.(hubModel); !m.busy || m.busyLabel == "Checking features..." || cmd == nil {
+		t.Errorf("build not started after a clean check: busy=%v label=%q", m.busy, m.busyLabel)
+	}
+}
+
+func TestHubCancelOpenWithCtrlC(t *testing.T) {
+	cb := HubCallbacks{
+		Open: func(ctx context.Context) (string, error) {
+			<-ctx.Done()
+			return "", ctx.Err()
+		},
+	}
+	model, cmd := newHubModel("proj", nil, template.CLIInfo{}, false, cb).startOpen()
+	model, quit := model.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	if quit != nil {
+		t.Error("Ctrl+C while busy quit the hub instead of cancelling")
+	}
+	model, _ = model.Update(nextMsg(cmd))
+	if m := model.(hubModel); m.result == nil || !m.result.cancelled || !strings.Contains(m.renderPreview(), "Open cancelled") {
+		t.Errorf("after Ctrl+C: result=%+v", m.result)
+	}
+}
+
+func TestHubBusySpinner(t *testing.T) {
+	cb := HubCallbacks{
+		Up: func(ctx context.Context) (string, string, error) {
+			return "abc123", "", nil
+		},
+	}
+	model, cmd := newHubModel("proj", nil, template.CLIInfo{}, false, cb).startUp()
+	if got := model.(hubModel).renderPreview(); !strings.Contains(got, "Starting devcontainer... 0s") {
+		t.Errorf("busy preview lacks the elapsed time:\n%s", got)
+	}
+	tick := cmd().(tea.BatchMsg)[0]()
+	if _, ok := tick.(spinner.TickMsg); !ok {
+		t.Fatalf("startUp did not start the spinner: %T", tick)
+	}
+	if _, next := model.Update(tick); next == nil {
+		t.Error("spinner stopped ticking while busy")
+	}
+
+	model, _ = model.Update(nextMsg(cmd))
+	if _, next := model.Update(tick); next != nil {
+		t.Error("spinner kept ticking after the command finished")
+	}
+}
+
+func TestFormatElapsed(t *testing.T) {
+	tests := map[time.Duration]string{
+		0:                                     "0s",
+		42*time.Second + 900*time.Millisecond: "42s",
+		65 * time.Second:                      "1m05s",
+		12 * time.Minute:                      "12m00s",
+	}
+	for d, want := range tests {
+		if got := formatElapsed(d); got != want {
+			t.Errorf("formatElapsed(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func TestPreviewShowsFeatureNotes(t *testing.T) {
+	config := map[string]any{
+		"features": map[string]any{"ghcr.io/devcontainers/features/node:1": map[string]any{}},
+		"customizations": map[string]any{
+			"dcc": map[string]any{"notes": map[string]any{"ghcr.io/devcontainers/features/node": "frontend build"}},
+		},
+	}
+	data, _ := json.MarshalIndent(config, "", "  ")
+	for _, line := range strings.Split(colorizeJSONMarked(string(data), nil, featureNotes(config)), "\n") {
+		if strings.Contains(line, "features/node:1") {
+			if !strings.Contains(line, "// frontend build") {
+				t.Errorf("feature line %q lacks its note", line)
+			}
+			return
+		}
+	}
+	t.Error("feature line not found in the preview")
+}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"sync"
+
+	"github.com/mochlast/devcontainer-companion/internal/prefs"
+)
+
+// menuRatioStep is how far one press of < or > in the hub moves the split.
+const menuRatioStep = 0.05
+
+var (
+	menuRatio     float64
+	menuRatioOnce sync.Once
+)
+
+// currentMenuRatio returns the menu/list column's share of the terminal
+// width, loaded once from the user preferences.
+func currentMenuRatio() float64 {
+	menuRatioOnce.Do(func() {
+		p, _ := prefs.Load()
+		menuRatio = p.MenuRatio()
+	})
+	return menuRatio
+}
+
+// splitWidth returns the width of the left-hand column for a terminal of the
+// given width.
+func splitWidth(width int) int {
+	return int(float64(width) * currentMenuRatio())
+}
+
+// nudgeMenuRatio moves the split by delta and persists the new ratio.
+func nudgeMenuRatio(delta float64) error {
+	ratio := prefs.ClampMenuRatio(currentMenuRatio() + delta)
+	menuRatio = ratio
+
+	p, err := prefs.Load()
+	if err != nil {
+		return err
+	}
+	p.Layout.MenuRatio = ratio
+	return prefs.Save(p)
+}
@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/mochlast/devcontainer-companion/internal/mountspec"
+)
+
+// editMountsField shows the configured mounts as a list where each mount can
+// be edited or removed individually, and new mounts can be added.
+func editMountsField(config map[string]any) (bool, error) {
+	mounts, _ := config["mounts"].([]any)
+	mounts = append([]any(nil), mounts...)
+	changed := false
+
+	const (
+		choiceAdd  = -1
+		choiceDone = -2
+	)
+
+	for {
+		opts := make([]huh.Option[int], 0, len(mounts)+2)
+		for i, m := range mounts {
+			opts = append(opts, huh.NewOption(mountLabel(m), i))
+		}
+		opts = append(opts,
+			huh.NewOption("+ Add mount", choiceAdd),
+			huh.NewOption("Done", choiceDone),
+		)
+
+		choice := choiceDone
+		if len(mounts) == 0 {
+			choice = choiceAdd
+		}
+		form := NewForm(huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Mounts").
+				Description("Select a mount to edit or remove it").
+				Options(opts...).
+				Value(&choice),
+		))
+		if err := form.Run(); err != nil {
+			return false, fmt.Errorf("editing mounts: %w", err)
+		}
+
+		switch {
+		case choice == choiceDone:
+			if changed {
+				if len(mounts) == 0 {
+					delete(config, "mounts")
+				} else {
+					config["mounts"] = mounts
+				}
+			}
+			return changed, nil
+
+		case choice == choiceAdd:
+			spec, remove, err := runMountForm(mountspec.Spec{Type: "bind"}, false)
+			if err != nil {
+				return false, err
+			}
+			if !remove && spec.Target != "" {
+				mounts = append(mounts, spec.String())
+				changed = true
+			}
+
+		default:
+			// Mounts keep the form they were written in.
+			if obj, ok := mounts[choice].(map[string]any); ok {
+				spec, remove, err := runMountForm(mountspec.FromObject(obj), true)
+				if err != nil {
+					return false, err
+				}
+				switch edited := spec.Object(); {
+				case remove:
+					mounts = append(mounts[:choice], mounts[choice+1:]...)
+					changed = true
+				case !reflect.DeepEqual(edited, obj):
+					mounts[choice] = edited
+					changed = true
+				}
+				continue
+			}
+
+			s, ok := mounts[choice].(string)
+			if !ok {
+				// Other values can't be edited here; offer removal only.
+				remove := false
+				confirm := NewForm(huh.NewGroup(
+					huh.NewConfirm().Title("Remove this mount?").Description(mountLabel(mounts[choice])).Value(&remove),
+				))
+				if err := confirm.Run(); err != nil {
+					return false, fmt.Errorf("editing mounts: %w", err)
+				}
+				if remove {
+					mounts = append(mounts[:choice], mounts[choice+1:]...)
+					changed = true
+				}
+				continue
+			}
+
+			spec, remove, err := runMountForm(mountspec.Parse(s), true)
+			if err != nil {
+				return false, err
+			}
+			switch {
+			case remove:
+				mounts = append(mounts[:choice], mounts[choice+1:]...)
+				changed = true
+			case spec.String() != s:
+				mounts[choice] = spec.String()
+				changed = true
+			}
+		}
+	}
+}
+
+// runMountForm shows the per-mount form. For existing mounts a removal
+// confirm is included. Returns the edited spec and whether to remove it.
+func runMountForm(spec mountspec.Spec, existing bool) (mountspec.Spec, bool, error) {
+	if spec.Type == "" {
+		spec.Type = "bind"
+	}
+	remove := false
+
+	fields := []huh.Field{
+		huh.NewSelect[string]().
+			Title("Type").
+			Options(mountTypeOptions(spec.Type, mountTypes...)...).
+			Value(&spec.Type),
+		huh.NewInput().
+			Title("Source").
+			Description("Host path or volume name, e.g. ${localWorkspaceFolder}/data").
+			Value(&spec.Source),
+		huh.NewInput().
+			Title("Target").
+			Description("Path inside the container").
+			Value(&spec.Target).
+			Validate(func(s string) error {
+				if strings.TrimSpace(s) == "" && !remove {
+					return fmt.Errorf("target is required")
+				}
+				return nil
+			}),
+		huh.NewSelect[string]().
+			Title("Consistency").
+			Options(
+				huh.NewOption("default", ""),
+				huh.NewOption("cached", "cached"),
+				huh.NewOption("delegated", "delegated"),
+				huh.NewOption("consistent", "consistent"),
+			).
+			Value(&spec.Consistency),
+	}
+	if existing {
+		fields = append([]huh.Field{
+			huh.NewConfirm().Title("Remove this mount?").Value(&remove),
+		}, fields...)
+	}
+
+	form := NewForm(huh.NewGroup(fields...))
+	if err := form.Run(); err != nil {
+		return spec, false, fmt.Errorf("editing mount: %w", err)
+	}
+
+	spec.Source = strings.TrimSpace(spec.Source)
+	spec.Target = strings.TrimSpace(spec.Target)
+	return spec, remove, nil
+}
+
+// mountTypes are the mount types the mount editor offers.
+var mountTypes = []string{"bind", "volume", "tmpfs"}
+
+// mountTypeOptions returns types as select options, followed by current if
+// it is another type, so editing a mount keeps a type the editor doesn't
+// offer instead of falling back to the first option.
+func mountTypeOptions(current string, types ...string) []huh.Option[string] {
+	if current != "" && !slices.Contains(types, current) {
+		types = append(slices.Clone(types), current)
+	}
+	return huh.NewOptions(types...)
+}
+
+// mountLabel renders a mount entry for the selection list.
+func mountLabel(m any) string {
+	if s, ok := m.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		return strconv.Quote(fmt.Sprintf("%v", m))
+	}
+	return string(data)
+}
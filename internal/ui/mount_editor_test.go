@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMountTypeOptions(t *testing.T) {
+	values := func(current string) []string {
+		var got []string
+		for _, o := range mountTypeOptions(current, mountTypes...) {
+			got = append(got, o.Value)
+		}
+		return got
+	}
+	if got, want := values("tmpfs"), []string{"bind", "volume", "tmpfs"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("options for tmpfs = %q, want %q", got, want)
+	}
+	// A type the editor doesn't offer stays selectable, so it isn't
+	// rewritten as bind.
+	if got, want := values("npipe"), []string{"bind", "volume", "tmpfs", "npipe"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("options for npipe = %q, want %q", got, want)
+	}
+	if len(mountTypes) != 3 {
+		t.Errorf("mountTypeOptions changed mountTypes: %q", mountTypes)
+	}
+}
@@ -1,6 +1,13 @@
 package ui
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
 
 // defaultToString converts an option default value to a string.
 // Handles the case where JSON defaults are arrays (e.g. ["3", "3.12"])
@@ -21,3 +28,54 @@ func defaultToString(v any) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+// optionHint is the faint line under an option's title: its type and, unless
+// the enum marks it, its default.
+func optionHint(opt registry.OptionDefinition) string {
+	var parts []string
+	if opt.Type != "" {
+		parts = append(parts, opt.Type)
+	}
+	if def := defaultToString(opt.Default); def != "" && len(opt.Enum) == 0 {
+		parts = append(parts, "default: "+def)
+	}
+	return strings.Join(parts, " · ")
+}
+
+// enumOptions lists an option's enum values with the default marked and,
+// where the metadata has them, their descriptions: "value — description".
+func enumOptions(opt registry.OptionDefinition, def string) []huh.Option[string] {
+	descriptions := enumDescriptions(opt)
+	opts := make([]huh.Option[string], len(opt.Enum))
+	for i, e := range opt.Enum {
+		label := e
+		if i < len(descriptions) && descriptions[i] != "" {
+			label += " — " + descriptions[i]
+		}
+		if e == def {
+			label += " (default)"
+		}
+		opts[i] = huh.NewOption(label, e)
+	}
+	return opts
+}
+
+// enumDescriptions returns what describes opt's enum values by position:
+// enumDescriptions, or else proposals of the same length, which some
+// features use to gloss each value. Proposals repeating the value describe
+// nothing.
+func enumDescriptions(opt registry.OptionDefinition) []string {
+	if len(opt.EnumDescriptions) > 0 {
+		return opt.EnumDescriptions
+	}
+	if len(opt.Proposals) != len(opt.Enum) {
+		return nil
+	}
+	descriptions := make([]string, len(opt.Enum))
+	for i, p := range opt.Proposals {
+		if p != opt.Enum[i] {
+			descriptions[i] = p
+		}
+	}
+	return descriptions
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+func TestOptionHint(t *testing.T) {
+	tests := []struct {
+		opt  registry.OptionDefinition
+		want string
+	}{
+		{registry.OptionDefinition{Type: "string", Default: "lts"}, "string · default: lts"},
+		{registry.OptionDefinition{Type: "boolean", Default: true}, "boolean · default: true"},
+		{registry.OptionDefinition{Type: "string", Default: []any{"3.12", "3.11"}}, "string · default: 3.12"},
+		{registry.OptionDefinition{Type: "string"}, "string"},
+		// Enums mark the default among their options instead.
+		{registry.OptionDefinition{Type: "string", Default: "bookworm", Enum: []string{"bookworm", "bullseye"}}, "string"},
+		{registry.OptionDefinition{}, ""},
+	}
+	for _, tt := range tests {
+		if got := optionHint(tt.opt); got != tt.want {
+			t.Errorf("optionHint(%+v) = %q, want %q", tt.opt, got, tt.want)
+		}
+	}
+}
+
+func TestEnumOptionsMarkDefault(t *testing.T) {
+	opts := enumOptions(registry.OptionDefinition{Enum: []string{"bookworm", "bullseye"}}, "bullseye")
+	if opts[0].Key != "bookworm" || opts[1].Key != "bullseye (default)" {
+		t.Errorf("labels = %q, %q", opts[0].Key, opts[1].Key)
+	}
+	if opts[1].Value != "bullseye" {
+		t.Errorf("default value = %q, want the bare enum value", opts[1].Value)
+	}
+}
+
+func TestEnumOptionsDescriptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opt  registry.OptionDefinition
+		want []string
+	}{
+		{
+			name: "enumDescriptions",
+			opt: registry.OptionDefinition{
+				Enum:             []string{"os-provided", "latest", "none"},
+				EnumDescriptions: []string{"From the image's package manager", "", "Skip the install"},
+			},
+			want: []string{"os-provided — From the image's package manager (default)", "latest", "none — Skip the install"},
+		},
+		{
+			name: "proposals paired with the enum",
+			opt: registry.OptionDefinition{
+				Enum:      []string{"os-provided", "latest"},
+				Proposals: []string{"From the image's package manager", "latest"},
+			},
+			want: []string{"os-provided — From the image's package manager (default)", "latest"},
+		},
+		{
+			name: "proposals that don't pair",
+			opt: registry.OptionDefinition{
+				Enum:      []string{"os-provided", "latest", "none"},
+				Proposals: []string{"lts"},
+			},
+			want: []string{"os-provided (default)", "latest", "none"},
+		},
+	}
+	for _, tt := range tests {
+		opts := enumOptions(tt.opt, "os-provided")
+		for i, want := range tt.want {
+			if opts[i].Key != want || opts[i].Value != tt.opt.Enum[i] {
+				t.Errorf("%s: option %d = %q (%q), want %q", tt.name, i, opts[i].Key, opts[i].Value, want)
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"sort"
@@ -37,6 +38,7 @@ func (i pluginItem) Description() string {
 // pluginDelegate renders plugin items with selection checkboxes.
 type pluginDelegate struct {
 	selectedItems map[string]bool
+	versions      map[string]string // pinned versions by plugin ID
 }
 
 func (d pluginDelegate) Height() int                             { return 2 }
@@ -50,6 +52,9 @@ func (d pluginDelegate) Render(w io.Writer, m list.Model, index int, listItem li
 	}
 
 	title := item.Title()
+	if v := d.versions[item.plugin.ID]; v != "" {
+		title += " @" + v
+	}
 	desc := item.Description()
 
 	isActive := index == m.Index()
@@ -64,22 +69,28 @@ func (d pluginDelegate) Render(w io.Writer, m list.Model, index int, listItem li
 	descStyle := lipgloss.NewStyle().PaddingLeft(6).Faint(true)
 
 	if isActive {
-		titleStyle = titleStyle.Bold(true).Foreground(lipgloss.Color("170"))
-		descStyle = descStyle.Foreground(lipgloss.Color("170"))
+		titleStyle = titleStyle.Bold(true).Foreground(theme.Accent)
+		descStyle = descStyle.Foreground(theme.Accent)
 		title = fmt.Sprintf("> %s %s", checkbox, title)
 	} else {
 		title = fmt.Sprintf("  %s %s", checkbox, title)
 	}
 
 	maxW := m.Width()
-	fmt.Fprintf(w, "%s\n%s", titleStyle.MaxWidth(maxW).Render(title), descStyle.MaxWidth(maxW).Render(desc))
+	fmt.Fprintf(w, "%s\n%s", truncateWidth(titleStyle.Render(title), maxW), truncateWidth(descStyle.Render(desc), maxW))
 }
 
 // pluginSearchResultMsg carries JetBrains search results back to the model.
+// gen is the searchGen the search was started for.
 type pluginSearchResultMsg struct {
 	plugins []marketplace.Plugin
 	err     error
-	query   string
+	gen     int
+}
+
+// pluginSearchTickMsg fires once the search debounce has elapsed.
+type pluginSearchTickMsg struct {
+	gen int
 }
 
 // pluginReadmeFetchedMsg carries the result of an async plugin readme fetch.
@@ -89,29 +100,59 @@ type pluginReadmeFetchedMsg struct {
 	err      error
 }
 
+// pluginVersionsMsg carries the available versions of a plugin.
+type pluginVersionsMsg struct {
+	pluginID string
+	versions []string
+	err      error
+}
+
+// pluginVersionPicker is the side panel for pinning a plugin version.
+type pluginVersionPicker struct {
+	active   bool
+	loading  bool
+	pluginID string
+	versions []string // choices; index 0 is "latest" (unpinned)
+	cursor   int
+	errMsg   string
+}
+
 // pluginPickerModel is the bubbletea model for JetBrains plugin picking with live search.
 type pluginPickerModel struct {
 	list          list.Model
 	selectedItems map[string]bool
+	versions      map[string]string // pinned versions by plugin ID
+	versionPick   pluginVersionPicker
 	confirmed     bool
 	quitting      bool
 	width         int
 	height        int
 	searchInput   string
 	searching     bool
+	retrying      bool
+	searchErr     string
+	searchUpdates <-chan tea.Msg     // messages of the search in flight
+	searchGen     int                // bumped per keystroke; older ticks and results are stale
+	cancelSearch  context.CancelFunc // aborts the request in flight
 	lastQuery     string
+	showInstalled bool        // list shows only checked items
+	savedItems    []list.Item // search results hidden while showInstalled
 	preview       readmePreview
 }
 
-func newPluginPicker(preSelected map[string]bool) pluginPickerModel {
+func newPluginPicker(preSelected map[string]bool, pinned map[string]string) pluginPickerModel {
 	selectedItems := make(map[string]bool)
 	if preSelected != nil {
 		for k, v := range preSelected {
 			selectedItems[k] = v
 		}
 	}
+	versions := make(map[string]string)
+	for k, v := range pinned {
+		versions[k] = v
+	}
 
-	delegate := pluginDelegate{selectedItems: selectedItems}
+	delegate := pluginDelegate{selectedItems: selectedItems, versions: versions}
 
 	// Show pre-selected plugins as initial items
 	var initialItems []list.Item
@@ -134,7 +175,7 @@ func newPluginPicker(preSelected map[string]bool) pluginPickerModel {
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(false)
 	l.SetShowHelp(true)
-	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170")).MarginLeft(2)
+	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).MarginLeft(2)
 
 	l.KeyMap.ShowFullHelp = key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "help"))
 	l.KeyMap.CloseFullHelp = key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "close help"))
@@ -142,12 +183,15 @@ func newPluginPicker(preSelected map[string]bool) pluginPickerModel {
 	l.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "details")),
+			key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "pin version")),
+			key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "selected only")),
 		}
 	}
 
 	return pluginPickerModel{
 		list:          l,
 		selectedItems: selectedItems,
+		versions:      versions,
 		preview:       newReadmePreview(),
 	}
 }
@@ -157,8 +201,8 @@ func (m pluginPickerModel) Init() tea.Cmd {
 }
 
 func (m *pluginPickerModel) applyLayout() {
-	if m.preview.visible {
-		listW := m.width / 3
+	if m.preview.visible || m.versionPick.active {
+		listW := splitWidth(m.width)
 		m.list.SetWidth(listW)
 		m.list.SetHeight(m.height - 3)
 		m.preview.SetSize(m.width-listW, m.height-2)
@@ -176,13 +220,37 @@ func (m pluginPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.applyLayout()
 		return m, nil
 
+	case pluginSearchTickMsg:
+		if msg.gen != m.searchGen {
+			return m, nil // superseded by a later keystroke
+		}
+		return m, m.runSearch()
+
+	case searchRetryMsg:
+		if msg.updates != m.searchUpdates {
+			return m, nil
+		}
+		m.retrying = true
+		return m, waitForSearch(msg.updates)
+
 	case pluginSearchResultMsg:
+		if msg.gen != m.searchGen {
+			return m, nil
+		}
+		if m.cancelSearch != nil {
+			m.cancelSearch() // done; releases the context
+			m.cancelSearch = nil
+		}
 		m.searching = false
-		if msg.err != nil || msg.query != m.lastQuery {
+		m.retrying = false
+		if msg.err != nil {
+			m.searchErr = searchErrorText(msg.err)
 			return m, nil
 		}
+		m.searchErr = ""
 		items := make([]list.Item, 0, len(msg.plugins))
 		for _, p := range msg.plugins {
+			adoptCanonicalID(m.selectedItems, m.versions, p.ID)
 			items = append(items, pluginItem{plugin: p})
 		}
 		if len(m.selectedItems) > 0 {
@@ -192,17 +260,21 @@ func (m pluginPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return iSel && !jSel
 			})
 		}
+		if m.showInstalled {
+			m.savedItems = items
+			return m, nil
+		}
 		m.list.SetItems(items)
-		m.list.SetDelegate(pluginDelegate{selectedItems: m.selectedItems})
+		m.list.SetDelegate(pluginDelegate{selectedItems: m.selectedItems, versions: m.versions})
 		return m, nil
 
 	case pluginReadmeFetchedMsg:
-		if msg.pluginID != m.preview.sourceURL {
+		if msg.pluginID != m.preview.key {
 			return m, nil
 		}
 		m.preview.loading = false
 		if msg.err != nil {
-			m.preview.errMsg = fmt.Sprintf("Error: %s", msg.err)
+			m.preview.errMsg = fetchErrorText(msg.err)
 			m.preview.viewport.SetContent(m.preview.errMsg)
 			return m, nil
 		}
@@ -211,7 +283,29 @@ func (m pluginPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.preview.viewport.GotoTop()
 		return m, nil
 
+	case pluginVersionsMsg:
+		if !m.versionPick.active || msg.pluginID != m.versionPick.pluginID {
+			return m, nil
+		}
+		m.versionPick.loading = false
+		if msg.err != nil {
+			m.versionPick.errMsg = fetchErrorText(msg.err)
+			return m, nil
+		}
+		m.versionPick.versions = append([]string{""}, msg.versions...)
+		m.versionPick.cursor = 0
+		for i, v := range m.versionPick.versions {
+			if v == m.versions[msg.pluginID] {
+				m.versionPick.cursor = i
+			}
+		}
+		return m, nil
+
 	case tea.KeyMsg:
+		if m.versionPick.active {
+			return m.updateVersionPick(msg)
+		}
+
 		if m.preview.visible {
 			switch msg.String() {
 			case "?", "esc":
@@ -234,7 +328,7 @@ func (m pluginPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				pluginID := item.plugin.ID
 				m.preview.visible = true
 				m.preview.loading = true
-				m.preview.sourceURL = pluginID
+				m.preview.key = pluginID
 				m.preview.errMsg = ""
 				m.preview.viewport.SetContent("Loading plugin details...")
 				m.applyLayout()
@@ -242,6 +336,19 @@ func (m pluginPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
+		case "ctrl+f":
+			m.toggleInstalledView()
+			return m, nil
+
+		case "tab":
+			if item, ok := m.list.SelectedItem().(pluginItem); ok {
+				pluginID := item.plugin.ID
+				m.versionPick = pluginVersionPicker{active: true, loading: true, pluginID: pluginID}
+				m.applyLayout()
+				return m, fetchPluginVersionsCmd(pluginID)
+			}
+			return m, nil
+
 		case "enter":
 			m.confirmed = true
 			m.quitting = true
@@ -251,21 +358,29 @@ func (m pluginPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if item, ok := m.list.SelectedItem().(pluginItem); ok {
 				id := item.plugin.ID
 				m.selectedItems[id] = !m.selectedItems[id]
-				m.list.SetDelegate(pluginDelegate{selectedItems: m.selectedItems})
+				m.list.SetDelegate(pluginDelegate{selectedItems: m.selectedItems, versions: m.versions})
 			}
 			return m, nil
 
 		case "backspace":
 			if len(m.searchInput) > 0 {
+				if m.showInstalled {
+					m.toggleInstalledView()
+				}
 				m.searchInput = m.searchInput[:len(m.searchInput)-1]
 				return m, m.triggerSearch()
 			}
 			return m, nil
 
 		case "esc":
+			if m.showInstalled {
+				m.toggleInstalledView()
+				return m, nil
+			}
 			if m.searchInput != "" {
 				m.searchInput = ""
 				m.lastQuery = ""
+				m.stopSearch()
 				var items []list.Item
 				for id, checked := range m.selectedItems {
 					if checked {
@@ -285,6 +400,9 @@ func (m pluginPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		default:
 			if len(msg.Runes) > 0 {
+				if m.showInstalled {
+					m.toggleInstalledView()
+				}
 				m.searchInput += string(msg.Runes)
 				return m, m.triggerSearch()
 			}
@@ -300,19 +418,85 @@ func (m pluginPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// toggleInstalledView switches between the search results and a view of only
+// the checked plugins. Unchecked items stay visible until the view is closed.
+func (m *pluginPickerModel) toggleInstalledView() {
+	if m.showInstalled {
+		m.showInstalled = false
+		m.list.SetItems(m.savedItems)
+		m.savedItems = nil
+		return
+	}
+
+	known := make(map[string]marketplace.Plugin)
+	for _, it := range m.list.Items() {
+		if pi, ok := it.(pluginItem); ok {
+			known[pi.plugin.ID] = pi.plugin
+		}
+	}
+
+	var items []list.Item
+	for id, checked := range m.selectedItems {
+		if !checked {
+			continue
+		}
+		plugin, ok := known[id]
+		if !ok {
+			plugin = marketplace.Plugin{ID: id, Name: id}
+		}
+		items = append(items, pluginItem{plugin: plugin})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].(pluginItem).plugin.ID < items[j].(pluginItem).plugin.ID
+	})
+
+	m.savedItems = m.list.Items()
+	m.showInstalled = true
+	m.list.SetItems(items)
+	m.list.ResetSelected()
+}
+
+// stopSearch cancels the request in flight and makes pending ticks and
+// results stale, like the extension picker's.
+func (m *pluginPickerModel) stopSearch() {
+	m.searchGen++
+	if m.cancelSearch != nil {
+		m.cancelSearch()
+		m.cancelSearch = nil
+	}
+	m.searchUpdates = nil
+	m.searching = false
+	m.retrying = false
+}
+
 func (m *pluginPickerModel) triggerSearch() tea.Cmd {
+	m.stopSearch()
 	query := strings.TrimSpace(m.searchInput)
 	if query == "" {
 		return nil
 	}
 	m.lastQuery = query
 	m.searching = true
-	return tea.Tick(300*time.Millisecond, func(_ time.Time) tea.Msg {
-		plugins, err := marketplace.SearchPlugins(query, 20)
-		return pluginSearchResultMsg{plugins: plugins, err: err, query: query}
+	gen := m.searchGen
+	return tick(300*time.Millisecond, func(_ time.Time) tea.Msg {
+		return pluginSearchTickMsg{gen: gen}
 	})
 }
 
+// runSearch queries the JetBrains marketplace for lastQuery.
+func (m *pluginPickerModel) runSearch() tea.Cmd {
+	query, gen := m.lastQuery, m.searchGen
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancelSearch = cancel
+	m.retrying = false
+	var cmd tea.Cmd
+	m.searchUpdates, cmd = streamSearch(func(onRetry marketplace.RetryFunc) tea.Msg {
+		plugins, err := marketplace.SearchPlugins(ctx, query, 20, onRetry)
+		return pluginSearchResultMsg{plugins: plugins, err: err, gen: gen}
+	})
+	return cmd
+}
+
 func fetchPluginReadmeCmd(pluginID string) tea.Cmd {
 	return func() tea.Msg {
 		content, err := marketplace.FetchPluginReadme(pluginID)
@@ -324,18 +508,103 @@ func fetchPluginReadmeCmd(pluginID string) tea.Cmd {
 	}
 }
 
+// updateVersionPick handles keys while the version panel is open. Enter pins
+// the highlighted version (or unpins for "latest") and selects the plugin.
+func (m pluginPickerModel) updateVersionPick(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	vp := &m.versionPick
+	switch msg.String() {
+	case "ctrl+c":
+		m.quitting = true
+		return m, tea.Quit
+	case "esc", "tab":
+		vp.active = false
+		m.applyLayout()
+	case "up", "k":
+		if vp.cursor > 0 {
+			vp.cursor--
+		}
+	case "down", "j":
+		if vp.cursor < len(vp.versions)-1 {
+			vp.cursor++
+		}
+	case "enter":
+		if vp.loading || len(vp.versions) == 0 {
+			return m, nil
+		}
+		if v := vp.versions[vp.cursor]; v != "" {
+			m.versions[vp.pluginID] = v
+		} else {
+			delete(m.versions, vp.pluginID)
+		}
+		m.selectedItems[vp.pluginID] = true
+		m.list.SetDelegate(pluginDelegate{selectedItems: m.selectedItems, versions: m.versions})
+		vp.active = false
+		m.applyLayout()
+	}
+	return m, nil
+}
+
+func fetchPluginVersionsCmd(pluginID string) tea.Cmd {
+	return func() tea.Msg {
+		versions, err := marketplace.FetchPluginVersions(pluginID, 20)
+		return pluginVersionsMsg{pluginID: pluginID, versions: versions, err: err}
+	}
+}
+
+// versionPickView renders the version panel in place of the README preview.
+func (m pluginPickerModel) versionPickView(width, height int) string {
+	vp := m.versionPick
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).PaddingLeft(1)
+	borderStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Accent).
+		Width(width - 2).
+		Height(height - 3)
+
+	var b strings.Builder
+	switch {
+	case vp.loading:
+		b.WriteString("Loading versions...")
+	case vp.errMsg != "":
+		b.WriteString(vp.errMsg)
+	default:
+		activeStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+		for i, v := range vp.versions {
+			label := v
+			if v == "" {
+				label = "latest (unpinned)"
+			}
+			if i == vp.cursor {
+				b.WriteString(activeStyle.Render("> "+label) + "\n")
+			} else {
+				b.WriteString("  " + label + "\n")
+			}
+		}
+	}
+
+	title := titleStyle.Render("Pin version: " + vp.pluginID)
+	return lipgloss.JoinVertical(lipgloss.Left, title, borderStyle.Render(b.String()))
+}
+
 func (m pluginPickerModel) View() string {
 	if m.quitting {
 		return ""
 	}
 
-	accentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("170"))
+	accentStyle := lipgloss.NewStyle().Foreground(theme.Accent)
 	faintStyle := lipgloss.NewStyle().Faint(true)
 
 	searchLine := accentStyle.Render(fmt.Sprintf("  Search: %s", m.searchInput))
 	searchLine += faintStyle.Render("_")
-	if m.searching {
+	if m.retrying {
+		searchLine += searchErrStyle.Render("  Search failed, retrying...")
+	} else if m.searching {
 		searchLine += accentStyle.Render("  Searching...")
+	} else if m.searchErr != "" {
+		searchLine += searchErrStyle.Render("  " + m.searchErr)
+	}
+	if m.showInstalled {
+		searchLine += accentStyle.Render("  Showing selected only (ctrl+f to go back)")
 	}
 
 	count := 0
@@ -352,8 +621,14 @@ func (m pluginPickerModel) View() string {
 
 	listView := "\n" + searchLine + "\n" + m.list.View() + status
 
+	if m.versionPick.active {
+		listW := splitWidth(m.width)
+		clipped := lipgloss.NewStyle().Width(listW).MaxWidth(listW).Render(listView)
+		return lipgloss.JoinHorizontal(lipgloss.Top, clipped, m.versionPickView(m.width-listW, m.height-2))
+	}
+
 	if m.preview.visible {
-		listW := m.width / 3
+		listW := splitWidth(m.width)
 		clipped := lipgloss.NewStyle().Width(listW).MaxWidth(listW).Render(listView)
 		return lipgloss.JoinHorizontal(lipgloss.Top, clipped, m.preview.View())
 	}
@@ -362,15 +637,17 @@ func (m pluginPickerModel) View() string {
 }
 
 // PickPlugins shows a multi-select plugin picker with live JetBrains Marketplace search.
-func PickPlugins(preSelected map[string]bool) ([]string, error) {
-	m := newPluginPicker(preSelected)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	finalModel, err := p.Run()
+// pinned maps plugin IDs to versions from existing "xmlId:version" entries; the
+// returned entries keep their version unless it was changed in the picker.
+func PickPlugins(preSelected map[string]bool, pinned map[string]string) ([]string, error) {
+	m := newPluginPicker(preSelected, pinned)
+	finalModel, err := runProgram(m)
 	if err != nil {
 		return nil, fmt.Errorf("running plugin picker: %w", err)
 	}
 
 	result := finalModel.(pluginPickerModel)
+	result.stopSearch()
 	if !result.confirmed {
 		return nil, ErrPickerCancelled
 	}
@@ -378,7 +655,7 @@ func PickPlugins(preSelected map[string]bool) ([]string, error) {
 	var selected []string
 	for id, checked := range result.selectedItems {
 		if checked {
-			selected = append(selected, id)
+			selected = append(selected, marketplace.FormatPluginRef(id, result.versions[id]))
 		}
 	}
 
@@ -0,0 +1,266 @@
+package ui
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// portSpec is one forwardPorts entry together with its portsAttributes.
+type portSpec struct {
+	Port          string // "3000" or "host:port", e.g. "db:5432"
+	Label         string
+	Protocol      string // "", "http" or "https"
+	OnAutoForward string
+	Attrs         map[string]any // other portsAttributes keys, kept verbatim
+}
+
+// readPorts parses forwardPorts and the matching portsAttributes entries.
+func readPorts(config map[string]any) []portSpec {
+	ports, _ := config["forwardPorts"].([]any)
+	attrs, _ := config["portsAttributes"].(map[string]any)
+
+	specs := make([]portSpec, 0, len(ports))
+	for _, p := range ports {
+		var spec portSpec
+		switch v := p.(type) {
+		case float64:
+			spec.Port = strconv.Itoa(int(v))
+		case int:
+			spec.Port = strconv.Itoa(v)
+		default:
+			spec.Port = fmt.Sprintf("%v", v)
+		}
+
+		if a, ok := attrs[spec.Port].(map[string]any); ok {
+			spec.Attrs = make(map[string]any, len(a))
+			for k, v := range a {
+				switch k {
+				case "label":
+					spec.Label, _ = v.(string)
+				case "protocol":
+					spec.Protocol, _ = v.(string)
+				case "onAutoForward":
+					spec.OnAutoForward, _ = v.(string)
+				default:
+					spec.Attrs[k] = v
+				}
+			}
+		}
+		specs = append(specs, spec)
+	}
+	return specs
+}
+
+// writePorts stores specs in forwardPorts (numeric ports as numbers) and
+// portsAttributes. Attributes of ports that were removed are dropped;
+// attributes for other keys (e.g. port ranges) are left alone.
+func writePorts(config map[string]any, before, specs []portSpec) {
+	attrs, _ := config["portsAttributes"].(map[string]any)
+	if attrs == nil {
+		attrs = make(map[string]any)
+	}
+	for _, old := range before {
+		delete(attrs, old.Port)
+	}
+
+	ports := make([]any, 0, len(specs))
+	for _, spec := range specs {
+		if n, err := strconv.Atoi(spec.Port); err == nil {
+			ports = append(ports, n)
+		} else {
+			ports = append(ports, spec.Port)
+		}
+
+		a := make(map[string]any, len(spec.Attrs)+3)
+		for k, v := range spec.Attrs {
+			a[k] = v
+		}
+		if spec.Label != "" {
+			a["label"] = spec.Label
+		}
+		if spec.Protocol != "" {
+			a["protocol"] = spec.Protocol
+		}
+		if spec.OnAutoForward != "" {
+			a["onAutoForward"] = spec.OnAutoForward
+		}
+		if len(a) > 0 {
+			attrs[spec.Port] = a
+		}
+	}
+
+	if len(ports) == 0 {
+		delete(config, "forwardPorts")
+	} else {
+		config["forwardPorts"] = ports
+	}
+	if len(attrs) == 0 {
+		delete(config, "portsAttributes")
+	} else {
+		config["portsAttributes"] = attrs
+	}
+}
+
+// portLabel renders a port for the selection list.
+func portLabel(spec portSpec) string {
+	s := spec.Port
+	if spec.Label != "" {
+		s += " — " + spec.Label
+	}
+	if spec.Protocol != "" {
+		s += " (" + spec.Protocol + ")"
+	}
+	return s
+}
+
+// editPortsField shows the forwarded ports as a list where each port can be
+// edited or removed individually, and new ports can be added.
+func editPortsField(config map[string]any) (bool, error) {
+	before := readPorts(config)
+	specs := append([]portSpec(nil), before...)
+	changed := false
+
+	const (
+		choiceAdd     = -1
+		choiceDone    = -2
+		choiceReorder = -3
+	)
+
+	for {
+		opts := make([]huh.Option[int], 0, len(specs)+2)
+		for i, spec := range specs {
+			opts = append(opts, huh.NewOption(portLabel(spec), i))
+		}
+		opts = append(opts, huh.NewOption("+ Add port", choiceAdd))
+		if len(specs) > 1 {
+			opts = append(opts, huh.NewOption("↕ Reorder ports", choiceReorder))
+		}
+		opts = append(opts, huh.NewOption("Done", choiceDone))
+
+		choice := choiceDone
+		if len(specs) == 0 {
+			choice = choiceAdd
+		}
+		form := NewForm(huh.NewGroup(
+			huh.NewSelect[int]().
+				Title("Forward Ports").
+				Description("Select a port to edit or remove it").
+				Options(opts...).
+				Value(&choice),
+		))
+		if err := form.Run(); err != nil {
+			return false, fmt.Errorf("editing forwardPorts: %w", err)
+		}
+
+		switch {
+		case choice == choiceDone:
+			if changed {
+				writePorts(config, before, specs)
+			}
+			return changed, nil
+
+		case choice == choiceReorder:
+			labels := make([]string, len(specs))
+			for i, spec := range specs {
+				labels[i] = portLabel(spec)
+			}
+			order, ok, err := PickOrder("Reorder forwarded ports", labels)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				reordered := make([]portSpec, len(specs))
+				for i, idx := range order {
+					reordered[i] = specs[idx]
+				}
+				specs = reordered
+				changed = true
+			}
+
+		case choice == choiceAdd:
+			spec, remove, err := runPortForm(portSpec{}, false)
+			if err != nil {
+				return false, err
+			}
+			if !remove && spec.Port != "" {
+				specs = append(specs, spec)
+				changed = true
+			}
+
+		default:
+			spec, remove, err := runPortForm(specs[choice], true)
+			if err != nil {
+				return false, err
+			}
+			switch {
+			case remove:
+				specs = append(specs[:choice], specs[choice+1:]...)
+				changed = true
+			case !reflect.DeepEqual(spec, specs[choice]):
+				specs[choice] = spec
+				changed = true
+			}
+		}
+	}
+}
+
+// runPortForm shows the per-port form. For existing ports a removal confirm
+// is included. Returns the edited spec and whether to remove it.
+func runPortForm(spec portSpec, existing bool) (portSpec, bool, error) {
+	remove := false
+
+	fields := []huh.Field{
+		huh.NewInput().
+			Title("Port").
+			Description("Port number or host:port (e.g. 3000, db:5432)").
+			Value(&spec.Port).
+			Validate(func(s string) error {
+				if strings.TrimSpace(s) == "" && !remove {
+					return fmt.Errorf("port is required")
+				}
+				return nil
+			}),
+		huh.NewInput().
+			Title("Label").
+			Description("Shown in the editor's ports view").
+			Value(&spec.Label),
+		huh.NewSelect[string]().
+			Title("Protocol").
+			Options(
+				huh.NewOption("default", ""),
+				huh.NewOption("http", "http"),
+				huh.NewOption("https", "https"),
+			).
+			Value(&spec.Protocol),
+		huh.NewSelect[string]().
+			Title("On Auto Forward").
+			Options(
+				huh.NewOption("default", ""),
+				huh.NewOption("notify", "notify"),
+				huh.NewOption("openBrowser", "openBrowser"),
+				huh.NewOption("openBrowserOnce", "openBrowserOnce"),
+				huh.NewOption("openPreview", "openPreview"),
+				huh.NewOption("silent", "silent"),
+				huh.NewOption("ignore", "ignore"),
+			).
+			Value(&spec.OnAutoForward),
+	}
+	if existing {
+		fields = append([]huh.Field{
+			huh.NewConfirm().Title("Remove this port?").Value(&remove),
+		}, fields...)
+	}
+
+	form := NewForm(huh.NewGroup(fields...))
+	if err := form.Run(); err != nil {
+		return spec, false, fmt.Errorf("editing port: %w", err)
+	}
+
+	spec.Port = strings.TrimSpace(spec.Port)
+	spec.Label = strings.TrimSpace(spec.Label)
+	return spec, remove, nil
+}
@@ -0,0 +1,59 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadWritePortsRoundTrip(t *testing.T) {
+	config := map[string]any{
+		"forwardPorts": []any{float64(3000), "db:5432"},
+		"portsAttributes": map[string]any{
+			"3000":      map[string]any{"label": "Web", "protocol": "https", "requireLocalPort": true},
+			"9000-9010": map[string]any{"onAutoForward": "ignore"},
+		},
+	}
+
+	specs := readPorts(config)
+	if len(specs) != 2 {
+		t.Fatalf("readPorts returned %d ports, want 2", len(specs))
+	}
+	if specs[0].Port != "3000" || specs[0].Label != "Web" || specs[0].Protocol != "https" {
+		t.Errorf("specs[0] = %+v", specs[0])
+	}
+	if specs[1].Port != "db:5432" || specs[1].Label != "" {
+		t.Errorf("specs[1] = %+v", specs[1])
+	}
+
+	specs[1].Label = "Postgres"
+	writePorts(config, readPorts(config), specs)
+
+	wantPorts := []any{3000, "db:5432"}
+	if !reflect.DeepEqual(config["forwardPorts"], wantPorts) {
+		t.Errorf("forwardPorts = %#v, want %#v", config["forwardPorts"], wantPorts)
+	}
+	wantAttrs := map[string]any{
+		"3000":      map[string]any{"label": "Web", "protocol": "https", "requireLocalPort": true},
+		"db:5432":   map[string]any{"label": "Postgres"},
+		"9000-9010": map[string]any{"onAutoForward": "ignore"},
+	}
+	if !reflect.DeepEqual(config["portsAttributes"], wantAttrs) {
+		t.Errorf("portsAttributes = %#v, want %#v", config["portsAttributes"], wantAttrs)
+	}
+}
+
+func TestWritePortsRemovesAttributes(t *testing.T) {
+	config := map[string]any{
+		"forwardPorts":    []any{float64(8080)},
+		"portsAttributes": map[string]any{"8080": map[string]any{"label": "API"}},
+	}
+
+	writePorts(config, readPorts(config), nil)
+
+	if _, ok := config["forwardPorts"]; ok {
+		t.Error("forwardPorts should be removed")
+	}
+	if _, ok := config["portsAttributes"]; ok {
+		t.Error("portsAttributes should be removed")
+	}
+}
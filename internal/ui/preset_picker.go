@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+)
+
+// PickPreset asks which saved preset to apply. It returns "" if the user
+// cancels or there are no presets.
+func PickPreset(names []string) (string, error) {
+	if len(names) == 0 {
+		err := NewForm(huh.NewGroup(
+			huh.NewNote().
+				Title("Apply Preset").
+				Description("No presets saved yet.\nSave one with: dcc preset save <name>").
+				Next(true).
+				NextLabel("Back"),
+		)).Run()
+		if err != nil {
+			return "", fmt.Errorf("picking preset: %w", err)
+		}
+		return "", nil
+	}
+
+	opts := make([]huh.Option[string], 0, len(names)+1)
+	for _, name := range names {
+		opts = append(opts, huh.NewOption(name, name))
+	}
+	opts = append(opts, huh.NewOption("Cancel", ""))
+
+	selected := names[0]
+	form := NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Apply Preset").
+			Description("Merged into devcontainer.json: objects merge, lists gain missing entries, other values are replaced").
+			Options(opts...).
+			Height(min(len(opts)+2, 12)).
+			Value(&selected),
+	))
+	if err := form.Run(); err != nil {
+		return "", fmt.Errorf("picking preset: %w", err)
+	}
+	return selected, nil
+}
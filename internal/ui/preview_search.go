@@ -0,0 +1,167 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// previewSearch is the pager-like search of the hub preview: "/" types a
+// query, n/N cycle the matches, Esc clears it. A line matches when its text
+// or the dotted key path of its key contains the query, case-insensitively,
+// so "vscode.ext" finds customizations.vscode.extensions.
+type previewSearch struct {
+	input   textinput.Model
+	editing bool  // the query is being typed
+	matches []int // preview lines that match, set by highlight
+	current int   // index into matches
+}
+
+func newPreviewSearch() previewSearch {
+	input := textinput.New()
+	input.Prompt = "/"
+	return previewSearch{input: input}
+}
+
+func (s previewSearch) query() string { return strings.TrimSpace(s.input.Value()) }
+
+// active reports whether the preview is searched or a query is being typed.
+func (s previewSearch) active() bool { return s.editing || s.query() != "" }
+
+// start begins typing a new query.
+func (s previewSearch) start() (previewSearch, tea.Cmd) {
+	s.input.SetValue("")
+	s.editing = true
+	s.current = 0
+	return s, s.input.Focus()
+}
+
+// update handles a key while the query is typed. Enter ends typing, Esc
+// also clears the query.
+func (s previewSearch) update(msg tea.KeyMsg) (previewSearch, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		s.editing = false
+		s.input.Blur()
+		return s, nil
+	case "esc", "ctrl+c":
+		return newPreviewSearch(), nil
+	}
+	var cmd tea.Cmd
+	s.input, cmd = s.input.Update(msg)
+	s.current = 0
+	return s, cmd
+}
+
+// step moves to the next (delta 1) or previous (delta -1) match.
+func (s previewSearch) step(delta int) previewSearch {
+	if len(s.matches) > 0 {
+		s.current = (s.current + delta + len(s.matches)) % len(s.matches)
+	}
+	return s
+}
+
+// line returns the preview line of the current match, or -1.
+func (s previewSearch) line() int {
+	if len(s.matches) == 0 {
+		return -1
+	}
+	return s.matches[s.current]
+}
+
+// title describes the search for the preview title.
+func (s previewSearch) title() string {
+	if s.editing {
+		return s.input.View()
+	}
+	if len(s.matches) == 0 {
+		return fmt.Sprintf("/%s (no matches)", s.query())
+	}
+	return fmt.Sprintf("/%s (%d/%d, n/N)", s.query(), s.current+1, len(s.matches))
+}
+
+// highlight marks the matching lines of the rendered preview and records
+// them in matches. Matching lines lose their JSON colors so the match
+// stands out; the current one is drawn in the accent color.
+func (s previewSearch) highlight(content string) (string, previewSearch) {
+	s.matches = nil
+	q := strings.ToLower(s.query())
+	if q == "" {
+		return content, s
+	}
+
+	lines := strings.Split(content, "\n")
+	plain := make([]string, len(lines))
+	for i, line := range lines {
+		plain[i] = ansi.Strip(line)
+	}
+	paths := lineKeyPaths(plain)
+	for i, line := range plain {
+		if strings.Contains(strings.ToLower(line), q) || strings.Contains(strings.ToLower(paths[i]), q) {
+			s.matches = append(s.matches, i)
+		}
+	}
+	if s.current >= len(s.matches) {
+		s.current = 0
+	}
+
+	for n, i := range s.matches {
+		style := searchMatchStyle
+		if n == s.current {
+			style = searchCurrentStyle
+		}
+		lines[i] = markMatches(plain[i], q, style.Render)
+	}
+	return strings.Join(lines, "\n"), s
+}
+
+// markMatches renders the occurrences of q (lowercase) in line with mark,
+// or all of the line's text if the match is in its key path only.
+func markMatches(line, q string, mark func(...string) string) string {
+	lower := strings.ToLower(line)
+	if !strings.Contains(lower, q) || len(lower) != len(line) {
+		trimmed := strings.TrimLeft(line, " ")
+		return line[:len(line)-len(trimmed)] + mark(trimmed)
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, q)
+		if i < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		b.WriteString(line[:i])
+		b.WriteString(mark(line[i : i+len(q)]))
+		line, lower = line[i+len(q):], lower[i+len(q):]
+	}
+}
+
+// lineKeyPaths returns the dotted key path of the key on each line of
+// indented JSON, as colorizeJSONMarked tracks it, or "" for lines without a
+// key.
+func lineKeyPaths(lines []string) []string {
+	paths := make([]string, len(lines))
+	var path []string
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		idx := strings.Index(trimmed, `":`)
+		if idx < 0 || !strings.HasPrefix(trimmed, `"`) {
+			continue
+		}
+		depth := max((len(line)-len(strings.TrimLeft(line, " ")))/2, 1)
+		for len(path) < depth-1 {
+			path = append(path, "")
+		}
+		var key string
+		if json.Unmarshal([]byte(trimmed[:idx+1]), &key) != nil {
+			continue
+		}
+		path = append(path[:depth-1], key)
+		paths[i] = joinKeyPath(path)
+	}
+	return paths
+}
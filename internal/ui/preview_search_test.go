@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/mochlast/devcontainer-companion/internal/template"
+)
+
+func TestLineKeyPaths(t *testing.T) {
+	config := map[string]any{
+		"name": "proj",
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go"}},
+		},
+	}
+	data, _ := json.MarshalIndent(config, "", "  ")
+	got := lineKeyPaths(strings.Split(string(data), "\n"))
+	want := []string{"", "customizations", "customizations.vscode", "customizations.vscode.extensions", "", "", "", "", "name", ""}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lineKeyPaths = %q, want %q", got, want)
+	}
+}
+
+func TestPreviewSearchHighlight(t *testing.T) {
+	content := strings.Join([]string{
+		`{`,
+		`  "customizations": {`,
+		`    "vscode": {`,
+		`      "extensions": [`,
+		`        "golang.go",`,
+		`        "ms-python.python"`,
+		`      ]`,
+		`    }`,
+		`  },`,
+		`  "name": "Python"`,
+		`}`,
+	}, "\n")
+
+	s := newPreviewSearch()
+	s.input.SetValue("PYTHON")
+	out, s := s.highlight(content)
+	if want := []int{5, 9}; !reflect.DeepEqual(s.matches, want) {
+		t.Fatalf("matches = %v, want %v", s.matches, want)
+	}
+	if ansi.Strip(out) != content {
+		t.Error("highlighting changed the preview text")
+	}
+
+	// Dotted queries match key paths, not only the text on the line.
+	s.input.SetValue("vscode.ext")
+	_, s = s.highlight(content)
+	if want := []int{3}; !reflect.DeepEqual(s.matches, want) {
+		t.Errorf("path matches = %v, want %v", s.matches, want)
+	}
+
+	s.input.SetValue("o")
+	_, s = s.highlight(content)
+	n := len(s.matches)
+	if s = s.step(-1); s.current != n-1 {
+		t.Errorf("step(-1) from the first match = %d, want %d", s.current, n-1)
+	}
+	if s = s.step(1); s.current != 0 {
+		t.Errorf("step(1) from the last match = %d, want 0", s.current)
+	}
+}
+
+func TestHubSearchKeys(t *testing.T) {
+	config := map[string]any{"name": "proj", "image": "mcr.microsoft.com/devcontainers/go:1"}
+	var model tea.Model = newHubModel("proj", config, template.CLIInfo{}, false, HubCallbacks{})
+	model, _ = model.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	press := func(keys ...string) {
+		for _, k := range keys {
+			msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+			switch k {
+			case "enter":
+				msg = tea.KeyMsg{Type: tea.KeyEnter}
+			case "esc":
+				msg = tea.KeyMsg{Type: tea.KeyEsc}
+			}
+			model, _ = model.Update(msg)
+		}
+	}
+
+	// While typing, hub shortcuts such as q go to the query.
+	press("/", "q")
+	m := model.(hubModel)
+	if m.quitting || m.search.query() != "q" {
+		t.Fatalf("typing q: quitting=%v query=%q", m.quitting, m.search.query())
+	}
+
+	press("esc", "/", "p", "r", "o", "j", "enter")
+	m = model.(hubModel)
+	if m.search.editing || len(m.search.matches) != 1 {
+		t.Fatalf("after enter: editing=%v matches=%v", m.search.editing, m.search.matches)
+	}
+	if !strings.Contains(m.View(), "/proj (1/1, n/N)") {
+		t.Error("preview title lacks the search status")
+	}
+
+	press("esc")
+	if m = model.(hubModel); m.search.active() {
+		t.Error("esc should clear the search")
+	}
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/charmbracelet/bubbles/cursor"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// script holds the keys scripted by scriptKeys; nil runs programs on the
+// terminal.
+var script []tea.KeyMsg
+
+// tick starts the timers of this package. Scripted runs replace it, so
+// timers never fire between scripted keys.
+var tick = tea.Tick
+
+// scriptKeys makes the following hub, picker and menu programs read keys
+// from the script instead of the terminal, so tests drive the TUI without
+// one. Names like "enter", "space", "down" or "esc" stand for their key;
+// anything else is typed as text. Programs take keys from the script until
+// they quit, so one script can cover several programs; calling scriptKeys
+// without keys restores the terminal.
+func scriptKeys(keys ...string) {
+	script = nil
+	tick = tea.Tick
+	if len(keys) == 0 {
+		return
+	}
+	script = make([]tea.KeyMsg, len(keys))
+	for i, k := range keys {
+		if key, ok := keyNames[k]; ok {
+			script[i] = key
+		} else {
+			script[i] = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		}
+	}
+	tick = func(time.Duration, func(time.Time) tea.Msg) tea.Cmd { return nil }
+}
+
+// keyNames maps the key names of scriptKeys to their key.
+var keyNames = map[string]tea.KeyMsg{
+	"enter":     {Type: tea.KeyEnter},
+	"space":     {Type: tea.KeySpace, Runes: []rune{' '}},
+	"tab":       {Type: tea.KeyTab},
+	"esc":       {Type: tea.KeyEsc},
+	"backspace": {Type: tea.KeyBackspace},
+	"up":        {Type: tea.KeyUp},
+	"down":      {Type: tea.KeyDown},
+	"right":     {Type: tea.KeyRight},
+	"left":      {Type: tea.KeyLeft},
+	"ctrl+a":    {Type: tea.KeyCtrlA},
+	"ctrl+c":    {Type: tea.KeyCtrlC},
+	"ctrl+t":    {Type: tea.KeyCtrlT},
+}
+
+// errScriptEnded is returned when the script runs out before the program
+// quits.
+var errScriptEnded = errors.New("scripted keys ended before the program quit")
+
+// runProgram runs m full-screen until it quits and returns its final model.
+func runProgram(m tea.Model) (tea.Model, error) {
+	if script == nil {
+		return tea.NewProgram(m, tea.WithAltScreen()).Run()
+	}
+	d := scriptedRun{model: m}
+	if d.run(m.Init()) {
+		return d.model, nil
+	}
+	for len(script) > 0 {
+		key := script[0]
+		script = script[1:]
+		var cmd tea.Cmd
+		d.model, cmd = d.model.Update(key)
+		if d.run(cmd) {
+			return d.model, nil
+		}
+	}
+	return d.model, errScriptEnded
+}
+
+// scriptedRun drives a model synchronously: every command runs to
+// completion, and its message is handled, before the next key.
+type scriptedRun struct {
+	model tea.Model
+}
+
+// cmdsType is the type of tea.BatchMsg and of the message of tea.Sequence.
+var cmdsType = reflect.TypeOf(tea.BatchMsg(nil))
+
+// run runs cmd and the commands the model returns while handling its
+// messages, and reports whether one of them quit the program. Cursor blinks
+// and spinner frames are dropped since they only wait for the next frame;
+// other program messages, such as tea.ExecProcess, are dropped as well.
+func (d *scriptedRun) run(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	msg := cmd()
+	if msg == nil {
+		return false
+	}
+	if v := reflect.ValueOf(msg); v.Type().ConvertibleTo(cmdsType) {
+		for _, c := range v.Convert(cmdsType).Interface().(tea.BatchMsg) {
+			if d.run(c) {
+				return true
+			}
+		}
+		return false
+	}
+	switch msg.(type) {
+	case tea.QuitMsg:
+		return true
+	case spinner.TickMsg:
+		return false
+	}
+	switch reflect.TypeOf(msg).PkgPath() {
+	case reflect.TypeOf(cursor.Model{}).PkgPath(), reflect.TypeOf(tea.QuitMsg{}).PkgPath():
+		return false
+	}
+	var next tea.Cmd
+	d.model, next = d.model.Update(msg)
+	return d.run(next)
+}
@@ -0,0 +1,99 @@
+package ui
+
+import (
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// readmeCacheSize bounds the READMEs a picker keeps.
+const readmeCacheSize = 32
+
+// readmePrefetchDelay is how long an item stays highlighted before its
+// README is prefetched, so scrolling past items doesn't fetch them all.
+const readmePrefetchDelay = artifactInfoDelay
+
+// readmeCache keeps the most recently used READMEs of a picker by preview
+// key, fetched on ? or prefetched while highlighted.
+type readmeCache struct {
+	keys     []string // least recently used first
+	contents map[string]string
+}
+
+func newReadmeCache() *readmeCache {
+	return &readmeCache{contents: make(map[string]string)}
+}
+
+func (c *readmeCache) get(key string) (string, bool) {
+	content, ok := c.contents[key]
+	if ok {
+		c.touch(key)
+	}
+	return content, ok
+}
+
+// put stores content, evicting the least recently used README when full.
+func (c *readmeCache) put(key, content string) {
+	if _, ok := c.contents[key]; !ok && len(c.keys) >= readmeCacheSize {
+		delete(c.contents, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+	c.contents[key] = content
+	c.touch(key)
+}
+
+// touch marks key as the most recently used.
+func (c *readmeCache) touch(key string) {
+	if i := slices.Index(c.keys, key); i >= 0 {
+		c.keys = slices.Delete(c.keys, i, i+1)
+	}
+	c.keys = append(c.keys, key)
+}
+
+// readmePrefetchDueMsg fires readmePrefetchDelay after an item was
+// highlighted.
+type readmePrefetchDueMsg struct {
+	key, sourceURL, ociRef string
+}
+
+// readmePrefetchedMsg carries a README fetched ahead of ?.
+type readmePrefetchedMsg readmeFetchedMsg
+
+// Prefetch notes that the item with sourceURL and ociRef is highlighted and
+// returns the timer after which its README is fetched in the background,
+// unless it is cached already.
+func (p *readmePreview) Prefetch(sourceURL, ociRef string) tea.Cmd {
+	key := sourceURL + "|" + ociRef
+	if (sourceURL == "" && ociRef == "") || key == p.prefetchKey {
+		return nil
+	}
+	p.prefetchKey = key
+	if _, ok := p.cache.contents[key]; ok {
+		return nil
+	}
+	return tick(readmePrefetchDelay, func(time.Time) tea.Msg {
+		return readmePrefetchDueMsg{key: key, sourceURL: sourceURL, ociRef: ociRef}
+	})
+}
+
+// HandlePrefetch processes the messages of Prefetch and the fetch it
+// starts. Items scrolled past before the delay ran out are not fetched; a
+// failed prefetch is dropped, so ? fetches again and shows the error.
+func (p *readmePreview) HandlePrefetch(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case readmePrefetchDueMsg:
+		if _, ok := p.cache.contents[msg.key]; ok || msg.key != p.prefetchKey {
+			return nil
+		}
+		fetch := fetchReadmeCmd(msg.key, msg.sourceURL, msg.ociRef)
+		return func() tea.Msg {
+			return readmePrefetchedMsg(fetch().(readmeFetchedMsg))
+		}
+	case readmePrefetchedMsg:
+		if msg.err == nil {
+			p.cache.put(msg.key, msg.content)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestReadmeCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	c := newReadmeCache()
+	for i := range readmeCacheSize {
+		c.put(fmt.Sprint(i), "readme")
+	}
+	c.get("0") // used again, so 1 is the oldest now
+	c.put("new", "readme")
+
+	if _, ok := c.contents["1"]; ok {
+		t.Error("least recently used entry kept")
+	}
+	if _, ok := c.get("0"); !ok {
+		t.Error("recently used entry evicted")
+	}
+	if len(c.contents) != readmeCacheSize || len(c.keys) != readmeCacheSize {
+		t.Errorf("cache holds %d contents, %d keys; want %d", len(c.contents), len(c.keys), readmeCacheSize)
+	}
+}
+
+func TestReadmePrefetch(t *testing.T) {
+	p := newReadmePreview()
+	if p.Prefetch("https://example.com/a", "ghcr.io/x/a:1") == nil {
+		t.Fatal("no prefetch timer for a new highlight")
+	}
+	if p.Prefetch("https://example.com/a", "ghcr.io/x/a:1") != nil {
+		t.Error("prefetch restarted for the same highlight")
+	}
+	keyA := "https://example.com/a|ghcr.io/x/a:1"
+
+	// The highlight moved on before the delay ran out.
+	p.Prefetch("https://example.com/b", "ghcr.io/x/b:1")
+	if cmd := p.HandlePrefetch(readmePrefetchDueMsg{key: keyA}); cmd != nil {
+		t.Error("prefetched an item scrolled past")
+	}
+
+	// A prefetched README opens without fetching.
+	p.HandlePrefetch(readmePrefetchedMsg{key: keyA, content: "# A"})
+	cmd := p.Toggle("https://example.com/a", "ghcr.io/x/a:1")
+	msg, ok := cmd().(readmeFetchedMsg)
+	if !ok || msg.key != keyA || msg.content != "# A" {
+		t.Fatalf("Toggle on a prefetched item = %#v", msg)
+	}
+	p.HandleFetchResult(msg)
+	if p.loading || p.errMsg != "" {
+		t.Errorf("preview after cached open: loading=%v err=%q", p.loading, p.errMsg)
+	}
+
+	// Cached items aren't prefetched again.
+	p.Prefetch("https://example.com/b", "ghcr.io/x/b:1")
+	if p.Prefetch("https://example.com/a", "ghcr.io/x/a:1") != nil {
+		t.Error("prefetch timer for a cached item")
+	}
+}
@@ -1,71 +1,142 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mochlast/devcontainer-companion/internal/catalog"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/httpclient"
+	"github.com/mochlast/devcontainer-companion/internal/marketplace"
+	"github.com/mochlast/devcontainer-companion/internal/registry"
 )
 
 // readmeFetchedMsg carries the result of an async README fetch.
 type readmeFetchedMsg struct {
-	sourceURL string
-	content   string
-	err       error
+	key     string
+	content string
+	err     error
 }
 
 // readmePreview is a reusable component that shows a README in a scrollable viewport.
 type readmePreview struct {
-	visible   bool
-	loading   bool
-	sourceURL string
-	viewport  viewport.Model
-	width     int
-	height    int
-	errMsg    string
+	visible  bool
+	loading  bool
+	key      string // identifies the item shown (source URL or OCI ref)
+	viewport viewport.Model
+	width    int
+	height   int
+	errMsg   string
+	title    string // what is shown, e.g. "README Preview"
+	info     string // shown next to the title, see artifactSummary
+	notice   string // warning shown next to the title, e.g. see platformNotice
+
+	cache       *readmeCache // shared by the copies of the picker model
+	prefetchKey string       // highlighted item, see Prefetch
 }
 
 func newReadmePreview() readmePreview {
-	return readmePreview{}
+	return readmePreview{cache: newReadmeCache()}
 }
 
-// Toggle opens or closes the preview. If opening for a new URL, returns a fetch command.
-// While loading, a repeated toggle for the same URL is ignored to prevent accidental close.
-func (p *readmePreview) Toggle(sourceURL string) tea.Cmd {
-	if p.visible && p.sourceURL == sourceURL {
-		if p.loading {
-			return nil // ignore toggle while fetch is in-flight
-		}
-		p.visible = false
+// Toggle opens or closes the preview. If opening for a new item, returns a fetch command.
+// The README is fetched from the GitHub source URL, falling back to the README.md
+// bundled in the OCI layer when ociRef is set.
+// While loading, a repeated toggle for the same item is ignored to prevent accidental close.
+func (p *readmePreview) Toggle(sourceURL, ociRef string) tea.Cmd {
+	key := sourceURL + "|" + ociRef
+	if p.toggleOff(key) {
 		return nil
 	}
 
-	if sourceURL == "" {
+	if sourceURL == "" && ociRef == "" {
 		p.visible = true
 		p.loading = false
-		p.sourceURL = ""
+		p.key = ""
+		p.title = "README Preview"
 		p.errMsg = "No source URL available"
 		p.viewport.SetContent(p.errMsg)
 		return nil
 	}
 
-	needsFetch := p.sourceURL != sourceURL || p.errMsg != "" || p.viewport.TotalLineCount() == 0
+	return p.open(key, "README Preview", "Loading README...", fetchReadmeCmd(key, sourceURL, ociRef))
+}
+
+// ToggleScript opens or closes the install.sh of the feature at ociRef, like
+// Toggle does for READMEs.
+func (p *readmePreview) ToggleScript(ociRef string) tea.Cmd {
+	key := "install.sh|" + ociRef
+	if p.toggleOff(key) {
+		return nil
+	}
+	return p.open(key, "install.sh", "Loading install.sh...", fetchInstallScriptCmd(key, ociRef))
+}
+
+// ToggleFiles opens or closes the list of files the template at ociRef adds
+// to the workspace at folder, each marked as new or replacing a file there.
+func (p *readmePreview) ToggleFiles(ociRef, folder string) tea.Cmd {
+	key := "files|" + ociRef
+	if p.toggleOff(key) {
+		return nil
+	}
+	return p.open(key, "Template files", "Loading template files...", fetchTemplateFilesCmd(key, ociRef, folder))
+}
+
+// toggleOff closes the preview if it already shows key, and reports whether
+// the toggle was handled (closed, or ignored while loading).
+func (p *readmePreview) toggleOff(key string) bool {
+	if !p.visible || p.key != key {
+		return false
+	}
+	if !p.loading {
+		p.visible = false
+	}
+	return true
+}
+
+// open shows the item identified by key, returning fetch unless its content
+// is already shown.
+func (p *readmePreview) open(key, title, loadingText string, fetch tea.Cmd) tea.Cmd {
+	needsFetch := p.key != key || p.errMsg != "" || p.viewport.TotalLineCount() == 0
 	p.visible = true
-	p.sourceURL = sourceURL
+	p.key = key
+	p.title = title
 
 	if needsFetch {
 		p.loading = true
 		p.errMsg = ""
-		p.viewport.SetContent("Loading README...")
-		return fetchReadmeCmd(sourceURL)
+		p.viewport.SetContent(loadingText)
+		if content, ok := p.cache.get(key); ok {
+			return func() tea.Msg { return readmeFetchedMsg{key: key, content: content} }
+		}
+		return fetch
 	}
 
 	return nil
 }
 
+// fetchErrorText turns an error of a README or version fetch into the
+// message shown in its panel.
+func fetchErrorText(err error) string {
+	switch {
+	case errors.Is(err, registry.ErrNotFound), errors.Is(err, marketplace.ErrNotFound):
+		return "Not found: it may have been unpublished or renamed.\n\n" + err.Error()
+	case errors.Is(err, registry.ErrUnauthorized):
+		return "Access denied: for a private registry, log in with docker login first.\n\n" + err.Error()
+	case errors.Is(err, registry.ErrRateLimited), errors.Is(err, marketplace.ErrRateLimited):
+		return "Rate limited, try again in a moment."
+	case httpclient.IsTimeout(err):
+		return fmt.Sprintf("Timed out after %s (set DCC_HTTP_TIMEOUT to change).", httpclient.Timeout())
+	}
+	return fmt.Sprintf("Error: %s", err)
+}
+
 // Close hides the preview panel.
 func (p *readmePreview) Close() {
 	p.visible = false
@@ -73,13 +144,16 @@ func (p *readmePreview) Close() {
 
 // HandleFetchResult processes the async fetch result.
 func (p *readmePreview) HandleFetchResult(msg readmeFetchedMsg) {
-	if msg.sourceURL != p.sourceURL {
+	if msg.err == nil {
+		p.cache.put(msg.key, msg.content)
+	}
+	if msg.key != p.key {
 		return
 	}
 	p.loading = false
 
 	if msg.err != nil {
-		p.errMsg = fmt.Sprintf("Error: %s", msg.err)
+		p.errMsg = fetchErrorText(msg.err)
 		p.viewport.SetContent(p.errMsg)
 		return
 	}
@@ -89,6 +163,16 @@ func (p *readmePreview) HandleFetchResult(msg readmeFetchedMsg) {
 	p.viewport.GotoTop()
 }
 
+// SetInfo shows info about the previewed item next to the title.
+func (p *readmePreview) SetInfo(info string) {
+	p.info = info
+}
+
+// SetNotice shows notice next to the title of every item previewed.
+func (p *readmePreview) SetNotice(notice string) {
+	p.notice = notice
+}
+
 // SetSize updates the viewport dimensions.
 func (p *readmePreview) SetSize(width, height int) {
 	p.width = width
@@ -115,39 +199,121 @@ func (p *readmePreview) View() string {
 
 	titleStyle := lipgloss.NewStyle().
 		Bold(true).
-		Foreground(lipgloss.Color("170")).
+		Foreground(theme.Accent).
 		PaddingLeft(1)
 
 	borderStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("170")).
+		BorderForeground(theme.Accent).
 		Width(p.width - 2).
 		Height(p.height - 3)
 
-	title := titleStyle.Render("README Preview")
+	title := titleStyle.Render(p.title)
+	if p.info != "" {
+		title += lipgloss.NewStyle().Faint(true).PaddingLeft(2).Render(p.info)
+	}
+	if p.notice != "" {
+		title += lipgloss.NewStyle().Foreground(theme.Warning).PaddingLeft(2).Render(p.notice)
+	}
+	title = lipgloss.NewStyle().MaxWidth(p.width).Render(title)
 	body := borderStyle.Render(p.viewport.View())
 	return lipgloss.JoinVertical(lipgloss.Left, title, body)
 }
 
 // fetchReadmeCmd returns a tea.Cmd that fetches a README asynchronously.
-func fetchReadmeCmd(sourceURL string) tea.Cmd {
+// If the source URL is empty or the fetch fails, the OCI layer is tried.
+func fetchReadmeCmd(key, sourceURL, ociRef string) tea.Cmd {
 	return func() tea.Msg {
-		content, err := catalog.FetchReadme(sourceURL)
+		var content string
+		var err error
+		if sourceURL != "" {
+			content, err = catalog.FetchReadme(sourceURL)
+		}
+		if (sourceURL == "" || err != nil) && ociRef != "" {
+			ociContent, ociErr := registry.FetchItemReadme(ociRef)
+			if ociErr == nil {
+				content, err = ociContent, nil
+			} else if err == nil {
+				err = ociErr
+			}
+		}
 		return readmeFetchedMsg{
-			sourceURL: sourceURL,
-			content:   content,
-			err:       err,
+			key:     key,
+			content: content,
+			err:     err,
 		}
 	}
 }
 
+// fetchInstallScriptCmd fetches a feature's install.sh, wrapped in a shell
+// code block so it is rendered with syntax highlighting.
+func fetchInstallScriptCmd(key, ociRef string) tea.Cmd {
+	return func() tea.Msg {
+		script, err := registry.FetchInstallScript(ociRef)
+		return readmeFetchedMsg{
+			key:     key,
+			content: codeBlock("sh", script),
+			err:     err,
+		}
+	}
+}
+
+// codeBlock fences code as a markdown code block in lang. The fence is
+// longer than any run of backticks in code, so a heredoc holding markdown
+// doesn't end the block early.
+func codeBlock(lang, code string) string {
+	longest, run := 0, 0
+	for _, r := range code {
+		if r != '`' {
+			run = 0
+			continue
+		}
+		run++
+		longest = max(longest, run)
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	return fence + lang + "\n" + strings.TrimRight(code, "\n") + "\n" + fence + "\n"
+}
+
+// fetchTemplateFilesCmd lists the files of a template as a markdown list,
+// checking which already exist in folder.
+func fetchTemplateFilesCmd(key, ociRef, folder string) tea.Cmd {
+	return func() tea.Msg {
+		names, err := registry.FetchTemplateFiles(ociRef)
+		return readmeFetchedMsg{key: key, content: templateFilesMarkdown(names, folder), err: err}
+	}
+}
+
+// templateFilesMarkdown lists names, paths relative to folder, with what
+// applying the template does to each.
+func templateFilesMarkdown(names []string, folder string) string {
+	var b strings.Builder
+	b.WriteString("Applying this template writes:\n\n")
+	for _, name := range names {
+		effect := "new"
+		switch {
+		case !devcontainer.FileExists(filepath.Join(folder, name)):
+		case name == ".devcontainer/devcontainer.json":
+			effect = "merged with your config"
+		default:
+			effect = "**replaces the existing file**"
+		}
+		fmt.Fprintf(&b, "- `%s` — %s\n", name, effect)
+	}
+	return b.String()
+}
+
 // renderMarkdown renders markdown content using glamour, falling back to raw text on error.
 func renderMarkdown(content string, width int) string {
 	if width < 10 {
 		width = 80
 	}
+	style := glamour.WithAutoStyle()
+	if theme.plain {
+		style = glamour.WithStandardStyle("notty")
+	}
 	r, err := glamour.NewTermRenderer(
-		glamour.WithAutoStyle(),
+		style,
 		glamour.WithWordWrap(width-4),
 	)
 	if err != nil {
@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTemplateFilesMarkdown(t *testing.T) {
+	folder := t.TempDir()
+	for _, name := range []string{".devcontainer/devcontainer.json", ".devcontainer/Dockerfile"} {
+		path := filepath.Join(folder, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got := templateFilesMarkdown([]string{".devcontainer/Dockerfile", ".devcontainer/devcontainer.json", ".devcontainer/setup.sh"}, folder)
+	want := "Applying this template writes:\n\n" +
+		"- `.devcontainer/Dockerfile` — **replaces the existing file**\n" +
+		"- `.devcontainer/devcontainer.json` — merged with your config\n" +
+		"- `.devcontainer/setup.sh` — new\n"
+	if got != want {
+		t.Errorf("templateFilesMarkdown =\n%s\nwant\n%s", got, want)
+	}
+}
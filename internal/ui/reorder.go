@@ -0,0 +1,114 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// moveItem moves s[i] by delta positions (clamped to the slice bounds) and
+// returns its new index.
+func moveItem[T any](s []T, i, delta int) int {
+	j := min(max(i+delta, 0), len(s)-1)
+	if i < 0 || i >= len(s) || i == j {
+		return i
+	}
+	item := s[i]
+	if j > i {
+		copy(s[i:j], s[i+1:j+1])
+	} else {
+		copy(s[j+1:i+1], s[j:i])
+	}
+	s[j] = item
+	return j
+}
+
+// reorderDelta maps the reorder keys (J/K, shift+down/up) to a move direction.
+func reorderDelta(key string) int {
+	switch key {
+	case "J", "shift+down":
+		return 1
+	case "K", "shift+up":
+		return -1
+	}
+	return 0
+}
+
+// reorderModel is a minimal list where the highlighted entry can be moved
+// with J/K. order holds indexes into labels.
+type reorderModel struct {
+	title     string
+	labels    []string
+	order     []int
+	cursor    int
+	confirmed bool
+	quitting  bool
+}
+
+func newReorderModel(title string, labels []string) reorderModel {
+	order := make([]int, len(labels))
+	for i := range order {
+		order[i] = i
+	}
+	return reorderModel{title: title, labels: labels, order: order}
+}
+
+func (m reorderModel) Init() tea.Cmd { return nil }
+
+func (m reorderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch key := keyMsg.String(); key {
+	case "ctrl+c", "esc", "q":
+		m.quitting = true
+		return m, tea.Quit
+	case "enter":
+		m.confirmed = true
+		m.quitting = true
+		return m, tea.Quit
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(m.order)-1)
+	default:
+		if delta := reorderDelta(key); delta != 0 {
+			m.cursor = moveItem(m.order, m.cursor, delta)
+		}
+	}
+	return m, nil
+}
+
+func (m reorderModel) View() string {
+	if m.quitting {
+		return ""
+	}
+	accent := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent)
+	faint := lipgloss.NewStyle().Faint(true)
+
+	var b strings.Builder
+	b.WriteString("\n" + accent.MarginLeft(2).Render(m.title) + "\n\n")
+	for i, idx := range m.order {
+		if i == m.cursor {
+			b.WriteString(accent.Render("  > "+m.labels[idx]) + "\n")
+		} else {
+			b.WriteString("    " + m.labels[idx] + "\n")
+		}
+	}
+	b.WriteString("\n" + faint.Render("  J/K move • ↑/↓ select • enter save • esc cancel"))
+	return b.String()
+}
+
+// PickOrder lets the user reorder labels with J/K and returns the new order
+// as indexes into labels. ok is false if the user cancelled.
+func PickOrder(title string, labels []string) (order []int, ok bool, err error) {
+	final, err := runProgram(newReorderModel(title, labels))
+	if err != nil {
+		return nil, false, fmt.Errorf("running reorder view: %w", err)
+	}
+	m := final.(reorderModel)
+	return m.order, m.confirmed, nil
+}
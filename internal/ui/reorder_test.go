@@ -0,0 +1,19 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoveItem(t *testing.T) {
+	s := []string{"a", "b", "c", "d"}
+	if j := moveItem(s, 0, 2); j != 2 || !reflect.DeepEqual(s, []string{"b", "c", "a", "d"}) {
+		t.Errorf("move down: index %d, slice %v", j, s)
+	}
+	if j := moveItem(s, 3, -1); j != 2 || !reflect.DeepEqual(s, []string{"b", "c", "d", "a"}) {
+		t.Errorf("move up: index %d, slice %v", j, s)
+	}
+	if j := moveItem(s, 0, -1); j != 0 || !reflect.DeepEqual(s, []string{"b", "c", "d", "a"}) {
+		t.Errorf("move past start should be a no-op: index %d, slice %v", j, s)
+	}
+}
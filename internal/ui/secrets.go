@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// defaultSecretKeys are the words that mark a key's value as secret.
+var defaultSecretKeys = []string{"token", "password", "secret", "key"}
+
+// secretKeys is the active list, see SetSecretKeys.
+var secretKeys = defaultSecretKeys
+
+// SetSecretKeys replaces the words that mark a value as secret in previews;
+// nil restores the defaults (token, password, secret, key).
+func SetSecretKeys(words []string) {
+	if len(words) == 0 {
+		secretKeys = defaultSecretKeys
+		return
+	}
+	secretKeys = make([]string, len(words))
+	for i, w := range words {
+		secretKeys[i] = strings.ToLower(w)
+	}
+}
+
+// isSecretKey reports whether a word of key is one of the secret words.
+// Words are split at punctuation and camelCase humps, so GITHUB_TOKEN and
+// apiKey match but keybindings doesn't.
+func isSecretKey(key string) bool {
+	for _, word := range keyWords(key) {
+		for _, secret := range secretKeys {
+			if word == secret {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func keyWords(key string) []string {
+	var words []string
+	var word []rune
+	runes := []rune(key)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, strings.ToLower(string(word)))
+				word = word[:0]
+			}
+			continue
+		}
+		// Split "apiKey" before K, and "APIKey" before K as well.
+		hump := unicode.IsLower(runes[max(i-1, 0)]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[max(i-1, 0)])
+		if unicode.IsUpper(r) && len(word) > 0 && hump {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, strings.ToLower(string(word)))
+	}
+	return words
+}
+
+// maskedValue is shown in previews instead of a secret string value.
+const maskedValue = `"****"`
+
+// maskSecret returns the JSON value token val with a secret string replaced
+// by maskedValue, keeping a trailing comma. References such as
+// "${localEnv:TOKEN}" reveal nothing and are kept.
+func maskSecret(val string) string {
+	bare := strings.TrimSuffix(val, ",")
+	if !strings.HasPrefix(bare, `"`) || bare == `""` {
+		return val
+	}
+	if strings.HasPrefix(bare, `"${`) && strings.HasSuffix(bare, `}"`) {
+		return val
+	}
+	return maskedValue + val[len(bare):]
+}
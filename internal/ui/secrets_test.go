@@ -0,0 +1,57 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsSecretKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"GITHUB_TOKEN":     true,
+		"apiKey":           true,
+		"APIKey":           true,
+		"db-password":      true,
+		"CLIENT_SECRET":    true,
+		"keybindings":      false,
+		"TOKENIZER_MODEL":  false,
+		"NODE_ENV":         false,
+		"workspaceFolder":  false,
+		"npm.registry.key": true,
+	} {
+		if got := isSecretKey(key); got != want {
+			t.Errorf("isSecretKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+
+	SetSecretKeys([]string{"PIN"})
+	t.Cleanup(func() { SetSecretKeys(nil) })
+	if !isSecretKey("SIM_PIN") || isSecretKey("GITHUB_TOKEN") {
+		t.Error("SetSecretKeys did not replace the default words")
+	}
+}
+
+func TestPreviewMasksSecrets(t *testing.T) {
+	preview := colorizeJSON(`{
+  "containerEnv": {
+    "API_TOKEN": "ghp_abc123",
+    "GH_TOKEN": "${localEnv:GH_TOKEN}",
+    "NODE_ENV": "development"
+  },
+  "build": {
+    "args": {
+      "NPM_PASSWORD": "hunter2",
+      "RETRIES": 3
+    }
+  }
+}`)
+	for _, leaked := range []string{"ghp_abc123", "hunter2"} {
+		if strings.Contains(preview, leaked) {
+			t.Errorf("preview shows secret %q:\n%s", leaked, preview)
+		}
+	}
+	for _, kept := range []string{`"****",`, `"****"`, "${localEnv:GH_TOKEN}", "development", "3"} {
+		if !strings.Contains(preview, kept) {
+			t.Errorf("preview lacks %q:\n%s", kept, preview)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mattn/go-runewidth"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+// maxConflictValue is how much of a conflicting value is shown.
+const maxConflictValue = 200
+
+// ResolveTemplateConflicts asks, for each value both the config and the
+// applied template changed, which of the two to keep. It returns the
+// conflicts to resolve with the template's value; aborting keeps the
+// config's everywhere.
+func ResolveTemplateConflicts(conflicts []devcontainer.Conflict) ([]devcontainer.Conflict, error) {
+	useTemplate := make([]bool, len(conflicts))
+	groups := make([]*huh.Group, len(conflicts))
+	for i, c := range conflicts {
+		groups[i] = huh.NewGroup(
+			huh.NewSelect[bool]().
+				Title(fmt.Sprintf("Conflict %d/%d: %s", i+1, len(conflicts), joinKeyPath(c.Path))).
+				Description(fmt.Sprintf("Yours:     %s\nTemplate:  %s", conflictValue(c.Mine), conflictValue(c.Theirs))).
+				Options(
+					huh.NewOption("Keep yours", false),
+					huh.NewOption("Use the template's", true),
+				).
+				Value(&useTemplate[i]),
+		)
+	}
+	err := NewForm(groups...).Run()
+	if errors.Is(err, huh.ErrUserAborted) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("resolving template conflicts: %w", err)
+	}
+
+	var resolved []devcontainer.Conflict
+	for i, c := range conflicts {
+		if useTemplate[i] {
+			resolved = append(resolved, c)
+		}
+	}
+	return resolved, nil
+}
+
+// conflictValue renders one side of a conflict as compact JSON.
+func conflictValue(v any) string {
+	if v == nil {
+		return "(removed)"
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return runewidth.Truncate(string(data), maxConflictValue, "...")
+}
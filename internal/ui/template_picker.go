@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/mochlast/devcontainer-companion/internal/catalog"
 )
@@ -63,28 +64,49 @@ func (d templateDelegate) Render(w io.Writer, m list.Model, index int, listItem
 	descStyle := lipgloss.NewStyle().PaddingLeft(4).Faint(true)
 
 	if isSelected {
-		titleStyle = titleStyle.Bold(true).Foreground(lipgloss.Color("170"))
-		descStyle = descStyle.Foreground(lipgloss.Color("170"))
+		titleStyle = titleStyle.Bold(true).Foreground(theme.Accent)
+		descStyle = descStyle.Foreground(theme.Accent)
+	}
+
+	if !item.isEmpty {
+		titleMatches, descMatches := catalogMatches(m, index, item.entry.Name)
+		title = highlightMatches(title, titleMatches, titleStyle)
+		desc = highlightMatches(desc, descMatches, descStyle)
+	} else if m.FilterState() != list.Unfiltered {
+		title = highlightMatches(title, m.MatchesForItem(index), titleStyle)
+	}
+	if item.entry.Recent {
+		desc += recentBadge
+	}
+	if item.entry.Offline {
+		desc += offlineBadge
+	}
+
+	if isSelected {
 		title = "> " + title
 	} else {
 		title = "  " + title
 	}
 
 	maxW := m.Width()
-	fmt.Fprintf(w, "%s\n%s", titleStyle.MaxWidth(maxW).Render(title), descStyle.MaxWidth(maxW).Render(desc))
+	fmt.Fprintf(w, "%s\n%s", truncateWidth(titleStyle.Render(title), maxW), truncateWidth(descStyle.Render(desc), maxW))
 }
 
 // templatePickerModel is the bubbletea model for the template picker.
 type templatePickerModel struct {
-	list     list.Model
-	selected *templateItem
-	quitting bool
-	preview  readmePreview
-	width    int
-	height   int
+	list      list.Model
+	selected  *templateItem
+	quitting  bool
+	preview   readmePreview
+	filter    catalogFilter
+	artifacts artifactInfos
+	notice    string // see staleNotice
+	folder    string // workspace the template is applied to
+	width     int
+	height    int
 }
 
-func newTemplatePicker(entries []catalog.CatalogEntry) templatePickerModel {
+func newTemplatePicker(entries []catalog.CatalogEntry, folder string) templatePickerModel {
 	items := make([]list.Item, 0, len(entries)+1)
 
 	// Add empty template as first item
@@ -94,13 +116,14 @@ func newTemplatePicker(entries []catalog.CatalogEntry) templatePickerModel {
 		items = append(items, templateItem{entry: e})
 	}
 
+	artifacts := newArtifactInfos()
 	l := list.New(items, templateDelegate{}, 80, 20)
 	l.Title = "Select a devcontainer template"
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
 	l.Filter = officialFirstFilterFunc(items)
 	l.SetShowHelp(true)
-	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170")).MarginLeft(2)
+	l.Styles.Title = lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).MarginLeft(2)
 
 	// Rebind help toggle from ? to h to free ? for README preview
 	l.KeyMap.ShowFullHelp = key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "help"))
@@ -110,12 +133,18 @@ func newTemplatePicker(entries []catalog.CatalogEntry) templatePickerModel {
 	l.AdditionalShortHelpKeys = func() []key.Binding {
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("?"), key.WithHelp("?", "README")),
+			key.NewBinding(key.WithKeys("ctrl+f"), key.WithHelp("ctrl+f", "files")),
 		}
 	}
+	l.AdditionalFullHelpKeys = catalogFilterKeys
 
 	return templatePickerModel{
-		list:    l,
-		preview: newReadmePreview(),
+		list:      l,
+		preview:   newReadmePreview(),
+		filter:    newCatalogFilter(items, l.Title),
+		artifacts: artifacts,
+		notice:    staleNotice(entries),
+		folder:    folder,
 	}
 }
 
@@ -125,17 +154,37 @@ func (m templatePickerModel) Init() tea.Cmd {
 
 func (m *templatePickerModel) applyLayout() {
 	if m.preview.visible {
-		listW := m.width / 3
+		listW := splitWidth(m.width)
 		m.list.SetWidth(listW)
-		m.list.SetHeight(m.height - 2)
+		m.list.SetHeight(m.height - 2 - extraNoticeLines(m.notice, listW))
 		m.preview.SetSize(m.width-listW, m.height-2)
 	} else {
 		m.list.SetWidth(m.width)
-		m.list.SetHeight(m.height - 2)
+		m.list.SetHeight(m.height - 2 - extraNoticeLines(m.notice, m.width))
 	}
 }
 
+// Update handles msg, shows the artifact info of the highlighted template in
+// the preview and then starts its artifact info fetch and README prefetch.
 func (m templatePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.update(msg)
+	picker := model.(templatePickerModel)
+	if picker.quitting {
+		return picker, cmd
+	}
+	ref, sourceURL := "", ""
+	if item, ok := picker.list.SelectedItem().(templateItem); ok && !item.isEmpty {
+		ref, sourceURL = FormatOciRefWithVersion(&item.entry), item.entry.SourceURL
+	}
+	picker.preview.SetInfo(artifactSummary(picker.artifacts.infos, ref))
+	// highlight and Prefetch update picker, so they run before it is
+	// returned.
+	highlight := picker.artifacts.highlight(ref)
+	prefetch := picker.preview.Prefetch(sourceURL, ref)
+	return picker, tea.Batch(cmd, highlight, prefetch)
+}
+
+func (m templatePickerModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -147,6 +196,12 @@ func (m templatePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.preview.HandleFetchResult(msg)
 		return m, nil
 
+	case artifactInfoDueMsg, artifactInfoFetchedMsg:
+		return m, m.artifacts.handle(msg)
+
+	case readmePrefetchDueMsg, readmePrefetchedMsg:
+		return m, m.preview.HandlePrefetch(msg)
+
 	case tea.KeyMsg:
 		// Don't intercept keys when already filtering
 		if m.list.FilterState() == list.Filtering {
@@ -155,14 +210,37 @@ func (m templatePickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		switch msg.String() {
 		case "?":
-			sourceURL := ""
+			sourceURL, ociRef := "", ""
 			if item, ok := m.list.SelectedItem().(templateItem); ok && !item.isEmpty {
 				sourceURL = item.entry.SourceURL
+				ociRef = FormatOciRefWithVersion(&item.entry)
 			}
-			cmd := m.preview.Toggle(sourceURL)
+			cmd := m.preview.Toggle(sourceURL, ociRef)
 			m.applyLayout()
 			return m, cmd
 
+		case "ctrl+f":
+			// List the files applying the template adds, marking the ones
+			// it would replace.
+			item, ok := m.list.SelectedItem().(templateItem)
+			if !ok || item.isEmpty {
+				return m, nil
+			}
+			cmd := m.preview.ToggleFiles(FormatOciRefWithVersion(&item.entry), m.folder)
+			m.applyLayout()
+			return m, cmd
+
+		case "ctrl+t", "ctrl+a":
+			if m.preview.visible {
+				return m, nil
+			}
+			if msg.String() == "ctrl+t" {
+				m.filter.nextCategory()
+			} else {
+				m.filter.toggleMaintainer(m.list.SelectedItem())
+			}
+			return m, m.filter.apply(&m.list)
+
 		case "esc":
 			if m.preview.visible {
 				m.preview.Close()
@@ -210,22 +288,22 @@ func (m templatePickerModel) View() string {
 	if m.quitting && m.selected != nil {
 		return ""
 	}
-	listView := "\n" + m.list.View()
+	listView := wrapNotice(m.notice, m.list.Width()) + "\n" + m.list.View()
 	if m.preview.visible {
-		listW := m.width / 3
+		listW := splitWidth(m.width)
 		clipped := lipgloss.NewStyle().Width(listW).MaxWidth(listW).Render(listView)
 		return lipgloss.JoinHorizontal(lipgloss.Top, clipped, m.preview.View())
 	}
 	return listView
 }
 
-// PickTemplate shows a fuzzy-finder to select a devcontainer template.
+// PickTemplate shows a fuzzy-finder to select a devcontainer template to
+// apply to the workspace at folder.
 // Returns the selected CatalogEntry, or nil if "Empty Template" was chosen.
 // Returns an error if the user quit without selecting.
-func PickTemplate(entries []catalog.CatalogEntry) (*catalog.CatalogEntry, error) {
-	m := newTemplatePicker(entries)
-	p := tea.NewProgram(m, tea.WithAltScreen())
-	finalModel, err := p.Run()
+func PickTemplate(entries []catalog.CatalogEntry, folder string) (*catalog.CatalogEntry, error) {
+	m := newTemplatePicker(entries, folder)
+	finalModel, err := runProgram(m)
 	if err != nil {
 		return nil, fmt.Errorf("running template picker: %w", err)
 	}
@@ -250,3 +328,27 @@ func FormatOciRefWithVersion(entry *catalog.CatalogEntry) string {
 	}
 	return ref
 }
+
+// PickTemplateMode asks whether a template replaces the configured base or is
+// layered on top of it. It returns true for layering, or ErrPickerCancelled.
+func PickTemplateMode() (bool, error) {
+	mode := "replace"
+	form := NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Apply template").
+			Description("Features, extensions and other settings are kept either way").
+			Options(
+				huh.NewOption("Replace the base (image, build, compose)", "replace"),
+				huh.NewOption("Layer on top, keeping the current base", "layer"),
+				huh.NewOption("Cancel", ""),
+			).
+			Value(&mode),
+	))
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("picking template mode: %w", err)
+	}
+	if mode == "" {
+		return false, ErrPickerCancelled
+	}
+	return mode == "layer", nil
+}
@@ -0,0 +1,199 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// Theme is the palette every component draws with.
+type Theme struct {
+	Accent  lipgloss.TerminalColor // titles, borders, selected items
+	Success lipgloss.TerminalColor
+	Warning lipgloss.TerminalColor
+	Error   lipgloss.TerminalColor
+
+	// Config preview.
+	JSONKey    lipgloss.TerminalColor
+	JSONString lipgloss.TerminalColor
+	JSONNumber lipgloss.TerminalColor
+	JSONBool   lipgloss.TerminalColor
+
+	plain bool // no colors at all, set by DisableColor
+}
+
+// Themes are the palettes selectable with --theme.
+var Themes = map[string]Theme{
+	"default": {
+		Accent:     lipgloss.Color("170"),
+		Success:    lipgloss.Color("10"),
+		Warning:    lipgloss.Color("11"),
+		Error:      lipgloss.Color("9"),
+		JSONKey:    lipgloss.Color("12"),
+		JSONString: lipgloss.Color("10"),
+		JSONNumber: lipgloss.Color("13"),
+		JSONBool:   lipgloss.Color("11"),
+	},
+	// colorblind uses the Okabe-Ito palette: success and failure differ in
+	// hue and brightness (bluish green vs. vermillion), never red vs. green.
+	"colorblind": {
+		Accent:     lipgloss.AdaptiveColor{Light: "#0072B2", Dark: "#56B4E9"},
+		Success:    lipgloss.Color("#009E73"),
+		Warning:    lipgloss.AdaptiveColor{Light: "#E69F00", Dark: "#F0E442"},
+		Error:      lipgloss.Color("#D55E00"),
+		JSONKey:    lipgloss.AdaptiveColor{Light: "#0072B2", Dark: "#56B4E9"},
+		JSONString: lipgloss.Color("#009E73"),
+		JSONNumber: lipgloss.Color("#CC79A7"),
+		JSONBool:   lipgloss.Color("#E69F00"),
+	},
+}
+
+// plainTheme keeps bold and faint text but no colors.
+var plainTheme = Theme{
+	Accent:     lipgloss.NoColor{},
+	Success:    lipgloss.NoColor{},
+	Warning:    lipgloss.NoColor{},
+	Error:      lipgloss.NoColor{},
+	JSONKey:    lipgloss.NoColor{},
+	JSONString: lipgloss.NoColor{},
+	JSONNumber: lipgloss.NoColor{},
+	JSONBool:   lipgloss.NoColor{},
+	plain:      true,
+}
+
+// theme is the active palette, themeName its key in Themes.
+var (
+	theme     = Themes["default"]
+	themeName = "default"
+)
+
+func init() { applyTheme(theme) }
+
+// ThemeNames lists the selectable themes, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetTheme switches to the named palette. It has no effect after
+// DisableColor.
+func SetTheme(name string) error {
+	t, ok := Themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	if !theme.plain {
+		themeName = name
+		applyTheme(t)
+	}
+	return nil
+}
+
+// DisableColor turns off colors everywhere, including forms and READMEs.
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+	applyTheme(plainTheme)
+}
+
+// NoColorRequested reports whether the NO_COLOR convention asks for plain
+// output (https://no-color.org).
+func NoColorRequested() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// ActiveTheme returns the palette in use.
+func ActiveTheme() Theme { return theme }
+
+// Styles built from the active theme.
+var (
+	previewSuccessStyle lipgloss.Style
+	previewWarnStyle    lipgloss.Style
+	previewBusyStyle    lipgloss.Style
+	previewHintStyle    lipgloss.Style
+	previewDetailStyle  lipgloss.Style
+
+	jsonKeyStyle  lipgloss.Style
+	jsonStrStyle  lipgloss.Style
+	jsonNumStyle  lipgloss.Style
+	jsonBoolStyle lipgloss.Style
+	jsonNullStyle lipgloss.Style
+	jsonBadStyle  lipgloss.Style
+	jsonNoteStyle lipgloss.Style
+
+	searchErrStyle     lipgloss.Style
+	searchMatchStyle   lipgloss.Style
+	searchCurrentStyle lipgloss.Style
+)
+
+func applyTheme(t Theme) {
+	theme = t
+
+	previewSuccessStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Success).PaddingLeft(1).PaddingTop(1)
+	previewWarnStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Warning).PaddingLeft(1).PaddingTop(1)
+	previewBusyStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Accent).PaddingLeft(1).PaddingTop(1)
+	previewHintStyle = lipgloss.NewStyle().Faint(true).PaddingLeft(1)
+	previewDetailStyle = lipgloss.NewStyle().PaddingLeft(1).Foreground(t.Error)
+
+	jsonKeyStyle = lipgloss.NewStyle().Foreground(t.JSONKey)
+	jsonStrStyle = lipgloss.NewStyle().Foreground(t.JSONString)
+	jsonNumStyle = lipgloss.NewStyle().Foreground(t.JSONNumber)
+	jsonBoolStyle = lipgloss.NewStyle().Foreground(t.JSONBool)
+	jsonNullStyle = lipgloss.NewStyle().Faint(true)
+	jsonBadStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Error)
+	jsonNoteStyle = lipgloss.NewStyle().Faint(true).Italic(true)
+
+	searchErrStyle = lipgloss.NewStyle().Foreground(t.Error)
+	searchMatchStyle = lipgloss.NewStyle().Reverse(true)
+	searchCurrentStyle = lipgloss.NewStyle().Bold(true).Reverse(true).Foreground(t.Accent)
+}
+
+// NewForm is huh.NewForm styled with the active theme.
+func NewForm(groups ...*huh.Group) *huh.Form {
+	return huh.NewForm(groups...).WithTheme(formTheme())
+}
+
+// formTheme adapts huh's default theme to the active palette.
+func formTheme() *huh.Theme {
+	if theme.plain {
+		return huh.ThemeBase()
+	}
+	t := huh.ThemeCharm()
+	if themeName == "default" {
+		return t
+	}
+
+	f := &t.Focused
+	f.Title = f.Title.Foreground(theme.Accent)
+	f.NoteTitle = f.NoteTitle.Foreground(theme.Accent)
+	f.Directory = f.Directory.Foreground(theme.Accent)
+	f.ErrorIndicator = f.ErrorIndicator.Foreground(theme.Error)
+	f.ErrorMessage = f.ErrorMessage.Foreground(theme.Error)
+	f.SelectSelector = f.SelectSelector.Foreground(theme.Accent)
+	f.NextIndicator = f.NextIndicator.Foreground(theme.Accent)
+	f.PrevIndicator = f.PrevIndicator.Foreground(theme.Accent)
+	f.MultiSelectSelector = f.MultiSelectSelector.Foreground(theme.Accent)
+	f.SelectedOption = f.SelectedOption.Foreground(theme.Success)
+	f.SelectedPrefix = f.SelectedPrefix.Foreground(theme.Success)
+	f.FocusedButton = f.FocusedButton.Background(theme.Accent)
+	f.Next = f.FocusedButton
+	f.TextInput.Cursor = f.TextInput.Cursor.Foreground(theme.Success)
+	f.TextInput.Prompt = f.TextInput.Prompt.Foreground(theme.Accent)
+
+	b := t.Blurred.Base
+	t.Blurred = t.Focused
+	t.Blurred.Base = b
+	t.Blurred.Card = b
+	t.Blurred.NextIndicator = lipgloss.NewStyle()
+	t.Blurred.PrevIndicator = lipgloss.NewStyle()
+	t.Group.Title = f.Title
+	return t
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// restoreTheme resets the palette and color profile changed by a test.
+func restoreTheme(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		themeName = "default"
+		applyTheme(Themes["default"])
+	})
+}
+
+func TestSetTheme(t *testing.T) {
+	restoreTheme(t)
+
+	if err := SetTheme("solarized"); err == nil || !strings.Contains(err.Error(), "colorblind, default") {
+		t.Errorf("SetTheme(unknown) = %v, want an error listing the themes", err)
+	}
+
+	if err := SetTheme("colorblind"); err != nil {
+		t.Fatal(err)
+	}
+	if jsonBadStyle.GetForeground() != Themes["colorblind"].Error {
+		t.Errorf("styles not rebuilt: jsonBadStyle foreground = %v", jsonBadStyle.GetForeground())
+	}
+	if formTheme().Focused.Title.GetForeground() != Themes["colorblind"].Accent {
+		t.Error("form titles don't use the theme accent")
+	}
+}
+
+func TestDisableColor(t *testing.T) {
+	restoreTheme(t)
+	DisableColor()
+
+	if _, ok := previewWarnStyle.GetForeground().(lipgloss.NoColor); !ok {
+		t.Errorf("previewWarnStyle foreground = %v, want NoColor", previewWarnStyle.GetForeground())
+	}
+	if got := jsonKeyStyle.Render("image"); got != "image" {
+		t.Errorf("rendered %q, want plain text", got)
+	}
+
+	// A theme chosen afterwards, e.g. from the preferences, doesn't bring
+	// colors back.
+	if err := SetTheme("colorblind"); err != nil {
+		t.Fatal(err)
+	}
+	if !theme.plain {
+		t.Error("SetTheme re-enabled colors after DisableColor")
+	}
+	if formTheme().Focused.Title.GetForeground() != huh.ThemeBase().Focused.Title.GetForeground() {
+		t.Error("forms are still themed")
+	}
+}
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+)
+
+// PickVersion asks which published tag of a template or feature to use.
+// tags are expected newest first; the preselected option is current when it
+// is among them, otherwise the newest tag.
+func PickVersion(name string, tags []string, current string) (string, error) {
+	if len(tags) == 0 {
+		return current, nil
+	}
+
+	selected := tags[0]
+	opts := make([]huh.Option[string], len(tags))
+	for i, tag := range tags {
+		label := tag
+		if i == 0 {
+			label += " (latest)"
+		}
+		opts[i] = huh.NewOption(label, tag)
+		if tag == current {
+			selected = tag
+		}
+	}
+
+	form := NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title(fmt.Sprintf("%s version", name)).
+			Options(opts...).
+			Height(min(len(opts)+2, 12)).
+			Value(&selected),
+	))
+	if err := form.Run(); err != nil {
+		return "", fmt.Errorf("picking version: %w", err)
+	}
+	return selected, nil
+}
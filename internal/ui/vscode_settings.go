@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/tidwall/jsonc"
+)
+
+// EditVSCodeSettings opens customizations.vscode.settings as pretty-printed
+// JSON in a text field and writes the edited object back. Invalid JSON keeps
+// the form open with the parse error so edits are never lost.
+func EditVSCodeSettings(absFolder string) error {
+	config, configPath, err := devcontainer.ReadConfig(absFolder)
+	if err != nil {
+		return err
+	}
+
+	current := vscodeSettings(config)
+	val, err := formatSettingsJSON(current)
+	if err != nil {
+		return err
+	}
+
+	form := NewForm(huh.NewGroup(
+		huh.NewText().
+			Title("VS Code Settings").
+			Description("JSON object written to customizations.vscode.settings. Clear to remove.").
+			Lines(16).
+			CharLimit(0).
+			Validate(func(s string) error {
+				_, err := parseSettingsJSON(s)
+				return err
+			}).
+			Value(&val),
+	))
+	if err := form.Run(); err != nil {
+		return fmt.Errorf("editing VS Code settings: %w", err)
+	}
+
+	settings, err := parseSettingsJSON(val)
+	if err != nil {
+		return err
+	}
+	if (len(settings) == 0 && len(current) == 0) || reflect.DeepEqual(settings, current) {
+		return nil
+	}
+
+	setVSCodeSettings(config, settings)
+	return devcontainer.WriteConfig(configPath, config)
+}
+
+// vscodeSettings returns customizations.vscode.settings, or nil if unset.
+func vscodeSettings(config map[string]any) map[string]any {
+	settings, _ := devcontainer.Customization(config, "vscode")["settings"].(map[string]any)
+	return settings
+}
+
+// setVSCodeSettings stores settings under customizations.vscode, removing the
+// key (and any parents left empty) when settings is empty.
+func setVSCodeSettings(config map[string]any, settings map[string]any) {
+	if len(settings) == 0 {
+		devcontainer.SetCustomization(config, "vscode", "settings", nil)
+		return
+	}
+	devcontainer.SetCustomization(config, "vscode", "settings", settings)
+}
+
+func formatSettingsJSON(settings map[string]any) (string, error) {
+	if len(settings) == 0 {
+		return "{\n}", nil
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("formatting VS Code settings: %w", err)
+	}
+	return string(data), nil
+}
+
+// parseSettingsJSON parses the edited text. Blank input means no settings;
+// anything else must be a JSON object. Comments and trailing commas are
+// accepted, as in devcontainer.json itself.
+func parseSettingsJSON(s string) (map[string]any, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	var settings map[string]any
+	if err := json.Unmarshal(jsonc.ToJSON([]byte(s)), &settings); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("settings must be a JSON object")
+		}
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if settings == nil {
+		return nil, fmt.Errorf("settings must be a JSON object")
+	}
+	return settings, nil
+}
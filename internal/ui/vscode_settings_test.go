@@ -0,0 +1,55 @@
+package ui
+
+import "testing"
+
+func TestParseSettingsJSON(t *testing.T) {
+	settings, err := parseSettingsJSON(`{
+  // editor
+  "editor.tabSize": 2,
+  "files.eol": "\n",
+}`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if settings["editor.tabSize"] != float64(2) || settings["files.eol"] != "\n" {
+		t.Errorf("unexpected settings: %v", settings)
+	}
+
+	if settings, err := parseSettingsJSON("  \n"); err != nil || settings != nil {
+		t.Errorf("blank input = %v, %v; want nil, nil", settings, err)
+	}
+
+	for _, bad := range []string{`{"a": }`, `[1, 2]`, `null`, `"x"`} {
+		if _, err := parseSettingsJSON(bad); err == nil {
+			t.Errorf("parseSettingsJSON(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestSetVSCodeSettings(t *testing.T) {
+	config := map[string]any{
+		"customizations": map[string]any{
+			"vscode": map[string]any{"extensions": []any{"golang.go"}},
+		},
+	}
+
+	setVSCodeSettings(config, map[string]any{"editor.tabSize": float64(4)})
+	if got := vscodeSettings(config); got["editor.tabSize"] != float64(4) {
+		t.Fatalf("settings not stored: %v", config)
+	}
+
+	setVSCodeSettings(config, nil)
+	vscode := config["customizations"].(map[string]any)["vscode"].(map[string]any)
+	if _, ok := vscode["settings"]; ok {
+		t.Errorf("settings not removed: %v", vscode)
+	}
+	if _, ok := vscode["extensions"]; !ok {
+		t.Errorf("extensions dropped: %v", vscode)
+	}
+
+	empty := map[string]any{"customizations": map[string]any{"vscode": map[string]any{"settings": map[string]any{"a": true}}}}
+	setVSCodeSettings(empty, nil)
+	if _, ok := empty["customizations"]; ok {
+		t.Errorf("empty customizations not removed: %v", empty)
+	}
+}
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// truncateWidth cuts s, which may be styled, to at most width terminal
+// cells. Cells are counted per grapheme by go-runewidth, so CJK characters
+// and emoji take two, as do East Asian ambiguous characters in a CJK
+// locale. A wide glyph that doesn't fit is dropped whole, and escape
+// sequences are kept so styles are still reset.
+func truncateWidth(s string, width int) string {
+	if runewidth.StringWidth(stripEscapes(s)) <= width {
+		return s
+	}
+	var b strings.Builder
+	remaining := width
+	full := false
+	for s != "" {
+		if n := escapeLen(s); n > 0 {
+			b.WriteString(s[:n])
+			s = s[n:]
+			continue
+		}
+		text := s
+		if i := strings.IndexByte(s, '\x1b'); i > 0 {
+			text = s[:i]
+		}
+		s = s[len(text):]
+		if full {
+			continue
+		}
+		kept := runewidth.Truncate(text, remaining, "")
+		b.WriteString(kept)
+		remaining -= runewidth.StringWidth(kept)
+		full = len(kept) < len(text)
+	}
+	return b.String()
+}
+
+// stripEscapes removes the escape sequences from s.
+func stripEscapes(s string) string {
+	if !strings.Contains(s, "\x1b") {
+		return s
+	}
+	var b strings.Builder
+	for s != "" {
+		if n := escapeLen(s); n > 0 {
+			s = s[n:]
+			continue
+		}
+		b.WriteByte(s[0])
+		s = s[1:]
+	}
+	return b.String()
+}
+
+// escapeLen returns the length of the escape sequence s starts with, or 0:
+// CSI sequences such as colors up to their final byte, OSC sequences such as
+// hyperlinks up to BEL or ST, other escapes with the byte after ESC.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' {
+		return 0
+	}
+	switch s[1] {
+	case '[':
+		for i := 2; i < len(s); i++ {
+			if s[i] >= 0x40 && s[i] <= 0x7e {
+				return i + 1
+			}
+		}
+	case ']':
+		for i := 2; i < len(s); i++ {
+			if s[i] == '\a' {
+				return i + 1
+			}
+			if s[i] == '\x1b' && i+1 < len(s) && s[i+1] == '\\' {
+				return i + 2
+			}
+		}
+	default:
+		return 2
+	}
+	return len(s)
+}
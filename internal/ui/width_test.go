@@ -0,0 +1,76 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/mattn/go-runewidth"
+
+	"github.com/mochlast/devcontainer-companion/internal/catalog"
+)
+
+func TestTruncateWidth(t *testing.T) {
+	tests := []struct {
+		s     string
+		width int
+		want  string
+	}{
+		{"golang.go", 20, "golang.go"},
+		{"golang.go", 6, "golang"},
+		{"日本語テキスト", 14, "日本語テキスト"},
+		{"日本語テキスト", 5, "日本"}, // 語 would need a sixth cell
+		{"日本語テキスト", 6, "日本語"},
+		{"🐳 Docker in Docker", 4, "🐳 D"},
+		{"👩‍💻 dev", 3, "👩‍💻 "}, // one grapheme, two cells
+		{"\x1b[1m日本語\x1b[0m tools", 3, "\x1b[1m日\x1b[0m"},
+		{"\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\", 2, "\x1b]8;;https://example.com\x1b\\li\x1b]8;;\x1b\\"},
+	}
+	for _, tt := range tests {
+		if got := truncateWidth(tt.s, tt.width); got != tt.want {
+			t.Errorf("truncateWidth(%q, %d) = %q, want %q", tt.s, tt.width, got, tt.want)
+		}
+	}
+}
+
+func TestTruncateWidthEastAsianLocale(t *testing.T) {
+	defer func(v bool) { runewidth.DefaultCondition.EastAsianWidth = v }(runewidth.DefaultCondition.EastAsianWidth)
+
+	runewidth.DefaultCondition.EastAsianWidth = false
+	if got := truncateWidth("a→b", 2); got != "a→" {
+		t.Errorf("narrow locale: truncateWidth = %q, want %q", got, "a→")
+	}
+	runewidth.DefaultCondition.EastAsianWidth = true
+	if got := truncateWidth("a→b", 2); got != "a" {
+		t.Errorf("CJK locale: truncateWidth = %q, want %q", got, "a")
+	}
+}
+
+func TestFeatureDelegateWideText(t *testing.T) {
+	const width = 20
+	items := []list.Item{featureItem{entry: catalog.CatalogEntry{
+		Name:       "データベース初期化ツール",
+		Maintainer: "開発チーム",
+		OciRef:     "ghcr.io/例/features/db",
+	}}}
+	d := featureDelegate{selectedItems: map[string]bool{}}
+	m := list.New(items, d, width, 10)
+
+	var buf bytes.Buffer
+	d.Render(&buf, m, 1, items[0]) // not the active item
+	lines := strings.Split(stripEscapes(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Render wrote %d lines, want 2", len(lines))
+	}
+	// Over-truncation would leave a cell we could have filled with a
+	// narrow glyph; the wide glyph that doesn't fit may leave one.
+	for _, line := range lines {
+		if w := runewidth.StringWidth(line); w > width || w < width-1 {
+			t.Errorf("line %q is %d cells wide, want %d or one less", line, w, width)
+		}
+	}
+	if want := "  [ ] データベース初"; lines[0] != want {
+		t.Errorf("title line = %q, want %q", lines[0], want)
+	}
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/mochlast/devcontainer-companion/internal/mountspec"
+)
+
+// workspaceKeys are the top-level keys owned by the workspace setting.
+var workspaceKeys = []string{"workspaceMount", "workspaceFolder"}
+
+// workspaceSpec holds workspaceMount, parsed, and workspaceFolder.
+type workspaceSpec struct {
+	Mount  mountspec.Spec
+	Folder string
+}
+
+// readWorkspace reads workspaceMount and workspaceFolder for editing.
+func readWorkspace(config map[string]any) workspaceSpec {
+	return workspaceSpec{
+		Mount:  mountspec.Parse(getString(config, "workspaceMount")),
+		Folder: getString(config, "workspaceFolder"),
+	}
+}
+
+// mount returns workspaceMount as written: "" without source and target,
+// and a bind mount unless another type is given.
+func (s workspaceSpec) mount() string {
+	m := s.Mount
+	m.Source = strings.TrimSpace(m.Source)
+	m.Target = strings.TrimSpace(m.Target)
+	if m.Source == "" && m.Target == "" {
+		return ""
+	}
+	if m.Type == "" {
+		m.Type = "bind"
+	}
+	return m.String()
+}
+
+// writeWorkspace stores spec, removing blank keys.
+func writeWorkspace(config map[string]any, spec workspaceSpec) {
+	setString(config, "workspaceMount", spec.mount())
+	setString(config, "workspaceFolder", strings.TrimSpace(spec.Folder))
+}
+
+// workspaceWarning explains why spec likely doesn't do what was meant, or
+// returns "". The two keys usually go together: the mount decides where the
+// sources end up, the folder where tools open.
+func workspaceWarning(spec workspaceSpec, compose bool) string {
+	mount, folder := spec.mount() != "", strings.TrimSpace(spec.Folder) != ""
+	switch {
+	case compose && mount:
+		return "workspaceMount is ignored with Docker Compose; mount the workspace in the compose file."
+	case compose:
+		return ""
+	case mount && !folder:
+		return "workspaceMount is set without workspaceFolder: tools open the default folder, not the mount target."
+	case folder && !mount:
+		return "workspaceFolder is set without workspaceMount: the sources stay mounted at the default path."
+	}
+	return ""
+}
+
+func checkWorkspaceMount(spec *workspaceSpec) func(string) error {
+	return func(target string) error {
+		if strings.TrimSpace(spec.Mount.Source) != "" && strings.TrimSpace(target) == "" {
+			return fmt.Errorf("target is required when a source is set")
+		}
+		return nil
+	}
+}
+
+// editWorkspaceField edits where the workspace is mounted and opened.
+func editWorkspaceField(config map[string]any) (bool, error) {
+	before := readWorkspace(config)
+	spec := before
+	if spec.Mount.Type == "" {
+		spec.Mount.Type = "bind"
+	}
+	compose := readBase(config).Kind == baseCompose
+
+	form := NewForm(huh.NewGroup(
+		huh.NewNote().
+			Title("Workspace Mount & Folder").
+			DescriptionFunc(func() string {
+				desc := "Where the sources are mounted and where tools open them.\nClear a field to remove its key."
+				if warning := workspaceWarning(spec, compose); warning != "" {
+					desc += "\n\n⚠ " + warning
+				}
+				return desc
+			}, &spec),
+		huh.NewInput().
+			Title("Workspace Folder").
+			Description("Path tools open inside the container, e.g. /workspace").
+			Value(&spec.Folder),
+		huh.NewSelect[string]().
+			Title("Mount Type").
+			Description("bind for a host folder, volume for a named volume (faster on macOS)").
+			Options(mountTypeOptions(spec.Mount.Type, "bind", "volume")...).
+			Value(&spec.Mount.Type),
+		huh.NewInput().
+			Title("Mount Source").
+			Description("Host path or volume name, e.g. ${localWorkspaceFolder}").
+			Value(&spec.Mount.Source),
+		huh.NewInput().
+			Title("Mount Target").
+			Description("Path inside the container, usually the workspace folder").
+			Validate(checkWorkspaceMount(&spec)).
+			Value(&spec.Mount.Target),
+		huh.NewSelect[string]().
+			Title("Consistency").
+			Options(
+				huh.NewOption("default", ""),
+				huh.NewOption("cached", "cached"),
+				huh.NewOption("delegated", "delegated"),
+				huh.NewOption("consistent", "consistent"),
+			).
+			Value(&spec.Mount.Consistency),
+	))
+	if err := form.Run(); err != nil {
+		return false, fmt.Errorf("editing workspace: %w", err)
+	}
+
+	if spec.mount() == before.mount() && strings.TrimSpace(spec.Folder) == before.Folder {
+		return false, nil
+	}
+	writeWorkspace(config, spec)
+	return true, nil
+}
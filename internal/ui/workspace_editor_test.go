@@ -0,0 +1,88 @@
+package ui
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/mountspec"
+)
+
+func TestWorkspaceRoundTrip(t *testing.T) {
+	config := map[string]any{
+		"workspaceMount":  "source=${localWorkspaceFolder},target=/workspace,type=bind,consistency=cached,readonly",
+		"workspaceFolder": "/workspace",
+		"image":           "mcr.microsoft.com/devcontainers/base:ubuntu",
+	}
+	spec := readWorkspace(config)
+	if spec.Mount.Source != "${localWorkspaceFolder}" || spec.Mount.Target != "/workspace" || spec.Folder != "/workspace" {
+		t.Fatalf("readWorkspace = %+v", spec)
+	}
+
+	writeWorkspace(config, spec)
+	want := map[string]any{
+		"workspaceMount":  "type=bind,source=${localWorkspaceFolder},target=/workspace,consistency=cached,readonly",
+		"workspaceFolder": "/workspace",
+		"image":           "mcr.microsoft.com/devcontainers/base:ubuntu",
+	}
+	if !reflect.DeepEqual(config, want) {
+		t.Errorf("after round trip = %v, want %v", config, want)
+	}
+
+	// A named volume; the type defaults to bind only when none is chosen.
+	writeWorkspace(config, workspaceSpec{
+		Mount:  mountspec.Spec{Type: "volume", Source: "proj-src", Target: "/workspace"},
+		Folder: " /workspace ",
+	})
+	if got := config["workspaceMount"]; got != "type=volume,source=proj-src,target=/workspace" {
+		t.Errorf("workspaceMount = %v", got)
+	}
+	if got := config["workspaceFolder"]; got != "/workspace" {
+		t.Errorf("workspaceFolder = %v, want trimmed", got)
+	}
+
+	// Clearing both fields removes both keys.
+	writeWorkspace(config, workspaceSpec{Mount: mountspec.Spec{Type: "bind"}})
+	if _, ok := config["workspaceMount"]; ok {
+		t.Error("workspaceMount should be removed")
+	}
+	if _, ok := config["workspaceFolder"]; ok {
+		t.Error("workspaceFolder should be removed")
+	}
+}
+
+func TestWorkspaceWarning(t *testing.T) {
+	mount := mountspec.Spec{Source: "${localWorkspaceFolder}", Target: "/workspace"}
+	tests := []struct {
+		name    string
+		spec    workspaceSpec
+		compose bool
+		warn    bool
+	}{
+		{"neither", workspaceSpec{}, false, false},
+		{"both", workspaceSpec{Mount: mount, Folder: "/workspace"}, false, false},
+		{"mount only", workspaceSpec{Mount: mount}, false, true},
+		{"folder only", workspaceSpec{Folder: "/workspace"}, false, true},
+		{"compose folder", workspaceSpec{Folder: "/workspace"}, true, false},
+		{"compose mount", workspaceSpec{Mount: mount, Folder: "/workspace"}, true, true},
+	}
+	for _, tt := range tests {
+		if got := workspaceWarning(tt.spec, tt.compose); (got != "") != tt.warn {
+			t.Errorf("%s: workspaceWarning = %q, want warning %v", tt.name, got, tt.warn)
+		}
+	}
+}
+
+func TestCheckWorkspaceMount(t *testing.T) {
+	spec := workspaceSpec{}
+	check := checkWorkspaceMount(&spec)
+	if err := check(""); err != nil {
+		t.Errorf("blank mount: %v", err)
+	}
+	spec.Mount.Source = "${localWorkspaceFolder}"
+	if err := check(""); err == nil {
+		t.Error("source without target should fail")
+	}
+	if err := check("/workspace"); err != nil {
+		t.Errorf("source and target: %v", err)
+	}
+}
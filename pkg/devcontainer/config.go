@@ -0,0 +1,271 @@
+// Package devcontainer reads, edits and writes devcontainer.json files. It is
+// the supported library surface of dcc: the same parsing (JSONC), merging and
+// order-preserving writes the dcc binary uses, behind a Config type with
+// typed accessors.
+//
+//	cfg, path, err := devcontainer.Read(".")
+//	if err != nil {
+//		return err
+//	}
+//	cfg.SetFeature("ghcr.io/devcontainers/features/node:1", map[string]any{"version": "lts"})
+//	cfg.AddExtensions("dbaeumer.vscode-eslint")
+//	return cfg.Save(path)
+package devcontainer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+	"github.com/mochlast/devcontainer-companion/internal/marketplace"
+)
+
+// ValidationError is a schema violation found by Config.Validate.
+type ValidationError = devcontainer.ValidationError
+
+// Config is a devcontainer.json document. The zero value is not usable; use
+// New, Parse, Load or Read.
+type Config struct {
+	values   map[string]any
+	baseline []byte // contents it was parsed from, for key order and comments
+}
+
+// New returns an empty config.
+func New() *Config {
+	return &Config{values: make(map[string]any)}
+}
+
+// Parse parses devcontainer.json contents, which may contain comments and
+// trailing commas. Writing the config back keeps their key order and the
+// comments attached to keys.
+func Parse(data []byte) (*Config, error) {
+	var values map[string]any
+	if err := json.Unmarshal(devcontainer.ToJSON(data), &values); err != nil {
+		return nil, fmt.Errorf("parsing devcontainer.json: %w", err)
+	}
+	if values == nil {
+		values = make(map[string]any)
+	}
+	return &Config{values: values, baseline: data}, nil
+}
+
+// Load reads and parses the config file at path.
+func Load(path string) (*Config, error) {
+	data, err := devcontainer.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return Parse(data)
+}
+
+// Find returns the devcontainer.json of a workspace folder, probing the
+// locations the spec allows: .devcontainer/devcontainer.json,
+// .devcontainer.json, then .devcontainer/<name>/devcontainer.json. Without
+// any, it returns .devcontainer/devcontainer.json.
+func Find(workspaceFolder string) string {
+	return devcontainer.ConfigPath(workspaceFolder)
+}
+
+// Read loads the config of a workspace folder and returns it with its path.
+func Read(workspaceFolder string) (*Config, string, error) {
+	path := Find(workspaceFolder)
+	c, err := Load(path)
+	return c, path, err
+}
+
+// Map returns the underlying values. Changes to it change the config.
+func (c *Config) Map() map[string]any { return c.values }
+
+// Get returns the top-level value of key.
+func (c *Config) Get(key string) (any, bool) {
+	v, ok := c.values[key]
+	return v, ok
+}
+
+// Set sets the top-level key to v, which must marshal to JSON.
+func (c *Config) Set(key string, v any) { c.values[key] = v }
+
+// Delete removes the top-level key.
+func (c *Config) Delete(key string) { delete(c.values, key) }
+
+// Name returns the display name.
+func (c *Config) Name() string { return c.stringValue("name") }
+
+// SetName sets the display name.
+func (c *Config) SetName(name string) { c.values["name"] = name }
+
+// Image returns the image the container is created from, if it is not built
+// from a Dockerfile or Compose file.
+func (c *Config) Image() string { return c.stringValue("image") }
+
+// SetImage sets the image the container is created from.
+func (c *Config) SetImage(image string) { c.values["image"] = image }
+
+func (c *Config) stringValue(key string) string {
+	s, _ := c.values[key].(string)
+	return s
+}
+
+// Features returns the configured features by their ref, with a copy of
+// their options.
+func (c *Config) Features() map[string]map[string]any {
+	features, _ := c.values["features"].(map[string]any)
+	out := make(map[string]map[string]any, len(features))
+	for ref, v := range features {
+		opts := make(map[string]any)
+		if m, ok := v.(map[string]any); ok {
+			for k, v := range m {
+				opts[k] = v
+			}
+		}
+		out[ref] = opts
+	}
+	return out
+}
+
+// Feature returns the configured ref and options of the feature ref, which
+// matches at any version.
+func (c *Config) Feature(ref string) (configured string, options map[string]any, ok bool) {
+	id := devcontainer.FeatureID(ref)
+	for configured, options := range c.Features() {
+		if devcontainer.FeatureID(configured) == id {
+			return configured, options, true
+		}
+	}
+	return "", nil, false
+}
+
+// SetFeature configures the feature ref with options, replacing the same
+// feature at any other version together with its options.
+func (c *Config) SetFeature(ref string, options map[string]any) {
+	features, _ := c.values["features"].(map[string]any)
+	if features == nil {
+		features = make(map[string]any)
+		c.values["features"] = features
+	}
+	id := devcontainer.FeatureID(ref)
+	for configured := range features {
+		if devcontainer.FeatureID(configured) == id {
+			delete(features, configured)
+		}
+	}
+	opts := make(map[string]any, len(options))
+	for k, v := range options {
+		opts[k] = v
+	}
+	features[ref] = opts
+}
+
+// RemoveFeature removes the feature ref at any version and reports whether
+// it was configured.
+func (c *Config) RemoveFeature(ref string) bool {
+	features, _ := c.values["features"].(map[string]any)
+	id := devcontainer.FeatureID(ref)
+	removed := false
+	for configured := range features {
+		if devcontainer.FeatureID(configured) == id {
+			delete(features, configured)
+			removed = true
+		}
+	}
+	if removed && len(features) == 0 {
+		delete(c.values, "features")
+	}
+	return removed
+}
+
+// Extensions returns the VS Code extensions in customizations.vscode.extensions.
+func (c *Config) Extensions() []string {
+	custom, _ := c.values["customizations"].(map[string]any)
+	vscode, _ := custom["vscode"].(map[string]any)
+	list, _ := vscode["extensions"].([]any)
+	var ids []string
+	for _, v := range list {
+		if id, ok := v.(string); ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SetExtensions replaces the VS Code extensions, keeping their order. An
+// empty list removes the key, and customizations with it if nothing else is
+// left there.
+func (c *Config) SetExtensions(ids []string) {
+	custom, _ := c.values["customizations"].(map[string]any)
+	vscode, _ := custom["vscode"].(map[string]any)
+
+	if len(ids) == 0 {
+		delete(vscode, "extensions")
+		if vscode != nil && len(vscode) == 0 {
+			delete(custom, "vscode")
+		}
+		if custom != nil && len(custom) == 0 {
+			delete(c.values, "customizations")
+		}
+		return
+	}
+
+	if custom == nil {
+		custom = make(map[string]any)
+		c.values["customizations"] = custom
+	}
+	if vscode == nil {
+		vscode = make(map[string]any)
+		custom["vscode"] = vscode
+	}
+	list := make([]any, len(ids))
+	for i, id := range ids {
+		list[i] = id
+	}
+	vscode["extensions"] = list
+}
+
+// AddExtensions appends the VS Code extensions that aren't configured yet.
+// IDs are compared case-insensitively, as the Marketplace does.
+func (c *Config) AddExtensions(ids ...string) {
+	c.SetExtensions(marketplace.DedupeExtensions(append(c.Extensions(), ids...)))
+}
+
+// Merge merges overlay into c: objects merge key by key, arrays gain the
+// overlay's missing entries, other values take the overlay's, and an overlay
+// feature replaces the same feature at another version.
+func (c *Config) Merge(overlay *Config) {
+	c.values = devcontainer.Merge(c.values, overlay.values)
+}
+
+// Validate checks the config against the devcontainer.json schema.
+func (c *Config) Validate() []ValidationError {
+	return devcontainer.Validate(c.values)
+}
+
+// Bytes returns the config as 2-space indented JSON. Keys keep the order
+// and comments of the contents it was parsed from; new keys are appended in
+// alphabetical order.
+func (c *Config) Bytes() []byte {
+	return devcontainer.MarshalConfig(c.values, c.baseline, devcontainer.DefaultFormat)
+}
+
+// WriteTo writes Bytes to w.
+func (c *Config) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(c.Bytes())
+	return int64(n), err
+}
+
+// Save writes the config to path, creating its directory. Contents it
+// changes are backed up like WriteConfig does.
+func (c *Config) Save(path string) error {
+	data := c.Bytes()
+	if existing, err := devcontainer.ReadFile(path); err == nil && !bytes.Equal(existing, data) {
+		if err := devcontainer.BackupConfig(path); err != nil {
+			return err
+		}
+	}
+	if err := devcontainer.WriteFile(path, data); err != nil {
+		return err
+	}
+	c.baseline = data
+	return nil
+}
@@ -0,0 +1,156 @@
+package devcontainer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mochlast/devcontainer-companion/internal/devcontainer"
+)
+
+const sample = `{
+  // The app container
+  "name": "app",
+  "image": "mcr.microsoft.com/devcontainers/base:ubuntu",
+  "features": {
+    "ghcr.io/devcontainers/features/node:1": {"version": "18"},
+  },
+}`
+
+func TestConfigFeatures(t *testing.T) {
+	c, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Name() != "app" || c.Image() != "mcr.microsoft.com/devcontainers/base:ubuntu" {
+		t.Errorf("name %q, image %q", c.Name(), c.Image())
+	}
+
+	ref, opts, ok := c.Feature("ghcr.io/devcontainers/features/node")
+	if !ok || ref != "ghcr.io/devcontainers/features/node:1" || opts["version"] != "18" {
+		t.Errorf("Feature(node) = %q, %v, %v", ref, opts, ok)
+	}
+
+	c.SetFeature("ghcr.io/devcontainers/features/node:2", map[string]any{"version": "lts"})
+	c.SetFeature("ghcr.io/devcontainers/features/go:1", nil)
+	want := map[string]map[string]any{
+		"ghcr.io/devcontainers/features/node:2": {"version": "lts"},
+		"ghcr.io/devcontainers/features/go:1":   {},
+	}
+	if got := c.Features(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Features = %v, want %v", got, want)
+	}
+
+	if !c.RemoveFeature("ghcr.io/devcontainers/features/node:1") || c.RemoveFeature("ghcr.io/devcontainers/features/node") {
+		t.Error("RemoveFeature should remove node at any version once")
+	}
+	c.RemoveFeature("ghcr.io/devcontainers/features/go")
+	if _, ok := c.Get("features"); ok {
+		t.Error("removing the last feature keeps an empty features object")
+	}
+}
+
+func TestConfigExtensions(t *testing.T) {
+	c := New()
+	c.AddExtensions("golang.go", "ms-python.python")
+	c.AddExtensions("golang.go", "dbaeumer.vscode-eslint")
+	if got := c.Extensions(); !reflect.DeepEqual(got, []string{"golang.go", "ms-python.python", "dbaeumer.vscode-eslint"}) {
+		t.Errorf("Extensions = %v", got)
+	}
+
+	c.Map()["customizations"].(map[string]any)["vscode"].(map[string]any)["settings"] = map[string]any{"a": 1.0}
+	c.SetExtensions(nil)
+	want := map[string]any{"customizations": map[string]any{"vscode": map[string]any{"settings": map[string]any{"a": 1.0}}}}
+	if !reflect.DeepEqual(c.Map(), want) {
+		t.Errorf("after clearing extensions: %v, want settings kept", c.Map())
+	}
+	delete(c.Map()["customizations"].(map[string]any)["vscode"].(map[string]any), "settings")
+	c.SetExtensions(nil)
+	if len(c.Map()) != 0 {
+		t.Errorf("empty customizations kept: %v", c.Map())
+	}
+}
+
+func TestConfigBytesKeepsOrderAndComments(t *testing.T) {
+	c, err := Parse([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetName("renamed")
+	c.Set("forwardPorts", []any{3000})
+	out := string(c.Bytes())
+
+	if !strings.Contains(out, "// The app container") {
+		t.Errorf("comment lost:\n%s", out)
+	}
+	name, image, ports := strings.Index(out, `"name"`), strings.Index(out, `"image"`), strings.Index(out, `"forwardPorts"`)
+	if name > image || image > ports {
+		t.Errorf("keys out of order (new keys last):\n%s", out)
+	}
+	if _, err := Parse(c.Bytes()); err != nil {
+		t.Errorf("output doesn't parse: %v", err)
+	}
+
+	// The CLI's --indent doesn't change the library's layout.
+	devcontainer.SetFormat(devcontainer.Format{Indent: "\t"})
+	t.Cleanup(func() { devcontainer.SetFormat(devcontainer.DefaultFormat) })
+	if out := string(c.Bytes()); !strings.Contains(out, "\n  \"name\"") || strings.Contains(out, "\t") {
+		t.Errorf("Bytes() doesn't use 2-space indentation:\n%s", out)
+	}
+}
+
+func TestConfigSaveAndRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".devcontainer", "devcontainer.json")
+	c := New()
+	c.SetImage("mcr.microsoft.com/devcontainers/go:1")
+	c.SetExtensions([]string{"golang.go"})
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	read, gotPath, err := Read(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != path || read.Image() != c.Image() || !reflect.DeepEqual(read.Extensions(), []string{"golang.go"}) {
+		t.Errorf("Read = %q, %v", gotPath, read.Map())
+	}
+
+	if _, _, err := Read(t.TempDir()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Read without a config: %v, want a not-exist error", err)
+	}
+}
+
+func TestConfigMergeAndValidate(t *testing.T) {
+	c, _ := Parse([]byte(sample))
+	overlay := New()
+	overlay.SetFeature("ghcr.io/devcontainers/features/node:2", nil)
+	overlay.Set("forwardPorts", []any{8080.0})
+	c.Merge(overlay)
+
+	if ref, _, _ := c.Feature("ghcr.io/devcontainers/features/node"); ref != "ghcr.io/devcontainers/features/node:2" {
+		t.Errorf("merged node = %q, want the overlay version", ref)
+	}
+	if issues := c.Validate(); len(issues) != 0 {
+		t.Errorf("Validate = %v", issues)
+	}
+	c.Set("forwardPorts", "8080")
+	if issues := c.Validate(); len(issues) == 0 {
+		t.Error("a string forwardPorts passes validation")
+	}
+}
+
+func TestDefaultOptions(t *testing.T) {
+	got := DefaultOptions(map[string]OptionDefinition{
+		"version": {Type: "string", Default: "lts"},
+		"nvm":     {Type: "boolean", Default: true},
+		"extra":   {Type: "string"},
+	})
+	if want := map[string]any{"version": "lts", "nvm": true}; !reflect.DeepEqual(got, want) {
+		t.Errorf("DefaultOptions = %v, want %v", got, want)
+	}
+}
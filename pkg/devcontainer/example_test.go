@@ -0,0 +1,37 @@
+package devcontainer_test
+
+import (
+	"fmt"
+
+	"github.com/mochlast/devcontainer-companion/pkg/devcontainer"
+)
+
+func Example() {
+	cfg, err := devcontainer.Parse([]byte(`{
+  // Shared by the whole team
+  "name": "api",
+  "image": "mcr.microsoft.com/devcontainers/go:1"
+}`))
+	if err != nil {
+		panic(err)
+	}
+	cfg.SetFeature("ghcr.io/devcontainers/features/node:1", map[string]any{"version": "lts"})
+	cfg.AddExtensions("golang.go")
+	fmt.Print(string(cfg.Bytes()))
+	// Output:
+	// {
+	//   // Shared by the whole team
+	//   "name": "api",
+	//   "image": "mcr.microsoft.com/devcontainers/go:1",
+	//   "customizations": {
+	//     "vscode": {
+	//       "extensions": ["golang.go"]
+	//     }
+	//   },
+	//   "features": {
+	//     "ghcr.io/devcontainers/features/node:1": {
+	//       "version": "lts"
+	//     }
+	//   }
+	// }
+}
@@ -0,0 +1,61 @@
+package devcontainer
+
+import (
+	"fmt"
+
+	"github.com/mochlast/devcontainer-companion/internal/registry"
+)
+
+// Metadata published with templates and features in their OCI registry.
+type (
+	FeatureDefinition  = registry.FeatureDefinition
+	TemplateDefinition = registry.TemplateDefinition
+	OptionDefinition   = registry.OptionDefinition
+)
+
+// Errors FetchFeature and FetchTemplate may match, for errors.Is.
+var (
+	ErrNotFound     = registry.ErrNotFound
+	ErrUnauthorized = registry.ErrUnauthorized
+	ErrRateLimited  = registry.ErrRateLimited
+	ErrInvalidRef   = registry.ErrInvalidRef
+)
+
+// FetchFeature fetches the devcontainer-feature.json of the feature ref, e.g.
+// "ghcr.io/devcontainers/features/node:1". Results are cached for a day in
+// ~/.cache/dcc/oci, and the cache is used when the registry is unreachable.
+func FetchFeature(ref string) (*FeatureDefinition, error) {
+	_, feat, err := registry.FetchItemMetadata(ref, false)
+	if err != nil {
+		return nil, err
+	}
+	if feat == nil {
+		return nil, fmt.Errorf("%s is not a feature", ref)
+	}
+	return feat, nil
+}
+
+// FetchTemplate fetches the devcontainer-template.json of the template ref,
+// cached like FetchFeature.
+func FetchTemplate(ref string) (*TemplateDefinition, error) {
+	tmpl, _, err := registry.FetchItemMetadata(ref, false)
+	if err != nil {
+		return nil, err
+	}
+	if tmpl == nil {
+		return nil, fmt.Errorf("%s is not a template", ref)
+	}
+	return tmpl, nil
+}
+
+// DefaultOptions returns the default value of each option that has one, as
+// a starting point for SetFeature.
+func DefaultOptions(options map[string]OptionDefinition) map[string]any {
+	defaults := make(map[string]any, len(options))
+	for name, opt := range options {
+		if opt.Default != nil {
+			defaults[name] = opt.Default
+		}
+	}
+	return defaults
+}